@@ -11,6 +11,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -32,8 +33,19 @@ type ClusterSpec struct {
 	Replicas *int32 `json:"replicas,omitempty"`
 	// Resources used by each Redpanda container
 	// To calculate overall resource consumption one need to
-	// multiply replicas against limits
+	// multiply replicas against limits.
+	// All brokers share the same container spec, also across the zone
+	// StatefulSets, so the same requirements apply to every broker.
+	// Per-broker overrides are not supported.
 	Resources corev1.ResourceRequirements `json:"resources"`
+	// ResourcePreset sizes the Redpanda container by name. A resource set
+	// in either requests or limits of Resources is not taken from the
+	// preset. The presets set equal requests and limits:
+	// small - 1 CPU and 2Gi of memory,
+	// medium - 2 CPUs and 8Gi of memory,
+	// large - 4 CPUs and 16Gi of memory.
+	// +kubebuilder:validation:Enum=small;medium;large
+	ResourcePreset string `json:"resourcePreset,omitempty"`
 	// Configuration represent redpanda specific configuration
 	Configuration RedpandaConfig `json:"configuration,omitempty"`
 	// If specified, Redpanda Pod tolerations
@@ -41,24 +53,387 @@ type ClusterSpec struct {
 	// If specified, Redpanda Pod node selectors. For reference please visit
 	// https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// If DedicatedNodes is set to true, the brokers are scheduled only on
+	// nodes labeled with DedicatedNodesKey=true, and tolerate the
+	// DedicatedNodesKey=true:NoSchedule taint that keeps other workloads
+	// off these nodes. The selector and the toleration are added to
+	// NodeSelector and Tolerations.
+	DedicatedNodes bool `json:"dedicatedNodes,omitempty"`
+	// ZoneStatefulSets runs the brokers of every zone in a StatefulSet of
+	// its own, pinned to the zone. For more information please go to
+	// ZoneStatefulSetsConfig
+	ZoneStatefulSets *ZoneStatefulSetsConfig `json:"zoneStatefulSets,omitempty"`
+	// If VerifySchedulability is set to true, the operator lists the nodes
+	// and sets Unschedulable condition when none of them accepts the brokers
+	// with the configured NodeSelector and Tolerations. It's opt-in as the
+	// operator needs to read all nodes of the Kubernetes cluster.
+	VerifySchedulability bool `json:"verifySchedulability,omitempty"`
+	// If VerifyArchitecture is set to true, the operator lists the nodes and
+	// sets ArchitectureMismatch condition when the brokers can be scheduled
+	// on nodes of an architecture the image is not built for. The image
+	// architecture is derived from the -amd64 or -arm64 suffix of Version,
+	// other images are expected to be multi-arch, but the brokers of a
+	// cluster with nodes of several architectures are still expected to
+	// select one by the kubernetes.io/arch label in NodeSelector.
+	VerifyArchitecture bool `json:"verifyArchitecture,omitempty"`
+	// If VerifyResourceQuota is set to true, the operator compares the
+	// resources of the brokers added by a scale up with the ResourceQuotas
+	// of the namespace and sets QuotaExceeded condition when the new Pods
+	// would be rejected. It's opt-in as the operator needs to read the
+	// ResourceQuotas.
+	VerifyResourceQuota bool `json:"verifyResourceQuota,omitempty"`
+	// HostNetwork runs the brokers in the network namespace of the node,
+	// so the listeners are reachable on the node IP without Services. It
+	// can't be combined with the external connectivity modes that forward
+	// node ports to the brokers.
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+	// SessionAffinity of the headless Service. ClientIP routes the
+	// connections of a client to the same broker. Defaults to None.
+	// +kubebuilder:validation:Enum=None;ClientIP
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
 
 	// ExternalConnectivity enables user to expose Redpanda
 	// nodes outside of a Kubernetes cluster. For more
 	// information please go to ExternalConnectivityConfig
 	ExternalConnectivity ExternalConnectivityConfig `json:"externalConnectivity,omitempty"`
+	// ExternalAdmin exposes the Admin API outside of the Kubernetes cluster
+	// without the Kafka API. For more information please go to
+	// ExternalAdminConfig
+	ExternalAdmin ExternalAdminConfig `json:"externalAdmin,omitempty"`
 	// Storage spec for cluster
 	Storage StorageSpec `json:"storage,omitempty"`
 	// Cloud storage configuration for cluster
 	CloudStorage CloudStorageConfig `json:"cloudStorage,omitempty"`
 	// List of superusers
 	Superusers []Superuser `json:"superUsers,omitempty"`
+	// PasswordRotation replaces the passwords of the superusers with
+	// PasswordSecretKeyRef on a schedule. For more information please go
+	// to PasswordRotationConfig
+	PasswordRotation *PasswordRotationConfig `json:"passwordRotation,omitempty"`
 	// SASL enablement flag
 	EnableSASL bool `json:"enableSasl,omitempty"`
+	// SASLMechanisms enabled on the SASL listeners (default - SCRAM-SHA-256).
+	// The mechanism of every superuser has to be enabled.
+	SASLMechanisms []SASLMechanism `json:"saslMechanisms,omitempty"`
+	// ReadOnlyRootFilesystem runs the Redpanda container with read-only
+	// root filesystem. Writable emptyDir volumes are mounted at the paths
+	// Redpanda needs to write to.
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+	// If PrePullOnUpgrade is set to true, the new image is pulled on the
+	// nodes by a temporary DaemonSet before the rolling update starts.
+	// It shortens the time brokers are down during the upgrade.
+	PrePullOnUpgrade bool `json:"prePullOnUpgrade,omitempty"`
+	// If PublishAdvertisedEndpoints is set to true, the advertised Kafka API
+	// addresses of the brokers are published as EndpointSlices labeled with
+	// the listener name, for service discovery tools reading the endpoints
+	// directly. The slices are kept in sync with the brokers in the status.
+	PublishAdvertisedEndpoints bool `json:"publishAdvertisedEndpoints,omitempty"`
+	// RunAsUser is the UID of Redpanda processes. It's also used as the owner
+	// of the data directory when Storage.FixPermissions is set. Defaults to
+	// the UID of the Redpanda image. Root is not allowed.
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+	// RunAsGroup is the GID of Redpanda processes and the fsGroup of the
+	// Pods. Defaults to the GID of the Redpanda image. Root is not allowed.
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+	// MetadataBackup schedules periodic exports of the cluster metadata and
+	// topic definitions to the cloud storage bucket
+	MetadataBackup *MetadataBackupConfig `json:"metadataBackup,omitempty"`
+	// SeccompProfile is set on the security context of the Redpanda Pods.
+	// Only RuntimeDefault and Localhost profiles are supported.
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+	// If ReportConsumerLag is set to true, the operator periodically polls
+	// the Kafka API and reports the largest consumer group lag in the
+	// status. It's opt-in due to the polling cost.
+	ReportConsumerLag bool `json:"reportConsumerLag,omitempty"`
+	// If ReportHealth is set to true, the operator periodically polls the
+	// Admin API of every broker and summarizes the cluster health in the
+	// status
+	ReportHealth bool `json:"reportHealth,omitempty"`
+	// MaxClockSkewSeconds enables the comparison of the broker clocks. The
+	// ClockSkew condition is set when the clocks of two brokers differ by
+	// more than the given seconds. The broker time is read from the Admin
+	// API responses with one second precision.
+	// +kubebuilder:validation:Minimum=2
+	MaxClockSkewSeconds *int32 `json:"maxClockSkewSeconds,omitempty"`
+	// StartupDelaySeconds staggers the start of the brokers, which are
+	// otherwise started at once. Every broker waits the given seconds
+	// multiplied by its ordinal, so the first broker starts immediately.
+	// The delay applies to every start of the broker pod, e.g. during a
+	// rolling update.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=600
+	StartupDelaySeconds *int32 `json:"startupDelaySeconds,omitempty"`
+	// ClusterDomain is the DNS domain of the Kubernetes cluster the broker
+	// addresses are advertised under, DefaultClusterDomain when not set
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+	// If VerifyClusterDomain is set to true, every broker verifies on start
+	// that ClusterDomain is resolved by the DNS resolver of its node, and
+	// ClusterDomainMismatch condition is set otherwise
+	VerifyClusterDomain bool `json:"verifyClusterDomain,omitempty"`
+	// OpenFilesLimit raises the limit of open files (nofile ulimit) of the
+	// broker process. Kubernetes has no API for ulimits, so the limit is set
+	// by the broker container before Redpanda is started. The limit can't
+	// exceed the hard limit given by the container runtime unless the
+	// container has the CAP_SYS_RESOURCE capability.
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=1048576
+	OpenFilesLimit *int64 `json:"openFilesLimit,omitempty"`
+	// If DrainOnScaleDown is set to true, replicas can be decreased by one.
+	// The broker with the highest ordinal is decommissioned through the
+	// Admin API and its Pod is removed only after all its partitions moved
+	// to the remaining brokers.
+	DrainOnScaleDown bool `json:"drainOnScaleDown,omitempty"`
+	// If AllowUnsafeScaleDown is set to true, replicas can be decreased
+	// while not all brokers are ready or below a majority of the ready
+	// brokers, e.g. to recover a cluster that lost brokers for good.
+	// Partitions can become unavailable.
+	AllowUnsafeScaleDown bool `json:"allowUnsafeScaleDown,omitempty"`
+	// BootstrapTopics are created through the Admin API once all brokers
+	// are ready, e.g. dead-letter or audit topics required by the platform.
+	// Existing topics are left untouched.
+	BootstrapTopics []BootstrapTopic `json:"bootstrapTopics,omitempty"`
+	// PodDisruptionBudget limits voluntary disruptions of the brokers, e.g.
+	// evictions by node drains. By default a majority of the brokers is
+	// kept available, so the quorum is not lost.
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
+	// LivenessProbe enables the liveness probe of the brokers, which
+	// restarts a broker when its Admin API or RPC port stops accepting
+	// connections. For more information please go to LivenessProbeConfig
+	LivenessProbe *LivenessProbeConfig `json:"livenessProbe,omitempty"`
+	// RollingUpdate configures how many brokers are restarted at once when
+	// the Redpanda version is upgraded
+	RollingUpdate *RollingUpdateConfig `json:"rollingUpdate,omitempty"`
+	// ServiceMonitor configures the prometheus-operator ServiceMonitor
+	// scraping the metrics of the brokers
+	ServiceMonitor *ServiceMonitorConfig `json:"serviceMonitor,omitempty"`
+	// If SeparateMetricsService is set to true, the metrics are exposed by
+	// the <cluster>-metrics headless Service, which has only the metrics
+	// port. Redpanda serves the metrics on the Admin API listener. The
+	// ServiceMonitor then selects this Service.
+	SeparateMetricsService bool `json:"separateMetricsService,omitempty"`
+	// ExtraVolumes are added to the broker pods, e.g. a custom CA bundle.
+	// Names of the volumes managed by the operator can't be used.
+	ExtraVolumes []corev1.Volume `json:"extraVolumes,omitempty"`
+	// ExtraVolumeMounts are added to the redpanda container and must
+	// reference one of the ExtraVolumes
+	ExtraVolumeMounts []corev1.VolumeMount `json:"extraVolumeMounts,omitempty"`
+	// ExtraEnv is added to the environment of the redpanda container.
+	// Variables set by the operator can't be overridden.
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+	// ExtraInInitContainers adds ExtraVolumeMounts and ExtraEnv to the
+	// init containers as well
+	ExtraInInitContainers bool `json:"extraInInitContainers,omitempty"`
+	// LogLevel is the default log level of the brokers set at startup.
+	// Defaults to debug.
+	// +kubebuilder:validation:Enum=trace;debug;info;warn;error
+	LogLevel string `json:"logLevel,omitempty"`
+	// AllowDowngrade rolls out Version even if it is older than the
+	// version running on the brokers or skips a major version. Older
+	// versions may not be able to read the data written by newer ones.
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+	// If PreUpgradeCheck is set to true, the feature state of the cluster
+	// is queried through the Admin API before a new image is rolled out.
+	// The rollout is blocked with UnsupportedUpgradePath condition while
+	// the previous upgrade is not finished.
+	PreUpgradeCheck bool `json:"preUpgradeCheck,omitempty"`
+	// ACLs are applied once the brokers are ready. ACLs removed from the
+	// list are deleted, ACLs created by others are left untouched. The
+	// operator authenticates as the first superuser with password when
+	// SASL is enabled.
+	ACLs []ACL `json:"acls,omitempty"`
 }
 
+// ACL allows or denies the principal an operation on Kafka resources
+type ACL struct {
+	// Principal the ACL applies to, e.g. User:alice or User:* for all users
+	Principal string `json:"principal"`
+	// Host the principal connects from (default - * for all hosts)
+	Host string `json:"host,omitempty"`
+	// ResourceType of the resources the ACL applies to
+	// +kubebuilder:validation:Enum=Topic;Group;Cluster;TransactionalID
+	ResourceType string `json:"resourceType"`
+	// ResourceName of the resources, * matches all resources of the type.
+	// The name of the Cluster resource is kafka-cluster.
+	ResourceName string `json:"resourceName"`
+	// PatternType decides if the ResourceName is the exact name or a prefix
+	// of the resource names (default - Literal)
+	// +kubebuilder:validation:Enum=Literal;Prefixed
+	PatternType string `json:"patternType,omitempty"`
+	// Operation the ACL applies to
+	// +kubebuilder:validation:Enum=All;Read;Write;Create;Delete;Alter;Describe;ClusterAction;DescribeConfigs;AlterConfigs;IdempotentWrite
+	Operation string `json:"operation"`
+	// Permission granted by the ACL (default - Allow)
+	// +kubebuilder:validation:Enum=Allow;Deny
+	Permission string `json:"permission,omitempty"`
+}
+
+// DefaultLogLevel is the log level of the brokers if LogLevel is not set
+const DefaultLogLevel = "debug"
+
+// LogLevels are the log levels supported by the brokers
+var LogLevels = []string{"trace", "debug", "info", "warn", "error"}
+
+// PodDisruptionBudgetMode selects when the disruption budget of the brokers
+// is tightened
+// +kubebuilder:validation:Enum=Quorum;Always;DuringUpgrade;Disabled
+type PodDisruptionBudgetMode string
+
+const (
+	// PodDisruptionBudgetQuorum keeps a majority of the brokers available,
+	// i.e. floor(replicas/2)+1
+	PodDisruptionBudgetQuorum PodDisruptionBudgetMode = "Quorum"
+	// PodDisruptionBudgetAlways keeps all brokers available at all times
+	PodDisruptionBudgetAlways PodDisruptionBudgetMode = "Always"
+	// PodDisruptionBudgetDuringUpgrade keeps all brokers available while
+	// a rolling upgrade is in progress and a majority of them otherwise,
+	// so routine node maintenance is not blocked
+	PodDisruptionBudgetDuringUpgrade PodDisruptionBudgetMode = "DuringUpgrade"
+	// PodDisruptionBudgetDisabled doesn't create the PodDisruptionBudget
+	PodDisruptionBudgetDisabled PodDisruptionBudgetMode = "Disabled"
+)
+
+// PodDisruptionBudgetConfig configures the PodDisruptionBudget of the brokers
+type PodDisruptionBudgetConfig struct {
+	// Mode selects when the budget is tightened (default - Quorum). The
+	// brokers kept available are floor(replicas/2)+1 in Quorum mode and all
+	// of them in Always mode. DuringUpgrade mode keeps all brokers available
+	// during a rolling upgrade and floor(replicas/2)+1 otherwise.
+	Mode PodDisruptionBudgetMode `json:"mode,omitempty"`
+	// MinAvailable overrides the number of brokers that can't be evicted
+	// derived from the Mode
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+}
+
+// LivenessProbeMode selects the ports probed by the liveness probe and the
+// failures that restart the broker
+// +kubebuilder:validation:Enum=Admin;RPC;Any;All
+type LivenessProbeMode string
+
+const (
+	// LivenessProbeAdmin restarts the broker when the Admin API port doesn't
+	// accept connections
+	LivenessProbeAdmin LivenessProbeMode = "Admin"
+	// LivenessProbeRPC restarts the broker when the RPC port doesn't accept
+	// connections
+	LivenessProbeRPC LivenessProbeMode = "RPC"
+	// LivenessProbeAny probes both ports and restarts the broker only when
+	// neither of them accepts connections
+	LivenessProbeAny LivenessProbeMode = "Any"
+	// LivenessProbeAll probes both ports and restarts the broker when any of
+	// them doesn't accept connections
+	LivenessProbeAll LivenessProbeMode = "All"
+)
+
+// LivenessProbeModes are the supported liveness probe modes
+var LivenessProbeModes = []string{
+	string(LivenessProbeAdmin), string(LivenessProbeRPC), string(LivenessProbeAny), string(LivenessProbeAll),
+}
+
+// LivenessProbeConfig configures the liveness probe of the brokers. The
+// probe only opens TCP connections, so it doesn't depend on the TLS and the
+// authentication of the listeners. Zero values keep the Kubernetes defaults.
+type LivenessProbeConfig struct {
+	// Mode selects the probed ports (default - Any)
+	Mode LivenessProbeMode `json:"mode,omitempty"`
+	// InitialDelaySeconds is the time the broker gets to start listening
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// PeriodSeconds is the interval between the probes
+	// +kubebuilder:validation:Minimum=0
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// FailureThreshold is the number of consecutive failed probes
+	// restarting the broker
+	// +kubebuilder:validation:Minimum=0
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// RollingUpdateConfig configures the rolling update of the brokers
+type RollingUpdateConfig struct {
+	// MaxUnavailable is the number of brokers restarted at once during the
+	// upgrade (default - 1). More than one broker is restarted at once only
+	// if the majority of the brokers stays available, i.e. up to
+	// (replicas-1)/2.
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+}
+
+// ServiceMonitorConfig configures the generated ServiceMonitor. It's
+// skipped when the ServiceMonitor CRD is not installed.
+type ServiceMonitorConfig struct {
+	// If Enabled is set to true, a ServiceMonitor selecting the headless
+	// Service of the cluster, or the metrics Service when
+	// SeparateMetricsService is set, is created
+	Enabled bool `json:"enabled,omitempty"`
+	// Labels added to the ServiceMonitor, e.g. to match the
+	// serviceMonitorSelector of the Prometheus instance
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// BootstrapTopic is a system topic created when the cluster is bootstrapped
+type BootstrapTopic struct {
+	// Name of the topic
+	Name string `json:"name"`
+	// Number of partitions of the topic
+	Partitions int `json:"partitions"`
+	// Number of replicas of every partition, at most the number of brokers
+	ReplicationFactor int `json:"replicationFactor"`
+}
+
+// MetadataBackupConfig configures the CronJob that exports cluster metadata
+// to the bucket configured in CloudStorage. The cluster info and the topics
+// with their configs are exported as JSON by rpk of the Redpanda image, which
+// has to support the --format, TLS and SASL flags. The export connects with
+// the operator client certificate and, when SASL is enabled, as the first
+// superuser with password. The Secret referenced by CloudStorage.SecretKeyRef
+// must be in the namespace of the Cluster, because the backup Pod can
+// reference only Secrets in its namespace.
+type MetadataBackupConfig struct {
+	// Schedule in the cron format, e.g. "0 */6 * * *"
+	Schedule string `json:"schedule"`
+	// Prefix of the object keys the exports are stored under
+	// (default - metadata-backup)
+	Prefix string `json:"prefix,omitempty"`
+	// UploaderImage is the image with AWS CLI used to upload the export
+	// (default - amazon/aws-cli)
+	UploaderImage string `json:"uploaderImage,omitempty"`
+}
+
+// SASLMechanism is a SCRAM mechanism of the SASL authentication
+// +kubebuilder:validation:Enum=SCRAM-SHA-256;SCRAM-SHA-512
+type SASLMechanism string
+
+const (
+	// SCRAMSHA256 is the default SASL mechanism
+	SCRAMSHA256 SASLMechanism = "SCRAM-SHA-256"
+	// SCRAMSHA512 is the SASL mechanism using SHA-512
+	SCRAMSHA512 SASLMechanism = "SCRAM-SHA-512"
+)
+
+// SASLMechanisms are the supported SASL mechanisms
+var SASLMechanisms = []string{string(SCRAMSHA256), string(SCRAMSHA512)}
+
 // Superuser has full access to the Redpanda cluster
 type Superuser struct {
 	Username string `json:"username"`
+	// Mechanism is the SCRAM mechanism the user is created with
+	// (default - SCRAM-SHA-256). It has to be enabled in SASLMechanisms.
+	Mechanism SASLMechanism `json:"mechanism,omitempty"`
+	// PasswordSecretKeyRef references the password of the SCRAM user that
+	// is created for the superuser. The Secret must be in the namespace of
+	// the Cluster. The user is not created if the reference is not set,
+	// e.g. for principals authenticated by mTLS.
+	PasswordSecretKeyRef *corev1.SecretKeySelector `json:"passwordSecretKeyRef,omitempty"`
+}
+
+// PasswordRotationConfig configures the rotation of superuser passwords.
+// The new password is generated by the operator and stored in the Secret of
+// the superuser under the key with ".pending" suffix. Once the SCRAM user is
+// updated, the pending password replaces the referenced key in a single
+// update of the Secret.
+type PasswordRotationConfig struct {
+	// IntervalHours between two rotations of a password. The first rotation
+	// happens an interval after the superuser is provisioned.
+	// +kubebuilder:validation:Minimum=1
+	IntervalHours int32 `json:"intervalHours"`
 }
 
 // CloudStorageConfig configures the Data Archiving feature in Redpanda
@@ -73,6 +448,10 @@ type CloudStorageConfig struct {
 	// The Secret must contain a data entry of the form:
 	// data[<SecretKeyRef.Name>] = <secret key>
 	SecretKeyRef corev1.ObjectReference `json:"secretKeyRef,omitempty"`
+	// ProjectedToken authenticates the brokers with short-lived credentials
+	// obtained by the web identity flow of the object store instead of the
+	// access and secret keys
+	ProjectedToken *CloudStorageProjectedToken `json:"projectedToken,omitempty"`
 	// Cloud storage region
 	Region string `json:"region,omitempty"`
 	// Cloud storage bucket
@@ -89,6 +468,46 @@ type CloudStorageConfig struct {
 	APIEndpoint string `json:"apiEndpoint,omitempty"`
 	// Used to override TLS port (443)
 	APIEndpointPort int `json:"apiEndpointPort,omitempty"`
+	// If VerifyReachability is set to true, the operator sends HEAD bucket
+	// request with the provided credentials and reports the outcome with
+	// CloudStorageUnreachable condition. It's opt-in as the object store
+	// may be located in a distant region.
+	VerifyReachability bool `json:"verifyReachability,omitempty"`
+	// CacheSize is the space of the data directory used for caching the
+	// segments read from cloud storage (cloud_storage_cache_size)
+	CacheSize *resource.Quantity `json:"cacheSize,omitempty"`
+	// If EgressNetworkPolicy is set, a NetworkPolicy allows the brokers to
+	// reach the object store, e.g. when the namespace denies egress traffic
+	// by default. The brokers can still reach each other and DNS.
+	EgressNetworkPolicy *CloudStorageEgressPolicy `json:"egressNetworkPolicy,omitempty"`
+}
+
+// defaultCloudStoragePort is the TLS port of the object store used when
+// APIEndpointPort is not set
+const defaultCloudStoragePort = 443
+
+// CloudStorageEgressPolicy configures the egress NetworkPolicy of the
+// object store. NetworkPolicy can't match host names, so the egress is
+// allowed to CIDRs on the port of APIEndpoint. CIDRs can be left out when
+// APIEndpoint is an IP address.
+type CloudStorageEgressPolicy struct {
+	// CIDRs of the object store
+	CIDRs []string `json:"cidrs,omitempty"`
+}
+
+// CloudStorageProjectedToken configures the service account token projected
+// into the brokers. The path of the token is set in AWS_WEB_IDENTITY_TOKEN_FILE
+// environment variable.
+type CloudStorageProjectedToken struct {
+	// Audience of the token, i.e. the identifier the identity provider of
+	// the object store expects, e.g. 'sts.amazonaws.com'
+	Audience string `json:"audience"`
+	// Requested validity of the token, refreshed by kubelet before it
+	// expires (default - 1 hour, minimum - 10 minutes)
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+	// RoleARN is set in AWS_ROLE_ARN environment variable, as the role
+	// assumed with the token
+	RoleARN string `json:"roleArn,omitempty"`
 }
 
 // StorageSpec defines the storage specification of the Cluster
@@ -97,6 +516,19 @@ type StorageSpec struct {
 	Capacity resource.Quantity `json:"capacity,omitempty"`
 	// Storage class name - https://kubernetes.io/docs/concepts/storage/storage-classes/
 	StorageClassName string `json:"storageClassName,omitempty"`
+	// If FixPermissions is set to true, an init container changes the owner
+	// of the data directory to the Redpanda user. It's meant for provisioners
+	// that create volumes owned by root. The init container runs as root.
+	FixPermissions bool `json:"fixPermissions,omitempty"`
+	// Access modes of the data directory volumes (default - ReadWriteOnce).
+	// The operator holds the reconciliation when the provisioner of the
+	// storage class is known not to support them.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// LocalRetention is the expected size of the partition data kept on the
+	// data directory of a broker. It's not rendered in the configuration,
+	// the webhook verifies that it fits in Capacity together with the
+	// cloud storage CacheSize.
+	LocalRetention *resource.Quantity `json:"localRetention,omitempty"`
 }
 
 // ExternalConnectivityConfig adds listener that can be reached outside
@@ -113,6 +545,12 @@ type StorageSpec struct {
 type ExternalConnectivityConfig struct {
 	// Enabled enables the external connectivity feature
 	Enabled bool `json:"enabled,omitempty"`
+	// Type selects how the brokers are exposed. NodePort and LoadBalancer
+	// create a Service for each broker and advertise its address. Subdomain
+	// advertises the brokers under the Subdomain. If Type is empty, the
+	// Subdomain is advertised when set, otherwise the public IP of the node.
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort;Subdomain
+	Type ExternalConnectivityType `json:"type,omitempty"`
 	// Subdomain can be used to change the behavior of an advertised
 	// KafkaAPI. Each broker advertises Kafka API as follows
 	// HOSTNAME_OF_A_POD.SUBDOMAIN:EXTERNAL_KAFKA_API_PORT.
@@ -121,8 +559,72 @@ type ExternalConnectivityConfig struct {
 	// If TLS is enabled then this subdomain will be requested
 	// as a subject alternative name.
 	Subdomain string `json:"subdomain,omitempty"`
+	// SkipSubdomainValidation disables the check that the Subdomain is
+	// a delegated DNS zone, e.g. when it's resolved by private DNS servers
+	// the operator can't reach.
+	SkipSubdomainValidation bool `json:"skipSubdomainValidation,omitempty"`
+	// DNSPropagationTimeoutSeconds enables an init container that waits
+	// until the name of the broker under the Subdomain resolves, so the
+	// broker doesn't advertise an unreachable address. The broker is
+	// started anyway once the timeout expires.
+	// +kubebuilder:validation:Minimum=1
+	DNSPropagationTimeoutSeconds *int32 `json:"dnsPropagationTimeoutSeconds,omitempty"`
+	// CloudProvider selects the annotation convention used to tag cloud
+	// load balancers created for the broker Services. GCP is not supported,
+	// because it has no Service annotation that labels the load balancer.
+	// +kubebuilder:validation:Enum=aws;azure
+	CloudProvider string `json:"cloudProvider,omitempty"`
+	// LoadBalancerTags are cost-allocation tags that are rendered into the
+	// cloud provider specific annotations of the broker Services. They
+	// require the LoadBalancer Type.
+	LoadBalancerTags map[string]string `json:"loadBalancerTags,omitempty"`
+}
+
+// ExternalAdminConfig exposes the Admin API of the brokers through the
+// '<redpanda-cluster-name>-admin-external' Service of type NodePort. The
+// Admin API is already exposed along with the Kafka API when
+// ExternalConnectivity is enabled, so the two can't be combined.
+type ExternalAdminConfig struct {
+	// Enabled enables the external Admin API
+	Enabled bool `json:"enabled,omitempty"`
+	// Subdomain the Admin API of each broker is reachable under as
+	// HOSTNAME_OF_A_POD.SUBDOMAIN:ADMIN_API_NODE_PORT. If Subdomain is
+	// empty, the PUBLIC_NODE_IP:ADMIN_API_NODE_PORT is reported instead.
+	// If Admin API TLS is enabled then this subdomain will be requested as
+	// a subject alternative name.
+	Subdomain string `json:"subdomain,omitempty"`
+}
+
+// ExternalConnectivityType selects how the brokers are reachable from
+// outside of the Kubernetes cluster
+type ExternalConnectivityType string
+
+const (
+	// ExternalConnectivityLoadBalancer exposes each broker through its own
+	// Service of type LoadBalancer
+	ExternalConnectivityLoadBalancer ExternalConnectivityType = "LoadBalancer"
+	// ExternalConnectivityNodePort exposes each broker through its own
+	// Service of type NodePort
+	ExternalConnectivityNodePort ExternalConnectivityType = "NodePort"
+	// ExternalConnectivitySubdomain advertises each broker under the
+	// Subdomain routed to the Kubernetes nodes
+	ExternalConnectivitySubdomain ExternalConnectivityType = "Subdomain"
+)
+
+// PerBrokerServices returns true if each broker is exposed through its own
+// Service
+func (c ExternalConnectivityConfig) PerBrokerServices() bool {
+	return c.Enabled &&
+		(c.Type == ExternalConnectivityNodePort || c.Type == ExternalConnectivityLoadBalancer)
 }
 
+const (
+	// CloudProviderAWS renders load balancer tags using AWS annotations
+	CloudProviderAWS = "aws"
+	// CloudProviderAzure renders load balancer tags using Azure annotations
+	CloudProviderAzure = "azure"
+)
+
 // ClusterStatus defines the observed state of Cluster
 type ClusterStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -137,8 +639,315 @@ type ClusterStatus struct {
 	// Indicates cluster is upgrading
 	// +optional
 	Upgrading bool `json:"upgrading"`
+	// Conditions represent the latest available observations of the cluster state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// UpgradePlan lists the brokers the pending rolling upgrade restarts.
+	// It's published also while the reconciliation is paused, so the plan
+	// can be reviewed before the upgrade is executed.
+	// +optional
+	UpgradePlan *UpgradePlanStatus `json:"upgradePlan,omitempty"`
+	// ConsumerLag summarizes the lag of consumer groups when
+	// ReportConsumerLag is enabled
+	// +optional
+	ConsumerLag *ConsumerLagStatus `json:"consumerLag,omitempty"`
+	// DecommissioningNode is the node ID of the broker being drained before
+	// the cluster is scaled down
+	// +optional
+	DecommissioningNode *int32 `json:"decommissioningNode,omitempty"`
+	// DecommissionProgress reports the partition movement away from the
+	// decommissioning node
+	// +optional
+	DecommissionProgress *DecommissionProgress `json:"decommissionProgress,omitempty"`
+	// BootstrappedTopics lists the bootstrap topics known to exist
+	// +optional
+	BootstrappedTopics []string `json:"bootstrappedTopics,omitempty"`
+	// ProvisionedSuperusers lists the SCRAM users created by the operator
+	// +optional
+	ProvisionedSuperusers []string `json:"provisionedSuperusers,omitempty"`
+	// PasswordRotations records the last password rotation of each
+	// superuser when PasswordRotation is enabled
+	// +optional
+	PasswordRotations []PasswordRotationStatus `json:"passwordRotations,omitempty"`
+	// ProvisionedACLs lists the ACLs created by the operator
+	// +optional
+	ProvisionedACLs []ACL `json:"provisionedAcls,omitempty"`
+	// Health summarizes the state of all brokers when ReportHealth is
+	// enabled
+	// +optional
+	Health *ClusterHealthSummary `json:"health,omitempty"`
+	// TLS summarizes the effective TLS configuration of each listener
+	// +optional
+	TLS []ListenerTLSStatus `json:"tls,omitempty"`
+	// OffsetReset is the outcome of the last consumer group offsets reset
+	// requested with ResetOffsetsAnnotation
+	// +optional
+	OffsetReset *OffsetResetStatus `json:"offsetReset,omitempty"`
+}
+
+// OffsetResetStatus is the outcome of a consumer group offsets reset
+type OffsetResetStatus struct {
+	Group string `json:"group"`
+	// To is the position the offsets were moved to, earliest or latest
+	To string `json:"to"`
+	// DryRun is true if the offsets were computed but not committed
+	DryRun bool `json:"dryRun"`
+	// Offsets are the offsets of the group by partition after the reset
+	// +optional
+	Offsets []PartitionOffset `json:"offsets,omitempty"`
+	// Time is the time the reset was issued
+	Time metav1.Time `json:"time"`
+}
+
+// PartitionOffset is the committed offset of a consumer group in a partition
+type PartitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// DecommissionProgress is the state of the partition movement away from a
+// decommissioned broker
+type DecommissionProgress struct {
+	// InitialPartitions is the number of partition replicas held by the
+	// broker when the progress was first observed
+	InitialPartitions int32 `json:"initialPartitions"`
+	// RemainingPartitions is the number of partition replicas still to be
+	// moved away from the broker
+	RemainingPartitions int32 `json:"remainingPartitions"`
+	// PercentComplete is the share of the initial partition replicas that
+	// were moved away
+	PercentComplete int32 `json:"percentComplete"`
+}
+
+// ListenerTLSStatus is the TLS configuration rendered for a single listener
+type ListenerTLSStatus struct {
+	// API is the API served by the listener, kafka or admin
+	API string `json:"api"`
+	// Listener is the name of the Kafka API listener, empty for Admin API
+	// +optional
+	Listener string `json:"listener,omitempty"`
+	// Enabled is true if the listener serves TLS
+	Enabled bool `json:"enabled"`
+	// RequireClientAuth is true if clients have to present a certificate
+	// +optional
+	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
+	// Issuer of the listener certificate, in the kind/name form when the
+	// kind is known. Empty if the certificate is provided by the user.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+	// CertSecret is the name of the Secret holding the listener certificate
+	// +optional
+	CertSecret string `json:"certSecret,omitempty"`
+}
+
+// UpgradePlanStatus is the rolling upgrade of the brokers to a new image
+type UpgradePlanStatus struct {
+	// TargetImage is the image the brokers are upgraded to
+	TargetImage string `json:"targetImage"`
+	// Pods are the brokers pending the restart, in the order of restarts.
+	// Only one broker is unavailable at a time.
+	// +optional
+	Pods []string `json:"pods,omitempty"`
 }
 
+// ConsumerLagStatus reports the consumer group that is the most behind
+type ConsumerLagStatus struct {
+	// MaxLag is the number of messages the group is behind, summed over
+	// all partitions it consumes
+	MaxLag int64 `json:"maxLag"`
+	// Group with the largest lag. Empty if there are no consumer groups.
+	Group string `json:"group,omitempty"`
+	// LastPollTime is the time the lag was polled
+	LastPollTime metav1.Time `json:"lastPollTime,omitempty"`
+}
+
+// PasswordRotationStatus is the last password rotation of a superuser
+type PasswordRotationStatus struct {
+	Username string `json:"username"`
+	// LastRotationTime is the time the password was replaced, or the time
+	// the rotation schedule started for passwords not rotated yet
+	LastRotationTime metav1.Time `json:"lastRotationTime"`
+}
+
+// ClusterHealthSummary aggregates the Admin API responses of all brokers
+type ClusterHealthSummary struct {
+	// HealthyBrokers is the number of brokers that responded to the poll
+	HealthyBrokers int32 `json:"healthyBrokers"`
+	// UnhealthyBrokers is the number of brokers that could not be polled
+	UnhealthyBrokers int32 `json:"unhealthyBrokers"`
+	// ControllerLeaderPresent is true when any broker knows the controller
+	// leader
+	ControllerLeaderPresent bool `json:"controllerLeaderPresent"`
+	// UnderReplicatedPartitions is the number of under-replicated replicas
+	// reported by the healthy brokers
+	UnderReplicatedPartitions int64 `json:"underReplicatedPartitions"`
+	// LastPollTime is the time the brokers were polled
+	LastPollTime metav1.Time `json:"lastPollTime,omitempty"`
+}
+
+const (
+	// ReadyConditionType is set to true when the current generation of the
+	// Cluster is reconciled
+	ReadyConditionType = "Ready"
+	// ReconcilingConditionType is set to true while the operator is working
+	// towards the desired state
+	ReconcilingConditionType = "Reconciling"
+	// StalledConditionType is set to true when the reconciliation fails
+	StalledConditionType = "Stalled"
+	// InvalidCertificateConditionType is set to true when a TLS Secret used
+	// by one of the listeners contains unusable certificate material
+	InvalidCertificateConditionType = "InvalidCertificate"
+	// ControllerInconsistencyConditionType is set to true when brokers
+	// report different controller leaders
+	ControllerInconsistencyConditionType = "ControllerInconsistency"
+	// SubdomainNotDelegatedConditionType is set to true when no name
+	// servers are published for the external connectivity Subdomain
+	SubdomainNotDelegatedConditionType = "SubdomainNotDelegated"
+	// CloudStorageUnreachableConditionType is set to true when the operator
+	// can't access the configured cloud storage bucket
+	CloudStorageUnreachableConditionType = "CloudStorageUnreachable"
+	// SuperuserBootstrapFailedConditionType is set to true when SCRAM users
+	// of the superusers do not exist. The cluster is not Ready until then.
+	SuperuserBootstrapFailedConditionType = "SuperuserBootstrapFailed"
+	// WaitingForSecretConditionType is set to true while a referenced
+	// credential Secret does not exist yet, e.g. before External Secrets
+	// Operator creates it
+	WaitingForSecretConditionType = "WaitingForSecret"
+	// ClusterConfiguredConditionType is set to true when all resources of
+	// the cluster are applied
+	ClusterConfiguredConditionType = "ClusterConfigured"
+	// BrokersReadyConditionType is set to true when all brokers are ready
+	BrokersReadyConditionType = "BrokersReady"
+	// TLSReadyConditionType is set to true when the node certificates of
+	// the enabled TLS listeners are issued
+	TLSReadyConditionType = "TLSReady"
+	// VolumeExpansionBlockedConditionType is set to true when the data
+	// directory volumes can't grow to the requested capacity because their
+	// storage class doesn't allow volume expansion
+	VolumeExpansionBlockedConditionType = "VolumeExpansionBlocked"
+	// ReconciliationPausedConditionType is set to true while the Cluster is
+	// annotated with ManagedAnnotation set to false
+	ReconciliationPausedConditionType = "ReconciliationPaused"
+	// VersionTransitionBlockedConditionType is set to true when Version
+	// is a downgrade or skips a major version of the running brokers and
+	// AllowDowngrade is not set. The StatefulSet is not rolled out then.
+	VersionTransitionBlockedConditionType = "VersionTransitionBlocked"
+	// UnsupportedUpgradePathConditionType is set to true when
+	// PreUpgradeCheck finds that the previous upgrade is not finished. The
+	// StatefulSet is not rolled out until it is.
+	UnsupportedUpgradePathConditionType = "UnsupportedUpgradePath"
+	// ClockSkewConditionType is set to true when the clocks of the brokers
+	// differ by more than MaxClockSkewSeconds
+	ClockSkewConditionType = "ClockSkew"
+	// AdminClientUnauthorizedConditionType is set to true when the CN of
+	// the Admin API client certificate of the operator is neither a
+	// superuser nor allowed by an ACL
+	AdminClientUnauthorizedConditionType = "AdminClientUnauthorized"
+	// SANMismatchConditionType is set to true when an address advertised
+	// by a TLS listener is not among the SANs of its node certificate
+	SANMismatchConditionType = "SANMismatch"
+	// CommonNameConflictConditionType is set to true when a node certificate
+	// has the same CN as a client certificate, so both are authorized as the
+	// same principal
+	CommonNameConflictConditionType = "CommonNameConflict"
+	// UnschedulableConditionType is set to true when no schedulable node
+	// matches NodeSelector and has all its taints tolerated by Tolerations,
+	// so the brokers would stay Pending
+	UnschedulableConditionType = "Unschedulable"
+	// ClusterDomainMismatchConditionType is set to true when the brokers
+	// can't resolve the names under ClusterDomain
+	ClusterDomainMismatchConditionType = "ClusterDomainMismatch"
+	// ArchitectureMismatchConditionType is set to true when the brokers can
+	// be scheduled on nodes of an architecture the image isn't built for, or
+	// on nodes of mixed architectures
+	ArchitectureMismatchConditionType = "ArchitectureMismatch"
+	// QuotaExceededConditionType is set to true when the brokers added by
+	// a scale up don't fit in the ResourceQuotas of the namespace
+	QuotaExceededConditionType = "QuotaExceeded"
+	// InsufficientLocalVolumesConditionType is set to true when the storage
+	// class is backed by local volumes and there are less available
+	// persistent volumes than replicas, so some brokers would stay Pending
+	InsufficientLocalVolumesConditionType = "InsufficientLocalVolumes"
+	// IssuerNotFoundConditionType is set to true when an Issuer referenced
+	// by the TLS configuration doesn't exist in the namespace of the cluster
+	IssuerNotFoundConditionType = "IssuerNotFound"
+	// WellKnownPortInUseConditionType is set to true when a port of the
+	// brokers exposed outside of the Kubernetes cluster is commonly used by
+	// components running on the nodes, e.g. etcd
+	WellKnownPortInUseConditionType = "WellKnownPortInUse"
+)
+
+// ManagedAnnotation set to "false" pauses the reconciliation of the Cluster,
+// so its resources can be fixed by hand, e.g. during an incident. Removing
+// the annotation or setting it to "true" resumes the reconciliation.
+const ManagedAnnotation = "redpanda.vectorized.io/managed"
+
+// ZoneStatefulSetsConfig spreads the brokers over one StatefulSet per
+// zone, e.g. for local storage that can't follow a broker to another zone.
+// The replicas of the cluster are split evenly over the zones, the zones
+// listed first get the remainder. All StatefulSets share the configuration
+// and the headless Service of the cluster. The zones can't be changed once
+// the cluster is created and the brokers can't be exposed by external
+// connectivity, as the advertised addresses are derived from the ordinals.
+type ZoneStatefulSetsConfig struct {
+	// Zones are the values of the topology.kubernetes.io/zone label of the
+	// nodes. The StatefulSet of a zone is named <cluster name>-<zone>.
+	// +kubebuilder:validation:MinItems=1
+	Zones []string `json:"zones"`
+}
+
+// DedicatedNodesKey is the label and taint key of the nodes dedicated to
+// the brokers when DedicatedNodes is set
+const DedicatedNodesKey = "redpanda.vectorized.io/dedicated"
+
+// DedicatedNodesValue is the value of the DedicatedNodesKey label and taint
+const DedicatedNodesValue = "true"
+
+// VPAManagedAnnotation set to "true" defers the resources of the redpanda
+// container to the VerticalPodAutoscaler. Resources of the existing
+// StatefulSet, e.g. applied from VPA recommendations, are not overwritten
+// by Resources of the Cluster.
+const VPAManagedAnnotation = "redpanda.vectorized.io/vpa-managed"
+
+// LastAppliedSpecAnnotation holds the JSON encoded spec of the last
+// reconciliation that brought the Cluster to Ready and provisioned its
+// topics, superusers and ACLs, so it can be compared with the current spec
+// or restored to roll back a change
+const LastAppliedSpecAnnotation = "redpanda.vectorized.io/last-applied-spec"
+
+// ResetOffsetsAnnotation set to the name of a consumer group resets the
+// committed offsets of the group over the Kafka API. The reset is a dry
+// run recorded in OffsetReset status unless ResetOffsetsConfirmAnnotation
+// repeats the group name. The annotations are removed once the operation
+// is done, so it is never repeated by accident.
+const ResetOffsetsAnnotation = "redpanda.vectorized.io/reset-offsets"
+
+// ResetOffsetsToAnnotation selects the offsets the consumer group is moved
+// to, OffsetResetEarliest when not set or OffsetResetLatest
+const ResetOffsetsToAnnotation = "redpanda.vectorized.io/reset-offsets-to"
+
+// ResetOffsetsConfirmAnnotation must be set to the group name of
+// ResetOffsetsAnnotation to commit the new offsets
+const ResetOffsetsConfirmAnnotation = "redpanda.vectorized.io/reset-offsets-confirm"
+
+// DebugDumpAnnotation set to a request ID, e.g. a support ticket number,
+// runs a one-shot Job that uploads the configuration, the state and the
+// recent logs of the brokers to the cloud storage bucket under
+// debug-dump/<request ID>. The dump is collected even while the cluster is
+// not healthy. The annotation is removed and the Job is cleaned up once it
+// finishes.
+const DebugDumpAnnotation = "redpanda.vectorized.io/debug-dump"
+
+const (
+	// OffsetResetEarliest moves the offsets to the start of the partitions
+	OffsetResetEarliest = "earliest"
+	// OffsetResetLatest moves the offsets to the end of the partitions
+	OffsetResetLatest = "latest"
+)
+
 // NodesList shows where client can find Redpanda brokers
 type NodesList struct {
 	Internal      []string `json:"internal,omitempty"`
@@ -176,6 +985,91 @@ type RedpandaConfig struct {
 	TLS           TLSConfig     `json:"tls,omitempty"`
 	// Number of partitions in the internal group membership topic
 	GroupTopicPartitions int `json:"groupTopicPartitions,omitempty"`
+	// Throughput quotas preventing noisy clients from starving others
+	ClientQuotas *ClientQuotas `json:"clientQuotas,omitempty"`
+	// AdditionalConfiguration holds redpanda cluster properties not covered
+	// by the fields above, e.g. log_segment_size. Values are rendered as
+	// YAML scalars, properties managed by the operator can't be overridden.
+	AdditionalConfiguration map[string]string `json:"additionalConfiguration,omitempty"`
+	// AdvertisedKafkaAPIPorts overrides the ports advertised to Kafka API
+	// clients when they differ from the listening ports, e.g. behind NAT.
+	// Declared Listeners set their AdvertisedPort instead.
+	AdvertisedKafkaAPIPorts *AdvertisedKafkaAPIPorts `json:"advertisedKafkaApiPorts,omitempty"`
+	// Listeners declares the Kafka API listeners of the brokers instead of
+	// the Internal listener on the KafkaAPI port and the External listener
+	// of ExternalConnectivity. The Internal listener on the KafkaAPI port
+	// is required, as it's used by the operator. The External listener is
+	// required on the next port when ExternalConnectivity is enabled, other
+	// listeners are reachable from within the Kubernetes cluster only.
+	Listeners []ListenerSpec `json:"listeners,omitempty"`
+}
+
+// ListenerSpec is a Kafka API listener of the brokers
+type ListenerSpec struct {
+	// Name of the listener, the name is lowercased in the names of the
+	// container and Service ports
+	Name string `json:"name"`
+	Port int    `json:"port"`
+	// AdvertisedPort is advertised to the clients instead of Port when
+	// they differ, e.g. behind NAT. Zero means Port is advertised.
+	AdvertisedPort int `json:"advertisedPort,omitempty"`
+	// TLS serves the listener with the Kafka API node certificate, Kafka
+	// API TLS has to be enabled
+	TLS bool `json:"tls,omitempty"`
+	// RequireClientAuth requires the clients of the TLS listener to present
+	// a client certificate, Kafka API RequireClientAuth has to be enabled
+	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
+	// External is set for the listener reachable from outside of the
+	// Kubernetes cluster
+	External bool `json:"external,omitempty"`
+}
+
+// ClientPort returns the port the clients of the listener connect to
+func (l ListenerSpec) ClientPort() int {
+	if l.AdvertisedPort != 0 {
+		return l.AdvertisedPort
+	}
+	return l.Port
+}
+
+const (
+	// InternalListener is the name of the Kafka API listener reachable
+	// from within the Kubernetes cluster
+	InternalListener = "Internal"
+	// ExternalListener is the name of the Kafka API listener reachable
+	// from outside of the Kubernetes cluster
+	ExternalListener = "External"
+)
+
+// AdvertisedKafkaAPIPorts are advertised by the Kafka API listeners instead
+// of the ports they listen on. Zero means the listening port is advertised.
+type AdvertisedKafkaAPIPorts struct {
+	// Internal is advertised by the internal listener instead of the
+	// KafkaAPI port
+	Internal int `json:"internal,omitempty"`
+	// External is advertised by the external listener instead of the host
+	// port or the port of the broker Service
+	External int `json:"external,omitempty"`
+}
+
+// ClientQuotas limits the throughput of Kafka API clients. Redpanda
+// identifies clients by the client ID they send with every request.
+type ClientQuotas struct {
+	// Default throughput in bytes per second of a single client
+	DefaultByteRate *int64 `json:"defaultByteRate,omitempty"`
+	// Throughput of groups of clients sharing client ID prefix
+	Groups []ClientGroupQuota `json:"groups,omitempty"`
+}
+
+// ClientGroupQuota limits the throughput of clients with the same client ID
+// prefix
+type ClientGroupQuota struct {
+	// Unique name of the group
+	Name string `json:"name"`
+	// Clients with client ID starting with the prefix belong to the group
+	ClientIDPrefix string `json:"clientIdPrefix"`
+	// Throughput in bytes per second of the group
+	ByteRate int64 `json:"byteRate"`
 }
 
 // TLSConfig configures TLS for Redpanda APIs
@@ -184,6 +1078,20 @@ type TLSConfig struct {
 	KafkaAPI KafkaAPITLS `json:"kafkaApi,omitempty"`
 	// Configuration of TLS for Admin API
 	AdminAPI AdminAPITLS `json:"adminApi,omitempty"`
+	// Configuration of TLS for metrics scraping
+	Metrics MetricsTLS `json:"metrics,omitempty"`
+	// If SharedNodeCert is set to true, a single node certificate is issued
+	// by the Kafka API issuer and used by both Kafka API and Admin API
+	// listeners. Both APIs must have TLS enabled.
+	SharedNodeCert bool `json:"sharedNodeCert,omitempty"`
+	// If VerifyCommonNames is set to true, the operator verifies that the
+	// node certificates don't share the CN with the client certificates it
+	// issues and sets CommonNameConflict condition otherwise
+	VerifyCommonNames bool `json:"verifyCommonNames,omitempty"`
+	// If VerifySANs is set to true, the operator verifies that the node
+	// certificates cover every address advertised by the TLS listeners and
+	// sets SANMismatch condition otherwise
+	VerifySANs bool `json:"verifySANs,omitempty"`
 }
 
 // KafkaAPITLS configures TLS for redpanda Kafka API
@@ -211,7 +1119,8 @@ type TLSConfig struct {
 type KafkaAPITLS struct {
 	Enabled bool `json:"enabled,omitempty"`
 	// References cert-manager Issuer or ClusterIssuer. When provided, this
-	// issuer will be used to issue node certificates.
+	// issuer will be used to issue node and client certificates and no
+	// self-signed issuer is created.
 	// Typically you want to provide the issuer when a generated self-signed one
 	// is not enough and you need to have a verifiable chain with a proper CA
 	// certificate.
@@ -226,6 +1135,20 @@ type KafkaAPITLS struct {
 	// Enables two-way verification on the server side. If enabled, all Kafka
 	// API clients are required to have a valid client certificate.
 	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
+	// If SeparateExternalCert is set to true, the external listener is
+	// served with a dedicated certificate for the ExternalConnectivity
+	// subdomain stored in '<redpanda-cluster-name>-redpanda-external' Secret,
+	// while the node certificate covers the internal FQDN only. Otherwise
+	// the node certificate carries both DNS names.
+	SeparateExternalCert bool `json:"separateExternalCert,omitempty"`
+	// CASecretNamespaces lists namespaces that get a copy of the CA
+	// certificate of the Kafka API, so clients running there can verify the
+	// brokers. The copy is stored in '<redpanda-cluster-name>-ca' Secret with
+	// 'ca.crt' key only, kept in sync when the certificate is rotated and
+	// removed when the namespace is not listed anymore or the cluster is
+	// deleted. The operator needs the permission to manage Secrets in the
+	// namespaces.
+	CASecretNamespaces []string `json:"caSecretNamespaces,omitempty"`
 }
 
 // AdminAPITLS configures TLS for Redpanda Admin API
@@ -242,8 +1165,26 @@ type KafkaAPITLS struct {
 //
 // All TLS secrets are stored in the same namespace as the Redpanda cluster.
 type AdminAPITLS struct {
-	Enabled           bool `json:"enabled,omitempty"`
-	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
+	Enabled bool `json:"enabled,omitempty"`
+	// References cert-manager Issuer or ClusterIssuer. When provided, this
+	// issuer will be used to issue Admin API node and client certificates
+	// instead of a self-signed issuer.
+	IssuerRef         *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	RequireClientAuth bool                    `json:"requireClientAuth,omitempty"`
+}
+
+// MetricsTLS configures TLS for the Prometheus metrics of the brokers
+//
+// Redpanda serves the metrics on the Admin API listener, so the metrics are
+// protected by the Admin API TLS configuration, which has to be enabled.
+// If Enabled is set to true, the ServiceMonitor scrapes the metrics over TLS
+// and verifies the Admin API node certificate.
+//
+// If Admin API RequireClientAuth is set to true, a client certificate for
+// the scraper is generated, which can be retrieved from the Secret named
+// '<redpanda-cluster-name>-metrics-client'.
+type MetricsTLS struct {
+	Enabled bool `json:"enabled,omitempty"`
 }
 
 // SocketAddress provide the way to configure the port
@@ -255,7 +1196,220 @@ func init() {
 	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
 }
 
+// SeparateExternalAdmin returns true if the Admin API is exposed by a
+// Service of its own, without the Kafka API
+func (r *Cluster) SeparateExternalAdmin() bool {
+	return r.Spec.ExternalAdmin.Enabled && !r.Spec.ExternalConnectivity.Enabled
+}
+
+// SeparateExternalCert returns true if the external Kafka API listener is
+// served with its own certificate
+func (r *Cluster) SeparateExternalCert() bool {
+	return r.Spec.Configuration.TLS.KafkaAPI.Enabled &&
+		r.Spec.Configuration.TLS.KafkaAPI.SeparateExternalCert &&
+		r.Spec.ExternalConnectivity.Enabled
+}
+
+// KafkaAPIListeners returns the declared Listeners, or the Internal and the
+// External listeners derived from the KafkaAPI port, ExternalConnectivity
+// and Kafka API TLS. With external connectivity the TLS is applied to the
+// External listener only, unless it has a certificate of its own. The
+// derived listeners advertise the AdvertisedKafkaAPIPorts.
+func (r *Cluster) KafkaAPIListeners() []ListenerSpec {
+	if len(r.Spec.Configuration.Listeners) > 0 {
+		return r.Spec.Configuration.Listeners
+	}
+	kafkaTLS := r.Spec.Configuration.TLS.KafkaAPI
+	external := r.Spec.ExternalConnectivity.Enabled
+	internalTLS := kafkaTLS.Enabled && (!external || r.SeparateExternalCert())
+	var advertised AdvertisedKafkaAPIPorts
+	if r.Spec.Configuration.AdvertisedKafkaAPIPorts != nil {
+		advertised = *r.Spec.Configuration.AdvertisedKafkaAPIPorts
+	}
+	listeners := []ListenerSpec{{
+		Name:              InternalListener,
+		Port:              r.Spec.Configuration.KafkaAPI.Port,
+		AdvertisedPort:    advertised.Internal,
+		TLS:               internalTLS,
+		RequireClientAuth: internalTLS && kafkaTLS.RequireClientAuth,
+	}}
+	if external {
+		listeners = append(listeners, ListenerSpec{
+			Name:              ExternalListener,
+			Port:              r.Spec.Configuration.KafkaAPI.Port + 1,
+			AdvertisedPort:    advertised.External,
+			TLS:               kafkaTLS.Enabled,
+			RequireClientAuth: kafkaTLS.Enabled && kafkaTLS.RequireClientAuth,
+			External:          true,
+		})
+	}
+	return listeners
+}
+
+// InternalKafkaAPIListener returns the Internal listener of
+// KafkaAPIListeners, the operator and the Jobs of the cluster connect to it
+func (r *Cluster) InternalKafkaAPIListener() ListenerSpec {
+	for _, l := range r.KafkaAPIListeners() {
+		if l.Name == InternalListener {
+			return l
+		}
+	}
+	// the webhook requires the Internal listener on the KafkaAPI port
+	return ListenerSpec{Name: InternalListener, Port: r.Spec.Configuration.KafkaAPI.Port}
+}
+
+// EnabledSASLMechanisms returns the mechanisms enabled on the SASL listeners
+func (r *Cluster) EnabledSASLMechanisms() []SASLMechanism {
+	if len(r.Spec.SASLMechanisms) == 0 {
+		return []SASLMechanism{SCRAMSHA256}
+	}
+	return r.Spec.SASLMechanisms
+}
+
+// SuperuserMechanism returns the SCRAM mechanism of the user, users that
+// are not superusers are created with the default mechanism
+func (r *Cluster) SuperuserMechanism(username string) SASLMechanism {
+	for _, superuser := range r.Spec.Superusers {
+		if superuser.Username == username && superuser.Mechanism != "" {
+			return superuser.Mechanism
+		}
+	}
+	return SCRAMSHA256
+}
+
+// StatefulSetZones returns the zones of the zone StatefulSets, nil if the
+// brokers run in a single StatefulSet
+func (r *Cluster) StatefulSetZones() []string {
+	if r.Spec.ZoneStatefulSets == nil {
+		return nil
+	}
+	return r.Spec.ZoneStatefulSets.Zones
+}
+
+// CloudStorageStaticCredentials returns true if the brokers authenticate to
+// the cloud storage with the access and secret keys
+func (r *Cluster) CloudStorageStaticCredentials() bool {
+	return r.Spec.CloudStorage.Enabled && r.Spec.CloudStorage.ProjectedToken == nil
+}
+
+// CloudStorageEndpointPort returns the port the brokers connect to the
+// object store on
+func (r *Cluster) CloudStorageEndpointPort() int {
+	if r.Spec.CloudStorage.APIEndpointPort != 0 {
+		return r.Spec.CloudStorage.APIEndpointPort
+	}
+	return defaultCloudStoragePort
+}
+
+// ReconciliationPaused returns true if the operator must not modify the
+// resources of the Cluster
+func (r *Cluster) ReconciliationPaused() bool {
+	return r.Annotations[ManagedAnnotation] == "false"
+}
+
+// DebugDumpRequest returns the ID of the requested debug dump, or empty
+// string when no dump is requested
+func (r *Cluster) DebugDumpRequest() string {
+	return r.Annotations[DebugDumpAnnotation]
+}
+
+// ResourcePresets are the CPU and memory of the Redpanda container by
+// ResourcePreset name
+var ResourcePresets = map[string]corev1.ResourceList{
+	"small": {
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+	},
+	"medium": {
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	},
+	"large": {
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	},
+}
+
+// ContainerResources returns Resources completed with the ResourcePreset.
+// The preset fills the resources missing from both requests and limits,
+// so explicit values are never combined with the preset ones.
+func (r *Cluster) ContainerResources() corev1.ResourceRequirements {
+	resources := *r.Spec.Resources.DeepCopy()
+	preset, ok := ResourcePresets[r.Spec.ResourcePreset]
+	if !ok {
+		return resources
+	}
+	for name, quantity := range preset {
+		_, requested := resources.Requests[name]
+		_, limited := resources.Limits[name]
+		if requested || limited {
+			continue
+		}
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		resources.Requests[name] = quantity.DeepCopy()
+		resources.Limits[name] = quantity.DeepCopy()
+	}
+	return resources
+}
+
+// ResourcesManagedByVPA returns true if the operator must keep the
+// resources of the redpanda container of the existing StatefulSet
+func (r *Cluster) ResourcesManagedByVPA() bool {
+	return r.Annotations[VPAManagedAnnotation] == "true"
+}
+
+// IsPrincipalAuthorized returns true if the user is a superuser or has an
+// ACL that allows any operation, e.g. the CN of a client certificate
+func (r *Cluster) IsPrincipalAuthorized(username string) bool {
+	for _, superuser := range r.Spec.Superusers {
+		if superuser.Username == username {
+			return true
+		}
+	}
+	for _, acl := range r.Spec.ACLs {
+		if acl.Permission != "" && acl.Permission != "Allow" {
+			continue
+		}
+		if acl.Principal == "User:"+username || acl.Principal == "User:*" {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultClusterDomain is the DNS domain of Kubernetes clusters unless
+// configured otherwise in the kubelet
+const DefaultClusterDomain = "cluster.local"
+
+// ClusterDomain returns the DNS domain of the Kubernetes cluster
+func (r *Cluster) ClusterDomain() string {
+	if r.Spec.ClusterDomain != "" {
+		return strings.TrimSuffix(r.Spec.ClusterDomain, ".")
+	}
+	return DefaultClusterDomain
+}
+
 // FullImageName returns image name including version
 func (r *Cluster) FullImageName() string {
 	return fmt.Sprintf("%s:%s", r.Spec.Image, r.Spec.Version)
 }
+
+// imageArchitectures are the architectures of the single-arch images, the
+// tags of which end with the architecture suffix
+var imageArchitectures = []string{"amd64", "arm64"}
+
+// ImageArchitecture returns the architecture of the image derived from the
+// suffix of Version, or an empty string for multi-arch images
+func (r *Cluster) ImageArchitecture() string {
+	for _, arch := range imageArchitectures {
+		if strings.HasSuffix(r.Spec.Version, "-"+arch) {
+			return arch
+		}
+	}
+	return ""
+}
@@ -10,9 +10,19 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -23,6 +33,25 @@ const (
 	kb = 1024
 	mb = 1024 * kb
 	gb = 1024 * mb
+
+	cronFieldsCount = 5
+
+	maxPort = 65535
+
+	issuerKind        = "Issuer"
+	clusterIssuerKind = "ClusterIssuer"
+
+	minClockSkewSeconds = 2
+	// maxStartupDelaySeconds bounds the delay between broker starts, so
+	// the last broker of a large cluster still starts in a reasonable time
+	maxStartupDelaySeconds = 600
+	// open files limit bounds, Redpanda needs more than the usual default
+	// and the kernel caps the limit at fs.nr_open, 1048576 by default
+	minOpenFilesLimit = 1024
+	maxOpenFilesLimit = 1048576
+	// DNS limits of the names in certificate SANs
+	maxDNSNameLength  = 253
+	maxDNSLabelLength = 63
 )
 
 // log is for logging in this package.
@@ -63,6 +92,9 @@ func (r *Cluster) ValidateCreate() error {
 	allErrs = append(allErrs, r.validateTLS()...)
 
 	allErrs = append(allErrs, r.validateArchivalStorage()...)
+	allErrs = append(allErrs, r.validateCloudStorageDeveloperMode()...)
+
+	allErrs = append(allErrs, r.validateSpec()...)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -79,12 +111,15 @@ func (r *Cluster) ValidateUpdate(old runtime.Object) error {
 	oldCluster := old.(*Cluster)
 	var allErrs field.ErrorList
 
-	if r.Spec.Replicas != nil && oldCluster.Spec.Replicas != nil && *r.Spec.Replicas < *oldCluster.Spec.Replicas {
-		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec").Child("replicas"),
-				r.Spec.Replicas,
-				"scaling down is not supported"))
-	}
+	allErrs = append(allErrs, r.validateReplicasChange(oldCluster)...)
+
+	allErrs = append(allErrs, r.validateScaleDownQuorum(oldCluster)...)
+
+	allErrs = append(allErrs, r.validateStorageChange(oldCluster)...)
+
+	allErrs = append(allErrs, r.validateClusterDomainChange(oldCluster)...)
+
+	allErrs = append(allErrs, r.validateZoneStatefulSetsChange(oldCluster)...)
 
 	allErrs = append(allErrs, r.checkCollidingPorts()...)
 
@@ -93,6 +128,9 @@ func (r *Cluster) ValidateUpdate(old runtime.Object) error {
 	allErrs = append(allErrs, r.validateTLS()...)
 
 	allErrs = append(allErrs, r.validateArchivalStorage()...)
+	allErrs = append(allErrs, r.validateCloudStorageDeveloperModeChange(oldCluster)...)
+
+	allErrs = append(allErrs, newErrors(r.validateSpec(), oldCluster.validateSpec())...)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -103,6 +141,99 @@ func (r *Cluster) ValidateUpdate(old runtime.Object) error {
 		r.Name, allErrs)
 }
 
+// validateSpec verifies the spec against the rules shared by create and
+// update that the existing Clusters might have been created without
+func (r *Cluster) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, r.validateResources()...)
+
+	allErrs = append(allErrs, r.validateResourcePreset()...)
+
+	allErrs = append(allErrs, r.validateAdminAPITLS()...)
+	allErrs = append(allErrs, r.validateIssuers()...)
+	allErrs = append(allErrs, r.validateSharedNodeCert()...)
+	allErrs = append(allErrs, r.validateSeparateExternalCert()...)
+	allErrs = append(allErrs, r.validateCASecretNamespaces()...)
+
+	allErrs = append(allErrs, r.validateProjectedToken()...)
+	allErrs = append(allErrs, r.validateCloudStorageEgress()...)
+
+	allErrs = append(allErrs, r.validateLoadBalancerTags()...)
+
+	allErrs = append(allErrs, r.validateClientQuotas()...)
+
+	allErrs = append(allErrs, r.validateAdditionalConfiguration()...)
+
+	allErrs = append(allErrs, r.validateRunAs()...)
+
+	allErrs = append(allErrs, r.validateMetadataBackup()...)
+
+	allErrs = append(allErrs, r.validateSeccompProfile()...)
+
+	allErrs = append(allErrs, r.validateReplicas()...)
+
+	allErrs = append(allErrs, r.validateStorage()...)
+
+	allErrs = append(allErrs, r.validateSuperusers()...)
+	allErrs = append(allErrs, r.validateSASLMechanisms()...)
+
+	allErrs = append(allErrs, r.validateSubdomain()...)
+	allErrs = append(allErrs, r.validateSubdomainSANs()...)
+
+	allErrs = append(allErrs, r.validateBootstrapTopics()...)
+
+	allErrs = append(allErrs, r.validateDisruption()...)
+
+	allErrs = append(allErrs, r.validateKafkaAPIPorts()...)
+
+	allErrs = append(allErrs, r.validateListeners()...)
+
+	allErrs = append(allErrs, r.validateReservedPorts()...)
+
+	allErrs = append(allErrs, r.validateExtras()...)
+
+	allErrs = append(allErrs, r.validateLogLevel()...)
+
+	allErrs = append(allErrs, r.validateACLs()...)
+
+	allErrs = append(allErrs, r.validateHostNetwork()...)
+	allErrs = append(allErrs, r.validateExternalAdmin()...)
+	allErrs = append(allErrs, r.validateSessionAffinity()...)
+	allErrs = append(allErrs, r.validateClockSkew()...)
+
+	allErrs = append(allErrs, r.validatePasswordRotation()...)
+
+	allErrs = append(allErrs, r.validateStartupDelay()...)
+	allErrs = append(allErrs, r.validateOpenFilesLimit()...)
+	allErrs = append(allErrs, r.validateLivenessProbe()...)
+
+	allErrs = append(allErrs, r.validateDedicatedNodes()...)
+	allErrs = append(allErrs, r.validateZoneStatefulSets()...)
+
+	allErrs = append(allErrs, r.validateClusterDomain()...)
+
+	return allErrs
+}
+
+// newErrors returns the errors the existing Cluster doesn't have. Updates
+// that leave invalid fields of a Cluster created before the rule was added
+// unchanged, e.g. the annotation patches of the operator, are not rejected
+// then.
+func newErrors(errs, existing field.ErrorList) field.ErrorList {
+	seen := make(map[string]bool, len(existing))
+	for _, err := range existing {
+		seen[err.Error()] = true
+	}
+	var result field.ErrorList
+	for _, err := range errs {
+		if !seen[err.Error()] {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
 // ReserveMemoryString is amount of memory that we reserve for other processes than redpanda in the container
 const ReserveMemoryString = "1M"
 
@@ -112,16 +243,403 @@ const ReserveMemoryString = "1M"
 func (r *Cluster) validateMemory() field.ErrorList {
 	var allErrs field.ErrorList
 	quantity := resource.MustParse(ReserveMemoryString)
-	if !r.Spec.Configuration.DeveloperMode && (r.Spec.Resources.Limits.Memory().Value()-quantity.Value()) < gb {
+	limits := r.ContainerResources().Limits
+	if !r.Spec.Configuration.DeveloperMode && (limits.Memory().Value()-quantity.Value()) < gb {
 		allErrs = append(allErrs,
 			field.Invalid(
 				field.NewPath("spec").Child("resources").Child("limits").Child("memory"),
-				r.Spec.Resources.Limits.Memory(),
+				limits.Memory(),
 				"need minimum of 1GB + 1MB of memory per node"))
 	}
 	return allErrs
 }
 
+// validateResources verifies that the requests and limits can be applied
+// to the redpanda container. Every broker shares the same container spec, so
+// there is no way to give a single broker more than what is declared here.
+// The checks mirror the validation of Pods, which would otherwise only fail
+// once the StatefulSet creates the brokers.
+func (r *Cluster) validateResources() field.ErrorList {
+	var allErrs field.ErrorList
+	resources := r.ContainerResources()
+	path := field.NewPath("spec").Child("resources")
+	allErrs = append(allErrs, validateResourceList(resources.Limits, path.Child("limits"))...)
+	allErrs = append(allErrs, validateResourceList(resources.Requests, path.Child("requests"))...)
+
+	for _, name := range sortedResourceNames(resources.Requests) {
+		request := resources.Requests[name]
+		limit, ok := resources.Limits[name]
+		if !ok {
+			continue
+		}
+		requestPath := path.Child("requests").Child(string(name))
+		switch {
+		case request.Cmp(limit) > 0:
+			allErrs = append(allErrs,
+				field.Invalid(requestPath, request.String(),
+					"request must be less than or equal to the limit, resources are shared by all brokers"))
+		case !isOvercommitAllowed(name) && request.Cmp(limit) != 0:
+			allErrs = append(allErrs,
+				field.Invalid(requestPath, request.String(),
+					fmt.Sprintf("request of %s must be equal to the limit", name)))
+		}
+	}
+	return allErrs
+}
+
+// validateResourceList rejects negative quantities and resources that can't
+// be assigned to a container
+func validateResourceList(
+	list corev1.ResourceList, path *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, name := range sortedResourceNames(list) {
+		quantity := list[name]
+		namePath := path.Child(string(name))
+		if !isContainerResource(name) {
+			allErrs = append(allErrs,
+				field.Invalid(namePath, string(name),
+					"only cpu, memory, ephemeral-storage, hugepages and extended resources can be assigned to the redpanda container"))
+			continue
+		}
+		if quantity.Sign() < 0 {
+			allErrs = append(allErrs,
+				field.Invalid(namePath, quantity.String(), "must be greater than or equal to 0"))
+		}
+	}
+	return allErrs
+}
+
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// isExtendedResource returns true for the resources advertised by device
+// plugins, e.g. nvidia.com/gpu
+func isExtendedResource(name corev1.ResourceName) bool {
+	return strings.Contains(string(name), "/") &&
+		!strings.HasPrefix(string(name), corev1.ResourceDefaultNamespacePrefix)
+}
+
+func isContainerResource(name corev1.ResourceName) bool {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
+		return true
+	}
+	return strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) || isExtendedResource(name)
+}
+
+// isOvercommitAllowed returns false for the resources which requests have
+// to be equal to the limits
+func isOvercommitAllowed(name corev1.ResourceName) bool {
+	return !strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) && !isExtendedResource(name)
+}
+
+// validateResourcePreset verifies that the preset is known
+func (r *Cluster) validateResourcePreset() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.ResourcePreset == "" {
+		return allErrs
+	}
+	if _, ok := ResourcePresets[r.Spec.ResourcePreset]; !ok {
+		presets := make([]string, 0, len(ResourcePresets))
+		for name := range ResourcePresets {
+			presets = append(presets, name)
+		}
+		sort.Strings(presets)
+		allErrs = append(allErrs,
+			field.NotSupported(field.NewPath("spec").Child("resourcePreset"), r.Spec.ResourcePreset, presets))
+	}
+	return allErrs
+}
+
+// MinimumStorageCapacityString is the smallest data directory Redpanda can
+// boot with
+const MinimumStorageCapacityString = "1Gi"
+
+// validateReplicas rejects clusters without brokers
+func (r *Cluster) validateReplicas() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Replicas != nil && *r.Spec.Replicas < 1 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("replicas"), *r.Spec.Replicas,
+				"cluster needs at least one replica"))
+	}
+	return allErrs
+}
+
+// validateDisruption verifies that the disruption budget can be satisfied
+// and that the rolling update restarts at least one broker at once, and
+// more than one only while the majority of the brokers stays available
+func (r *Cluster) validateDisruption() field.ErrorList {
+	var allErrs field.ErrorList
+	var replicas int32
+	if r.Spec.Replicas != nil {
+		replicas = *r.Spec.Replicas
+	}
+	if pdb := r.Spec.PodDisruptionBudget; pdb != nil && pdb.MinAvailable != nil &&
+		(*pdb.MinAvailable < 0 || *pdb.MinAvailable > replicas) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("podDisruptionBudget").Child("minAvailable"), *pdb.MinAvailable,
+				"has to be between 0 and the number of replicas"))
+	}
+	if r.Spec.RollingUpdate != nil && r.Spec.RollingUpdate.MaxUnavailable < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("rollingUpdate").Child("maxUnavailable"), r.Spec.RollingUpdate.MaxUnavailable,
+				"cannot be negative"))
+	} else if r.Spec.RollingUpdate != nil && r.Spec.RollingUpdate.MaxUnavailable > 1 &&
+		r.Spec.RollingUpdate.MaxUnavailable > (replicas-1)/2 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("rollingUpdate").Child("maxUnavailable"), r.Spec.RollingUpdate.MaxUnavailable,
+				fmt.Sprintf("restarting more than %d brokers at once loses the majority of the replicas", (replicas-1)/2)))
+	}
+	return allErrs
+}
+
+// validateStorage verifies that the requested data directory capacity is
+// enough for Redpanda to boot and holds the expected local retention and
+// the cloud storage cache. Zero capacity means the default is used.
+func (r *Cluster) validateStorage() field.ErrorList {
+	var allErrs field.ErrorList
+	capacity := r.Spec.Storage.Capacity
+	minimum := resource.MustParse(MinimumStorageCapacityString)
+	if !capacity.IsZero() && capacity.Cmp(minimum) < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("storage").Child("capacity"), capacity.String(),
+				"need minimum of "+MinimumStorageCapacityString+" of storage per node"))
+	}
+
+	retention := r.Spec.Storage.LocalRetention
+	retentionPath := field.NewPath("spec").Child("storage").Child("localRetention")
+	if retention != nil && retention.Sign() < 0 {
+		allErrs = append(allErrs,
+			field.Invalid(retentionPath, retention.String(), "cannot be negative"))
+		return allErrs
+	}
+	var cache *resource.Quantity
+	cachePath := field.NewPath("spec").Child("cloudStorage").Child("cacheSize")
+	if r.Spec.CloudStorage.Enabled {
+		cache = r.Spec.CloudStorage.CacheSize
+	}
+	if cache != nil && cache.Sign() <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(cachePath, cache.String(), "has to be positive"))
+		return allErrs
+	}
+	if capacity.IsZero() || (retention == nil && cache == nil) {
+		return allErrs
+	}
+
+	used := resource.Quantity{}
+	path := cachePath
+	if cache != nil {
+		used.Add(*cache)
+	}
+	if retention != nil {
+		used.Add(*retention)
+		path = retentionPath
+	}
+	if used.Cmp(capacity) > 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path, used.String(),
+				fmt.Sprintf("local retention and cloud storage cache need %s, which exceeds the storage capacity %s", used.String(), capacity.String())))
+	}
+	return allErrs
+}
+
+// validateSuperusers rejects superusers listed more than once and
+// incomplete password references
+func (r *Cluster) validateSuperusers() field.ErrorList {
+	var allErrs field.ErrorList
+	usernames := map[string]bool{}
+	for i, superuser := range r.Spec.Superusers {
+		path := field.NewPath("spec").Child("superUsers").Index(i)
+		if usernames[superuser.Username] {
+			allErrs = append(allErrs,
+				field.Duplicate(path.Child("username"), superuser.Username))
+		}
+		usernames[superuser.Username] = true
+		if ref := superuser.PasswordSecretKeyRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+			allErrs = append(allErrs,
+				field.Required(path.Child("passwordSecretKeyRef"), "both Secret name and key have to be provided"))
+		}
+	}
+	return allErrs
+}
+
+// validateSASLMechanisms rejects unsupported mechanisms and superusers
+// whose mechanism is not enabled on the SASL listeners, as they would not be
+// able to authenticate
+func (r *Cluster) validateSASLMechanisms() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("saslMechanisms")
+	enabled := map[SASLMechanism]bool{}
+	for i, mechanism := range r.Spec.SASLMechanisms {
+		if !isSASLMechanism(mechanism) {
+			allErrs = append(allErrs,
+				field.NotSupported(path.Index(i), mechanism, SASLMechanisms))
+		}
+		if enabled[mechanism] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), mechanism))
+		}
+		enabled[mechanism] = true
+	}
+	for _, mechanism := range r.EnabledSASLMechanisms() {
+		enabled[mechanism] = true
+	}
+
+	for i, superuser := range r.Spec.Superusers {
+		mechanismPath := field.NewPath("spec").Child("superUsers").Index(i).Child("mechanism")
+		mechanism := superuser.Mechanism
+		if mechanism == "" {
+			mechanism = SCRAMSHA256
+		}
+		switch {
+		case !isSASLMechanism(mechanism):
+			allErrs = append(allErrs,
+				field.NotSupported(mechanismPath, mechanism, SASLMechanisms))
+		case r.Spec.EnableSASL && !enabled[mechanism]:
+			allErrs = append(allErrs,
+				field.Invalid(mechanismPath, mechanism,
+					fmt.Sprintf("the mechanism is not enabled on the SASL listeners, add it to %s", path)))
+		}
+	}
+	return allErrs
+}
+
+func isSASLMechanism(mechanism SASLMechanism) bool {
+	for _, supported := range SASLMechanisms {
+		if string(mechanism) == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSubdomain verifies that the brokers can be addressed under the
+// external connectivity subdomain
+func (r *Cluster) validateSubdomain() field.ErrorList {
+	var allErrs field.ErrorList
+	subdomain := r.Spec.ExternalConnectivity.Subdomain
+	switch r.Spec.ExternalConnectivity.Type {
+	case ExternalConnectivitySubdomain:
+		if subdomain == "" {
+			allErrs = append(allErrs,
+				field.Required(field.NewPath("spec").Child("externalConnectivity").Child("subdomain"), "subdomain has to be provided for the Subdomain type"))
+		}
+	case ExternalConnectivityNodePort, ExternalConnectivityLoadBalancer:
+		if subdomain != "" {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec").Child("externalConnectivity").Child("type"), r.Spec.ExternalConnectivity.Type, "subdomain is advertised only by the Subdomain type"))
+		}
+	}
+	if timeout := r.Spec.ExternalConnectivity.DNSPropagationTimeoutSeconds; timeout != nil {
+		path := field.NewPath("spec").Child("externalConnectivity").Child("dnsPropagationTimeoutSeconds")
+		if !r.Spec.ExternalConnectivity.Enabled || subdomain == "" {
+			allErrs = append(allErrs,
+				field.Invalid(path, *timeout, "waiting for DNS propagation requires external connectivity with subdomain"))
+		}
+		if *timeout < 1 {
+			allErrs = append(allErrs,
+				field.Invalid(path, *timeout, "timeout has to be positive"))
+		}
+	}
+	if subdomain == "" {
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(subdomain, ".")) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("externalConnectivity").Child("subdomain"), subdomain, msg))
+	}
+	return allErrs
+}
+
+// validateSubdomainSANs verifies that the node certificates can be issued
+// for the external subdomains. The certificates cover a subdomain with a
+// wildcard SAN and the brokers are advertised as '<ordinal>.<subdomain>', both
+// have to fit the DNS limits, otherwise cert-manager fails to issue the
+// certificates. The CN doesn't depend on the subdomain, it's shortened to the
+// 64 characters allowed by cert-manager.
+func (r *Cluster) validateSubdomainSANs() field.ErrorList {
+	var allErrs field.ErrorList
+	tls := r.Spec.Configuration.TLS
+	if tls.KafkaAPI.Enabled && r.Spec.ExternalConnectivity.Enabled && r.Spec.ExternalConnectivity.Subdomain != "" {
+		allErrs = append(allErrs,
+			r.subdomainSANErrors(field.NewPath("spec").Child("externalConnectivity").Child("subdomain"),
+				r.Spec.ExternalConnectivity.Subdomain)...)
+	}
+	if tls.AdminAPI.Enabled && r.SeparateExternalAdmin() && r.Spec.ExternalAdmin.Subdomain != "" {
+		allErrs = append(allErrs,
+			r.subdomainSANErrors(field.NewPath("spec").Child("externalAdmin").Child("subdomain"),
+				r.Spec.ExternalAdmin.Subdomain)...)
+	}
+	return allErrs
+}
+
+func (r *Cluster) subdomainSANErrors(
+	path *field.Path, subdomain string,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	domain := strings.TrimSuffix(subdomain, ".")
+	for _, label := range strings.Split(domain, ".") {
+		if len(label) > maxDNSLabelLength {
+			allErrs = append(allErrs,
+				field.Invalid(path, subdomain,
+					fmt.Sprintf("label %s has %d characters, labels of certificate SANs can't exceed %d characters",
+						label, len(label), maxDNSLabelLength)))
+		}
+	}
+
+	// the wildcard is shorter than the ordinal of the last broker from
+	// the tenth broker on
+	prefix := "*"
+	if r.Spec.Replicas != nil && *r.Spec.Replicas > 0 {
+		if last := strconv.Itoa(int(*r.Spec.Replicas - 1)); len(last) > len(prefix) {
+			prefix = last
+		}
+	}
+	if length := len(prefix) + 1 + len(domain); length > maxDNSNameLength {
+		allErrs = append(allErrs,
+			field.Invalid(path, subdomain,
+				fmt.Sprintf("%s.<subdomain> has %d characters, names in certificate SANs can't exceed %d characters, shorten the subdomain by %d characters",
+					prefix, length, maxDNSNameLength, length-maxDNSNameLength)))
+	}
+	return allErrs
+}
+
+// validateBootstrapTopics verifies that the topics can be created by the
+// brokers of the cluster
+func (r *Cluster) validateBootstrapTopics() field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{}
+	for i, topic := range r.Spec.BootstrapTopics {
+		path := field.NewPath("spec").Child("bootstrapTopics").Index(i)
+		if topic.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "topic name has to be provided"))
+		} else if names[topic.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), topic.Name))
+		}
+		names[topic.Name] = true
+		if topic.Partitions < 1 {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("partitions"), topic.Partitions, "topic needs at least one partition"))
+		}
+		if topic.ReplicationFactor < 1 {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("replicationFactor"), topic.ReplicationFactor, "topic needs at least one replica"))
+		} else if r.Spec.Replicas != nil && topic.ReplicationFactor > int(*r.Spec.Replicas) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("replicationFactor"), topic.ReplicationFactor, "replication factor can't exceed the number of brokers"))
+		}
+	}
+	return allErrs
+}
+
 func (r *Cluster) validateTLS() field.ErrorList {
 	var allErrs field.ErrorList
 	if r.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth && !r.Spec.Configuration.TLS.KafkaAPI.Enabled {
@@ -141,94 +659,1342 @@ func (r *Cluster) validateTLS() field.ErrorList {
 	return allErrs
 }
 
-func (r *Cluster) validateArchivalStorage() field.ErrorList {
+// validateAdminAPITLS verifies that the client auth and the metrics served
+// with TLS have the Admin API TLS enabled
+func (r *Cluster) validateAdminAPITLS() field.ErrorList {
 	var allErrs field.ErrorList
-	if !r.Spec.CloudStorage.Enabled {
-		return allErrs
-	}
-	if r.Spec.CloudStorage.AccessKey == "" {
+	if r.Spec.Configuration.TLS.AdminAPI.RequireClientAuth && !r.Spec.Configuration.TLS.AdminAPI.Enabled {
 		allErrs = append(allErrs,
 			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("accessKey"),
-				r.Spec.CloudStorage.AccessKey,
-				"AccessKey has to be provided for cloud storage to be enabled"))
+				field.NewPath("spec").Child("configuration").Child("tls").Child("adminApi").Child("requireClientAuth"),
+				r.Spec.Configuration.TLS.AdminAPI.RequireClientAuth,
+				"Enabled has to be set to true for RequireClientAuth to be allowed to be true, otherwise there is no issuer for client certificates"))
 	}
-	if r.Spec.CloudStorage.Bucket == "" {
+	if r.Spec.Configuration.TLS.Metrics.Enabled && !r.Spec.Configuration.TLS.AdminAPI.Enabled {
 		allErrs = append(allErrs,
 			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("bucket"),
-				r.Spec.CloudStorage.Bucket,
-				"Bucket has to be provided for cloud storage to be enabled"))
+				field.NewPath("spec").Child("configuration").Child("tls").Child("metrics").Child("enabled"),
+				r.Spec.Configuration.TLS.Metrics.Enabled,
+				"metrics are served by the Admin API, which has to have TLS enabled"))
 	}
-	if r.Spec.CloudStorage.Region == "" {
-		allErrs = append(allErrs,
-			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("region"),
-				r.Spec.CloudStorage.Region,
-				"Region has to be provided for cloud storage to be enabled"))
+	return allErrs
+}
+
+// validateIssuers verifies the issuers of the Kafka API and Admin API
+// certificates
+func (r *Cluster) validateIssuers() field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs,
+		validateIssuerRef(r.Spec.Configuration.TLS.KafkaAPI.IssuerRef,
+			field.NewPath("spec").Child("configuration").Child("tls").Child("kafkaApi").Child("issuerRef"))...)
+	allErrs = append(allErrs,
+		validateIssuerRef(r.Spec.Configuration.TLS.AdminAPI.IssuerRef,
+			field.NewPath("spec").Child("configuration").Child("tls").Child("adminApi").Child("issuerRef"))...)
+	allErrs = append(allErrs,
+		validateIssuerNamespace(r.Spec.Configuration.TLS.KafkaAPI.IssuerRef,
+			field.NewPath("spec").Child("configuration").Child("tls").Child("kafkaApi").Child("issuerRef"))...)
+	allErrs = append(allErrs,
+		validateIssuerNamespace(r.Spec.Configuration.TLS.AdminAPI.IssuerRef,
+			field.NewPath("spec").Child("configuration").Child("tls").Child("adminApi").Child("issuerRef"))...)
+	return allErrs
+}
+
+// validateCASecretNamespaces verifies that the CA certificate can be copied
+// to the listed namespaces
+func (r *Cluster) validateCASecretNamespaces() field.ErrorList {
+	var allErrs field.ErrorList
+	kafkaTLS := r.Spec.Configuration.TLS.KafkaAPI
+	if len(kafkaTLS.CASecretNamespaces) == 0 {
+		return allErrs
 	}
-	if r.Spec.CloudStorage.SecretKeyRef.Name == "" {
+	path := field.NewPath("spec").Child("configuration").Child("tls").Child("kafkaApi").Child("caSecretNamespaces")
+	if !kafkaTLS.Enabled {
 		allErrs = append(allErrs,
-			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("secretKeyRef").Child("name"),
-				r.Spec.CloudStorage.SecretKeyRef.Name,
-				"SecretKeyRef name has to be provided for cloud storage to be enabled"))
+			field.Invalid(path, kafkaTLS.CASecretNamespaces,
+				"TLS has to be enabled for the CA certificate to be copied"))
 	}
-	if r.Spec.CloudStorage.SecretKeyRef.Namespace == "" {
-		allErrs = append(allErrs,
-			field.Invalid(
-				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("secretKeyRef").Child("namespace"),
-				r.Spec.CloudStorage.SecretKeyRef.Namespace,
-				"SecretKeyRef namespace has to be provided for cloud storage to be enabled"))
+	namespaces := map[string]bool{}
+	for i, namespace := range kafkaTLS.CASecretNamespaces {
+		switch {
+		case namespaces[namespace]:
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), namespace))
+		case namespace == r.Namespace:
+			allErrs = append(allErrs,
+				field.Invalid(path.Index(i), namespace,
+					"the CA certificate is already available in the namespace of the cluster"))
+		default:
+			for _, msg := range validation.IsDNS1123Label(namespace) {
+				allErrs = append(allErrs, field.Invalid(path.Index(i), namespace, msg))
+			}
+		}
+		namespaces[namespace] = true
 	}
 	return allErrs
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *Cluster) ValidateDelete() error {
-	log.Info("validate delete", "name", r.Name)
-
-	// TODO(user): fill in your validation logic upon object deletion.
-	return nil
+// validateSeparateExternalCert verifies that there is an external subdomain
+// to issue the dedicated certificate for
+func (r *Cluster) validateSeparateExternalCert() field.ErrorList {
+	var allErrs field.ErrorList
+	kafkaTLS := r.Spec.Configuration.TLS.KafkaAPI
+	if !kafkaTLS.SeparateExternalCert {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("tls").Child("kafkaApi").Child("separateExternalCert")
+	if !kafkaTLS.Enabled || !r.Spec.ExternalConnectivity.Enabled || r.Spec.ExternalConnectivity.Subdomain == "" {
+		allErrs = append(allErrs,
+			field.Invalid(path, kafkaTLS.SeparateExternalCert,
+				"TLS and external connectivity with subdomain have to be enabled for separate external certificate"))
+	}
+	if kafkaTLS.NodeSecretRef != nil {
+		allErrs = append(allErrs,
+			field.Invalid(path, kafkaTLS.SeparateExternalCert,
+				"Cannot provide both NodeSecretRef and SeparateExternalCert"))
+	}
+	return allErrs
 }
 
-func (r *Cluster) checkCollidingPorts() field.ErrorList {
+// validateSharedNodeCert verifies that single node certificate can serve
+// both Kafka API and Admin API listeners
+func (r *Cluster) validateSharedNodeCert() field.ErrorList {
 	var allErrs field.ErrorList
-
-	if r.Spec.Configuration.AdminAPI.Port == r.Spec.Configuration.KafkaAPI.Port {
+	tls := r.Spec.Configuration.TLS
+	if !tls.SharedNodeCert {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("tls").Child("sharedNodeCert")
+	if !tls.KafkaAPI.Enabled || !tls.AdminAPI.Enabled {
 		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec").Child("configuration", "admin", "port"),
-				r.Spec.Configuration.AdminAPI.Port,
-				"admin port collide with Spec.Configuration.KafkaAPI.Port"))
+			field.Invalid(path, tls.SharedNodeCert,
+				"TLS has to be enabled for both Kafka API and Admin API to share node certificate"))
 	}
-
-	if r.Spec.Configuration.RPCServer.Port == r.Spec.Configuration.KafkaAPI.Port {
+	if tls.KafkaAPI.NodeSecretRef != nil {
 		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec").Child("configuration", "rpcServer", "port"),
-				r.Spec.Configuration.RPCServer.Port,
-				"rpc port collide with Spec.Configuration.KafkaAPI.Port"))
+			field.Invalid(path, tls.SharedNodeCert,
+				"Cannot share node certificate provided in NodeSecretRef"))
 	}
-
-	if r.Spec.Configuration.AdminAPI.Port == r.Spec.Configuration.RPCServer.Port {
+	if tls.AdminAPI.IssuerRef != nil {
 		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec").Child("configuration", "admin", "port"),
-				r.Spec.Configuration.AdminAPI.Port,
-				"admin port collide with Spec.Configuration.RPCServer.Port"))
+			field.Invalid(path, tls.SharedNodeCert,
+				"Shared node certificate is issued by Kafka API issuer, Admin API IssuerRef cannot be provided"))
 	}
+	return allErrs
+}
 
-	if r.Spec.ExternalConnectivity.Enabled && r.Spec.Configuration.KafkaAPI.Port+1 == r.Spec.Configuration.RPCServer.Port {
+// validateIssuerRef verifies that the reference points to cert-manager
+// Issuer or ClusterIssuer
+func validateIssuerRef(
+	issuerRef *cmmeta.ObjectReference, path *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if issuerRef == nil {
+		return allErrs
+	}
+	if issuerRef.Name == "" {
 		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec").Child("configuration", "rpcServer", "port"),
-				r.Spec.Configuration.RPCServer.Port,
-				"rpc port collide with external Kafka API that is not visible in the Cluster CR"))
+			field.Required(path.Child("name"), "issuer name has to be provided"))
+	}
+	switch issuerRef.Kind {
+	case "", issuerKind, clusterIssuerKind:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path.Child("kind"), issuerRef.Kind, []string{issuerKind, clusterIssuerKind}))
 	}
+	return allErrs
+}
 
-	if r.Spec.ExternalConnectivity.Enabled && r.Spec.Configuration.KafkaAPI.Port+1 == r.Spec.Configuration.AdminAPI.Port {
+// validateIssuerNamespace rejects Issuer referenced with its namespace.
+// Namespaced issuers can't sign certificates in other namespaces, a
+// ClusterIssuer has to be used instead. The existence of the Issuer in the
+// namespace of the cluster is reported by IssuerNotFound condition.
+func validateIssuerNamespace(
+	issuerRef *cmmeta.ObjectReference, path *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+	if issuerRef == nil || (issuerRef.Kind != "" && issuerRef.Kind != issuerKind) {
+		return allErrs
+	}
+	if strings.Contains(issuerRef.Name, "/") {
 		allErrs = append(allErrs,
-			field.Invalid(field.NewPath("spec").Child("configuration", "admin", "port"),
-				r.Spec.Configuration.AdminAPI.Port,
-				"admin port collide with external Kafka API that is not visible in the Cluster CR"))
+			field.Invalid(path.Child("name"), issuerRef.Name,
+				"an Issuer can only be referenced from its own namespace, use ClusterIssuer to share the issuer across namespaces"))
 	}
+	return allErrs
+}
 
+// validateRunAs rejects running Redpanda as root
+func (r *Cluster) validateRunAs() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.RunAsUser != nil && *r.Spec.RunAsUser <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("runAsUser"), *r.Spec.RunAsUser,
+				"Redpanda has to run as non-root user"))
+	}
+	if r.Spec.RunAsGroup != nil && *r.Spec.RunAsGroup <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("runAsGroup"), *r.Spec.RunAsGroup,
+				"Redpanda has to run as non-root group"))
+	}
+	return allErrs
+}
+
+// reservedVolumeNames are the volumes of the broker pods managed by the
+// operator
+var reservedVolumeNames = map[string]bool{
+	"datadir":             true,
+	"configmap-dir":       true,
+	"config-dir":          true,
+	"tmp-dir":             true,
+	"tlscert":             true,
+	"tlsexternalcert":     true,
+	"tlsca":               true,
+	"tlsadmincert":        true,
+	"cloud-storage-token": true,
+}
+
+// reservedEnvNames are the environment variables set by the operator in the
+// redpanda container and the init containers
+var reservedEnvNames = map[string]bool{
+	"REDPANDA_ENVIRONMENT":            true,
+	"POD_NAME":                        true,
+	"POD_NAMESPACE":                   true,
+	"POD_IP":                          true,
+	"AWS_WEB_IDENTITY_TOKEN_FILE":     true,
+	"AWS_ROLE_ARN":                    true,
+	"SERVICE_FQDN":                    true,
+	"CONFIG_SOURCE_DIR":               true,
+	"CONFIG_DESTINATION":              true,
+	"REDPANDA_RPC_PORT":               true,
+	"NODE_NAME":                       true,
+	"EXTERNAL_CONNECTIVITY":           true,
+	"EXTERNAL_CONNECTIVITY_SUBDOMAIN": true,
+	"EXTERNAL_CONNECTIVITY_TYPE":      true,
+	"HOST_PORT":                       true,
+}
+
+// validateExtras rejects extra volumes and environment variables colliding
+// with the ones managed by the operator. Extra volume mounts can only
+// reference the extra volumes.
+func (r *Cluster) validateExtras() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec")
+
+	volumes := make(map[string]bool, len(r.Spec.ExtraVolumes))
+	for i, v := range r.Spec.ExtraVolumes {
+		namePath := path.Child("extraVolumes").Index(i).Child("name")
+		switch {
+		case reservedVolumeNames[v.Name]:
+			allErrs = append(allErrs,
+				field.Invalid(namePath, v.Name, "volume name is managed by the operator"))
+		case volumes[v.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, v.Name))
+		}
+		volumes[v.Name] = true
+	}
+
+	for i, m := range r.Spec.ExtraVolumeMounts {
+		if !volumes[m.Name] || reservedVolumeNames[m.Name] {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("extraVolumeMounts").Index(i).Child("name"), m.Name,
+					"volume mount has to reference one of the extraVolumes"))
+		}
+	}
+
+	env := make(map[string]bool, len(r.Spec.ExtraEnv))
+	for i, e := range r.Spec.ExtraEnv {
+		namePath := path.Child("extraEnv").Index(i).Child("name")
+		switch {
+		case reservedEnvNames[e.Name]:
+			allErrs = append(allErrs,
+				field.Invalid(namePath, e.Name, "environment variable is set by the operator"))
+		case env[e.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, e.Name))
+		}
+		env[e.Name] = true
+	}
+	return allErrs
+}
+
+// validateLogLevel verifies that the brokers can start with the log level
+func (r *Cluster) validateLogLevel() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.LogLevel == "" {
+		return allErrs
+	}
+	for _, level := range LogLevels {
+		if r.Spec.LogLevel == level {
+			return allErrs
+		}
+	}
+	return append(allErrs,
+		field.NotSupported(field.NewPath("spec").Child("logLevel"), r.Spec.LogLevel, LogLevels))
+}
+
+var (
+	aclResourceTypes = []string{"Topic", "Group", "Cluster", "TransactionalID"}
+	aclPatternTypes  = []string{"Literal", "Prefixed"}
+	aclOperations    = []string{
+		"All", "Read", "Write", "Create", "Delete", "Alter", "Describe",
+		"ClusterAction", "DescribeConfigs", "AlterConfigs", "IdempotentWrite",
+	}
+	aclPermissions = []string{"Allow", "Deny"}
+)
+
+// aclClusterResourceName is the only name of the Cluster resource in Kafka
+const aclClusterResourceName = "kafka-cluster"
+
+// validateACLs verifies that the ACLs can be created by the Kafka API
+func (r *Cluster) validateACLs() field.ErrorList {
+	var allErrs field.ErrorList
+	for i, acl := range r.Spec.ACLs {
+		path := field.NewPath("spec").Child("acls").Index(i)
+		if !strings.HasPrefix(acl.Principal, "User:") || len(acl.Principal) == len("User:") {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("principal"), acl.Principal,
+					"principal must be in the User:<name> form"))
+		}
+		if !contains(aclResourceTypes, acl.ResourceType) {
+			allErrs = append(allErrs,
+				field.NotSupported(path.Child("resourceType"), acl.ResourceType, aclResourceTypes))
+		}
+		switch {
+		case acl.ResourceName == "":
+			allErrs = append(allErrs,
+				field.Required(path.Child("resourceName"), "resource name has to be provided"))
+		case acl.ResourceType == "Cluster" && acl.ResourceName != aclClusterResourceName:
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("resourceName"), acl.ResourceName,
+					"the name of the Cluster resource must be "+aclClusterResourceName))
+		case acl.ResourceName == "*" && acl.PatternType == "Prefixed":
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("resourceName"), acl.ResourceName,
+					"* matches all resources only with Literal pattern type"))
+		}
+		if acl.PatternType != "" && !contains(aclPatternTypes, acl.PatternType) {
+			allErrs = append(allErrs,
+				field.NotSupported(path.Child("patternType"), acl.PatternType, aclPatternTypes))
+		}
+		if !contains(aclOperations, acl.Operation) {
+			allErrs = append(allErrs,
+				field.NotSupported(path.Child("operation"), acl.Operation, aclOperations))
+		}
+		if acl.Permission != "" && !contains(aclPermissions, acl.Permission) {
+			allErrs = append(allErrs,
+				field.NotSupported(path.Child("permission"), acl.Permission, aclPermissions))
+		}
+	}
+	return allErrs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSeccompProfile allows only the profiles that harden the Pods
+func (r *Cluster) validateSeccompProfile() field.ErrorList {
+	var allErrs field.ErrorList
+	profile := r.Spec.SeccompProfile
+	if profile == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("seccompProfile")
+	switch profile.Type {
+	case corev1.SeccompProfileTypeRuntimeDefault:
+		if profile.LocalhostProfile != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("localhostProfile"), *profile.LocalhostProfile,
+					"localhostProfile can be set only for Localhost profile type"))
+		}
+	case corev1.SeccompProfileTypeLocalhost:
+		if profile.LocalhostProfile == nil || *profile.LocalhostProfile == "" {
+			allErrs = append(allErrs,
+				field.Required(path.Child("localhostProfile"),
+					"localhostProfile has to be provided for Localhost profile type"))
+		}
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path.Child("type"), profile.Type,
+				[]string{string(corev1.SeccompProfileTypeRuntimeDefault), string(corev1.SeccompProfileTypeLocalhost)}))
+	}
+	return allErrs
+}
+
+// cronFieldRegexp matches a single field of the standard cron schedule
+var cronFieldRegexp = regexp.MustCompile(`^(\*|\?|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?(,(\*|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?)*$`)
+
+// cronMacros are the predefined schedules supported by CronJob
+var cronMacros = map[string]bool{
+	"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+	"@daily": true, "@midnight": true, "@hourly": true,
+}
+
+func isValidCronSchedule(schedule string) bool {
+	if cronMacros[schedule] {
+		return true
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != cronFieldsCount {
+		return false
+	}
+	for _, f := range fields {
+		if !cronFieldRegexp.MatchString(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMetadataBackup verifies the backup schedule, that the backup has a
+// bucket to write to and credentials to export with. The backup Pod reads the
+// secret key of the bucket through an environment variable, and Pods can
+// reference only Secrets in their own namespace.
+func (r *Cluster) validateMetadataBackup() field.ErrorList {
+	var allErrs field.ErrorList
+	backup := r.Spec.MetadataBackup
+	if backup == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("metadataBackup")
+	if !isValidCronSchedule(backup.Schedule) {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("schedule"), backup.Schedule,
+				"schedule has to be in the cron format"))
+	}
+	if !r.Spec.CloudStorage.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("cloudStorage").Child("enabled"),
+				r.Spec.CloudStorage.Enabled,
+				"cloud storage has to be enabled to provide the bucket for metadata backup"))
+	}
+	secretNs := r.Spec.CloudStorage.SecretKeyRef.Namespace
+	if r.Spec.CloudStorage.Enabled && secretNs != "" && secretNs != r.Namespace {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("cloudStorage").Child("secretKeyRef").Child("namespace"),
+				secretNs,
+				"the Secret has to be in the namespace of the cluster, because the backup Pod can reference only Secrets in its namespace"))
+	}
+	if r.Spec.EnableSASL && !r.hasSuperuserPassword() {
+		allErrs = append(allErrs,
+			field.Required(field.NewPath("spec").Child("superusers"),
+				"a superuser with passwordSecretKeyRef is required to export the metadata with SASL enabled"))
+	}
+	return allErrs
+}
+
+// hasSuperuserPassword returns true if any superuser has a SCRAM password
+func (r *Cluster) hasSuperuserPassword() bool {
+	for _, superuser := range r.Spec.Superusers {
+		if superuser.PasswordSecretKeyRef != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateClientQuotas verifies that all quotas are positive and client
+// groups can be told apart
+func (r *Cluster) validateClientQuotas() field.ErrorList {
+	var allErrs field.ErrorList
+	quotas := r.Spec.Configuration.ClientQuotas
+	if quotas == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("configuration").Child("clientQuotas")
+	if quotas.DefaultByteRate != nil && *quotas.DefaultByteRate <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("defaultByteRate"), *quotas.DefaultByteRate, "byte rate has to be positive"))
+	}
+	names := map[string]bool{}
+	for i, group := range quotas.Groups {
+		groupPath := path.Child("groups").Index(i)
+		if group.Name == "" {
+			allErrs = append(allErrs, field.Required(groupPath.Child("name"), "group name has to be provided"))
+		} else if names[group.Name] {
+			allErrs = append(allErrs, field.Duplicate(groupPath.Child("name"), group.Name))
+		}
+		names[group.Name] = true
+		if group.ClientIDPrefix == "" {
+			allErrs = append(allErrs, field.Required(groupPath.Child("clientIdPrefix"), "client ID prefix has to be provided"))
+		}
+		if group.ByteRate <= 0 {
+			allErrs = append(allErrs, field.Invalid(groupPath.Child("byteRate"), group.ByteRate, "byte rate has to be positive"))
+		}
+	}
+	return allErrs
+}
+
+// operatorManagedProperties are rendered by the operator from the spec and
+// can't be set through the additional configuration
+var operatorManagedProperties = map[string]bool{
+	"data_directory":                     true,
+	"node_id":                            true,
+	"seed_servers":                       true,
+	"rpc_server":                         true,
+	"advertised_rpc_api":                 true,
+	"kafka_api":                          true,
+	"advertised_kafka_api":               true,
+	"kafka_api_tls":                      true,
+	"admin":                              true,
+	"admin_api_tls":                      true,
+	"developer_mode":                     true,
+	"superusers":                         true,
+	"enable_sasl":                        true,
+	"sasl_mechanisms":                    true,
+	"group_topic_partitions":             true,
+	"target_quota_byte_rate":             true,
+	"kafka_client_group_byte_rate_quota": true,
+}
+
+// numericProperties and booleanProperties are well known redpanda cluster
+// properties whose values are checked before they reach the brokers
+var (
+	numericProperties = map[string]bool{
+		"log_segment_size":               true,
+		"compacted_log_segment_size":     true,
+		"default_topic_partitions":       true,
+		"default_topic_replications":     true,
+		"delete_retention_ms":            true,
+		"retention_bytes":                true,
+		"log_compaction_interval_ms":     true,
+		"fetch_reads_debounce_timeout":   true,
+		"group_initial_rebalance_delay":  true,
+		"group_new_member_join_timeout":  true,
+		"transactional_id_expiration_ms": true,
+	}
+	booleanProperties = map[string]bool{
+		"auto_create_topics_enabled": true,
+		"enable_idempotence":         true,
+		"enable_transactions":        true,
+		"enable_leader_balancer":     true,
+		"disable_metrics":            true,
+	}
+)
+
+// IsOperatorManagedProperty returns true if the redpanda property is
+// rendered by the operator and can't be overridden by the additional
+// configuration
+func IsOperatorManagedProperty(key string) bool {
+	return operatorManagedProperties[key] || strings.HasPrefix(key, "cloud_storage_")
+}
+
+// validateAdditionalConfiguration rejects properties managed by the operator
+// and values of well known properties that redpanda would fail to parse
+func (r *Cluster) validateAdditionalConfiguration() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("configuration").Child("additionalConfiguration")
+	for key, value := range r.Spec.Configuration.AdditionalConfiguration {
+		switch {
+		case IsOperatorManagedProperty(key):
+			allErrs = append(allErrs,
+				field.Forbidden(path.Key(key), "the property is managed by the operator"))
+		case numericProperties[key]:
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				allErrs = append(allErrs,
+					field.Invalid(path.Key(key), value, "the property has to be an integer"))
+			}
+		case booleanProperties[key]:
+			if _, err := strconv.ParseBool(value); err != nil {
+				allErrs = append(allErrs,
+					field.Invalid(path.Key(key), value, "the property has to be a boolean"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func (r *Cluster) validateArchivalStorage() field.ErrorList {
+	var allErrs field.ErrorList
+	if !r.Spec.CloudStorage.Enabled {
+		return allErrs
+	}
+	if r.CloudStorageStaticCredentials() && r.Spec.CloudStorage.AccessKey == "" {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("accessKey"),
+				r.Spec.CloudStorage.AccessKey,
+				"AccessKey has to be provided for cloud storage to be enabled"))
+	}
+	if r.Spec.CloudStorage.Bucket == "" {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("bucket"),
+				r.Spec.CloudStorage.Bucket,
+				"Bucket has to be provided for cloud storage to be enabled"))
+	}
+	if r.Spec.CloudStorage.Region == "" {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("region"),
+				r.Spec.CloudStorage.Region,
+				"Region has to be provided for cloud storage to be enabled"))
+	}
+	if r.CloudStorageStaticCredentials() && r.Spec.CloudStorage.SecretKeyRef.Name == "" {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("secretKeyRef").Child("name"),
+				r.Spec.CloudStorage.SecretKeyRef.Name,
+				"SecretKeyRef name has to be provided for cloud storage to be enabled"))
+	}
+	if r.CloudStorageStaticCredentials() && r.Spec.CloudStorage.SecretKeyRef.Namespace == "" {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec").Child("configuration").Child("cloudStorage").Child("secretKeyRef").Child("namespace"),
+				r.Spec.CloudStorage.SecretKeyRef.Namespace,
+				"SecretKeyRef namespace has to be provided for cloud storage to be enabled"))
+	}
+	return allErrs
+}
+
+// validateCloudStorageDeveloperMode rejects the cloud storage in developer
+// mode. Developer mode skips fsync, so the segments uploaded to the bucket
+// may miss writes that were acknowledged to the producers.
+func (r *Cluster) validateCloudStorageDeveloperMode() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.CloudStorage.Enabled && r.Spec.Configuration.DeveloperMode {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("configuration").Child("developerMode"),
+				"developer mode relaxes the durability guarantees cloud storage depends on, disable either developer mode or cloud storage"))
+	}
+	return allErrs
+}
+
+// validateCloudStorageDeveloperModeChange rejects the cloud storage in
+// developer mode only when either of them changes, so the clusters that
+// combined them before the validation was introduced can still be updated
+func (r *Cluster) validateCloudStorageDeveloperModeChange(
+	oldCluster *Cluster,
+) field.ErrorList {
+	if r.Spec.CloudStorage.Enabled == oldCluster.Spec.CloudStorage.Enabled &&
+		r.Spec.Configuration.DeveloperMode == oldCluster.Spec.Configuration.DeveloperMode {
+		return nil
+	}
+	return r.validateCloudStorageDeveloperMode()
+}
+
+// validateCloudStorageEgress verifies that the egress NetworkPolicy can
+// address the object store, i.e. it's either reached on an IP address or
+// its CIDRs are provided
+func (r *Cluster) validateCloudStorageEgress() field.ErrorList {
+	var allErrs field.ErrorList
+	cloudStorage := r.Spec.CloudStorage
+	if cloudStorage.EgressNetworkPolicy == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("cloudStorage")
+	endpoint := cloudStorage.APIEndpoint
+	if endpoint != "" && net.ParseIP(endpoint) == nil {
+		for _, msg := range validation.IsDNS1123Subdomain(endpoint) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("apiEndpoint"), endpoint, msg))
+		}
+	}
+	if cloudStorage.APIEndpointPort != 0 {
+		for _, msg := range validation.IsValidPortNum(cloudStorage.APIEndpointPort) {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("apiEndpointPort"), cloudStorage.APIEndpointPort, msg))
+		}
+	}
+	cidrs := cloudStorage.EgressNetworkPolicy.CIDRs
+	for i, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("egressNetworkPolicy").Child("cidrs").Index(i), cidr,
+					"has to be a CIDR, e.g. 52.92.0.0/17"))
+		}
+	}
+	if len(cidrs) == 0 && net.ParseIP(endpoint) == nil {
+		allErrs = append(allErrs,
+			field.Required(path.Child("egressNetworkPolicy").Child("cidrs"),
+				"CIDRs of the object store have to be provided unless apiEndpoint is an IP address, NetworkPolicy can't match host names"))
+	}
+	return allErrs
+}
+
+// minProjectedTokenExpirationSeconds is the shortest validity of projected
+// service account tokens accepted by Kubernetes
+const minProjectedTokenExpirationSeconds = 600
+
+// validateProjectedToken verifies the audience and validity of the token.
+// The reachability of the bucket is verified with the static credentials
+// only.
+func (r *Cluster) validateProjectedToken() field.ErrorList {
+	var allErrs field.ErrorList
+	token := r.Spec.CloudStorage.ProjectedToken
+	if token == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("cloudStorage").Child("projectedToken")
+	if token.Audience == "" || strings.ContainsAny(token.Audience, " \t\n") {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("audience"), token.Audience,
+				"audience has to be provided without whitespace"))
+	}
+	if token.ExpirationSeconds != nil && *token.ExpirationSeconds < minProjectedTokenExpirationSeconds {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("expirationSeconds"), *token.ExpirationSeconds,
+				fmt.Sprintf("token has to be valid for at least %d seconds", minProjectedTokenExpirationSeconds)))
+	}
+	if r.Spec.CloudStorage.VerifyReachability {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("cloudStorage").Child("verifyReachability"),
+				"reachability can't be verified with projected token"))
+	}
+	return allErrs
+}
+
+func (r *Cluster) validateLoadBalancerTags() field.ErrorList {
+	var allErrs field.ErrorList
+	extConn := r.Spec.ExternalConnectivity
+	if len(extConn.LoadBalancerTags) == 0 {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("externalConnectivity")
+	if !extConn.Enabled || extConn.Type != ExternalConnectivityLoadBalancer {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("type"),
+				extConn.Type,
+				"load balancer tags require external connectivity of the LoadBalancer type"))
+	}
+	// GCP has no Service annotation that labels the load balancer
+	switch extConn.CloudProvider {
+	case CloudProviderAWS, CloudProviderAzure:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(path.Child("cloudProvider"),
+				extConn.CloudProvider,
+				[]string{CloudProviderAWS, CloudProviderAzure}))
+	}
+	for k, v := range extConn.LoadBalancerTags {
+		// tags are rendered as comma separated key=value pairs
+		if k == "" || strings.ContainsAny(k, ",=") || strings.ContainsAny(v, ",=") {
+			allErrs = append(allErrs,
+				field.Invalid(path.Child("loadBalancerTags").Key(k),
+					v,
+					"tag key must not be empty and neither key nor value can contain ',' or '='"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Cluster) ValidateDelete() error {
+	log.Info("validate delete", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object deletion.
+	return nil
+}
+
+// validateStorageChange rejects shrinking the data directory, as volumes can
+// only be expanded
+func (r *Cluster) validateStorageChange(oldCluster *Cluster) field.ErrorList {
+	var allErrs field.ErrorList
+	capacity := r.Spec.Storage.Capacity
+	oldCapacity := oldCluster.Spec.Storage.Capacity
+	if capacity.IsZero() || oldCapacity.IsZero() || capacity.Cmp(oldCapacity) >= 0 {
+		return allErrs
+	}
+	allErrs = append(allErrs,
+		field.Invalid(field.NewPath("spec").Child("storage").Child("capacity"), capacity.String(),
+			"storage capacity can't be decreased from "+oldCapacity.String()))
+	return allErrs
+}
+
+// validateReplicasChange allows scaling down only with DrainOnScaleDown, one
+// broker at a time. Replicas can't change while a broker is decommissioned.
+func (r *Cluster) validateReplicasChange(oldCluster *Cluster) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Replicas == nil || oldCluster.Spec.Replicas == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("replicas")
+
+	if oldCluster.Status.DecommissioningNode != nil && *r.Spec.Replicas != *oldCluster.Spec.Replicas {
+		allErrs = append(allErrs,
+			field.Forbidden(path,
+				fmt.Sprintf("replicas can't be changed while broker %d is being decommissioned", *oldCluster.Status.DecommissioningNode)))
+		return allErrs
+	}
+
+	if *r.Spec.Replicas >= *oldCluster.Spec.Replicas {
+		return allErrs
+	}
+	if !r.Spec.DrainOnScaleDown {
+		allErrs = append(allErrs,
+			field.Invalid(path,
+				r.Spec.Replicas,
+				"scaling down is not supported"))
+	} else if *oldCluster.Spec.Replicas-*r.Spec.Replicas > 1 {
+		allErrs = append(allErrs,
+			field.Invalid(path,
+				r.Spec.Replicas,
+				"scaling down is supported by one broker at a time"))
+	}
+	return allErrs
+}
+
+// validateKafkaAPIPorts verifies that the listening and the advertised
+// Kafka API ports are valid port numbers. The advertised port of the
+// external listener requires external connectivity, declared listeners
+// advertise their own ports.
+func (r *Cluster) validateKafkaAPIPorts() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("configuration")
+	kafkaPort := r.Spec.Configuration.KafkaAPI.Port
+	if kafkaPort < 0 || kafkaPort > maxPort {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("kafkaApi", "port"), kafkaPort, "port is out of range"))
+	} else if r.Spec.ExternalConnectivity.Enabled && kafkaPort+1 > maxPort {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("kafkaApi", "port"), kafkaPort,
+				"external Kafka API listens on the next port that is out of range"))
+	}
+
+	advertised := r.Spec.Configuration.AdvertisedKafkaAPIPorts
+	if advertised == nil {
+		return allErrs
+	}
+	if len(r.Spec.Configuration.Listeners) > 0 {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("advertisedKafkaApiPorts"), "set the advertisedPort of the declared listeners instead"))
+	}
+	if advertised.Internal < 0 || advertised.Internal > maxPort {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("advertisedKafkaApiPorts", "internal"), advertised.Internal, "port is out of range"))
+	}
+	if advertised.External < 0 || advertised.External > maxPort {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("advertisedKafkaApiPorts", "external"), advertised.External, "port is out of range"))
+	} else if advertised.External != 0 && !r.Spec.ExternalConnectivity.Enabled {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("advertisedKafkaApiPorts", "external"), advertised.External,
+				"external connectivity has to be enabled"))
+	}
+	return allErrs
+}
+
+// reservedListenerPortNames are the names of the ports of the Internal and
+// the External listeners and of the Admin API, which can't be taken by the
+// ports of the additional listeners
+var reservedListenerPortNames = map[string]bool{
+	"kafka":          true,
+	"kafka-internal": true,
+	"kafka-external": true,
+	"admin":          true,
+	"admin-internal": true,
+	"admin-external": true,
+}
+
+// validateListeners verifies that the declared listeners have unique names
+// and ports, and that the Internal and the External listeners are where the
+// operator and the external Services expect them
+// nolint:funlen,gocyclo // the checks are simple
+func (r *Cluster) validateListeners() field.ErrorList {
+	listeners := r.Spec.Configuration.Listeners
+	if len(listeners) == 0 {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	path := field.NewPath("spec").Child("configuration", "listeners")
+	kafkaTLS := r.Spec.Configuration.TLS.KafkaAPI
+	names := map[string]bool{}
+	ports := map[int]bool{
+		r.Spec.Configuration.AdminAPI.Port:  true,
+		r.Spec.Configuration.RPCServer.Port: true,
+	}
+	var internal, external bool
+	for i, l := range listeners {
+		p := path.Index(i)
+		if names[strings.ToLower(l.Name)] {
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), l.Name))
+		}
+		names[strings.ToLower(l.Name)] = true
+		if ports[l.Port] {
+			allErrs = append(allErrs,
+				field.Invalid(p.Child("port"), l.Port, "port collides with another listener, the Admin API or the RPC server"))
+		}
+		ports[l.Port] = true
+		if l.Port < 0 || l.Port > maxPort {
+			allErrs = append(allErrs, field.Invalid(p.Child("port"), l.Port, "port is out of range"))
+		}
+		if l.AdvertisedPort < 0 || l.AdvertisedPort > maxPort {
+			allErrs = append(allErrs, field.Invalid(p.Child("advertisedPort"), l.AdvertisedPort, "port is out of range"))
+		}
+		if l.TLS && !kafkaTLS.Enabled {
+			allErrs = append(allErrs,
+				field.Invalid(p.Child("tls"), l.TLS, "Kafka API TLS has to be enabled to issue the node certificate"))
+		}
+		if l.RequireClientAuth && (!l.TLS || !kafkaTLS.RequireClientAuth) {
+			allErrs = append(allErrs,
+				field.Invalid(p.Child("requireClientAuth"), l.RequireClientAuth,
+					"requires TLS of the listener and Kafka API RequireClientAuth, which provides the client CA"))
+		}
+
+		switch l.Name {
+		case InternalListener:
+			internal = true
+			if l.External || l.Port != r.Spec.Configuration.KafkaAPI.Port {
+				allErrs = append(allErrs,
+					field.Invalid(p, l.Name, "Internal listener must be internal and listen on the KafkaAPI port"))
+			}
+		case ExternalListener:
+			external = true
+			if !l.External || !r.Spec.ExternalConnectivity.Enabled || l.Port != r.Spec.Configuration.KafkaAPI.Port+1 {
+				allErrs = append(allErrs,
+					field.Invalid(p, l.Name, "External listener requires external connectivity and listens on the port following the KafkaAPI port"))
+			}
+		default:
+			if l.External {
+				allErrs = append(allErrs,
+					field.Invalid(p.Child("external"), l.External,
+						fmt.Sprintf("only the %s listener can be reachable from outside of the Kubernetes cluster", ExternalListener)))
+			}
+			portName := strings.ToLower(l.Name)
+			if reservedListenerPortNames[portName] {
+				allErrs = append(allErrs,
+					field.Invalid(p.Child("name"), l.Name, "name is reserved for the ports of the Internal and External listeners"))
+			} else if errs := validation.IsValidPortName(portName); len(errs) > 0 {
+				allErrs = append(allErrs,
+					field.Invalid(p.Child("name"), l.Name, "lowercased name has to be a valid port name: "+strings.Join(errs, ", ")))
+			}
+		}
+	}
+	if !internal {
+		allErrs = append(allErrs,
+			field.Required(path, fmt.Sprintf("%s listener on the KafkaAPI port is required", InternalListener)))
+	}
+	if r.Spec.ExternalConnectivity.Enabled && !external {
+		allErrs = append(allErrs,
+			field.Required(path, fmt.Sprintf("%s listener is required with external connectivity", ExternalListener)))
+	}
+	return allErrs
+}
+
+type portRange struct {
+	first, last int
+	owner       string
+}
+
+// reservedPortRanges can't be used by brokers reachable from outside of the
+// Kubernetes cluster. The kubelet and kube-proxy listen on the node, and
+// the NodePort range is handed out by the API server to node port services
+var reservedPortRanges = []portRange{
+	{10248, 10260, "kubelet and kube-proxy"},
+	{30000, 32767, "Kubernetes node port range"},
+}
+
+// wellKnownPortRanges are commonly taken on Kubernetes nodes but are not
+// reserved by Kubernetes itself, using them is reported by the
+// WellKnownPortInUse condition
+var wellKnownPortRanges = []portRange{
+	{2379, 2380, "etcd"},
+	{6443, 6443, "Kubernetes API server"},
+	{9100, 9100, "Prometheus node exporter"},
+}
+
+func findPortRange(ranges []portRange, port int) *portRange {
+	for i := range ranges {
+		if port >= ranges[i].first && port <= ranges[i].last {
+			return &ranges[i]
+		}
+	}
+	return nil
+}
+
+type exposedPort struct {
+	path *field.Path
+	port int
+	msg  string
+}
+
+// exposedPorts returns the ports of the brokers reachable from outside of
+// the Kubernetes cluster, none without external connectivity
+func (r *Cluster) exposedPorts() []exposedPort {
+	if !r.Spec.ExternalConnectivity.Enabled {
+		return nil
+	}
+	path := field.NewPath("spec").Child("configuration")
+	kafkaPort := r.Spec.Configuration.KafkaAPI.Port
+	return []exposedPort{
+		{path.Child("kafkaApi", "port"), kafkaPort, "port"},
+		{path.Child("kafkaApi", "port"), kafkaPort + 1, "external Kafka API port"},
+		{path.Child("admin", "port"), r.Spec.Configuration.AdminAPI.Port, "port"},
+		{path.Child("rpcServer", "port"), r.Spec.Configuration.RPCServer.Port, "port"},
+	}
+}
+
+// validateReservedPorts rejects ports that collide with the ports reserved
+// by Kubernetes when the brokers are exposed outside of the cluster
+func (r *Cluster) validateReservedPorts() field.ErrorList {
+	var allErrs field.ErrorList
+	for _, p := range r.exposedPorts() {
+		if reserved := findPortRange(reservedPortRanges, p.port); reserved != nil {
+			allErrs = append(allErrs,
+				field.Invalid(p.path, p.port,
+					fmt.Sprintf("%s %d is reserved by %s (%d-%d)",
+						p.msg, p.port, reserved.owner, reserved.first, reserved.last)))
+		}
+	}
+	return allErrs
+}
+
+// WellKnownPortsInUse describes the ports of the brokers exposed outside of
+// the Kubernetes cluster that are commonly used by components running on
+// the nodes. The webhook can't return warnings, so they are reported by the
+// controller instead of rejected.
+func (r *Cluster) WellKnownPortsInUse() []string {
+	var inUse []string
+	for _, p := range r.exposedPorts() {
+		if known := findPortRange(wellKnownPortRanges, p.port); known != nil {
+			inUse = append(inUse,
+				fmt.Sprintf("%s: %s %d is commonly used by %s", p.path, p.msg, p.port, known.owner))
+		}
+	}
+	return inUse
+}
+
+// validateHostNetwork rejects the external connectivity modes that don't
+// work when the brokers run in the host network
+func (r *Cluster) validateHostNetwork() field.ErrorList {
+	var allErrs field.ErrorList
+	external := r.Spec.ExternalConnectivity
+	if !r.Spec.HostNetwork || !external.Enabled {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("hostNetwork")
+	switch external.Type {
+	case ExternalConnectivityNodePort:
+		allErrs = append(allErrs,
+			field.Invalid(path, r.Spec.HostNetwork,
+				"NodePort external connectivity is redundant, brokers in the host network are already reachable on the node IP"))
+	case ExternalConnectivityLoadBalancer:
+		log.Info("brokers in the host network are reachable on the node IP besides the load balancers",
+			"name", r.Name)
+	default:
+		// the external listener is published through a host port that
+		// differs from the container port, which is not allowed in the
+		// host network
+		allErrs = append(allErrs,
+			field.Invalid(path, r.Spec.HostNetwork,
+				"host network is supported only with LoadBalancer external connectivity type, the external listener is published on a host port different from the container port"))
+	}
+	return allErrs
+}
+
+// validateSessionAffinity verifies that the session affinity is supported
+// by Services
+func (r *Cluster) validateSessionAffinity() field.ErrorList {
+	var allErrs field.ErrorList
+	switch r.Spec.SessionAffinity {
+	case "", corev1.ServiceAffinityNone, corev1.ServiceAffinityClientIP:
+	default:
+		allErrs = append(allErrs,
+			field.NotSupported(field.NewPath("spec").Child("sessionAffinity"), r.Spec.SessionAffinity,
+				[]string{string(corev1.ServiceAffinityNone), string(corev1.ServiceAffinityClientIP)}))
+	}
+	return allErrs
+}
+
+// validateClockSkew verifies that the allowed clock skew is detectable with
+// the one second precision of the broker time
+func (r *Cluster) validateClockSkew() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.MaxClockSkewSeconds != nil && *r.Spec.MaxClockSkewSeconds < minClockSkewSeconds {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("maxClockSkewSeconds"), *r.Spec.MaxClockSkewSeconds,
+				fmt.Sprintf("clock skew has to be at least %d seconds, broker time is reported with one second precision", minClockSkewSeconds)))
+	}
+	return allErrs
+}
+
+// validateExternalAdmin rejects the external Admin API Service when the
+// Admin API is already exposed by the external connectivity, and in the host
+// network where the node port can't be published on another host port
+func (r *Cluster) validateExternalAdmin() field.ErrorList {
+	var allErrs field.ErrorList
+	if !r.Spec.ExternalAdmin.Enabled {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("externalAdmin")
+	if r.Spec.ExternalConnectivity.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("enabled"),
+				"the Admin API is exposed along with the Kafka API when external connectivity is enabled"))
+	}
+	if r.Spec.HostNetwork {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("enabled"), r.Spec.ExternalAdmin.Enabled,
+				"brokers in the host network are already reachable on the node IP"))
+	}
+	if subdomain := r.Spec.ExternalAdmin.Subdomain; subdomain != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(subdomain) {
+			allErrs = append(allErrs, field.Invalid(path.Child("subdomain"), subdomain, msg))
+		}
+	}
+	return allErrs
+}
+
+func (r *Cluster) checkCollidingPorts() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.Configuration.AdminAPI.Port == r.Spec.Configuration.KafkaAPI.Port {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("configuration", "admin", "port"),
+				r.Spec.Configuration.AdminAPI.Port,
+				"admin port collide with Spec.Configuration.KafkaAPI.Port"))
+	}
+
+	if r.Spec.Configuration.RPCServer.Port == r.Spec.Configuration.KafkaAPI.Port {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("configuration", "rpcServer", "port"),
+				r.Spec.Configuration.RPCServer.Port,
+				"rpc port collide with Spec.Configuration.KafkaAPI.Port"))
+	}
+
+	if r.Spec.Configuration.AdminAPI.Port == r.Spec.Configuration.RPCServer.Port {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("configuration", "admin", "port"),
+				r.Spec.Configuration.AdminAPI.Port,
+				"admin port collide with Spec.Configuration.RPCServer.Port"))
+	}
+
+	if r.Spec.ExternalConnectivity.Enabled && r.Spec.Configuration.KafkaAPI.Port+1 == r.Spec.Configuration.RPCServer.Port {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("configuration", "rpcServer", "port"),
+				r.Spec.Configuration.RPCServer.Port,
+				"rpc port collide with external Kafka API that is not visible in the Cluster CR"))
+	}
+
+	if r.Spec.ExternalConnectivity.Enabled && r.Spec.Configuration.KafkaAPI.Port+1 == r.Spec.Configuration.AdminAPI.Port {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("configuration", "admin", "port"),
+				r.Spec.Configuration.AdminAPI.Port,
+				"admin port collide with external Kafka API that is not visible in the Cluster CR"))
+	}
+
+	return allErrs
+}
+
+// validatePasswordRotation verifies the rotation interval
+func (r *Cluster) validatePasswordRotation() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.PasswordRotation != nil && r.Spec.PasswordRotation.IntervalHours < 1 {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("passwordRotation").Child("intervalHours"), r.Spec.PasswordRotation.IntervalHours,
+				"rotation interval has to be at least one hour"))
+	}
+	return allErrs
+}
+
+// validateStartupDelay verifies that the delay between broker starts is
+// within bounds
+func (r *Cluster) validateStartupDelay() field.ErrorList {
+	var allErrs field.ErrorList
+	delay := r.Spec.StartupDelaySeconds
+	if delay != nil && (*delay < 1 || *delay > maxStartupDelaySeconds) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("startupDelaySeconds"), *delay,
+				fmt.Sprintf("startup delay has to be between 1 and %d seconds", maxStartupDelaySeconds)))
+	}
+	return allErrs
+}
+
+// validateOpenFilesLimit verifies that the open files limit of the broker is
+// within bounds
+func (r *Cluster) validateOpenFilesLimit() field.ErrorList {
+	var allErrs field.ErrorList
+	limit := r.Spec.OpenFilesLimit
+	if limit != nil && (*limit < minOpenFilesLimit || *limit > maxOpenFilesLimit) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("openFilesLimit"), *limit,
+				fmt.Sprintf("open files limit has to be between %d and %d", minOpenFilesLimit, maxOpenFilesLimit)))
+	}
+	return allErrs
+}
+
+// validateLivenessProbe verifies the liveness probe mode
+func (r *Cluster) validateLivenessProbe() field.ErrorList {
+	var allErrs field.ErrorList
+	probe := r.Spec.LivenessProbe
+	if probe == nil || probe.Mode == "" {
+		return allErrs
+	}
+	for _, mode := range LivenessProbeModes {
+		if string(probe.Mode) == mode {
+			return allErrs
+		}
+	}
+	return append(allErrs,
+		field.NotSupported(field.NewPath("spec").Child("livenessProbe").Child("mode"), probe.Mode, LivenessProbeModes))
+}
+
+// validateDedicatedNodes rejects node selectors and tolerations that
+// contradict the dedicated nodes label and taint. Without DedicatedNodes, the
+// brokers selecting the dedicated nodes would not tolerate their taint.
+func (r *Cluster) validateDedicatedNodes() field.ErrorList {
+	var allErrs field.ErrorList
+	value, selected := r.Spec.NodeSelector[DedicatedNodesKey]
+	selectorPath := field.NewPath("spec").Child("nodeSelector").Key(DedicatedNodesKey)
+	if !r.Spec.DedicatedNodes {
+		if selected && value == DedicatedNodesValue && !r.toleratesDedicatedNodes() {
+			allErrs = append(allErrs,
+				field.Invalid(selectorPath, value,
+					"the dedicated nodes are tainted, set dedicatedNodes to tolerate the taint"))
+		}
+		return allErrs
+	}
+
+	if selected && value != DedicatedNodesValue {
+		allErrs = append(allErrs,
+			field.Invalid(selectorPath, value,
+				fmt.Sprintf("dedicated nodes are selected with %s=%s", DedicatedNodesKey, DedicatedNodesValue)))
+	}
+	for i, toleration := range r.Spec.Tolerations {
+		if toleration.Key == DedicatedNodesKey && toleration.Operator != corev1.TolerationOpExists &&
+			toleration.Value != DedicatedNodesValue {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec").Child("tolerations").Index(i).Child("value"), toleration.Value,
+					fmt.Sprintf("dedicated nodes are tainted with %s=%s", DedicatedNodesKey, DedicatedNodesValue)))
+		}
+	}
+	return allErrs
+}
+
+// toleratesDedicatedNodes returns true if Tolerations include the taint of
+// the dedicated nodes
+func (r *Cluster) toleratesDedicatedNodes() bool {
+	taint := corev1.Taint{
+		Key:    DedicatedNodesKey,
+		Value:  DedicatedNodesValue,
+		Effect: corev1.TaintEffectNoSchedule,
+	}
+	for i := range r.Spec.Tolerations {
+		if r.Spec.Tolerations[i].ToleratesTaint(&taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Cluster) validateClusterDomain() field.ErrorList {
+	var allErrs field.ErrorList
+	domain := r.Spec.ClusterDomain
+	if domain == "" {
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(domain, ".")) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec").Child("clusterDomain"), domain, msg))
+	}
+	return allErrs
+}
+
+// validateClusterDomainChange rejects changes of the domain, the brokers
+// would not reach each other on the addresses registered in the cluster
+func (r *Cluster) validateClusterDomainChange(oldCluster *Cluster) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.ClusterDomain() != oldCluster.ClusterDomain() {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("clusterDomain"),
+				"cluster domain can't be changed"))
+	}
+	return allErrs
+}
+
+// maxZoneStatefulSetNameLength leaves room for the controller revision hash
+// the StatefulSet controller appends to the name in the Pod labels
+const maxZoneStatefulSetNameLength = 52
+
+// validateZoneStatefulSets verifies that the zone StatefulSets can be named
+// after the zones and rejects external connectivity, as the advertised
+// addresses of the brokers are derived from their ordinals
+func (r *Cluster) validateZoneStatefulSets() field.ErrorList {
+	var allErrs field.ErrorList
+	config := r.Spec.ZoneStatefulSets
+	if config == nil {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("zoneStatefulSets")
+	if len(config.Zones) == 0 {
+		allErrs = append(allErrs,
+			field.Required(path.Child("zones"), "at least one zone has to be provided"))
+	}
+	zones := map[string]bool{}
+	for i, zone := range config.Zones {
+		zonePath := path.Child("zones").Index(i)
+		if zones[zone] {
+			allErrs = append(allErrs, field.Duplicate(zonePath, zone))
+		}
+		zones[zone] = true
+		name := r.Name + "-" + zone
+		for _, msg := range validation.IsDNS1123Label(name) {
+			allErrs = append(allErrs,
+				field.Invalid(zonePath, zone, fmt.Sprintf("StatefulSet name %s: %s", name, msg)))
+		}
+		if len(name) > maxZoneStatefulSetNameLength {
+			allErrs = append(allErrs,
+				field.Invalid(zonePath, zone,
+					fmt.Sprintf("StatefulSet name %s must be no more than %d characters", name, maxZoneStatefulSetNameLength)))
+		}
+	}
+	if r.Spec.ExternalConnectivity.Enabled {
+		allErrs = append(allErrs,
+			field.Forbidden(path, "zone StatefulSets can't be combined with external connectivity"))
+	}
+	return allErrs
+}
+
+// validateZoneStatefulSetsChange rejects changes of the zones, the
+// StatefulSets and the node IDs of their brokers are derived from them
+func (r *Cluster) validateZoneStatefulSetsChange(oldCluster *Cluster) field.ErrorList {
+	var allErrs field.ErrorList
+	zones, oldZones := r.StatefulSetZones(), oldCluster.StatefulSetZones()
+	changed := (r.Spec.ZoneStatefulSets == nil) != (oldCluster.Spec.ZoneStatefulSets == nil) ||
+		len(zones) != len(oldZones)
+	for i := 0; !changed && i < len(zones); i++ {
+		changed = zones[i] != oldZones[i]
+	}
+	if changed {
+		allErrs = append(allErrs,
+			field.Forbidden(field.NewPath("spec").Child("zoneStatefulSets"),
+				"zone StatefulSets can't be changed"))
+	}
+	return allErrs
+}
+
+// validateScaleDownQuorum rejects scaling down unless all brokers are ready
+// and the remaining brokers are a majority of the ready ones, so the
+// partitions replicated across the ready brokers keep their quorum while the
+// decommissioned broker is drained. The check is skipped with
+// AllowUnsafeScaleDown.
+func (r *Cluster) validateScaleDownQuorum(oldCluster *Cluster) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.AllowUnsafeScaleDown || r.Spec.Replicas == nil || oldCluster.Spec.Replicas == nil {
+		return allErrs
+	}
+	replicas := *r.Spec.Replicas
+	oldReplicas := *oldCluster.Spec.Replicas
+	if replicas >= oldReplicas {
+		return allErrs
+	}
+	path := field.NewPath("spec").Child("replicas")
+
+	ready := oldCluster.Status.Replicas
+	if ready < oldReplicas {
+		allErrs = append(allErrs,
+			field.Forbidden(path,
+				fmt.Sprintf("scaling down requires all %d brokers to be ready, %d are ready", oldReplicas, ready)))
+		return allErrs
+	}
+	if replicas <= ready/2 {
+		allErrs = append(allErrs,
+			field.Invalid(path, replicas,
+				fmt.Sprintf("%d brokers are not a majority of the %d ready brokers", replicas, ready)))
+	}
 	return allErrs
 }
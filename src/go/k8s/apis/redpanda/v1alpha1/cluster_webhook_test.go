@@ -10,10 +10,12 @@
 package v1alpha1_test
 
 import (
+	"strings"
 	"testing"
 
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -95,6 +97,100 @@ func TestValidateUpdate(t *testing.T) {
 	}
 }
 
+func TestValidateResources(t *testing.T) {
+	redpandaCluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "redpanda",
+		},
+		Spec: v1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: v1alpha1.RedpandaConfig{
+				KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+				AdminAPI:  v1alpha1.SocketAddress{Port: 125},
+				RPCServer: v1alpha1.SocketAddress{Port: 126},
+			},
+			Storage: v1alpha1.StorageSpec{
+				Capacity: resource.MustParse("10Gi"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		limits         corev1.ResourceList
+		requests       corev1.ResourceList
+		expectedFields []string
+	}{
+		{"requests within limits",
+			corev1.ResourceList{"cpu": resource.MustParse("2"), "memory": resource.MustParse("4Gi")},
+			corev1.ResourceList{"cpu": resource.MustParse("1"), "memory": resource.MustParse("2Gi")},
+			nil},
+		{"requests above limits",
+			corev1.ResourceList{"cpu": resource.MustParse("1"), "memory": resource.MustParse("2Gi")},
+			corev1.ResourceList{"cpu": resource.MustParse("2"), "memory": resource.MustParse("4Gi")},
+			[]string{"spec.resources.requests.cpu", "spec.resources.requests.memory"}},
+		{"requests without limits",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi")},
+			corev1.ResourceList{"cpu": resource.MustParse("1"), "ephemeral-storage": resource.MustParse("1Gi")},
+			nil},
+		{"ephemeral storage request above limit",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "ephemeral-storage": resource.MustParse("1Gi")},
+			corev1.ResourceList{"ephemeral-storage": resource.MustParse("2Gi")},
+			[]string{"spec.resources.requests.ephemeral-storage"}},
+		{"negative limit",
+			corev1.ResourceList{"cpu": resource.MustParse("-1"), "memory": resource.MustParse("2Gi")},
+			nil,
+			[]string{"spec.resources.limits.cpu"}},
+		{"negative request",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi")},
+			corev1.ResourceList{"ephemeral-storage": resource.MustParse("-1Gi")},
+			[]string{"spec.resources.requests.ephemeral-storage"}},
+		{"unsupported resource",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "storage": resource.MustParse("10Gi")},
+			nil,
+			[]string{"spec.resources.limits.storage"}},
+		{"hugepages request equal to limit",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "hugepages-2Mi": resource.MustParse("1Gi")},
+			corev1.ResourceList{"hugepages-2Mi": resource.MustParse("1Gi")},
+			nil},
+		{"hugepages request below limit",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "hugepages-2Mi": resource.MustParse("1Gi")},
+			corev1.ResourceList{"hugepages-2Mi": resource.MustParse("512Mi")},
+			[]string{"spec.resources.requests.hugepages-2Mi"}},
+		{"hugepages request above limit",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "hugepages-1Gi": resource.MustParse("1Gi")},
+			corev1.ResourceList{"hugepages-1Gi": resource.MustParse("2Gi")},
+			[]string{"spec.resources.requests.hugepages-1Gi"}},
+		{"extended resource request equal to limit",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "example.com/nic": resource.MustParse("1")},
+			corev1.ResourceList{"example.com/nic": resource.MustParse("1")},
+			nil},
+		{"extended resource request below limit",
+			corev1.ResourceList{"memory": resource.MustParse("2Gi"), "example.com/nic": resource.MustParse("2")},
+			corev1.ResourceList{"example.com/nic": resource.MustParse("1")},
+			[]string{"spec.resources.requests.example.com/nic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := redpandaCluster.DeepCopy()
+			cluster.Spec.Resources = corev1.ResourceRequirements{Limits: tt.limits, Requests: tt.requests}
+
+			err := cluster.ValidateCreate()
+			if len(tt.expectedFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).Status().Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			assert.Equal(t, tt.expectedFields, fields)
+		})
+	}
+}
+
 func TestValidateUpdate_NoError(t *testing.T) {
 	var replicas2 int32 = 2
 
@@ -135,6 +231,49 @@ func TestValidateUpdate_NoError(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("scale down by one with drain", func(t *testing.T) {
+		ready := redpandaCluster.DeepCopy()
+		ready.Spec.Replicas = pointer.Int32Ptr(3)
+		ready.Status.Replicas = 3
+		drained := ready.DeepCopy()
+		drained.Spec.DrainOnScaleDown = true
+		drained.Spec.Replicas = pointer.Int32Ptr(2)
+		err := drained.ValidateUpdate(ready)
+		assert.NoError(t, err)
+	})
+
+	t.Run("scale down by more than one with drain", func(t *testing.T) {
+		var scaleDown int32 = 0
+		drained := redpandaCluster.DeepCopy()
+		drained.Spec.DrainOnScaleDown = true
+		drained.Spec.Replicas = &scaleDown
+		err := drained.ValidateUpdate(redpandaCluster)
+		assert.Error(t, err)
+	})
+
+	t.Run("replicas change while decommissioning", func(t *testing.T) {
+		var scaleUp int32 = *redpandaCluster.Spec.Replicas + 1
+		decommissioning := redpandaCluster.DeepCopy()
+		decommissioning.Status.DecommissioningNode = pointer.Int32Ptr(2)
+		updated := decommissioning.DeepCopy()
+		updated.Spec.Replicas = &scaleUp
+		err := updated.ValidateUpdate(decommissioning)
+		assert.Error(t, err)
+	})
+
+	t.Run("storage capacity change", func(t *testing.T) {
+		withStorage := redpandaCluster.DeepCopy()
+		withStorage.Spec.Storage.Capacity = resource.MustParse("10Gi")
+
+		grown := withStorage.DeepCopy()
+		grown.Spec.Storage.Capacity = resource.MustParse("20Gi")
+		assert.NoError(t, grown.ValidateUpdate(withStorage))
+
+		shrunk := withStorage.DeepCopy()
+		shrunk.Spec.Storage.Capacity = resource.MustParse("5Gi")
+		assert.Error(t, shrunk.ValidateUpdate(withStorage))
+	})
+
 	t.Run("change image and tag", func(t *testing.T) {
 		updatedImage := redpandaCluster.DeepCopy()
 		updatedImage.Spec.Image = "differentimage"
@@ -185,6 +324,209 @@ func TestValidateUpdate_NoError(t *testing.T) {
 	})
 }
 
+func TestValidateUpdate_ScaleDownQuorum(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldReplicas int32
+		ready       int32
+		replicas    int32
+		allowUnsafe bool
+		expectError bool
+	}{
+		{"scale up with unready brokers", 3, 1, 4, false, false},
+		{"scale down keeps majority", 3, 3, 2, false, false},
+		{"scale down larger cluster", 5, 5, 4, false, false},
+		{"scale down with unready broker", 3, 2, 2, false, true},
+		{"scale down below majority", 2, 2, 1, false, true},
+		{"unsafe scale down with unready broker", 3, 2, 2, true, false},
+		{"unsafe scale down below majority", 2, 2, 1, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.ClusterSpec{
+					Replicas:         pointer.Int32Ptr(tt.oldReplicas),
+					DrainOnScaleDown: true,
+					Configuration: v1alpha1.RedpandaConfig{
+						KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+						AdminAPI:  v1alpha1.SocketAddress{Port: 125},
+						RPCServer: v1alpha1.SocketAddress{Port: 126},
+					},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+				Status: v1alpha1.ClusterStatus{Replicas: tt.ready},
+			}
+			updated := oldCluster.DeepCopy()
+			updated.Spec.Replicas = pointer.Int32Ptr(tt.replicas)
+			updated.Spec.AllowUnsafeScaleDown = tt.allowUnsafe
+
+			err := updated.ValidateUpdate(oldCluster)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate_ZoneStatefulSets(t *testing.T) {
+	zones := func(zones ...string) *v1alpha1.ZoneStatefulSetsConfig {
+		return &v1alpha1.ZoneStatefulSetsConfig{Zones: zones}
+	}
+	tests := []struct {
+		name        string
+		old         *v1alpha1.ZoneStatefulSetsConfig
+		updated     *v1alpha1.ZoneStatefulSetsConfig
+		expectError bool
+	}{
+		{"unchanged", zones("a", "b"), zones("a", "b"), false},
+		{"enabled", nil, zones("a", "b"), true},
+		{"disabled", zones("a", "b"), nil, true},
+		{"zone added", zones("a", "b"), zones("a", "b", "c"), true},
+		{"zones reordered", zones("a", "b"), zones("b", "a"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.ClusterSpec{
+					Replicas:         pointer.Int32Ptr(3),
+					ZoneStatefulSets: tt.old,
+					Configuration: v1alpha1.RedpandaConfig{
+						KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+						AdminAPI:  v1alpha1.SocketAddress{Port: 125},
+						RPCServer: v1alpha1.SocketAddress{Port: 126},
+					},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			}
+			updated := oldCluster.DeepCopy()
+			updated.Spec.ZoneStatefulSets = tt.updated
+
+			err := updated.ValidateUpdate(oldCluster)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate_CloudStorageDeveloperMode(t *testing.T) {
+	tests := []struct {
+		name             string
+		oldCloudStorage  bool
+		oldDeveloperMode bool
+		cloudStorage     bool
+		developerMode    bool
+		expectError      bool
+	}{
+		{"combined before validation", true, true, true, true, false},
+		{"developer mode disabled", true, true, true, false, false},
+		{"developer mode enabled", true, false, true, true, true},
+		{"cloud storage enabled", false, true, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCluster := &v1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.ClusterSpec{
+					Replicas: pointer.Int32Ptr(3),
+					Configuration: v1alpha1.RedpandaConfig{
+						KafkaAPI:      v1alpha1.SocketAddress{Port: 123},
+						AdminAPI:      v1alpha1.SocketAddress{Port: 125},
+						RPCServer:     v1alpha1.SocketAddress{Port: 126},
+						DeveloperMode: tt.oldDeveloperMode,
+					},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			}
+			if tt.oldCloudStorage {
+				oldCluster.Spec.CloudStorage = cloudStorageWithEgress("10.0.0.15")
+			}
+			updated := oldCluster.DeepCopy()
+			updated.Spec.Configuration.DeveloperMode = tt.developerMode
+			if tt.cloudStorage {
+				updated.Spec.CloudStorage = cloudStorageWithEgress("10.0.0.15")
+			}
+			// unrelated change of the cluster
+			updated.Spec.Replicas = pointer.Int32Ptr(4)
+
+			err := updated.ValidateUpdate(oldCluster)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate_ExistingErrors(t *testing.T) {
+	// the log level was not validated when the Cluster was created
+	oldCluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Configuration: v1alpha1.RedpandaConfig{
+				KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+				AdminAPI:  v1alpha1.SocketAddress{Port: 125},
+				RPCServer: v1alpha1.SocketAddress{Port: 126},
+			},
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+			Storage: v1alpha1.StorageSpec{
+				Capacity: resource.MustParse("10Gi"),
+			},
+			LogLevel: "verbose",
+		},
+	}
+	require.Error(t, oldCluster.ValidateCreate())
+
+	t.Run("annotation patch", func(t *testing.T) {
+		updated := oldCluster.DeepCopy()
+		updated.Annotations = map[string]string{v1alpha1.LastAppliedSpecAnnotation: "{}"}
+		assert.NoError(t, updated.ValidateUpdate(oldCluster))
+	})
+
+	t.Run("unrelated change", func(t *testing.T) {
+		updated := oldCluster.DeepCopy()
+		updated.Spec.Replicas = pointer.Int32Ptr(4)
+		assert.NoError(t, updated.ValidateUpdate(oldCluster))
+	})
+
+	t.Run("invalid field changed", func(t *testing.T) {
+		updated := oldCluster.DeepCopy()
+		updated.Spec.LogLevel = "chatty"
+		assert.Error(t, updated.ValidateUpdate(oldCluster))
+	})
+
+	t.Run("new error", func(t *testing.T) {
+		updated := oldCluster.DeepCopy()
+		updated.Spec.Replicas = pointer.Int32Ptr(4)
+		updated.Spec.StartupDelaySeconds = pointer.Int32Ptr(-1)
+		assert.Error(t, updated.ValidateUpdate(oldCluster))
+	})
+}
+
 func TestCreation(t *testing.T) {
 	redpandaCluster := &v1alpha1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -261,6 +603,32 @@ func TestCreation(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("requests within limits", func(t *testing.T) {
+		res := redpandaCluster.DeepCopy()
+		res.Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2G"),
+			corev1.ResourceCPU:    resource.MustParse("1"),
+		}
+
+		err := res.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("requests exceed limits", func(t *testing.T) {
+		res := redpandaCluster.DeepCopy()
+		res.Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("3G"),
+		}
+
+		err := res.ValidateCreate()
+		assert.Error(t, err)
+		statusError := err.(*apierrors.StatusError)
+		assert.Len(t, statusError.Status().Details.Causes, 1)
+		assert.Equal(t,
+			field.NewPath("spec").Child("resources").Child("requests").Child("memory").String(),
+			statusError.Status().Details.Causes[0].Field)
+	})
+
 	t.Run("tls properly configured", func(t *testing.T) {
 		tls := redpandaCluster.DeepCopy()
 		tls.Spec.Configuration.TLS.KafkaAPI.Enabled = true
@@ -270,6 +638,58 @@ func TestCreation(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("issuer ref per api", func(t *testing.T) {
+		tls := redpandaCluster.DeepCopy()
+		tls.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+		tls.Spec.Configuration.TLS.KafkaAPI.IssuerRef = &cmmeta.ObjectReference{
+			Name: "kafka-issuer",
+			Kind: "ClusterIssuer",
+		}
+		tls.Spec.Configuration.TLS.AdminAPI.Enabled = true
+		tls.Spec.Configuration.TLS.AdminAPI.IssuerRef = &cmmeta.ObjectReference{
+			Name: "admin-issuer",
+			Kind: "Issuer",
+		}
+
+		err := tls.ValidateCreate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid admin api issuer ref", func(t *testing.T) {
+		tls := redpandaCluster.DeepCopy()
+		tls.Spec.Configuration.TLS.AdminAPI.Enabled = true
+		tls.Spec.Configuration.TLS.AdminAPI.IssuerRef = &cmmeta.ObjectReference{
+			Kind: "Certificate",
+		}
+
+		err := tls.ValidateCreate()
+		assert.Error(t, err)
+		statusError := err.(*apierrors.StatusError)
+		assert.Len(t, statusError.Status().Details.Causes, 2)
+	})
+
+	t.Run("load balancer tags require cloud provider", func(t *testing.T) {
+		tags := redpandaCluster.DeepCopy()
+		tags.Spec.ExternalConnectivity.Enabled = true
+		tags.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivityLoadBalancer
+		tags.Spec.ExternalConnectivity.LoadBalancerTags = map[string]string{"team": "streaming"}
+
+		err := tags.ValidateCreate()
+		assert.Error(t, err)
+
+		tags.Spec.ExternalConnectivity.CloudProvider = "gcp"
+		err = tags.ValidateCreate()
+		assert.Error(t, err, "gcp has no load balancer tags annotation")
+
+		tags.Spec.ExternalConnectivity.CloudProvider = v1alpha1.CloudProviderAWS
+		err = tags.ValidateCreate()
+		assert.NoError(t, err)
+
+		tags.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivityNodePort
+		err = tags.ValidateCreate()
+		assert.Error(t, err, "node port Services don't provision load balancers")
+	})
+
 	t.Run("require client auth without tls enabled", func(t *testing.T) {
 		tls := redpandaCluster.DeepCopy()
 		tls.Spec.Configuration.TLS.KafkaAPI.Enabled = false
@@ -278,4 +698,954 @@ func TestCreation(t *testing.T) {
 		err := tls.ValidateCreate()
 		assert.Error(t, err)
 	})
+	t.Run("run as root", func(t *testing.T) {
+		root := redpandaCluster.DeepCopy()
+		root.Spec.RunAsUser = pointer.Int64Ptr(1001)
+		root.Spec.RunAsGroup = pointer.Int64Ptr(1001)
+		err := root.ValidateCreate()
+		assert.NoError(t, err)
+
+		root.Spec.RunAsUser = pointer.Int64Ptr(0)
+		root.Spec.RunAsGroup = pointer.Int64Ptr(0)
+		err = root.ValidateCreate()
+		assert.Error(t, err)
+		statusError := err.(*apierrors.StatusError)
+		assert.Len(t, statusError.Status().Details.Causes, 2)
+	})
+
+	t.Run("client quotas", func(t *testing.T) {
+		quotas := redpandaCluster.DeepCopy()
+		quotas.Spec.Configuration.ClientQuotas = &v1alpha1.ClientQuotas{
+			DefaultByteRate: pointer.Int64Ptr(1 << 20),
+			Groups: []v1alpha1.ClientGroupQuota{
+				{Name: "batch", ClientIDPrefix: "batch-", ByteRate: 1 << 10},
+			},
+		}
+		err := quotas.ValidateCreate()
+		assert.NoError(t, err)
+
+		quotas.Spec.Configuration.ClientQuotas.DefaultByteRate = pointer.Int64Ptr(0)
+		quotas.Spec.Configuration.ClientQuotas.Groups = append(quotas.Spec.Configuration.ClientQuotas.Groups,
+			v1alpha1.ClientGroupQuota{Name: "batch", ByteRate: -1})
+		err = quotas.ValidateCreate()
+		assert.Error(t, err)
+		statusError := err.(*apierrors.StatusError)
+		// zero default, duplicate name, missing prefix and negative rate
+		assert.Len(t, statusError.Status().Details.Causes, 4)
+	})
+
+	t.Run("shared node certificate", func(t *testing.T) {
+		shared := redpandaCluster.DeepCopy()
+		shared.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+		shared.Spec.Configuration.TLS.SharedNodeCert = true
+
+		err := shared.ValidateCreate()
+		assert.Error(t, err, "admin api tls is disabled")
+
+		shared.Spec.Configuration.TLS.AdminAPI.Enabled = true
+		err = shared.ValidateCreate()
+		assert.NoError(t, err)
+
+		shared.Spec.Configuration.TLS.AdminAPI.IssuerRef = &cmmeta.ObjectReference{Name: "admin-issuer"}
+		err = shared.ValidateCreate()
+		assert.Error(t, err, "conflicting admin api issuer")
+	})
+
+	t.Run("metadata backup", func(t *testing.T) {
+		backup := redpandaCluster.DeepCopy()
+		backup.Namespace = "redpanda"
+		backup.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+			Enabled:      true,
+			AccessKey:    "access",
+			Region:       "us-west-1",
+			Bucket:       "archive",
+			SecretKeyRef: corev1.ObjectReference{Name: "secret", Namespace: "redpanda"},
+		}
+		backup.Spec.MetadataBackup = &v1alpha1.MetadataBackupConfig{Schedule: "0 */6 * * *"}
+		err := backup.ValidateCreate()
+		assert.NoError(t, err)
+
+		backup.Spec.MetadataBackup.Schedule = "@daily"
+		err = backup.ValidateCreate()
+		assert.NoError(t, err)
+
+		backup.Spec.MetadataBackup.Schedule = "every day"
+		backup.Spec.CloudStorage.SecretKeyRef.Namespace = "other"
+		err = backup.ValidateCreate()
+		assert.Error(t, err)
+		statusError := err.(*apierrors.StatusError)
+		assert.Len(t, statusError.Status().Details.Causes, 2)
+
+		backup.Spec.MetadataBackup.Schedule = "0 0 * * *"
+		backup.Spec.CloudStorage.SecretKeyRef.Namespace = "redpanda"
+		backup.Spec.EnableSASL = true
+		backup.Spec.Superusers = []v1alpha1.Superuser{{Username: "admin"}}
+		err = backup.ValidateCreate()
+		assert.Error(t, err, "no superuser to export with")
+
+		backup.Spec.Superusers[0].PasswordSecretKeyRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "admin"},
+			Key:                  "password",
+		}
+		err = backup.ValidateCreate()
+		assert.NoError(t, err)
+
+		backup.Spec.CloudStorage = v1alpha1.CloudStorageConfig{}
+		err = backup.ValidateCreate()
+		assert.Error(t, err, "cloud storage is disabled")
+	})
+
+	t.Run("seccomp profile", func(t *testing.T) {
+		seccomp := redpandaCluster.DeepCopy()
+		seccomp.Spec.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+		err := seccomp.ValidateCreate()
+		assert.NoError(t, err)
+
+		seccomp.Spec.SeccompProfile = &corev1.SeccompProfile{
+			Type:             corev1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: pointer.StringPtr("profiles/redpanda.json"),
+		}
+		err = seccomp.ValidateCreate()
+		assert.NoError(t, err)
+
+		seccomp.Spec.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost}
+		err = seccomp.ValidateCreate()
+		assert.Error(t, err, "missing localhost profile")
+
+		seccomp.Spec.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+		err = seccomp.ValidateCreate()
+		assert.Error(t, err, "unconfined profile")
+	})
+
+	t.Run("separate external certificate", func(t *testing.T) {
+		tls := redpandaCluster.DeepCopy()
+		tls.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+		tls.Spec.Configuration.TLS.KafkaAPI.SeparateExternalCert = true
+
+		err := tls.ValidateCreate()
+		assert.Error(t, err, "external connectivity is disabled")
+
+		tls.Spec.ExternalConnectivity.Enabled = true
+		tls.Spec.ExternalConnectivity.Subdomain = "redpanda.example.com"
+		err = tls.ValidateCreate()
+		assert.NoError(t, err)
+	})
+}
+
+func TestValidateSpec(t *testing.T) {
+	redpandaCluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "",
+		},
+		Spec: v1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Configuration: v1alpha1.RedpandaConfig{
+				KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+				AdminAPI:  v1alpha1.SocketAddress{Port: 125},
+				RPCServer: v1alpha1.SocketAddress{Port: 126},
+			},
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("2G"),
+				},
+			},
+			Storage: v1alpha1.StorageSpec{
+				Capacity: resource.MustParse("10Gi"),
+			},
+			Superusers: []v1alpha1.Superuser{{Username: "admin"}, {Username: "operator"}},
+			ExternalConnectivity: v1alpha1.ExternalConnectivityConfig{
+				Enabled:   true,
+				Subdomain: "redpanda.example.com.",
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		mutate        func(cluster *v1alpha1.Cluster)
+		expectedField string
+	}{
+		{"valid spec", func(*v1alpha1.Cluster) {}, ""},
+		{"zero replicas", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Replicas = pointer.Int32Ptr(0)
+		}, "spec.replicas"},
+		{"log level", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.LogLevel = "warn"
+		}, ""},
+		{"unsupported log level", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.LogLevel = "verbose"
+		}, "spec.logLevel"},
+		{"log level in upper case", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.LogLevel = "INFO"
+		}, "spec.logLevel"},
+		{"acl", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ACLs = []v1alpha1.ACL{
+				{Principal: "User:client", ResourceType: "Topic", ResourceName: "orders", PatternType: "Prefixed", Operation: "Read"},
+				{Principal: "User:*", ResourceType: "Cluster", ResourceName: "kafka-cluster", Operation: "Describe", Permission: "Deny"},
+			}
+		}, ""},
+		{"acl principal without type", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ACLs = []v1alpha1.ACL{{Principal: "client", ResourceType: "Topic", ResourceName: "orders", Operation: "Read"}}
+		}, "spec.acls[0].principal"},
+		{"acl without resource name", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ACLs = []v1alpha1.ACL{{Principal: "User:client", ResourceType: "Group", Operation: "Read"}}
+		}, "spec.acls[0].resourceName"},
+		{"acl of unnamed cluster resource", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ACLs = []v1alpha1.ACL{{Principal: "User:client", ResourceType: "Cluster", ResourceName: "redpanda", Operation: "Alter"}}
+		}, "spec.acls[0].resourceName"},
+		{"acl prefixed wildcard", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ACLs = []v1alpha1.ACL{{Principal: "User:client", ResourceType: "Topic", ResourceName: "*", PatternType: "Prefixed", Operation: "Read"}}
+		}, "spec.acls[0].resourceName"},
+		{"acl unsupported operation", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ACLs = []v1alpha1.ACL{{Principal: "User:client", ResourceType: "Topic", ResourceName: "orders", Operation: "Produce"}}
+		}, "spec.acls[0].operation"},
+		{"extra volume", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExtraVolumes = []corev1.Volume{{Name: "ca-bundle"}}
+			cluster.Spec.ExtraVolumeMounts = []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ca"}}
+			cluster.Spec.ExtraEnv = []corev1.EnvVar{{Name: "SSL_CERT_DIR", Value: "/etc/ca"}}
+		}, ""},
+		{"extra volume colliding with data directory", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExtraVolumes = []corev1.Volume{{Name: "datadir"}}
+		}, "spec.extraVolumes[0].name"},
+		{"duplicated extra volume", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExtraVolumes = []corev1.Volume{{Name: "ca-bundle"}, {Name: "ca-bundle"}}
+		}, "spec.extraVolumes[1].name"},
+		{"extra volume mount of operator volume", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExtraVolumeMounts = []corev1.VolumeMount{{Name: "tlscert", MountPath: "/etc/tls"}}
+		}, "spec.extraVolumeMounts[0].name"},
+		{"extra env set by the operator", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExtraEnv = []corev1.EnvVar{{Name: "SSL_CERT_DIR"}, {Name: "POD_IP"}}
+		}, "spec.extraEnv[1].name"},
+		{"host network without external connectivity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.HostNetwork = true
+			cluster.Spec.ExternalConnectivity.Enabled = false
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+		}, ""},
+		{"host network with load balancers", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.HostNetwork = true
+			cluster.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivityLoadBalancer
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+		}, ""},
+		{"host network with node ports", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.HostNetwork = true
+			cluster.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivityNodePort
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+		}, "spec.hostNetwork"},
+		{"host network with subdomain", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.HostNetwork = true
+			cluster.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivitySubdomain
+		}, "spec.hostNetwork"},
+		{"host network with node IP", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.HostNetwork = true
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+		}, "spec.hostNetwork"},
+		{"client ip session affinity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+		}, ""},
+		{"unsupported session affinity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.SessionAffinity = "Cookie"
+		}, "spec.sessionAffinity"},
+		{"dns propagation timeout", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.DNSPropagationTimeoutSeconds = pointer.Int32Ptr(300)
+		}, ""},
+		{"dns propagation timeout without subdomain", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+			cluster.Spec.ExternalConnectivity.DNSPropagationTimeoutSeconds = pointer.Int32Ptr(300)
+		}, "spec.externalConnectivity.dnsPropagationTimeoutSeconds"},
+		{"max clock skew", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.MaxClockSkewSeconds = pointer.Int32Ptr(5)
+		}, ""},
+		{"max clock skew below precision", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.MaxClockSkewSeconds = pointer.Int32Ptr(1)
+		}, "spec.maxClockSkewSeconds"},
+		{"kubelet port", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdminAPI.Port = 10250
+		}, "spec.configuration.admin.port"},
+		{"rpc port in node port range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.RPCServer.Port = 30000
+		}, "spec.configuration.rpcServer.port"},
+		{"kafka port in node port range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.KafkaAPI.Port = 32767
+		}, "spec.configuration.kafkaApi.port"},
+		{"external kafka port reserved", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.KafkaAPI.Port = 10247
+		}, "spec.configuration.kafkaApi.port"},
+		{"port below node port range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.KafkaAPI.Port = 29998
+		}, ""},
+		{"port above node port range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.RPCServer.Port = 32768
+		}, ""},
+		{"well known port is reported by the controller", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdminAPI.Port = 9100
+		}, ""},
+		{"reserved port without external connectivity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Enabled = false
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+			cluster.Spec.Configuration.AdminAPI.Port = 10250
+		}, ""},
+		{"metrics TLS without admin API TLS", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.Metrics.Enabled = true
+		}, "spec.configuration.tls.metrics.enabled"},
+		{"metrics TLS with admin API TLS", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.AdminAPI.Enabled = true
+			cluster.Spec.Configuration.TLS.Metrics.Enabled = true
+		}, ""},
+		{"storage below minimum", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Storage.Capacity = resource.MustParse("100Mi")
+		}, "spec.storage.capacity"},
+		{"cloud storage with projected token", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+				Enabled:        true,
+				Region:         "us-west-1",
+				Bucket:         "archive",
+				ProjectedToken: &v1alpha1.CloudStorageProjectedToken{Audience: "sts.amazonaws.com"},
+			}
+		}, ""},
+		{"projected token without audience", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+				Enabled:        true,
+				Region:         "us-west-1",
+				Bucket:         "archive",
+				ProjectedToken: &v1alpha1.CloudStorageProjectedToken{},
+			}
+		}, "spec.cloudStorage.projectedToken.audience"},
+		{"projected token audience with whitespace", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+				Enabled:        true,
+				Region:         "us-west-1",
+				Bucket:         "archive",
+				ProjectedToken: &v1alpha1.CloudStorageProjectedToken{Audience: "sts amazonaws"},
+			}
+		}, "spec.cloudStorage.projectedToken.audience"},
+		{"projected token expiring too soon", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+				Enabled: true,
+				Region:  "us-west-1",
+				Bucket:  "archive",
+				ProjectedToken: &v1alpha1.CloudStorageProjectedToken{
+					Audience:          "sts.amazonaws.com",
+					ExpirationSeconds: pointer.Int64Ptr(60),
+				},
+			}
+		}, "spec.cloudStorage.projectedToken.expirationSeconds"},
+		{"egress policy with CIDRs", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithEgress("s3.us-west-1.amazonaws.com", "52.92.0.0/17")
+		}, ""},
+		{"egress policy to IP endpoint", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithEgress("10.0.0.15")
+		}, ""},
+		{"egress policy to host name without CIDRs", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithEgress("s3.us-west-1.amazonaws.com")
+		}, "spec.cloudStorage.egressNetworkPolicy.cidrs"},
+		{"egress policy with invalid CIDR", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithEgress("10.0.0.15", "10.0.0.300/24")
+		}, "spec.cloudStorage.egressNetworkPolicy.cidrs[0]"},
+		{"egress policy to invalid endpoint", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithEgress("https://s3.amazonaws.com", "52.92.0.0/17")
+		}, "spec.cloudStorage.apiEndpoint"},
+		{"developer mode without cloud storage", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.DeveloperMode = true
+		}, ""},
+		{"cloud storage in developer mode", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithEgress("10.0.0.15")
+			cluster.Spec.Configuration.DeveloperMode = true
+		}, "spec.configuration.developerMode"},
+		{"cloud storage without secret", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = v1alpha1.CloudStorageConfig{
+				Enabled:      true,
+				AccessKey:    "access",
+				Region:       "us-west-1",
+				Bucket:       "archive",
+				SecretKeyRef: corev1.ObjectReference{Namespace: "default"},
+			}
+		}, "spec.configuration.cloudStorage.secretKeyRef.name"},
+		{"admin api client auth without issuer", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth = true
+		}, "spec.configuration.tls.adminApi.requireClientAuth"},
+		{"duplicate superuser", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Superusers = append(cluster.Spec.Superusers, v1alpha1.Superuser{Username: "admin"})
+		}, "spec.superUsers[2].username"},
+		{"superuser password without key", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Superusers[0].PasswordSecretKeyRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "admin-password"},
+			}
+		}, "spec.superUsers[0].passwordSecretKeyRef"},
+		{"superuser mechanism enabled on the SASL listeners", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.EnableSASL = true
+			cluster.Spec.SASLMechanisms = []v1alpha1.SASLMechanism{v1alpha1.SCRAMSHA256, v1alpha1.SCRAMSHA512}
+			cluster.Spec.Superusers[1].Mechanism = v1alpha1.SCRAMSHA512
+		}, ""},
+		{"superuser mechanism not enabled on the SASL listeners", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.EnableSASL = true
+			cluster.Spec.Superusers[1].Mechanism = v1alpha1.SCRAMSHA512
+		}, "spec.superUsers[1].mechanism"},
+		{"default superuser mechanism not enabled on the SASL listeners", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.EnableSASL = true
+			cluster.Spec.SASLMechanisms = []v1alpha1.SASLMechanism{v1alpha1.SCRAMSHA512}
+			cluster.Spec.Superusers[1].Mechanism = v1alpha1.SCRAMSHA512
+		}, "spec.superUsers[0].mechanism"},
+		{"unsupported SASL mechanism", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.EnableSASL = true
+			cluster.Spec.SASLMechanisms = []v1alpha1.SASLMechanism{v1alpha1.SCRAMSHA256, "PLAIN"}
+		}, "spec.saslMechanisms[1]"},
+		{"invalid subdomain", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Subdomain = "Redpanda_Example.com"
+		}, "spec.externalConnectivity.subdomain"},
+		{"subdomain type without subdomain", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivitySubdomain
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+		}, "spec.externalConnectivity.subdomain"},
+		{"node port type with subdomain", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivityNodePort
+		}, "spec.externalConnectivity.type"},
+		{"load balancer type", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Type = v1alpha1.ExternalConnectivityLoadBalancer
+			cluster.Spec.ExternalConnectivity.Subdomain = ""
+		}, ""},
+		{"additional configuration", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdditionalConfiguration = map[string]string{
+				"log_segment_size":           "536870912",
+				"auto_create_topics_enabled": "false",
+				"default_topic_cleanup":      "compact",
+			}
+		}, ""},
+		{"additional configuration overrides managed property", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdditionalConfiguration = map[string]string{"seed_servers": "[]"}
+		}, "spec.configuration.additionalConfiguration[seed_servers]"},
+		{"additional configuration with invalid number", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdditionalConfiguration = map[string]string{"log_segment_size": "1GB"}
+		}, "spec.configuration.additionalConfiguration[log_segment_size]"},
+		{"additional configuration with invalid boolean", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdditionalConfiguration = map[string]string{"auto_create_topics_enabled": "yes"}
+		}, "spec.configuration.additionalConfiguration[auto_create_topics_enabled]"},
+		{"bootstrap topics", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.BootstrapTopics = []v1alpha1.BootstrapTopic{
+				{Name: "dead-letter", Partitions: 3, ReplicationFactor: 3},
+				{Name: "audit", Partitions: 1, ReplicationFactor: 1},
+			}
+		}, ""},
+		{"duplicate bootstrap topic", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.BootstrapTopics = []v1alpha1.BootstrapTopic{
+				{Name: "audit", Partitions: 1, ReplicationFactor: 1},
+				{Name: "audit", Partitions: 3, ReplicationFactor: 1},
+			}
+		}, "spec.bootstrapTopics[1].name"},
+		{"bootstrap topic replicated to more brokers than available", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.BootstrapTopics = []v1alpha1.BootstrapTopic{
+				{Name: "audit", Partitions: 1, ReplicationFactor: 5},
+			}
+		}, "spec.bootstrapTopics[0].replicationFactor"},
+		{"disruption budget override", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Replicas = pointer.Int32Ptr(5)
+			cluster.Spec.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetConfig{MinAvailable: pointer.Int32Ptr(3)}
+			cluster.Spec.RollingUpdate = &v1alpha1.RollingUpdateConfig{MaxUnavailable: 2}
+		}, ""},
+		{"disruption budget exceeding replicas", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetConfig{MinAvailable: pointer.Int32Ptr(4)}
+		}, "spec.podDisruptionBudget.minAvailable"},
+		{"negative max unavailable", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.RollingUpdate = &v1alpha1.RollingUpdateConfig{MaxUnavailable: -1}
+		}, "spec.rollingUpdate.maxUnavailable"},
+		{"max unavailable losing the majority", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.RollingUpdate = &v1alpha1.RollingUpdateConfig{MaxUnavailable: 2}
+		}, "spec.rollingUpdate.maxUnavailable"},
+		{"advertised kafka api ports", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdvertisedKafkaAPIPorts = &v1alpha1.AdvertisedKafkaAPIPorts{Internal: 19092, External: 443}
+		}, ""},
+		{"advertised kafka api port out of range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.AdvertisedKafkaAPIPorts = &v1alpha1.AdvertisedKafkaAPIPorts{Internal: 70000}
+		}, "spec.configuration.advertisedKafkaApiPorts.internal"},
+		{"external advertised port without external connectivity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity = v1alpha1.ExternalConnectivityConfig{}
+			cluster.Spec.Configuration.AdvertisedKafkaAPIPorts = &v1alpha1.AdvertisedKafkaAPIPorts{External: 443}
+		}, "spec.configuration.advertisedKafkaApiPorts.external"},
+		{"external kafka api port out of range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.KafkaAPI.Port = 65535
+		}, "spec.configuration.kafkaApi.port"},
+		{"resource preset", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Resources = corev1.ResourceRequirements{}
+			cluster.Spec.ResourcePreset = "small"
+		}, ""},
+		{"unsupported resource preset", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ResourcePreset = "huge"
+		}, "spec.resourcePreset"},
+		{"resource preset with insufficient memory override", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ResourcePreset = "large"
+			cluster.Spec.Resources.Limits[corev1.ResourceMemory] = resource.MustParse("1Gi")
+		}, "spec.resources.limits.memory"},
+		{"password rotation", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.PasswordRotation = &v1alpha1.PasswordRotationConfig{IntervalHours: 720}
+		}, ""},
+		{"password rotation without interval", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.PasswordRotation = &v1alpha1.PasswordRotationConfig{}
+		}, "spec.passwordRotation.intervalHours"},
+		{"startup delay", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.StartupDelaySeconds = pointer.Int32Ptr(30)
+		}, ""},
+		{"zero startup delay", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.StartupDelaySeconds = pointer.Int32Ptr(0)
+		}, "spec.startupDelaySeconds"},
+		{"startup delay too long", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.StartupDelaySeconds = pointer.Int32Ptr(3600)
+		}, "spec.startupDelaySeconds"},
+		{"open files limit", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.OpenFilesLimit = pointer.Int64Ptr(65536)
+		}, ""},
+		{"open files limit too low", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.OpenFilesLimit = pointer.Int64Ptr(256)
+		}, "spec.openFilesLimit"},
+		{"open files limit above kernel maximum", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.OpenFilesLimit = pointer.Int64Ptr(4194304)
+		}, "spec.openFilesLimit"},
+		{"liveness probe", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.LivenessProbe = &v1alpha1.LivenessProbeConfig{Mode: v1alpha1.LivenessProbeAll}
+		}, ""},
+		{"liveness probe with unknown mode", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.LivenessProbe = &v1alpha1.LivenessProbeConfig{Mode: "Kafka"}
+		}, "spec.livenessProbe.mode"},
+		{"external admin", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Enabled = false
+			cluster.Spec.ExternalAdmin = v1alpha1.ExternalAdminConfig{Enabled: true, Subdomain: "admin.example.com"}
+		}, ""},
+		{"external admin with external connectivity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalAdmin.Enabled = true
+		}, "spec.externalAdmin.enabled"},
+		{"external admin with invalid subdomain", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Enabled = false
+			cluster.Spec.ExternalAdmin = v1alpha1.ExternalAdminConfig{Enabled: true, Subdomain: "Admin_Example"}
+		}, "spec.externalAdmin.subdomain"},
+		{"local retention within capacity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Storage.LocalRetention = quantity("8Gi")
+		}, ""},
+		{"local retention exceeding capacity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Storage.LocalRetention = quantity("11Gi")
+		}, "spec.storage.localRetention"},
+		{"negative local retention", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Storage.LocalRetention = quantity("-1Gi")
+		}, "spec.storage.localRetention"},
+		{"cache and local retention within capacity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithCache("4Gi")
+			cluster.Spec.Storage.LocalRetention = quantity("6Gi")
+		}, ""},
+		{"cache and local retention exceeding capacity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithCache("4Gi")
+			cluster.Spec.Storage.LocalRetention = quantity("7Gi")
+		}, "spec.storage.localRetention"},
+		{"cache exceeding capacity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithCache("12Gi")
+		}, "spec.cloudStorage.cacheSize"},
+		{"zero cache", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage = cloudStorageWithCache("0")
+		}, "spec.cloudStorage.cacheSize"},
+		{"cache of disabled cloud storage", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.CloudStorage.CacheSize = quantity("12Gi")
+			cluster.Spec.Storage.LocalRetention = quantity("6Gi")
+		}, ""},
+		{"cache and local retention with default capacity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Storage.Capacity = resource.Quantity{}
+			cluster.Spec.CloudStorage = cloudStorageWithCache("40Gi")
+			cluster.Spec.Storage.LocalRetention = quantity("40Gi")
+		}, ""},
+		{"dedicated nodes", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.DedicatedNodes = true
+			cluster.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+		}, ""},
+		{"dedicated nodes with conflicting selector", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.DedicatedNodes = true
+			cluster.Spec.NodeSelector = map[string]string{v1alpha1.DedicatedNodesKey: "false"}
+		}, "spec.nodeSelector[redpanda.vectorized.io/dedicated]"},
+		{"dedicated nodes with conflicting toleration", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.DedicatedNodes = true
+			cluster.Spec.Tolerations = []corev1.Toleration{
+				{Key: v1alpha1.DedicatedNodesKey, Operator: corev1.TolerationOpEqual, Value: "kafka", Effect: corev1.TaintEffectNoSchedule},
+			}
+		}, "spec.tolerations[0].value"},
+		{"dedicated nodes selected without toleration", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.NodeSelector = map[string]string{v1alpha1.DedicatedNodesKey: v1alpha1.DedicatedNodesValue}
+		}, "spec.nodeSelector[redpanda.vectorized.io/dedicated]"},
+		{"dedicated nodes selected and tolerated by hand", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.NodeSelector = map[string]string{v1alpha1.DedicatedNodesKey: v1alpha1.DedicatedNodesValue}
+			cluster.Spec.Tolerations = []corev1.Toleration{
+				{Key: v1alpha1.DedicatedNodesKey, Operator: corev1.TolerationOpExists},
+			}
+		}, ""},
+		{"zone StatefulSets", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity = v1alpha1.ExternalConnectivityConfig{}
+			cluster.Spec.ZoneStatefulSets = &v1alpha1.ZoneStatefulSetsConfig{Zones: []string{"us-east1-a", "us-east1-b"}}
+		}, ""},
+		{"zone StatefulSets with duplicate zone", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity = v1alpha1.ExternalConnectivityConfig{}
+			cluster.Spec.ZoneStatefulSets = &v1alpha1.ZoneStatefulSetsConfig{Zones: []string{"a", "a"}}
+		}, "spec.zoneStatefulSets.zones[1]"},
+		{"zone StatefulSets with invalid name", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity = v1alpha1.ExternalConnectivityConfig{}
+			cluster.Spec.ZoneStatefulSets = &v1alpha1.ZoneStatefulSetsConfig{Zones: []string{"US_East"}}
+		}, "spec.zoneStatefulSets.zones[0]"},
+		{"zone StatefulSets with external connectivity", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ZoneStatefulSets = &v1alpha1.ZoneStatefulSetsConfig{Zones: []string{"a"}}
+		}, "spec.zoneStatefulSets"},
+		{"subdomain at the certificate SAN limit", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.ExternalConnectivity.Subdomain = subdomainOfLength(251)
+		}, ""},
+		{"subdomain over the certificate SAN limit", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.ExternalConnectivity.Subdomain = subdomainOfLength(252)
+		}, "spec.externalConnectivity.subdomain"},
+		{"subdomain over the certificate SAN limit without TLS", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.ExternalConnectivity.Subdomain = subdomainOfLength(252)
+		}, ""},
+		{"subdomain at the certificate SAN limit with many brokers", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.Replicas = pointer.Int32Ptr(11)
+			cluster.Spec.ExternalConnectivity.Subdomain = subdomainOfLength(251)
+		}, "spec.externalConnectivity.subdomain"},
+		{"subdomain with label at the certificate SAN limit", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.ExternalConnectivity.Subdomain = strings.Repeat("a", 63) + ".example.com."
+		}, ""},
+		{"subdomain with label over the certificate SAN limit", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.ExternalConnectivity.Subdomain = strings.Repeat("a", 64) + ".example.com."
+		}, "spec.externalConnectivity.subdomain"},
+		{"CA secret namespaces", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.Configuration.TLS.KafkaAPI.CASecretNamespaces = []string{"clients", "apps"}
+		}, ""},
+		{"CA secret namespaces without TLS", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.CASecretNamespaces = []string{"clients"}
+		}, "spec.configuration.tls.kafkaApi.caSecretNamespaces"},
+		{"CA secret namespaces with duplicate", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.Configuration.TLS.KafkaAPI.CASecretNamespaces = []string{"clients", "clients"}
+		}, "spec.configuration.tls.kafkaApi.caSecretNamespaces[1]"},
+		{"CA secret namespaces with invalid name", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.Configuration.TLS.KafkaAPI.CASecretNamespaces = []string{"Clients"}
+		}, "spec.configuration.tls.kafkaApi.caSecretNamespaces[0]"},
+		{"listeners", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Replication", Port: 9094})
+		}, ""},
+		{"listener with advertised port", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Replication", Port: 9094, AdvertisedPort: 19094})
+		}, ""},
+		{"listener with advertised port out of range", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Replication", Port: 9094, AdvertisedPort: 70000})
+		}, "spec.configuration.listeners[2].advertisedPort"},
+		{"advertised kafka api ports with listeners", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners()
+			cluster.Spec.Configuration.AdvertisedKafkaAPIPorts = &v1alpha1.AdvertisedKafkaAPIPorts{Internal: 19092}
+		}, "spec.configuration.advertisedKafkaApiPorts"},
+		{"listeners with duplicate names", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(
+				v1alpha1.ListenerSpec{Name: "Replication", Port: 9094},
+				v1alpha1.ListenerSpec{Name: "replication", Port: 9095})
+		}, "spec.configuration.listeners[3].name"},
+		{"listener on the Admin API port", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Replication", Port: 125})
+		}, "spec.configuration.listeners[2].port"},
+		{"listeners without Internal listener", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Replication", Port: 9094})[1:]
+		}, "spec.configuration.listeners"},
+		{"additional external listener", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Public", Port: 9094, External: true})
+		}, "spec.configuration.listeners[2].external"},
+		{"listener TLS without Kafka API TLS", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Replication", Port: 9094, TLS: true})
+		}, "spec.configuration.listeners[2].tls"},
+		{"listener with reserved port name", func(cluster *v1alpha1.Cluster) {
+			cluster.Spec.Configuration.Listeners = withDefaultListeners(v1alpha1.ListenerSpec{Name: "Kafka", Port: 9094})
+		}, "spec.configuration.listeners[2].name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := redpandaCluster.DeepCopy()
+			tt.mutate(cluster)
+
+			err := cluster.ValidateCreate()
+			if tt.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			statusError := err.(*apierrors.StatusError)
+			causes := statusError.Status().Details.Causes
+			if assert.Len(t, causes, 1) {
+				assert.Equal(t, tt.expectedField, causes[0].Field)
+			}
+		})
+	}
+}
+
+// withDefaultListeners returns the Internal and External listeners of the
+// cluster in TestValidateSpec followed by the additional listeners
+func withDefaultListeners(
+	additional ...v1alpha1.ListenerSpec,
+) []v1alpha1.ListenerSpec {
+	return append([]v1alpha1.ListenerSpec{
+		{Name: v1alpha1.InternalListener, Port: 123},
+		{Name: v1alpha1.ExternalListener, Port: 124, External: true},
+	}, additional...)
+}
+
+func quantity(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
+func cloudStorageWithCache(cacheSize string) v1alpha1.CloudStorageConfig {
+	return v1alpha1.CloudStorageConfig{
+		Enabled:        true,
+		Region:         "us-west-1",
+		Bucket:         "archive",
+		ProjectedToken: &v1alpha1.CloudStorageProjectedToken{Audience: "sts.amazonaws.com"},
+		CacheSize:      quantity(cacheSize),
+	}
+}
+
+// subdomainOfLength returns a valid subdomain of the length, the labels
+// have 63 characters at most
+func subdomainOfLength(length int) string {
+	var labels []string
+	for length > 0 {
+		label := length
+		if label > 63 {
+			label = 63
+		}
+		labels = append(labels, strings.Repeat("a", label))
+		length -= label + 1
+	}
+	return strings.Join(labels, ".")
+}
+
+func TestValidateIssuerNamespace(t *testing.T) {
+	redpandaCluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "redpanda",
+		},
+		Spec: v1alpha1.ClusterSpec{
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: v1alpha1.RedpandaConfig{
+				KafkaAPI:  v1alpha1.SocketAddress{Port: 123},
+				AdminAPI:  v1alpha1.SocketAddress{Port: 125},
+				RPCServer: v1alpha1.SocketAddress{Port: 126},
+			},
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("2G"),
+				},
+			},
+			Storage: v1alpha1.StorageSpec{
+				Capacity: resource.MustParse("10Gi"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		kafkaIssuer   *cmmeta.ObjectReference
+		adminIssuer   *cmmeta.ObjectReference
+		expectedField string
+	}{
+		{"issuer in cluster namespace", &cmmeta.ObjectReference{Name: "local-issuer", Kind: "Issuer"}, nil, ""},
+		{"issuer without kind", &cmmeta.ObjectReference{Name: "local-issuer"}, nil, ""},
+		{"issuer in other namespace", &cmmeta.ObjectReference{Name: "cert-manager/shared-issuer", Kind: "Issuer"}, nil,
+			"spec.configuration.tls.kafkaApi.issuerRef.name"},
+		{"admin api issuer in other namespace", nil, &cmmeta.ObjectReference{Name: "cert-manager/shared-issuer"},
+			"spec.configuration.tls.adminApi.issuerRef.name"},
+		{"cluster issuer", &cmmeta.ObjectReference{Name: "shared-issuer", Kind: "ClusterIssuer"}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := redpandaCluster.DeepCopy()
+			cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			cluster.Spec.Configuration.TLS.KafkaAPI.IssuerRef = tt.kafkaIssuer
+			cluster.Spec.Configuration.TLS.AdminAPI.Enabled = true
+			cluster.Spec.Configuration.TLS.AdminAPI.IssuerRef = tt.adminIssuer
+
+			err := cluster.ValidateCreate()
+			if tt.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			causes := err.(*apierrors.StatusError).Status().Details.Causes
+			if assert.Len(t, causes, 1) {
+				assert.Equal(t, tt.expectedField, causes[0].Field)
+				assert.Contains(t, causes[0].Message, "ClusterIssuer")
+			}
+		})
+	}
+}
+
+func TestWellKnownPortsInUse(t *testing.T) {
+	cluster := &v1alpha1.Cluster{
+		Spec: v1alpha1.ClusterSpec{
+			Configuration: v1alpha1.RedpandaConfig{
+				KafkaAPI:  v1alpha1.SocketAddress{Port: 6442},
+				AdminAPI:  v1alpha1.SocketAddress{Port: 9100},
+				RPCServer: v1alpha1.SocketAddress{Port: 126},
+			},
+		},
+	}
+	assert.Empty(t, cluster.WellKnownPortsInUse(), "ports are not exposed without external connectivity")
+
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	assert.Equal(t, []string{
+		"spec.configuration.kafkaApi.port: external Kafka API port 6443 is commonly used by Kubernetes API server",
+		"spec.configuration.admin.port: port 9100 is commonly used by Prometheus node exporter",
+	}, cluster.WellKnownPortsInUse())
+}
+
+func TestContainerResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		resources corev1.ResourceRequirements
+		expected  corev1.ResourceRequirements
+	}{
+		{
+			name: "no preset",
+			resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+		},
+		{
+			name:   "small",
+			preset: "small",
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		},
+		{
+			name:   "medium",
+			preset: "medium",
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+			},
+		},
+		{
+			name:   "large",
+			preset: "large",
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				},
+			},
+		},
+		{
+			name:   "explicit resources override the preset",
+			preset: "large",
+			resources: corev1.ResourceRequirements{
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Gi")},
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+			},
+			expected: corev1.ResourceRequirements{
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Gi")},
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+			},
+		},
+		{
+			name:   "preset completes explicit resources",
+			preset: "medium",
+			resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &v1alpha1.Cluster{
+				Spec: v1alpha1.ClusterSpec{
+					Resources:      tt.resources,
+					ResourcePreset: tt.preset,
+				},
+			}
+			actual := cluster.ContainerResources()
+			assert.Equal(t, tt.expected, actual)
+			// the spec itself is left untouched
+			assert.Equal(t, tt.resources, cluster.Spec.Resources)
+		})
+	}
+}
+
+func TestIsPrincipalAuthorized(t *testing.T) {
+	tests := []struct {
+		name       string
+		superusers []v1alpha1.Superuser
+		acls       []v1alpha1.ACL
+		expected   bool
+	}{
+		{"no superusers", nil, nil, false},
+		{"superuser", []v1alpha1.Superuser{{Username: "admin"}, {Username: "cluster-admin-api-client"}}, nil, true},
+		{"other superuser", []v1alpha1.Superuser{{Username: "admin"}}, nil, false},
+		{"allowed by acl", nil, []v1alpha1.ACL{
+			{Principal: "User:cluster-admin-api-client", ResourceType: "Cluster", ResourceName: "kafka-cluster", Operation: "All"},
+		}, true},
+		{"allowed by wildcard acl", nil, []v1alpha1.ACL{
+			{Principal: "User:*", ResourceType: "Cluster", ResourceName: "kafka-cluster", Operation: "Describe", Permission: "Allow"},
+		}, true},
+		{"denied by acl", nil, []v1alpha1.ACL{
+			{Principal: "User:cluster-admin-api-client", ResourceType: "Cluster", ResourceName: "kafka-cluster", Operation: "All", Permission: "Deny"},
+		}, false},
+		{"acl of other principal", nil, []v1alpha1.ACL{
+			{Principal: "User:client", ResourceType: "Topic", ResourceName: "orders", Operation: "Read"},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &v1alpha1.Cluster{
+				Spec: v1alpha1.ClusterSpec{
+					Superusers: tt.superusers,
+					ACLs:       tt.acls,
+				},
+			}
+			assert.Equal(t, tt.expected, cluster.IsPrincipalAuthorized("cluster-admin-api-client"))
+		})
+	}
+}
+
+func cloudStorageWithEgress(endpoint string, cidrs ...string) v1alpha1.CloudStorageConfig {
+	return v1alpha1.CloudStorageConfig{
+		Enabled:             true,
+		Region:              "us-west-1",
+		Bucket:              "archive",
+		ProjectedToken:      &v1alpha1.CloudStorageProjectedToken{Audience: "sts.amazonaws.com"},
+		APIEndpoint:         endpoint,
+		EgressNetworkPolicy: &v1alpha1.CloudStorageEgressPolicy{CIDRs: cidrs},
+	}
 }
@@ -14,14 +14,36 @@
 package v1alpha1
 
 import (
-	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
-	"k8s.io/api/core/v1"
+	"github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACL) DeepCopyInto(out *ACL) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACL.
+func (in *ACL) DeepCopy() *ACL {
+	if in == nil {
+		return nil
+	}
+	out := new(ACL)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdminAPITLS) DeepCopyInto(out *AdminAPITLS) {
 	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdminAPITLS.
@@ -34,10 +56,95 @@ func (in *AdminAPITLS) DeepCopy() *AdminAPITLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvertisedKafkaAPIPorts) DeepCopyInto(out *AdvertisedKafkaAPIPorts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvertisedKafkaAPIPorts.
+func (in *AdvertisedKafkaAPIPorts) DeepCopy() *AdvertisedKafkaAPIPorts {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvertisedKafkaAPIPorts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapTopic) DeepCopyInto(out *BootstrapTopic) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapTopic.
+func (in *BootstrapTopic) DeepCopy() *BootstrapTopic {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapTopic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientGroupQuota) DeepCopyInto(out *ClientGroupQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientGroupQuota.
+func (in *ClientGroupQuota) DeepCopy() *ClientGroupQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientGroupQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientQuotas) DeepCopyInto(out *ClientQuotas) {
+	*out = *in
+	if in.DefaultByteRate != nil {
+		in, out := &in.DefaultByteRate, &out.DefaultByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]ClientGroupQuota, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientQuotas.
+func (in *ClientQuotas) DeepCopy() *ClientQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStorageConfig) DeepCopyInto(out *CloudStorageConfig) {
 	*out = *in
 	out.SecretKeyRef = in.SecretKeyRef
+	if in.ProjectedToken != nil {
+		in, out := &in.ProjectedToken, &out.ProjectedToken
+		*out = new(CloudStorageProjectedToken)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CacheSize != nil {
+		in, out := &in.CacheSize, &out.CacheSize
+		*out = new(resource.Quantity)
+		**out = (*in).DeepCopy()
+	}
+	if in.EgressNetworkPolicy != nil {
+		in, out := &in.EgressNetworkPolicy, &out.EgressNetworkPolicy
+		*out = new(CloudStorageEgressPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageConfig.
@@ -50,6 +157,46 @@ func (in *CloudStorageConfig) DeepCopy() *CloudStorageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStorageEgressPolicy) DeepCopyInto(out *CloudStorageEgressPolicy) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageEgressPolicy.
+func (in *CloudStorageEgressPolicy) DeepCopy() *CloudStorageEgressPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStorageEgressPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStorageProjectedToken) DeepCopyInto(out *CloudStorageProjectedToken) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageProjectedToken.
+func (in *CloudStorageProjectedToken) DeepCopy() *CloudStorageProjectedToken {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStorageProjectedToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Cluster) DeepCopyInto(out *Cluster) {
 	*out = *in
@@ -77,6 +224,22 @@ func (in *Cluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterHealthSummary) DeepCopyInto(out *ClusterHealthSummary) {
+	*out = *in
+	in.LastPollTime.DeepCopyInto(&out.LastPollTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHealthSummary.
+func (in *ClusterHealthSummary) DeepCopy() *ClusterHealthSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterHealthSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterList) DeepCopyInto(out *ClusterList) {
 	*out = *in
@@ -121,7 +284,7 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	in.Configuration.DeepCopyInto(&out.Configuration)
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -133,12 +296,116 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = val
 		}
 	}
-	out.ExternalConnectivity = in.ExternalConnectivity
+	if in.ZoneStatefulSets != nil {
+		in, out := &in.ZoneStatefulSets, &out.ZoneStatefulSets
+		*out = new(ZoneStatefulSetsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	out.SessionAffinity = in.SessionAffinity
+	in.ExternalConnectivity.DeepCopyInto(&out.ExternalConnectivity)
 	in.Storage.DeepCopyInto(&out.Storage)
-	out.CloudStorage = in.CloudStorage
+	in.CloudStorage.DeepCopyInto(&out.CloudStorage)
 	if in.Superusers != nil {
 		in, out := &in.Superusers, &out.Superusers
 		*out = make([]Superuser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(PasswordRotationConfig)
+		**out = **in
+	}
+	if in.SASLMechanisms != nil {
+		in, out := &in.SASLMechanisms, &out.SASLMechanisms
+		*out = make([]SASLMechanism, len(*in))
+		copy(*out, *in)
+	}
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsGroup != nil {
+		in, out := &in.RunAsGroup, &out.RunAsGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetadataBackup != nil {
+		in, out := &in.MetadataBackup, &out.MetadataBackup
+		*out = new(MetadataBackupConfig)
+		**out = **in
+	}
+	if in.SeccompProfile != nil {
+		in, out := &in.SeccompProfile, &out.SeccompProfile
+		*out = new(corev1.SeccompProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxClockSkewSeconds != nil {
+		in, out := &in.MaxClockSkewSeconds, &out.MaxClockSkewSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartupDelaySeconds != nil {
+		in, out := &in.StartupDelaySeconds, &out.StartupDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OpenFilesLimit != nil {
+		in, out := &in.OpenFilesLimit, &out.OpenFilesLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BootstrapTopics != nil {
+		in, out := &in.BootstrapTopics, &out.BootstrapTopics
+		*out = make([]BootstrapTopic, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(LivenessProbeConfig)
+		**out = **in
+	}
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateConfig)
+		**out = **in
+	}
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(ServiceMonitorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ACLs != nil {
+		in, out := &in.ACLs, &out.ACLs
+		*out = make([]ACL, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -157,6 +424,70 @@ func (in *ClusterSpec) DeepCopy() *ClusterSpec {
 func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	*out = *in
 	in.Nodes.DeepCopyInto(&out.Nodes)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradePlan != nil {
+		in, out := &in.UpgradePlan, &out.UpgradePlan
+		*out = new(UpgradePlanStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConsumerLag != nil {
+		in, out := &in.ConsumerLag, &out.ConsumerLag
+		*out = new(ConsumerLagStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DecommissioningNode != nil {
+		in, out := &in.DecommissioningNode, &out.DecommissioningNode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DecommissionProgress != nil {
+		in, out := &in.DecommissionProgress, &out.DecommissionProgress
+		*out = new(DecommissionProgress)
+		**out = **in
+	}
+	if in.BootstrappedTopics != nil {
+		in, out := &in.BootstrappedTopics, &out.BootstrappedTopics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProvisionedSuperusers != nil {
+		in, out := &in.ProvisionedSuperusers, &out.ProvisionedSuperusers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PasswordRotations != nil {
+		in, out := &in.PasswordRotations, &out.PasswordRotations
+		*out = make([]PasswordRotationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProvisionedACLs != nil {
+		in, out := &in.ProvisionedACLs, &out.ProvisionedACLs
+		*out = make([]ACL, len(*in))
+		copy(*out, *in)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(ClusterHealthSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = make([]ListenerTLSStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.OffsetReset != nil {
+		in, out := &in.OffsetReset, &out.OffsetReset
+		*out = new(OffsetResetStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
@@ -169,9 +500,67 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerLagStatus) DeepCopyInto(out *ConsumerLagStatus) {
+	*out = *in
+	in.LastPollTime.DeepCopyInto(&out.LastPollTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerLagStatus.
+func (in *ConsumerLagStatus) DeepCopy() *ConsumerLagStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerLagStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecommissionProgress) DeepCopyInto(out *DecommissionProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecommissionProgress.
+func (in *DecommissionProgress) DeepCopy() *DecommissionProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(DecommissionProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAdminConfig) DeepCopyInto(out *ExternalAdminConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAdminConfig.
+func (in *ExternalAdminConfig) DeepCopy() *ExternalAdminConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAdminConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalConnectivityConfig) DeepCopyInto(out *ExternalConnectivityConfig) {
 	*out = *in
+	if in.DNSPropagationTimeoutSeconds != nil {
+		in, out := &in.DNSPropagationTimeoutSeconds, &out.DNSPropagationTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LoadBalancerTags != nil {
+		in, out := &in.LoadBalancerTags, &out.LoadBalancerTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalConnectivityConfig.
@@ -189,14 +578,19 @@ func (in *KafkaAPITLS) DeepCopyInto(out *KafkaAPITLS) {
 	*out = *in
 	if in.IssuerRef != nil {
 		in, out := &in.IssuerRef, &out.IssuerRef
-		*out = new(metav1.ObjectReference)
+		*out = new(v1.ObjectReference)
 		**out = **in
 	}
 	if in.NodeSecretRef != nil {
 		in, out := &in.NodeSecretRef, &out.NodeSecretRef
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
+	if in.CASecretNamespaces != nil {
+		in, out := &in.CASecretNamespaces, &out.CASecretNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaAPITLS.
@@ -209,6 +603,81 @@ func (in *KafkaAPITLS) DeepCopy() *KafkaAPITLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerSpec) DeepCopyInto(out *ListenerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerSpec.
+func (in *ListenerSpec) DeepCopy() *ListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerTLSStatus) DeepCopyInto(out *ListenerTLSStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerTLSStatus.
+func (in *ListenerTLSStatus) DeepCopy() *ListenerTLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerTLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LivenessProbeConfig) DeepCopyInto(out *LivenessProbeConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LivenessProbeConfig.
+func (in *LivenessProbeConfig) DeepCopy() *LivenessProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LivenessProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataBackupConfig) DeepCopyInto(out *MetadataBackupConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataBackupConfig.
+func (in *MetadataBackupConfig) DeepCopy() *MetadataBackupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataBackupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsTLS) DeepCopyInto(out *MetricsTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsTLS.
+func (in *MetricsTLS) DeepCopy() *MetricsTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodesList) DeepCopyInto(out *NodesList) {
 	*out = *in
@@ -239,6 +708,93 @@ func (in *NodesList) DeepCopy() *NodesList {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordRotationConfig) DeepCopyInto(out *PasswordRotationConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordRotationConfig.
+func (in *PasswordRotationConfig) DeepCopy() *PasswordRotationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordRotationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OffsetResetStatus) DeepCopyInto(out *OffsetResetStatus) {
+	*out = *in
+	if in.Offsets != nil {
+		in, out := &in.Offsets, &out.Offsets
+		*out = make([]PartitionOffset, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OffsetResetStatus.
+func (in *OffsetResetStatus) DeepCopy() *OffsetResetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OffsetResetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionOffset) DeepCopyInto(out *PartitionOffset) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionOffset.
+func (in *PartitionOffset) DeepCopy() *PartitionOffset {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionOffset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordRotationStatus) DeepCopyInto(out *PasswordRotationStatus) {
+	*out = *in
+	in.LastRotationTime.DeepCopyInto(&out.LastRotationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordRotationStatus.
+func (in *PasswordRotationStatus) DeepCopy() *PasswordRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetConfig) DeepCopyInto(out *PodDisruptionBudgetConfig) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetConfig.
+func (in *PodDisruptionBudgetConfig) DeepCopy() *PodDisruptionBudgetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedpandaConfig) DeepCopyInto(out *RedpandaConfig) {
 	*out = *in
@@ -246,6 +802,28 @@ func (in *RedpandaConfig) DeepCopyInto(out *RedpandaConfig) {
 	out.KafkaAPI = in.KafkaAPI
 	out.AdminAPI = in.AdminAPI
 	in.TLS.DeepCopyInto(&out.TLS)
+	if in.ClientQuotas != nil {
+		in, out := &in.ClientQuotas, &out.ClientQuotas
+		*out = new(ClientQuotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalConfiguration != nil {
+		in, out := &in.AdditionalConfiguration, &out.AdditionalConfiguration
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AdvertisedKafkaAPIPorts != nil {
+		in, out := &in.AdvertisedKafkaAPIPorts, &out.AdvertisedKafkaAPIPorts
+		*out = new(AdvertisedKafkaAPIPorts)
+		**out = **in
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]ListenerSpec, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedpandaConfig.
@@ -258,6 +836,43 @@ func (in *RedpandaConfig) DeepCopy() *RedpandaConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateConfig) DeepCopyInto(out *RollingUpdateConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateConfig.
+func (in *RollingUpdateConfig) DeepCopy() *RollingUpdateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceMonitorConfig) DeepCopyInto(out *ServiceMonitorConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMonitorConfig.
+func (in *ServiceMonitorConfig) DeepCopy() *ServiceMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SocketAddress) DeepCopyInto(out *SocketAddress) {
 	*out = *in
@@ -277,6 +892,16 @@ func (in *SocketAddress) DeepCopy() *SocketAddress {
 func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
 	*out = *in
 	out.Capacity = in.Capacity.DeepCopy()
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.LocalRetention != nil {
+		in, out := &in.LocalRetention, &out.LocalRetention
+		*out = new(resource.Quantity)
+		**out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
@@ -292,6 +917,11 @@ func (in *StorageSpec) DeepCopy() *StorageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Superuser) DeepCopyInto(out *Superuser) {
 	*out = *in
+	if in.PasswordSecretKeyRef != nil {
+		in, out := &in.PasswordSecretKeyRef, &out.PasswordSecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Superuser.
@@ -308,7 +938,8 @@ func (in *Superuser) DeepCopy() *Superuser {
 func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
 	in.KafkaAPI.DeepCopyInto(&out.KafkaAPI)
-	out.AdminAPI = in.AdminAPI
+	in.AdminAPI.DeepCopyInto(&out.AdminAPI)
+	out.Metrics = in.Metrics
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
@@ -320,3 +951,58 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePlanStatus) DeepCopyInto(out *UpgradePlanStatus) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePlanStatus.
+func (in *UpgradePlanStatus) DeepCopy() *UpgradePlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneStatefulSetsConfig) DeepCopyInto(out *ZoneStatefulSetsConfig) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneStatefulSetsConfig.
+func (in *ZoneStatefulSetsConfig) DeepCopy() *ZoneStatefulSetsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneStatefulSetsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *portRange) DeepCopyInto(out *portRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new portRange.
+func (in *portRange) DeepCopy() *portRange {
+	if in == nil {
+		return nil
+	}
+	out := new(portRange)
+	in.DeepCopyInto(out)
+	return out
+}
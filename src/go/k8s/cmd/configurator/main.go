@@ -22,6 +22,8 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/afero"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/networking"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
@@ -40,6 +42,9 @@ const (
 	externalConnectivityEnvVar          = "EXTERNAL_CONNECTIVITY"
 	externalConnectivitySubDomainEnvVar = "EXTERNAL_CONNECTIVITY_SUBDOMAIN"
 	hostPortEnvVar                      = "HOST_PORT"
+	externalConnectivityTypeEnvVar      = "EXTERNAL_CONNECTIVITY_TYPE"
+	podNamespaceEnvVar                  = "POD_NAMESPACE"
+	nodeIDOffsetEnvVar                  = "NODE_ID_OFFSET"
 )
 
 type brokerID int
@@ -54,6 +59,17 @@ type configuratorConfig struct {
 	externalConnectivity bool
 	redpandaRPCPort      int
 	hostPort             int
+	connectivityType     redpandav1alpha1.ExternalConnectivityType
+	podNamespace         string
+	nodeIDOffset         int
+}
+
+// perBrokerService returns true if the broker is exposed through its own
+// Service
+func (c *configuratorConfig) perBrokerService() bool {
+	return c.externalConnectivity &&
+		(c.connectivityType == redpandav1alpha1.ExternalConnectivityNodePort ||
+			c.connectivityType == redpandav1alpha1.ExternalConnectivityLoadBalancer)
 }
 
 func (c *configuratorConfig) String() string {
@@ -66,7 +82,10 @@ func (c *configuratorConfig) String() string {
 		"externalConnectivity: %t\n"+
 		"externalConnectivitySubdomain: %s\n"+
 		"redpandaRPCPort: %d\n"+
-		"hostPort: %d\n",
+		"hostPort: %d\n"+
+		"externalConnectivityType: %s\n"+
+		"podNamespace: %s\n"+
+		"nodeIDOffset: %d\n",
 		c.hostName,
 		c.svcFQDN,
 		c.configSourceDir,
@@ -75,7 +94,10 @@ func (c *configuratorConfig) String() string {
 		c.externalConnectivity,
 		c.subdomain,
 		c.redpandaRPCPort,
-		c.hostPort)
+		c.hostPort,
+		c.connectivityType,
+		c.podNamespace,
+		c.nodeIDOffset)
 }
 
 var errorMissingEnvironmentVariable = errors.New("missing environment variable")
@@ -130,16 +152,19 @@ func main() {
 
 	log.Printf("Host index calculated %d", hostIndex)
 
-	err = registerAdvertisedKafkaAPI(&c, cfg, hostIndex, kafkaAPIPort)
+	err = registerAdvertisedKafkaAPI(&c, cfg, hostIndex, kafkaAPIPort, inClusterClientset)
 	if err != nil {
 		log.Fatalf("%s", fmt.Errorf("unable to register advertised kafka API: %w", err))
 	}
 
-	cfg.Redpanda.Id = int(hostIndex)
+	// the brokers of zone StatefulSets are numbered from the offset of
+	// their zone
+	nodeID := brokerID(c.nodeIDOffset) + hostIndex
+	cfg.Redpanda.Id = int(nodeID)
 
 	// First Redpanda node need to have cleared seed servers in order
 	// to form raft group 0
-	if hostIndex == 0 {
+	if nodeID == 0 {
 		cfg.Redpanda.SeedServers = []config.SeedServer{}
 	}
 
@@ -161,15 +186,58 @@ var errInternalPortMissing = errors.New("port configration is missing internal p
 
 func getInternalKafkaAPIPort(cfg *config.Config) (int, error) {
 	for _, l := range cfg.Redpanda.KafkaApi {
-		if l.Name == "Internal" {
+		if l.Name == redpandav1alpha1.InternalListener {
 			return l.Port, nil
 		}
 	}
 	return 0, fmt.Errorf("%w %v", errInternalPortMissing, cfg.Redpanda.KafkaApi)
 }
 
+func inClusterClientset() (kubernetes.Interface, error) {
+	k8sconfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create in cluster config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(k8sconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create clientset: %w", err)
+	}
+	return clientset, nil
+}
+
+// registerAdvertisedKafkaAPI advertises the address of the broker for each
+// Kafka API listener. The ports rendered by the operator take precedence over
+// the listening ports, e.g. behind port mapping.
 func registerAdvertisedKafkaAPI(
-	c *configuratorConfig, cfg *config.Config, index brokerID, kafkaAPIPort int,
+	c *configuratorConfig,
+	cfg *config.Config,
+	index brokerID,
+	kafkaAPIPort int,
+	newClientset func() (kubernetes.Interface, error),
+) error {
+	rendered := cfg.Redpanda.AdvertisedKafkaApi
+	if err := registerAdvertisedAddresses(c, cfg, index, kafkaAPIPort, newClientset); err != nil {
+		return err
+	}
+
+	for i := range cfg.Redpanda.AdvertisedKafkaApi {
+		advertised := &cfg.Redpanda.AdvertisedKafkaApi[i]
+		for _, r := range rendered {
+			if r.Name == advertised.Name && r.Port != 0 {
+				advertised.Port = r.Port
+			}
+		}
+	}
+	return nil
+}
+
+func registerAdvertisedAddresses(
+	c *configuratorConfig,
+	cfg *config.Config,
+	index brokerID,
+	kafkaAPIPort int,
+	newClientset func() (kubernetes.Interface, error),
 ) error {
 	cfg.Redpanda.AdvertisedKafkaApi = []config.NamedSocketAddress{
 		{
@@ -177,9 +245,23 @@ func registerAdvertisedKafkaAPI(
 				Address: c.hostName + "." + c.svcFQDN,
 				Port:    kafkaAPIPort,
 			},
-			Name: "Internal",
+			Name: redpandav1alpha1.InternalListener,
 		},
 	}
+	// the additional listeners declared in the Cluster are reachable on the
+	// same address as the Internal listener
+	for _, l := range cfg.Redpanda.KafkaApi {
+		if l.Name == redpandav1alpha1.InternalListener || l.Name == redpandav1alpha1.ExternalListener {
+			continue
+		}
+		cfg.Redpanda.AdvertisedKafkaApi = append(cfg.Redpanda.AdvertisedKafkaApi, config.NamedSocketAddress{
+			SocketAddress: config.SocketAddress{
+				Address: c.hostName + "." + c.svcFQDN,
+				Port:    l.Port,
+			},
+			Name: l.Name,
+		})
+	}
 
 	if !c.externalConnectivity {
 		return nil
@@ -191,19 +273,18 @@ func registerAdvertisedKafkaAPI(
 				Address: fmt.Sprintf("%d.%s", index, c.subdomain),
 				Port:    c.hostPort,
 			},
-			Name: "External",
+			Name: redpandav1alpha1.ExternalListener,
 		})
 		return nil
 	}
 
-	k8sconfig, err := rest.InClusterConfig()
+	clientset, err := newClientset()
 	if err != nil {
-		return fmt.Errorf("unable to create in cluster config: %w", err)
+		return err
 	}
 
-	clientset, err := kubernetes.NewForConfig(k8sconfig)
-	if err != nil {
-		return fmt.Errorf("unable to create clientset: %w", err)
+	if c.perBrokerService() {
+		return registerBrokerServiceAddress(c, cfg, clientset)
 	}
 
 	node, err := clientset.CoreV1().Nodes().Get(context.Background(), c.nodeName, metav1.GetOptions{})
@@ -216,7 +297,42 @@ func registerAdvertisedKafkaAPI(
 			Address: getExternalIP(node),
 			Port:    c.hostPort,
 		},
-		Name: "External",
+		Name: redpandav1alpha1.ExternalListener,
+	})
+	return nil
+}
+
+// registerBrokerServiceAddress advertises the address of the Service
+// exposing the broker. The Service is created by the operator before the
+// broker, the address is missing only until the node port or the load
+// balancer is assigned and the Pod is restarted until then.
+func registerBrokerServiceAddress(
+	c *configuratorConfig, cfg *config.Config, clientset kubernetes.Interface,
+) error {
+	svc, err := clientset.CoreV1().Services(c.podNamespace).Get(context.Background(), networking.BrokerServiceName(c.hostName), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to retrieve broker service: %w", err)
+	}
+
+	var nodeIP string
+	if svc.Spec.Type == corev1.ServiceTypeNodePort {
+		node, err := clientset.CoreV1().Nodes().Get(context.Background(), c.nodeName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to retrieve node: %w", err)
+		}
+		nodeIP = getExternalIP(node)
+	}
+
+	host, port, err := networking.BrokerServiceAddress(svc, networking.KafkaPortName, nodeIP)
+	if err != nil {
+		return err
+	}
+	cfg.Redpanda.AdvertisedKafkaApi = append(cfg.Redpanda.AdvertisedKafkaApi, config.NamedSocketAddress{
+		SocketAddress: config.SocketAddress{
+			Address: host,
+			Port:    int(port),
+		},
+		Name: redpandav1alpha1.ExternalListener,
 	})
 	return nil
 }
@@ -238,6 +354,7 @@ func checkEnvVars() (configuratorConfig, error) {
 	var extCon string
 	var rpcPort string
 	var hostPort string
+	var connectivityType string
 
 	c := configuratorConfig{}
 
@@ -281,6 +398,14 @@ func checkEnvVars() (configuratorConfig, error) {
 			value: &hostPort,
 			name:  hostPortEnvVar,
 		},
+		{
+			value: &connectivityType,
+			name:  externalConnectivityTypeEnvVar,
+		},
+		{
+			value: &c.podNamespace,
+			name:  podNamespaceEnvVar,
+		},
 	}
 	for _, envVar := range envVarList {
 		v, exist := os.LookupEnv(envVar.name)
@@ -306,8 +431,19 @@ func checkEnvVars() (configuratorConfig, error) {
 		result = multierror.Append(result, fmt.Errorf("unable to convert rpc port from string to int: %w", err))
 	}
 
+	c.connectivityType = redpandav1alpha1.ExternalConnectivityType(connectivityType)
+
+	// the offset is set only for the brokers of zone StatefulSets
+	if offset, exist := os.LookupEnv(nodeIDOffsetEnvVar); exist {
+		c.nodeIDOffset, err = strconv.Atoi(offset)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("unable to convert node ID offset from string to int: %w", err))
+		}
+	}
+
+	// the host port is not used by the brokers exposed by their own Service
 	c.hostPort, err = strconv.Atoi(hostPort)
-	if err != nil && c.externalConnectivity {
+	if err != nil && c.externalConnectivity && !c.perBrokerService() {
 		result = multierror.Append(result, fmt.Errorf("unable to convert host port from string to int: %w", err))
 	}
 
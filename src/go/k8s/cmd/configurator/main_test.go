@@ -0,0 +1,187 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// nolint:testpackage // the configurator is a main package that can not be imported
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/networking"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRegisterAdvertisedKafkaAPI(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       configuratorConfig
+		objects []runtime.Object
+		// advertised ports rendered by the operator
+		rendered []config.NamedSocketAddress
+		// listeners declared in addition to Internal and External
+		additional []config.NamedSocketAddress
+		golden     string
+	}{
+		{
+			name: "internal listener only",
+			c: configuratorConfig{
+				hostName: "cluster-1",
+				svcFQDN:  "cluster.default.svc.cluster.local.",
+			},
+			golden: "internal_listener.golden",
+		},
+		{
+			name: "internal and external listeners",
+			c: configuratorConfig{
+				hostName:             "cluster-1",
+				svcFQDN:              "cluster.default.svc.cluster.local.",
+				externalConnectivity: true,
+				subdomain:            "redpanda.example.com",
+				hostPort:             30001,
+			},
+			golden: "internal_external_listeners.golden",
+		},
+		{
+			name: "node port service of the broker",
+			c: configuratorConfig{
+				hostName:             "cluster-1",
+				svcFQDN:              "cluster.default.svc.cluster.local.",
+				nodeName:             "node-1",
+				podNamespace:         "default",
+				externalConnectivity: true,
+				connectivityType:     redpandav1alpha1.ExternalConnectivityNodePort,
+			},
+			objects: []runtime.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster-1-external", Namespace: "default"},
+					Spec: corev1.ServiceSpec{
+						Type:  corev1.ServiceTypeNodePort,
+						Ports: []corev1.ServicePort{{Name: networking.KafkaPortName, Port: 9093, NodePort: 31001}},
+					},
+				},
+				&corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+					Status: corev1.NodeStatus{
+						Addresses: []corev1.NodeAddress{{Type: corev1.NodeExternalIP, Address: "203.0.113.10"}},
+					},
+				},
+			},
+			golden: "node_port_service.golden",
+		},
+		{
+			name: "load balancer service of the broker",
+			c: configuratorConfig{
+				hostName:             "cluster-1",
+				svcFQDN:              "cluster.default.svc.cluster.local.",
+				podNamespace:         "default",
+				externalConnectivity: true,
+				connectivityType:     redpandav1alpha1.ExternalConnectivityLoadBalancer,
+			},
+			objects: []runtime.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster-1-external", Namespace: "default"},
+					Spec: corev1.ServiceSpec{
+						Type:  corev1.ServiceTypeLoadBalancer,
+						Ports: []corev1.ServicePort{{Name: networking.KafkaPortName, Port: 9093, NodePort: 31001}},
+					},
+					Status: corev1.ServiceStatus{
+						LoadBalancer: corev1.LoadBalancerStatus{
+							Ingress: []corev1.LoadBalancerIngress{{Hostname: "broker-1.elb.example.com"}},
+						},
+					},
+				},
+			},
+			golden: "load_balancer_service.golden",
+		},
+		{
+			name: "advertised ports differ from listening ports",
+			c: configuratorConfig{
+				hostName:             "cluster-1",
+				svcFQDN:              "cluster.default.svc.cluster.local.",
+				externalConnectivity: true,
+				subdomain:            "redpanda.example.com",
+				hostPort:             30001,
+			},
+			rendered: []config.NamedSocketAddress{
+				{SocketAddress: config.SocketAddress{Port: 19092}, Name: redpandav1alpha1.InternalListener},
+				{SocketAddress: config.SocketAddress{Port: 443}, Name: redpandav1alpha1.ExternalListener},
+			},
+			golden: "advertised_ports.golden",
+		},
+		{
+			name: "additional listeners",
+			c: configuratorConfig{
+				hostName:             "cluster-1",
+				svcFQDN:              "cluster.default.svc.cluster.local.",
+				externalConnectivity: true,
+				subdomain:            "redpanda.example.com",
+				hostPort:             30001,
+			},
+			additional: []config.NamedSocketAddress{
+				{SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9094}, Name: "Replication"},
+				{SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9095}, Name: "Tooling"},
+			},
+			golden: "additional_listeners.golden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Redpanda.KafkaApi = []config.NamedSocketAddress{
+				{
+					SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9092},
+					Name:          redpandav1alpha1.InternalListener,
+				},
+			}
+			if tt.c.externalConnectivity {
+				cfg.Redpanda.KafkaApi = append(cfg.Redpanda.KafkaApi, config.NamedSocketAddress{
+					SocketAddress: config.SocketAddress{Address: "0.0.0.0", Port: 9093},
+					Name:          redpandav1alpha1.ExternalListener,
+				})
+			}
+			cfg.Redpanda.KafkaApi = append(cfg.Redpanda.KafkaApi, tt.additional...)
+			cfg.Redpanda.AdvertisedKafkaApi = tt.rendered
+
+			clientset := func() (kubernetes.Interface, error) {
+				return fake.NewSimpleClientset(tt.objects...), nil
+			}
+			err := registerAdvertisedKafkaAPI(&tt.c, cfg, 1, 9092, clientset)
+			require.NoError(t, err)
+
+			listeners := struct {
+				KafkaAPI           []config.NamedSocketAddress `yaml:"kafka_api"`
+				AdvertisedKafkaAPI []config.NamedSocketAddress `yaml:"advertised_kafka_api"`
+			}{cfg.Redpanda.KafkaApi, cfg.Redpanda.AdvertisedKafkaApi}
+			actual, err := yaml.Marshal(&listeners)
+			require.NoError(t, err)
+
+			goldenPath := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, ioutil.WriteFile(goldenPath, actual, 0600))
+			}
+			expected, err := ioutil.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
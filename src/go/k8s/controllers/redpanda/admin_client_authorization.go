@@ -0,0 +1,43 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportAdminClientAuthorization warns with AdminClientUnauthorized
+// condition when the operator would lock itself out of the Admin API,
+// because the CN of its client certificate is not authorized
+func (r *ClusterReconciler) reportAdminClientAuthorization(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, cn string,
+) error {
+	if cn == "" {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.AdminClientUnauthorizedConditionType, "Admin API doesn't require client auth")
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.AdminClientUnauthorizedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ClientAuthorized",
+		Message: fmt.Sprintf("Admin API client %s is authorized", cn),
+	}
+	if !redpandaCluster.IsPrincipalAuthorized(cn) {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ClientUnauthorized"
+		condition.Message = fmt.Sprintf("Admin API client %s is neither a superuser nor allowed by an ACL, add it to the superusers", cn)
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportArchitecture warns with ArchitectureMismatch condition when the
+// brokers can be scheduled on nodes of an architecture the image isn't built
+// for
+func (r *ClusterReconciler) reportArchitecture(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.Spec.VerifyArchitecture {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.ArchitectureMismatchConditionType, "Architecture of the nodes is not validated")
+	}
+
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return err
+	}
+	tolerations, nodeSelector := resources.BrokerPlacement(redpandaCluster)
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.ArchitectureMismatchConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ArchitectureCompatible",
+		Message: "The brokers are scheduled only on nodes the image is built for",
+	}
+	if mismatch := resources.ArchitectureMismatch(nodes.Items, tolerations, nodeSelector, redpandaCluster.ImageArchitecture()); mismatch != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ArchitectureMismatch"
+		condition.Message = mismatch
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportClockSkew compares the clocks of the brokers when
+// MaxClockSkewSeconds is set and reports the ClockSkew condition
+func (r *ClusterReconciler) reportClockSkew(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	maxSkew := redpandaCluster.Spec.MaxClockSkewSeconds
+	if r.AdminAPIClientFactory == nil || maxSkew == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+
+	clients := make(map[string]admin.AdminAPIClient, len(redpandaCluster.Status.Nodes.Internal))
+	for _, host := range redpandaCluster.Status.Nodes.Internal {
+		c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, host)
+		if err != nil {
+			return err
+		}
+		clients[host] = c
+	}
+
+	offsets, err := admin.QueryClockOffsets(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.ClockSkewConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ClocksSynchronized",
+		Message: "Broker clocks are synchronized",
+	}
+	if offsets.Skew() > time.Duration(*maxSkew)*time.Second {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ClockSkewExceeded"
+		condition.Message = fmt.Sprintf("Broker clocks differ by %s, offsets from the operator clock: %s",
+			offsets.Skew().Round(time.Second), offsets)
+	}
+	return r.setCondition(ctx, redpandaCluster, condition)
+}
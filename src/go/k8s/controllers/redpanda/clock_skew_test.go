@@ -0,0 +1,151 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClockSkew(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxSkew       *int32
+		clocks        map[string]time.Duration
+		expectedSkew  bool
+		expectChecked bool
+	}{
+		{"synchronized clocks", pointer.Int32Ptr(10), map[string]time.Duration{"skew-0": 0, "skew-1": 0}, false, true},
+		{"skew within threshold", pointer.Int32Ptr(10), map[string]time.Duration{"skew-0": 0, "skew-1": 5 * time.Second}, false, true},
+		{"skew beyond threshold", pointer.Int32Ptr(10), map[string]time.Duration{"skew-0": -20 * time.Second, "skew-1": 20 * time.Second}, true, true},
+		{"check disabled", nil, map[string]time.Duration{"skew-0": 0, "skew-1": time.Hour}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(s))
+			require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "skew",
+					Namespace: "default",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Image:               "vectorized/redpanda",
+					Version:             "v21.4.12",
+					Replicas:            pointer.Int32Ptr(1),
+					MaxClockSkewSeconds: tt.maxSkew,
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+						KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+						AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+					},
+					Storage: redpandav1alpha1.StorageSpec{
+						Capacity:         resource.MustParse("10Gi"),
+						StorageClassName: "local",
+					},
+				},
+			}
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+				Provisioner: resources.LocalVolumeProvisioner,
+			}
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+				Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+				Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+			// each broker is queried on its own host with its own clock
+			r := &redpandacontrollers.ClusterReconciler{
+				Client:   c,
+				Log:      ctrl.Log.WithName("test"),
+				Scheme:   s,
+				Recorder: record.NewFakeRecorder(100),
+				AdminAPIClientFactory: func(_ context.Context, _ client.Reader, _ *redpandav1alpha1.Cluster, host string) (admin.AdminAPIClient, error) {
+					api := &fakeAdminAPI{versions: map[int]string{0: "v21.4.12 (rev 6c1b5f6)"}}
+					for pod, clock := range tt.clocks {
+						if strings.HasPrefix(host, pod+".") {
+							api.clock = clock
+						}
+					}
+					return api, nil
+				},
+			}
+			key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+			ctx := context.Background()
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			var sts appsv1.StatefulSet
+			require.NoError(t, c.Get(ctx, key, &sts))
+			sts.Status.ReadyReplicas = 1
+			require.NoError(t, c.Update(ctx, &sts))
+			for pod := range tt.clocks {
+				require.NoError(t, c.Create(ctx, &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      pod,
+						Namespace: cluster.Namespace,
+						Labels:    labels.ForCluster(cluster),
+					},
+				}))
+			}
+			// the first reconciliation observes the brokers, the second
+			// one compares their clocks
+			for i := 0; i < 2; i++ {
+				_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+				require.NoError(t, err)
+			}
+
+			var actual redpandav1alpha1.Cluster
+			require.NoError(t, c.Get(ctx, key, &actual))
+			require.Len(t, actual.Status.Nodes.Internal, len(tt.clocks))
+			condition := meta.FindStatusCondition(actual.Status.Conditions, redpandav1alpha1.ClockSkewConditionType)
+			if !tt.expectChecked {
+				assert.Nil(t, condition)
+				return
+			}
+			require.NotNil(t, condition)
+			if tt.expectedSkew {
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				assert.Equal(t, "ClockSkewExceeded", condition.Reason)
+				assert.Contains(t, condition.Message, "40s")
+				return
+			}
+			assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		})
+	}
+}
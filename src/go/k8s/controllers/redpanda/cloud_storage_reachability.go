@@ -0,0 +1,68 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/cloudstorage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reportCloudStorageReachability sends HEAD bucket request to the configured
+// object store and sets CloudStorageUnreachable condition accordingly
+func (r *ClusterReconciler) reportCloudStorageReachability(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	cloudStorage := redpandaCluster.Spec.CloudStorage
+	if r.CloudStorageChecker == nil || !redpandaCluster.CloudStorageStaticCredentials() || !cloudStorage.VerifyReachability {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.CloudStorageUnreachableConditionType, "Cloud storage reachability is not validated")
+	}
+
+	var secret corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      cloudStorage.SecretKeyRef.Name,
+		Namespace: cloudStorage.SecretKeyRef.Namespace,
+	}, &secret)
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.CloudStorageUnreachableConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "BucketReachable",
+		Message: fmt.Sprintf("Bucket %s is reachable", cloudStorage.Bucket),
+	}
+	err = r.CloudStorageChecker.HeadBucket(ctx, cloudstorage.Bucket{
+		Name:       cloudStorage.Bucket,
+		Region:     cloudStorage.Region,
+		AccessKey:  cloudStorage.AccessKey,
+		SecretKey:  string(secret.Data[cloudStorage.SecretKeyRef.Name]),
+		Endpoint:   cloudStorage.APIEndpoint,
+		Port:       cloudStorage.APIEndpointPort,
+		DisableTLS: cloudStorage.DisableTLS,
+	})
+	if err != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "HeadBucketFailed"
+		if errors.Is(err, cloudstorage.ErrAccessDenied) {
+			condition.Reason = "AccessDenied"
+		}
+		condition.Message = err.Error()
+	}
+	return r.setCondition(ctx, redpandaCluster, condition)
+}
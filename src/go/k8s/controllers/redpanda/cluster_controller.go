@@ -15,25 +15,63 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/cloudstorage"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/dns"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/metrics"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/networking"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+// consumerLagPollInterval is how often the consumer lag is polled when
+// reporting is enabled
+const consumerLagPollInterval = time.Minute
+
+// healthPollInterval is how often the brokers are polled for the health
+// summary when reporting is enabled
+const healthPollInterval = time.Minute
+
+// superuserBootstrapRetryInterval is how often the superusers are verified
+// until all of them exist
+const superuserBootstrapRetryInterval = 10 * time.Second
+
+// secretPollInterval is how often the missing credential Secrets are looked
+// up in addition to the Secret watch
+const secretPollInterval = 10 * time.Second
+
 var (
 	errNonexistentLastObservesState = errors.New("expecting to have statefulset LastObservedState set but it's nil")
 	errNodePortMissing              = errors.New("the node port is missing from the service")
+	errEmptySuperuserPassword       = errors.New("the password is missing or empty")
+	errSuperuserBootstrapFailed     = errors.New("superuser bootstrap failed")
+	errBrokersNotReported           = errors.New("no brokers are reported in the status")
 )
 
 // ClusterReconciler reconciles a Cluster object
@@ -41,20 +79,53 @@ type ClusterReconciler struct {
 	client.Client
 	Log             logr.Logger
 	configuratorTag string
+	pauseImage      string
+	clusterSelector k8slabels.Selector
+	certStagger     *certmanager.IssuanceStagger
+	restartLimiter  *resources.RestartLimiter
 	Scheme          *runtime.Scheme
+	Recorder        record.EventRecorder
+	// AdminAPIClientFactory creates clients for the Admin API of brokers.
+	// Checks relying on the Admin API are skipped when it is not set.
+	AdminAPIClientFactory admin.AdminAPIClientFactory
+	// Resolver is used to verify external connectivity subdomain delegation.
+	// The check is skipped when it is not set.
+	Resolver dns.Resolver
+	// CloudStorageChecker is used to verify that the cloud storage bucket is
+	// reachable. The check is skipped when it is not set.
+	CloudStorageChecker cloudstorage.Checker
+	// RESTMapper is used to detect optional CRDs, e.g. ServiceMonitor.
+	// ServiceMonitors are not created when it is not set.
+	RESTMapper meta.RESTMapper
 }
 
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=redpanda.vectorized.io,resources=clusters/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;
-//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
-//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;
-//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete;
+//+kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// Secrets of other namespaces are managed only as the CA certificate copies
+// of CASecretNamespaces, which are deleted by the finalizer of the cluster
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;delete
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cert-manager.io,resources=issuers;certificates;clusterissuers,verbs=create;get;list;watch;patch;delete;
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -67,6 +138,15 @@ type ClusterReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *ClusterReconciler) Reconcile(
 	ctx context.Context, req ctrl.Request,
+) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	metrics.ObserveReconcile(result, err, time.Since(start))
+	return result, err
+}
+
+func (r *ClusterReconciler) reconcile(
+	ctx context.Context, req ctrl.Request,
 ) (ctrl.Result, error) {
 	log := r.Log.WithValues("redpandacluster", req.NamespacedName)
 
@@ -80,6 +160,8 @@ func (r *ClusterReconciler) Reconcile(
 		// requeue (we'll need to wait for a new notification), and we can get them
 		// on deleted requests.
 		if apierrors.IsNotFound(err) {
+			r.restartLimiter.Release(req.NamespacedName)
+			r.certStagger.Release(req.NamespacedName)
 			if removeError := crb.RemoveSubject(ctx, req.NamespacedName); removeError != nil {
 				return ctrl.Result{}, fmt.Errorf("unable to remove subject in ClusterroleBinding: %w", removeError)
 			}
@@ -88,78 +170,484 @@ func (r *ClusterReconciler) Reconcile(
 		return ctrl.Result{}, fmt.Errorf("unable to retrieve Cluster resource: %w", err)
 	}
 
+	if !redpandaCluster.DeletionTimestamp.IsZero() {
+		if err := resources.FinalizeCASecretCopies(ctx, r.Client, &redpandaCluster, log); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to remove CA certificate copies: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// events of owned resources are not filtered by the selector
+	if !r.matchesClusterSelector(&redpandaCluster) {
+		log.Info("Cluster does not match label selector, skipping")
+		return ctrl.Result{}, nil
+	}
+
 	ports := []resources.NamedServicePort{
 		{Name: resources.AdminPortName, Port: redpandaCluster.Spec.Configuration.AdminAPI.Port},
 		{Name: resources.KafkaPortName, Port: redpandaCluster.Spec.Configuration.KafkaAPI.Port},
 	}
-	headlessSvc := resources.NewHeadlessService(r.Client, &redpandaCluster, r.Scheme, ports, log)
+	// the additional listeners are reachable from within the Kubernetes
+	// cluster only
+	headlessPorts := append(append([]resources.NamedServicePort{}, ports...),
+		resources.AdditionalListenerPorts(&redpandaCluster)...)
+	headlessSvc := resources.NewHeadlessService(r.Client, &redpandaCluster, r.Scheme, headlessPorts, log)
 	nodeportSvc := resources.NewNodePortService(r.Client, &redpandaCluster, r.Scheme, ports, log)
+	adminNodeportSvc := resources.NewAdminNodePortService(r.Client, &redpandaCluster, r.Scheme, log)
 
-	pki := certmanager.NewPki(r.Client, &redpandaCluster, headlessSvc.HeadlessServiceFQDN(), r.Scheme, log)
+	pki := certmanager.NewPki(r.Client, &redpandaCluster, headlessSvc.HeadlessServiceFQDN(), r.Scheme, log).
+		WithIssuanceStagger(r.certStagger).
+		WithRecorder(r.Recorder)
 	sa := resources.NewServiceAccount(r.Client, &redpandaCluster, r.Scheme, log)
-	sts := resources.NewStatefulSet(
-		r.Client,
-		&redpandaCluster,
-		r.Scheme,
-		headlessSvc.HeadlessServiceFQDN(),
-		headlessSvc.Key().Name,
-		nodeportSvc.Key(),
-		pki.NodeCert(),
-		pki.OperatorClientCert(),
-		pki.AdminCert(),
-		pki.AdminAPINodeCert(),
-		sa.Key().Name,
-		r.configuratorTag,
-		log)
+	// the brokers run in a StatefulSet per zone with ZoneStatefulSets
+	brokerSets := resources.BrokerStatefulSets(&redpandaCluster)
+	// the broker with ordinal 0 of the first StatefulSet is never removed
+	// by scaling down
+	firstBroker := fmt.Sprintf("%s-0.%s", brokerSets[0].Name, headlessSvc.HeadlessServiceFQDN())
+	statefulSets := make([]*resources.StatefulSetResource, 0, len(brokerSets))
+	for _, set := range brokerSets {
+		sts := resources.NewStatefulSet(
+			r.Client,
+			&redpandaCluster,
+			r.Scheme,
+			headlessSvc.HeadlessServiceFQDN(),
+			headlessSvc.Key().Name,
+			nodeportSvc.Key(),
+			pki.NodeCert(),
+			pki.OperatorClientCert(),
+			pki.AdminCert(),
+			pki.AdminAPINodeCert(),
+			sa.Key().Name,
+			r.configuratorTag,
+			log).WithExternalCert(pki.ExternalNodeCert()).
+			WithAdminNodePort(adminNodeportSvc.Key()).
+			WithRestartLimiter(r.restartLimiter).
+			WithPauseImage(r.pauseImage).
+			WithBrokerStatefulSet(set)
+		if r.AdminAPIClientFactory != nil {
+			sts.WithBrokerDecommissioner(func(ctx context.Context) (resources.BrokerDecommissioner, error) {
+				return r.AdminAPIClientFactory(ctx, r.Client, &redpandaCluster, firstBroker)
+			})
+		}
+		statefulSets = append(statefulSets, sts)
+	}
+
+	// Deletion is not affected by the pause, owned resources are garbage
+	// collected and the ClusterRoleBinding subject is removed above once
+	// the Cluster is gone
+	if redpandaCluster.ReconciliationPaused() {
+		log.Info("Reconciliation is paused", "annotation", redpandav1alpha1.ManagedAnnotation)
+		r.reportPaused(ctx, &redpandaCluster, true, log)
+		// the plan of a pending upgrade can be reviewed before the
+		// reconciliation is resumed
+		if err := r.reportUpgradePlan(ctx, &redpandaCluster, statefulSets); err != nil {
+			log.Info("Unable to plan the upgrade", "error", err.Error())
+		}
+		return ctrl.Result{}, nil
+	}
+	r.reportPaused(ctx, &redpandaCluster, false, log)
+
 	toApply := []resources.Reconciler{
 		headlessSvc,
 		nodeportSvc,
-		resources.NewConfigMap(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), log),
+		adminNodeportSvc,
+		resources.NewBrokerServices(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewConfigMap(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(), r.Recorder, log),
 		pki,
+		resources.NewMetricsService(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewServiceMonitor(r.Client, &redpandaCluster, r.Scheme, r.RESTMapper,
+			headlessSvc.HeadlessServiceFQDN(), pki.AdminAPINodeCert(), pki.MetricsClientCert(), log),
+		resources.NewAccessModeValidator(r.Client, &redpandaCluster, r.Recorder, log),
+		resources.NewVolumeExpander(r.Client, &redpandaCluster, r.Recorder, log),
 		sa,
 		resources.NewClusterRole(r.Client, &redpandaCluster, r.Scheme, log),
 		crb,
-		sts,
+		// the budget is tightened before the rolling upgrade continues
+		resources.NewPodDisruptionBudget(r.Client, &redpandaCluster, r.Scheme, log),
+	}
+	for _, sts := range statefulSets {
+		toApply = append(toApply, sts)
 	}
+	toApply = append(toApply,
+		resources.NewCloudStorageNetworkPolicy(r.Client, &redpandaCluster, r.Scheme, log),
+		resources.NewMetadataBackup(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(),
+			pki.NodeCert(), pki.OperatorClientCert(), log),
+	)
 
+	r.reportNewGeneration(ctx, &redpandaCluster, log)
+
+	// diagnostics are most needed when the cluster is not healthy, so the
+	// dump doesn't wait for the resources to be applied
+	dump := resources.NewDebugDump(r.Client, &redpandaCluster, r.Scheme, headlessSvc.HeadlessServiceFQDN(),
+		pki.NodeCert(), pki.OperatorClientCert(), log)
+	if err := dump.Ensure(ctx); err != nil {
+		log.Error(err, "Unable to start debug dump")
+	}
+	if err := r.completeDebugDump(ctx, &redpandaCluster); err != nil {
+		log.Info("Unable to complete debug dump", "error", err.Error())
+	}
+
+	// credential Secrets can be created by other controllers after the
+	// Cluster, the reconciliation waits for them instead of failing
+	missing, err := r.reportMissingSecrets(ctx, &redpandaCluster)
+	if err != nil {
+		log.Error(err, "Unable to verify credential Secrets")
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		return ctrl.Result{}, err
+	}
+	if len(missing) > 0 {
+		log.Info("Waiting for credential Secrets", "secrets", missing)
+		r.reportClusterConfigured(ctx, &redpandaCluster, false, reasonSecret, missingSecretsMessage(missing), log)
+		r.reportProgressing(ctx, &redpandaCluster, reasonSecret, missingSecretsMessage(missing), log)
+		return ctrl.Result{RequeueAfter: secretPollInterval}, nil
+	}
+
+	if err := r.checkVersionTransition(ctx, &redpandaCluster, log); err != nil {
+		log.Error(err, "Unable to roll out the version")
+		r.reportClusterConfigured(ctx, &redpandaCluster, false, reasonFailed, err.Error(), log)
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		if errors.Is(err, errUpgradeNotFinished) {
+			return ctrl.Result{RequeueAfter: upgradeNotFinishedRetryInterval}, nil
+		}
+		if isVersionTransitionBlocked(err) {
+			// retrying doesn't help until the Cluster changes
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// pending brokers keep the StatefulSet from progressing, so the checks
+	// run before the resources are applied
+	r.runStatusChecks(ctx, &redpandaCluster, log,
+		statusCheck{"Unable to verify schedulability of the brokers", r.reportSchedulability},
+		statusCheck{"Unable to verify cluster domain", r.reportClusterDomain},
+		statusCheck{"Unable to verify architecture of the nodes", r.reportArchitecture},
+		statusCheck{"Unable to verify resource quota", r.reportResourceQuota},
+		statusCheck{"Unable to verify local volumes", r.reportLocalVolumes},
+		statusCheck{"Unable to verify issuers", r.reportIssuers},
+		statusCheck{"Unable to verify exposed ports", r.reportWellKnownPorts},
+		statusCheck{"Unable to plan the upgrade", func(ctx context.Context, c *redpandav1alpha1.Cluster) error {
+			return r.reportUpgradePlan(ctx, c, statefulSets)
+		}},
+	)
+
+	decommissioning := redpandaCluster.Status.DecommissioningNode
 	for _, res := range toApply {
 		err := res.Ensure(ctx)
 
 		var e *resources.RequeueAfterError
 		if errors.As(err, &e) {
 			log.Info(e.Error())
+			r.reportClusterConfigured(ctx, &redpandaCluster, false, reasonRequeued, e.Msg, log)
+			r.reportProgressing(ctx, &redpandaCluster, reasonRequeued, e.Msg, log)
 			return ctrl.Result{RequeueAfter: e.RequeueAfter}, nil
 		}
 
+		var certErr *certmanager.InvalidCertificateError
+		if errors.As(err, &certErr) {
+			log.Error(err, "Invalid TLS certificate")
+			if condErr := r.setCondition(ctx, &redpandaCluster, metav1.Condition{
+				Type:    redpandav1alpha1.InvalidCertificateConditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  "InvalidSecret",
+				Message: certErr.Error(),
+			}); condErr != nil {
+				log.Error(condErr, "Unable to set InvalidCertificate condition")
+			}
+			if condErr := r.setCondition(ctx, &redpandaCluster, metav1.Condition{
+				Type:    redpandav1alpha1.TLSReadyConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidCertificate",
+				Message: certErr.Error(),
+			}); condErr != nil {
+				log.Error(condErr, "Unable to update TLSReady condition")
+			}
+			r.reportClusterConfigured(ctx, &redpandaCluster, false, reasonFailed, err.Error(), log)
+			r.reportFailure(ctx, &redpandaCluster, err, log)
+			return ctrl.Result{}, err
+		}
+
 		if err != nil {
 			log.Error(err, "Failed to reconcile resource")
+			r.reportClusterConfigured(ctx, &redpandaCluster, false, reasonFailed, err.Error(), log)
+			r.reportFailure(ctx, &redpandaCluster, err, log)
 			return ctrl.Result{}, err
 		}
 	}
+	r.reportClusterConfigured(ctx, &redpandaCluster, true, reasonSucceeded, "All resources are applied", log)
+	r.reportTLSReady(ctx, &redpandaCluster, pki.NodeCertificates(), log)
+	if decommissioning != nil && redpandaCluster.Status.DecommissioningNode == nil {
+		r.Recorder.Eventf(&redpandaCluster, corev1.EventTypeNormal, "BrokerDecommissioned",
+			"Broker %d was decommissioned and removed", *decommissioning)
+	}
 
-	err := r.reportStatus(ctx, &redpandaCluster, sts.LastObservedState, headlessSvc.HeadlessServiceFQDN(), nodeportSvc.Key())
+	if meta.FindStatusCondition(redpandaCluster.Status.Conditions, redpandav1alpha1.InvalidCertificateConditionType) != nil {
+		if err := r.setCondition(ctx, &redpandaCluster, metav1.Condition{
+			Type:    redpandav1alpha1.InvalidCertificateConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidSecret",
+			Message: "TLS certificates are valid",
+		}); err != nil {
+			log.Error(err, "Unable to clear InvalidCertificate condition")
+		}
+	}
+
+	observedStatefulSets := make([]*appsv1.StatefulSet, 0, len(statefulSets))
+	for _, sts := range statefulSets {
+		observedStatefulSets = append(observedStatefulSets, sts.LastObservedState)
+	}
+	err = r.reportStatus(ctx, &redpandaCluster, observedStatefulSets, headlessSvc.HeadlessServiceFQDN(), nodeportSvc.Key(), adminNodeportSvc.Key())
 	if err != nil {
 		log.Error(err, "Unable to report status")
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		return ctrl.Result{}, err
+	}
+	r.reportBrokersReady(ctx, &redpandaCluster, log)
+	if err := r.reportListenerTLS(ctx, &redpandaCluster, pki.ListenerTLS()); err != nil {
+		log.Error(err, "Unable to report listener TLS configuration")
+	}
+	if err := r.reportAdminClientAuthorization(ctx, &redpandaCluster, pki.AdminAPIClientCommonName()); err != nil {
+		log.Error(err, "Unable to verify authorization of the Admin API client")
+	}
+	if err := r.reportSANMismatch(ctx, &redpandaCluster, pki.AdvertisedAddresses(redpandaCluster.Status.Nodes)); err != nil {
+		log.Error(err, "Unable to verify SANs of node certificates")
+	}
+	if err := r.reportCommonNameConflict(ctx, &redpandaCluster, pki.NodeCertificates(), pki.ClientCommonNames()); err != nil {
+		log.Error(err, "Unable to verify CNs of node certificates")
+	}
+
+	err = resources.NewBootstrapConfigMap(r.Client, &redpandaCluster, r.Scheme, log).Ensure(ctx)
+	if err != nil {
+		log.Error(err, "Unable to publish bootstrap broker list")
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		return ctrl.Result{}, err
+	}
+
+	err = resources.NewAdvertisedEndpoints(r.Client, &redpandaCluster, r.Scheme, log).Ensure(ctx)
+	if err != nil {
+		log.Error(err, "Unable to publish advertised endpoints")
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		return ctrl.Result{}, err
+	}
+
+	err = resources.NewClientConfigSecret(r.Client, &redpandaCluster, r.Scheme, pki.NodeCert(), pki.UserClientCert(), log).Ensure(ctx)
+	if err != nil {
+		log.Error(err, "Unable to publish client configuration")
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		return ctrl.Result{}, err
+	}
+
+	err = resources.NewCASecretCopies(r.Client, &redpandaCluster, pki.NodeCert(), log).Ensure(ctx)
+	if err != nil {
+		log.Error(err, "Unable to copy CA certificate to other namespaces")
+		r.reportFailure(ctx, &redpandaCluster, err, log)
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, err
+	// the checks are informative only, failure to reach brokers is not an
+	// error of the reconciliation
+	r.runStatusChecks(ctx, &redpandaCluster, log,
+		statusCheck{"Unable to verify controller leader consistency", r.reportControllerConsistency},
+		statusCheck{"Unable to verify broker clock skew", r.reportClockSkew},
+		statusCheck{"Unable to verify subdomain delegation", r.reportSubdomainDelegation},
+		statusCheck{"Unable to verify cloud storage reachability", r.reportCloudStorageReachability},
+		statusCheck{"Unable to report consumer lag", r.reportConsumerLag},
+		statusCheck{"Unable to report cluster health", r.reportHealth},
+	)
+
+	// the spec is recorded as last applied only if all of it is provisioned
+	provisioned := true
+	if err := r.bootstrapTopics(ctx, &redpandaCluster); err != nil {
+		log.Info("Unable to create bootstrap topics", "error", err.Error())
+		provisioned = false
+	}
+
+	if err := r.provisionSuperusers(ctx, &redpandaCluster); err != nil {
+		log.Info("Unable to provision superusers", "error", err.Error())
+		provisioned = false
+	}
+
+	// clients would be locked out of the cluster without superusers
+	if err := r.reportSuperuserBootstrap(ctx, &redpandaCluster); err != nil {
+		log.Info("Superuser bootstrap is not complete", "error", err.Error())
+		r.reportProgressing(ctx, &redpandaCluster, reasonSuperusers, err.Error(), log)
+		return ctrl.Result{RequeueAfter: superuserBootstrapRetryInterval}, nil
+	}
+
+	nextRotation, err := r.rotateSuperuserPasswords(ctx, &redpandaCluster)
+	if err != nil {
+		log.Info("Unable to rotate superuser passwords", "error", err.Error())
+		provisioned = false
+	}
+
+	if err := r.provisionACLs(ctx, &redpandaCluster); err != nil {
+		log.Info("Unable to provision ACLs", "error", err.Error())
+		provisioned = false
+	}
+
+	if err := r.resetConsumerGroupOffsets(ctx, &redpandaCluster); err != nil {
+		log.Info("Unable to reset consumer group offsets", "error", err.Error())
+		provisioned = false
+	}
+
+	r.reportReady(ctx, &redpandaCluster, log)
+	if provisioned {
+		if err := r.snapshotSpec(ctx, &redpandaCluster); err != nil {
+			log.Info("Unable to record the last applied spec", "error", err.Error())
+		}
+	}
+	// the earliest of the polls and the password rotation is due first
+	requeueAfter := nextRotation
+	if redpandaCluster.Spec.ReportConsumerLag {
+		var lastPoll metav1.Time
+		if redpandaCluster.Status.ConsumerLag != nil {
+			lastPoll = redpandaCluster.Status.ConsumerLag.LastPollTime
+		}
+		requeueAfter = earliest(requeueAfter, untilNextPoll(lastPoll, consumerLagPollInterval))
+	}
+	if redpandaCluster.Spec.ReportHealth {
+		var lastPoll metav1.Time
+		if redpandaCluster.Status.Health != nil {
+			lastPoll = redpandaCluster.Status.Health.LastPollTime
+		}
+		requeueAfter = earliest(requeueAfter, untilNextPoll(lastPoll, healthPollInterval))
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&redpandav1alpha1.Cluster{}).
+		For(&redpandav1alpha1.Cluster{}, builder.WithPredicates(predicate.NewPredicateFuncs(r.matchesClusterSelector))).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&batchv1.Job{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.certificateSecretToCluster)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.credentialSecretToClusters)).
 		Complete(r)
 }
 
+// certificateSecretToCluster maps the Secret issued by cert-manager to the
+// Cluster owning the Certificate, so renewed node certificates are rolled
+// out to the brokers
+func (r *ClusterReconciler) certificateSecretToCluster(
+	obj client.Object,
+) []reconcile.Request {
+	certName, ok := obj.GetAnnotations()[cmapiv1.CertificateNameKey]
+	if !ok {
+		return nil
+	}
+	var cert cmapiv1.Certificate
+	err := r.Get(context.Background(), types.NamespacedName{Name: certName, Namespace: obj.GetNamespace()}, &cert)
+	if err != nil {
+		return nil
+	}
+	owner := metav1.GetControllerOf(&cert)
+	if owner == nil || owner.Kind != "Cluster" || owner.APIVersion != redpandav1alpha1.GroupVersion.String() {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: owner.Name, Namespace: obj.GetNamespace()},
+	}}
+}
+
+// credentialSecretToClusters maps a credential Secret to the Clusters
+// referencing it, so the reconciliation proceeds as soon as the Secret is
+// created
+func (r *ClusterReconciler) credentialSecretToClusters(
+	obj client.Object,
+) []reconcile.Request {
+	var clusters redpandav1alpha1.ClusterList
+	if err := r.List(context.Background(), &clusters); err != nil {
+		return nil
+	}
+	key := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	var requests []reconcile.Request
+	for i := range clusters.Items {
+		for _, secret := range credentialSecrets(&clusters.Items[i]) {
+			if secret == key {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: clusters.Items[i].Name, Namespace: clusters.Items[i].Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// credentialSecrets returns the Secrets holding credentials consumed by
+// the operator
+func credentialSecrets(
+	redpandaCluster *redpandav1alpha1.Cluster,
+) []types.NamespacedName {
+	var secrets []types.NamespacedName
+	if redpandaCluster.CloudStorageStaticCredentials() {
+		secrets = append(secrets, types.NamespacedName{
+			Name:      redpandaCluster.Spec.CloudStorage.SecretKeyRef.Name,
+			Namespace: redpandaCluster.Spec.CloudStorage.SecretKeyRef.Namespace,
+		})
+	}
+	for _, superuser := range redpandaCluster.Spec.Superusers {
+		if superuser.PasswordSecretKeyRef != nil {
+			secrets = append(secrets, types.NamespacedName{
+				Name:      superuser.PasswordSecretKeyRef.Name,
+				Namespace: redpandaCluster.Namespace,
+			})
+		}
+	}
+	return secrets
+}
+
+// reportMissingSecrets returns the credential Secrets that don't exist yet
+// and reflects them in the WaitingForSecret condition
+func (r *ClusterReconciler) reportMissingSecrets(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) ([]string, error) {
+	var missing []string
+	for _, key := range credentialSecrets(redpandaCluster) {
+		var secret corev1.Secret
+		err := r.Get(ctx, key, &secret)
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, key.String())
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch Secret %s: %w", key, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return missing, r.setCondition(ctx, redpandaCluster, metav1.Condition{
+			Type:    redpandav1alpha1.WaitingForSecretConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "SecretNotFound",
+			Message: missingSecretsMessage(missing),
+		})
+	}
+	if meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, redpandav1alpha1.WaitingForSecretConditionType) {
+		return nil, r.setCondition(ctx, redpandaCluster, metav1.Condition{
+			Type:    redpandav1alpha1.WaitingForSecretConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "SecretFound",
+			Message: "All credential Secrets exist",
+		})
+	}
+	return nil, nil
+}
+
+func missingSecretsMessage(missing []string) string {
+	return fmt.Sprintf("Waiting for Secrets: %s", strings.Join(missing, ", "))
+}
+
 func (r *ClusterReconciler) reportStatus(
 	ctx context.Context,
 	redpandaCluster *redpandav1alpha1.Cluster,
-	lastObservedSts *appsv1.StatefulSet,
+	lastObservedSts []*appsv1.StatefulSet,
 	internalFQDN string,
 	nodeportSvcName types.NamespacedName,
+	adminNodeportSvcName types.NamespacedName,
 ) error {
 	var observedPods corev1.PodList
 
@@ -177,32 +665,26 @@ func (r *ClusterReconciler) reportStatus(
 		observedNodesInternal = append(observedNodesInternal, fmt.Sprintf("%s.%s", item.Name, internalFQDN))
 	}
 
-	observedNodesExternal, observedExternalAdmin, err := r.createExternalNodesList(ctx, observedPods.Items, redpandaCluster, nodeportSvcName)
+	observedNodesExternal, observedExternalAdmin, err := r.createExternalNodesList(ctx, observedPods.Items, redpandaCluster, nodeportSvcName, adminNodeportSvcName)
 	if err != nil {
 		return fmt.Errorf("failed to construct external node list: %w", err)
 	}
 
-	if lastObservedSts == nil {
-		return errNonexistentLastObservesState
+	// the ready replicas of the zone StatefulSets add up
+	var readyReplicas int32
+	for _, sts := range lastObservedSts {
+		if sts == nil {
+			return errNonexistentLastObservesState
+		}
+		readyReplicas += sts.Status.ReadyReplicas
 	}
 
-	if statusShouldBeUpdated(&redpandaCluster.Status, observedNodesInternal, observedNodesExternal, lastObservedSts.Status.ReadyReplicas) {
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			var cluster redpandav1alpha1.Cluster
-			err := r.Get(ctx, types.NamespacedName{
-				Name:      redpandaCluster.Name,
-				Namespace: redpandaCluster.Namespace,
-			}, &cluster)
-			if err != nil {
-				return err
-			}
-
+	if statusShouldBeUpdated(&redpandaCluster.Status, observedNodesInternal, observedNodesExternal, observedExternalAdmin, readyReplicas) {
+		err := r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
 			cluster.Status.Nodes.Internal = observedNodesInternal
 			cluster.Status.Nodes.External = observedNodesExternal
 			cluster.Status.Nodes.ExternalAdmin = observedExternalAdmin
-			cluster.Status.Replicas = lastObservedSts.Status.ReadyReplicas
-
-			return r.Status().Update(ctx, &cluster)
+			cluster.Status.Replicas = readyReplicas
 		})
 
 		if err != nil {
@@ -212,13 +694,394 @@ func (r *ClusterReconciler) reportStatus(
 	return nil
 }
 
+// reportListenerTLS publishes the effective TLS configuration of the
+// listeners in the status, if it changed
+func (r *ClusterReconciler) reportListenerTLS(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	summary []redpandav1alpha1.ListenerTLSStatus,
+) error {
+	if reflect.DeepEqual(redpandaCluster.Status.TLS, summary) {
+		return nil
+	}
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.TLS = summary
+	})
+}
+
+// reportConsumerLag polls the Kafka API for the consumer group lag and
+// reports the largest one in the status
+func (r *ClusterReconciler) reportConsumerLag(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if r.AdminAPIClientFactory == nil || !redpandaCluster.Spec.ReportConsumerLag {
+		// do not leave stale lag behind
+		if redpandaCluster.Status.ConsumerLag != nil {
+			return r.updateConsumerLag(ctx, redpandaCluster, nil)
+		}
+		return nil
+	}
+	if len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+	// the status update triggers another reconciliation, the lag is polled
+	// once per interval only
+	if lag := redpandaCluster.Status.ConsumerLag; lag != nil &&
+		time.Since(lag.LastPollTime.Time) < consumerLagPollInterval {
+		return nil
+	}
+
+	clients := make([]admin.AdminAPIClient, 0, len(redpandaCluster.Status.Nodes.Internal))
+	for _, host := range redpandaCluster.Status.Nodes.Internal {
+		c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, host)
+		if err != nil {
+			return err
+		}
+		clients = append(clients, c)
+	}
+
+	maxLag, err := admin.MaxConsumerGroupLag(ctx, clients)
+	if err != nil {
+		return err
+	}
+	return r.updateConsumerLag(ctx, redpandaCluster, &redpandav1alpha1.ConsumerLagStatus{
+		MaxLag:       maxLag.Lag,
+		Group:        maxLag.Group,
+		LastPollTime: metav1.Now(),
+	})
+}
+
+func (r *ClusterReconciler) updateConsumerLag(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	consumerLag *redpandav1alpha1.ConsumerLagStatus,
+) error {
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.ConsumerLag = consumerLag
+	})
+}
+
+// reportHealth polls the Admin API of every broker and stores the
+// aggregated health summary in the status
+func (r *ClusterReconciler) reportHealth(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if r.AdminAPIClientFactory == nil || !redpandaCluster.Spec.ReportHealth {
+		// do not leave stale summary behind
+		if redpandaCluster.Status.Health != nil {
+			return r.updateHealth(ctx, redpandaCluster, nil)
+		}
+		return nil
+	}
+	if len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+	// the status update triggers another reconciliation, the brokers are
+	// polled once per interval only
+	if health := redpandaCluster.Status.Health; health != nil &&
+		time.Since(health.LastPollTime.Time) < healthPollInterval {
+		return nil
+	}
+
+	clients := make(map[string]admin.AdminAPIClient, len(redpandaCluster.Status.Nodes.Internal))
+	for _, host := range redpandaCluster.Status.Nodes.Internal {
+		c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, host)
+		if err != nil {
+			return err
+		}
+		clients[host] = c
+	}
+
+	summary := admin.SummarizeHealth(ctx, clients)
+	summary.LastPollTime = metav1.Now()
+	return r.updateHealth(ctx, redpandaCluster, &summary)
+}
+
+func (r *ClusterReconciler) updateHealth(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	health *redpandav1alpha1.ClusterHealthSummary,
+) error {
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.Health = health
+	})
+}
+
+// bootstrapTopics creates the bootstrap topics once all brokers are ready.
+// Topics reported in the status are not requested again.
+func (r *ClusterReconciler) bootstrapTopics(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if r.AdminAPIClientFactory == nil || len(redpandaCluster.Spec.BootstrapTopics) == 0 {
+		return nil
+	}
+	if redpandaCluster.Spec.Replicas == nil || redpandaCluster.Status.Replicas != *redpandaCluster.Spec.Replicas ||
+		redpandaCluster.Status.Upgrading || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+
+	bootstrapped := make(map[string]bool, len(redpandaCluster.Status.BootstrappedTopics))
+	for _, name := range redpandaCluster.Status.BootstrappedTopics {
+		bootstrapped[name] = true
+	}
+	var pending []admin.Topic
+	for _, topic := range redpandaCluster.Spec.BootstrapTopics {
+		if !bootstrapped[topic.Name] {
+			pending = append(pending, admin.Topic{
+				Name:              topic.Name,
+				Partitions:        topic.Partitions,
+				ReplicationFactor: topic.ReplicationFactor,
+			})
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+	if err != nil {
+		return err
+	}
+	if err = admin.EnsureTopics(ctx, c, pending); err != nil {
+		return err
+	}
+
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		for _, topic := range pending {
+			cluster.Status.BootstrappedTopics = append(cluster.Status.BootstrappedTopics, topic.Name)
+		}
+	})
+}
+
+// provisionSuperusers creates SCRAM users of the superusers with password
+// and deletes the users provisioned for removed superusers. The passwords
+// must never be logged.
+func (r *ClusterReconciler) provisionSuperusers(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if r.AdminAPIClientFactory == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+
+	desired := map[string]string{}
+	for _, superuser := range redpandaCluster.Spec.Superusers {
+		ref := superuser.PasswordSecretKeyRef
+		if ref == nil {
+			continue
+		}
+		var secret corev1.Secret
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: redpandaCluster.Namespace}, &secret)
+		if err != nil {
+			return fmt.Errorf("unable to fetch password of superuser %s: %w", superuser.Username, err)
+		}
+		password, ok := secret.Data[ref.Key]
+		if !ok || len(password) == 0 {
+			return fmt.Errorf("password of superuser %s in Secret %s: %w", superuser.Username, ref.Name, errEmptySuperuserPassword)
+		}
+		desired[superuser.Username] = string(password)
+	}
+	provisioned := redpandaCluster.Status.ProvisionedSuperusers
+	if len(desired) == 0 && len(provisioned) == 0 {
+		return nil
+	}
+
+	c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+	if err != nil {
+		return err
+	}
+	if err = admin.SyncUsers(ctx, c, desired, provisioned); err != nil {
+		return err
+	}
+
+	usernames := make([]string, 0, len(desired))
+	for username := range desired {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+	if reflect.DeepEqual(usernames, provisioned) {
+		return nil
+	}
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.ProvisionedSuperusers = usernames
+	})
+}
+
+// provisionACLs creates the ACLs declared in the spec and deletes the
+// provisioned ACLs removed from the spec
+func (r *ClusterReconciler) provisionACLs(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if r.AdminAPIClientFactory == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+	desired := aclsWithDefaults(redpandaCluster.Spec.ACLs)
+	provisioned := redpandaCluster.Status.ProvisionedACLs
+	if len(desired) == 0 && len(provisioned) == 0 {
+		return nil
+	}
+
+	c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+	if err != nil {
+		return err
+	}
+	if err = admin.SyncACLs(ctx, c, toAdminACLs(desired), toAdminACLs(provisioned)); err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(desired, provisioned) {
+		return nil
+	}
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.ProvisionedACLs = desired
+	})
+}
+
+// aclsWithDefaults returns the unique ACLs with defaults applied in a stable
+// order, so the provisioned ACLs in the status don't change between
+// reconciliations
+func aclsWithDefaults(acls []redpandav1alpha1.ACL) []redpandav1alpha1.ACL {
+	seen := map[admin.ACL]bool{}
+	var result []redpandav1alpha1.ACL
+	for _, acl := range acls {
+		a := toAdminACL(acl).WithDefaults()
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+		result = append(result, redpandav1alpha1.ACL{
+			Principal:    a.Principal,
+			Host:         a.Host,
+			ResourceType: a.ResourceType,
+			ResourceName: a.ResourceName,
+			PatternType:  a.PatternType,
+			Operation:    a.Operation,
+			Permission:   a.Permission,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return toAdminACL(result[i]).String() < toAdminACL(result[j]).String()
+	})
+	return result
+}
+
+func toAdminACLs(acls []redpandav1alpha1.ACL) []admin.ACL {
+	result := make([]admin.ACL, 0, len(acls))
+	for _, acl := range acls {
+		result = append(result, toAdminACL(acl))
+	}
+	return result
+}
+
+func toAdminACL(acl redpandav1alpha1.ACL) admin.ACL {
+	return admin.ACL{
+		Principal:    acl.Principal,
+		Host:         acl.Host,
+		ResourceType: acl.ResourceType,
+		ResourceName: acl.ResourceName,
+		PatternType:  acl.PatternType,
+		Operation:    acl.Operation,
+		Permission:   acl.Permission,
+	}
+}
+
+// setCondition updates the condition in the Cluster status if it differs
+// from the currently observed one
+func (r *ClusterReconciler) setCondition(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	condition metav1.Condition,
+) error {
+	return r.setConditions(ctx, redpandaCluster, condition)
+}
+
+// setConditions updates the conditions in the Cluster status with a single
+// status update. Conditions are stamped with the Cluster generation.
+func (r *ClusterReconciler) setConditions(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	conditions ...metav1.Condition,
+) error {
+	changed := make([]metav1.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		condition.ObservedGeneration = redpandaCluster.Generation
+		existing := meta.FindStatusCondition(redpandaCluster.Status.Conditions, condition.Type)
+		if existing != nil && existing.Status == condition.Status &&
+			existing.Reason == condition.Reason && existing.Message == condition.Message &&
+			existing.ObservedGeneration == condition.ObservedGeneration {
+			continue
+		}
+		changed = append(changed, condition)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	err := r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		for _, condition := range changed {
+			meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster status conditions: %w", err)
+	}
+	return nil
+}
+
+// updateClusterStatus applies update to the latest version of the Cluster
+// and updates its status, retrying on conflicts. The status of
+// redpandaCluster is replaced with the updated one.
+func (r *ClusterReconciler) updateClusterStatus(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	update func(*redpandav1alpha1.Cluster),
+) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cluster redpandav1alpha1.Cluster
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      redpandaCluster.Name,
+			Namespace: redpandaCluster.Namespace,
+		}, &cluster)
+		if err != nil {
+			return err
+		}
+
+		update(&cluster)
+		if err := r.Status().Update(ctx, &cluster); err != nil {
+			return err
+		}
+		redpandaCluster.Status = cluster.Status
+		// the resources update the status of redpandaCluster later, they would
+		// conflict with the outdated version
+		redpandaCluster.ResourceVersion = cluster.ResourceVersion
+		return nil
+	})
+}
+
+// untilNextPoll returns the time left until the next poll of the brokers,
+// the full interval if the last poll is overdue
+func untilNextPoll(lastPoll metav1.Time, interval time.Duration) time.Duration {
+	if left := interval - time.Since(lastPoll.Time); left > 0 {
+		return left
+	}
+	return interval
+}
+
+// earliest returns the shorter of the requeue delays, zero is no requeue
+func earliest(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
 func statusShouldBeUpdated(
 	status *redpandav1alpha1.ClusterStatus,
-	nodesInternal, nodesExternal []string,
+	nodesInternal, nodesExternal, nodesExternalAdmin []string,
 	readyReplicas int32,
 ) bool {
 	return !reflect.DeepEqual(nodesInternal, status.Nodes.Internal) ||
 		!reflect.DeepEqual(nodesExternal, status.Nodes.External) ||
+		!reflect.DeepEqual(nodesExternalAdmin, status.Nodes.ExternalAdmin) ||
 		status.Replicas != readyReplicas
 }
 
@@ -230,15 +1093,66 @@ func (r *ClusterReconciler) WithConfiguratorTag(
 	return r
 }
 
+// WithPauseImage sets the image keeping the Pods of the image pre-pull
+// DaemonSet running
+func (r *ClusterReconciler) WithPauseImage(
+	pauseImage string,
+) *ClusterReconciler {
+	r.pauseImage = pauseImage
+	return r
+}
+
+// WithClusterLabelSelector restricts reconciliation to Cluster resources
+// matching the selector, so the clusters can be sharded between operators
+func (r *ClusterReconciler) WithClusterLabelSelector(
+	selector k8slabels.Selector,
+) *ClusterReconciler {
+	r.clusterSelector = selector
+	return r
+}
+
+// WithCertIssuanceStagger spreads creation of certificates of the clusters
+// over time to avoid hitting rate limits of the issuers
+func (r *ClusterReconciler) WithCertIssuanceStagger(
+	stagger *certmanager.IssuanceStagger,
+) *ClusterReconciler {
+	r.certStagger = stagger
+	return r
+}
+
+// WithRestartLimiter limits the number of clusters running the rolling
+// update at once
+func (r *ClusterReconciler) WithRestartLimiter(
+	limiter *resources.RestartLimiter,
+) *ClusterReconciler {
+	r.restartLimiter = limiter
+	return r
+}
+
+func (r *ClusterReconciler) matchesClusterSelector(obj client.Object) bool {
+	if r.clusterSelector == nil {
+		return true
+	}
+	return r.clusterSelector.Matches(k8slabels.Set(obj.GetLabels()))
+}
+
 func (r *ClusterReconciler) createExternalNodesList(
 	ctx context.Context,
 	pods []corev1.Pod,
 	pandaCluster *redpandav1alpha1.Cluster,
 	nodePortName types.NamespacedName,
+	adminNodePortName types.NamespacedName,
 ) (external, externalAdmin []string, err error) {
+	if pandaCluster.SeparateExternalAdmin() {
+		externalAdmin, err = r.createExternalAdminNodesList(ctx, pods, pandaCluster, adminNodePortName)
+		return []string{}, externalAdmin, err
+	}
 	if !pandaCluster.Spec.ExternalConnectivity.Enabled {
 		return []string{}, []string{}, nil
 	}
+	if pandaCluster.Spec.ExternalConnectivity.PerBrokerServices() {
+		return r.createBrokerServicesNodesList(ctx, pods, pandaCluster)
+	}
 
 	var nodePortSvc corev1.Service
 	if err := r.Get(ctx, nodePortName, &nodePortSvc); err != nil {
@@ -287,6 +1201,81 @@ func (r *ClusterReconciler) createExternalNodesList(
 	return observedNodesExternal, observedNodesExternalAdmin, nil
 }
 
+// createExternalAdminNodesList returns the Admin API addresses of the brokers
+// exposed by the Service of the Admin API only
+func (r *ClusterReconciler) createExternalAdminNodesList(
+	ctx context.Context,
+	pods []corev1.Pod,
+	pandaCluster *redpandav1alpha1.Cluster,
+	adminNodePortName types.NamespacedName,
+) ([]string, error) {
+	var adminNodePortSvc corev1.Service
+	if err := r.Get(ctx, adminNodePortName, &adminNodePortSvc); err != nil {
+		return []string{}, fmt.Errorf("failed to retrieve admin node port service %s: %w", adminNodePortName, err)
+	}
+	nodePort := getNodePort(&adminNodePortSvc, resources.AdminPortName)
+	if nodePort == 0 {
+		return []string{}, fmt.Errorf("admin node port service %s: %w", adminNodePortName, errNodePortMissing)
+	}
+
+	subdomain := pandaCluster.Spec.ExternalAdmin.Subdomain
+	observedExternalAdmin := make([]string, 0, len(pods))
+	for i := range pods {
+		if subdomain != "" {
+			prefixLen := len(pods[i].GenerateName)
+			observedExternalAdmin = append(observedExternalAdmin,
+				fmt.Sprintf("%s.%s:%d", pods[i].Name[prefixLen:], subdomain, nodePort))
+			continue
+		}
+		var node corev1.Node
+		if err := r.Get(ctx, types.NamespacedName{Name: pods[i].Spec.NodeName}, &node); err != nil {
+			return []string{}, fmt.Errorf("failed to retrieve node %s: %w", pods[i].Spec.NodeName, err)
+		}
+		observedExternalAdmin = append(observedExternalAdmin,
+			fmt.Sprintf("%s:%d", getExternalIP(&node), nodePort))
+	}
+	return observedExternalAdmin, nil
+}
+
+// createBrokerServicesNodesList returns the addresses of the Services
+// exposing each broker
+func (r *ClusterReconciler) createBrokerServicesNodesList(
+	ctx context.Context,
+	pods []corev1.Pod,
+	pandaCluster *redpandav1alpha1.Cluster,
+) (external, externalAdmin []string, err error) {
+	external = make([]string, 0, len(pods))
+	externalAdmin = make([]string, 0, len(pods))
+	for i := range pods {
+		var svc corev1.Service
+		key := types.NamespacedName{Name: networking.BrokerServiceName(pods[i].Name), Namespace: pods[i].Namespace}
+		if err := r.Get(ctx, key, &svc); err != nil {
+			return []string{}, []string{}, fmt.Errorf("failed to retrieve broker service %s: %w", key, err)
+		}
+
+		var nodeIP string
+		if svc.Spec.Type == corev1.ServiceTypeNodePort {
+			var node corev1.Node
+			if err := r.Get(ctx, types.NamespacedName{Name: pods[i].Spec.NodeName}, &node); err != nil {
+				return []string{}, []string{}, fmt.Errorf("failed to retrieve node %s: %w", pods[i].Spec.NodeName, err)
+			}
+			nodeIP = getExternalIP(&node)
+		}
+
+		host, port, err := networking.BrokerServiceAddress(&svc, resources.KafkaPortName, nodeIP)
+		if err != nil {
+			return []string{}, []string{}, err
+		}
+		external = append(external, fmt.Sprintf("%s:%d", host, port))
+		host, port, err = networking.BrokerServiceAddress(&svc, resources.AdminPortName, nodeIP)
+		if err != nil {
+			return []string{}, []string{}, err
+		}
+		externalAdmin = append(externalAdmin, fmt.Sprintf("%s:%d", host, port))
+	}
+	return external, externalAdmin, nil
+}
+
 func getExternalIP(node *corev1.Node) string {
 	if node == nil {
 		return ""
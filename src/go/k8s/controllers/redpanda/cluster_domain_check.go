@@ -0,0 +1,55 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reportClusterDomain warns with ClusterDomainMismatch condition when the
+// brokers fail to resolve names under the cluster domain on start
+func (r *ClusterReconciler) reportClusterDomain(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.Spec.VerifyClusterDomain {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.ClusterDomainMismatchConditionType, "Cluster domain is not validated")
+	}
+
+	var pods corev1.PodList
+	err := r.List(ctx, &pods, &client.ListOptions{
+		LabelSelector: labels.ForCluster(redpandaCluster).AsClientSelector(),
+		Namespace:     redpandaCluster.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.ClusterDomainMismatchConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ClusterDomainResolved",
+		Message: fmt.Sprintf("Cluster domain %s is resolved by the brokers", redpandaCluster.ClusterDomain()),
+	}
+	if failure := resources.FailedClusterDomainCheck(pods.Items); failure != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ClusterDomainNotResolved"
+		condition.Message = failure
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
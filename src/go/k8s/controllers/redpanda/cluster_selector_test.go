@@ -0,0 +1,64 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileIgnoresClusterNotMatchingSelector(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-shard",
+			Namespace: "default",
+			Labels:    map[string]string{"shard": "b"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster).Build()
+
+	selector, err := labels.Parse("shard=a")
+	require.NoError(t, err)
+
+	r := (&redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(10),
+	}).WithClusterLabelSelector(selector)
+
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	var sts appsv1.StatefulSet
+	err = c.Get(context.Background(), key, &sts)
+	assert.True(t, apierrors.IsNotFound(err), "expecting no StatefulSet for ignored cluster")
+}
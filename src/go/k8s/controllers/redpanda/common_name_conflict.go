@@ -0,0 +1,68 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reportCommonNameConflict warns with CommonNameConflict condition when a
+// node certificate has the CN of a client certificate issued by the operator.
+// Certificates that are not issued yet are skipped.
+func (r *ClusterReconciler) reportCommonNameConflict(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	nodeCerts []types.NamespacedName,
+	clientNames map[types.NamespacedName]string,
+) error {
+	if !redpandaCluster.Spec.Configuration.TLS.VerifyCommonNames || len(nodeCerts) == 0 || len(clientNames) == 0 {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.CommonNameConflictConditionType, "CNs of node certificates are not validated")
+	}
+
+	nodeNames := make(map[types.NamespacedName]string, len(nodeCerts))
+	for _, key := range nodeCerts {
+		var secret corev1.Secret
+		err := r.Get(ctx, key, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cn, err := certmanager.CertificateCommonName(&secret)
+		if err != nil {
+			return fmt.Errorf("unable to parse certificate of Secret %s: %w", key, err)
+		}
+		nodeNames[key] = cn
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.CommonNameConflictConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "CommonNamesDistinct",
+		Message: "Node certificates don't share the CN with client certificates",
+	}
+	if conflicts := certmanager.ConflictingCommonNames(nodeNames, clientNames); len(conflicts) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CommonNameConflict"
+		condition.Message = fmt.Sprintf("Certificates %s", strings.Join(conflicts, "; "))
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,286 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The conditions below follow kstatus conventions
+// (https://github.com/kubernetes-sigs/cli-utils/tree/master/pkg/kstatus),
+// so tools like `kubectl wait` or GitOps controllers can follow the rollout.
+// Reconciling and Stalled are abnormal-true conditions that are set to false
+// once the reconciliation succeeds.
+const (
+	reasonProgressing = "Progressing"
+	reasonRequeued    = "Requeued"
+	reasonFailed      = "ReconcileFailed"
+	reasonSucceeded   = "ReconcileSucceeded"
+	reasonSuperusers  = "SuperuserBootstrapPending"
+	reasonSecret      = "WaitingForSecret"
+	reasonPaused      = "ReconciliationPaused"
+	reasonResumed     = "ReconciliationResumed"
+	reasonSkipped     = "ValidationSkipped"
+)
+
+// reportNewGeneration marks the Cluster as reconciling when its spec has not
+// been reconciled yet
+func (r *ClusterReconciler) reportNewGeneration(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, log logr.Logger,
+) {
+	ready := meta.FindStatusCondition(redpandaCluster.Status.Conditions, redpandav1alpha1.ReadyConditionType)
+	if ready != nil && ready.ObservedGeneration == redpandaCluster.Generation {
+		return
+	}
+	r.reportProgressing(ctx, redpandaCluster, reasonProgressing, "Reconciling new generation", log)
+}
+
+// reportProgressing marks the Cluster as reconciling, e.g. waiting for
+// brokers to restart
+func (r *ClusterReconciler) reportProgressing(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	reason, message string,
+	log logr.Logger,
+) {
+	r.reportConditions(ctx, redpandaCluster, log,
+		metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, reason, message)
+}
+
+// reportFailure marks the Cluster as stalled by the error if it persists
+// until the Cluster changes, e.g. a disallowed version transition. Other
+// errors, e.g. of the API server, are retried, so the Cluster is marked as
+// reconciling.
+func (r *ClusterReconciler) reportFailure(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	err error,
+	log logr.Logger,
+) {
+	var certErr *certmanager.InvalidCertificateError
+	if isVersionTransitionBlocked(err) || errors.As(err, &certErr) || apierrors.IsInvalid(err) {
+		r.reportStalled(ctx, redpandaCluster, err, log)
+		return
+	}
+	r.reportProgressing(ctx, redpandaCluster, reasonFailed, err.Error(), log)
+}
+
+// reportStalled marks the Cluster as not able to progress due to an error
+func (r *ClusterReconciler) reportStalled(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	err error,
+	log logr.Logger,
+) {
+	r.reportConditions(ctx, redpandaCluster, log,
+		metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue, reasonFailed, err.Error())
+}
+
+// reportReady marks the current generation of the Cluster as reconciled
+func (r *ClusterReconciler) reportReady(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, log logr.Logger,
+) {
+	r.reportConditions(ctx, redpandaCluster, log,
+		metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse, reasonSucceeded, "Cluster is reconciled")
+}
+
+func (r *ClusterReconciler) reportConditions(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	log logr.Logger,
+	ready, reconciling, stalled metav1.ConditionStatus,
+	reason, message string,
+) {
+	err := r.setConditions(ctx, redpandaCluster,
+		metav1.Condition{Type: redpandav1alpha1.ReadyConditionType, Status: ready, Reason: reason, Message: message},
+		metav1.Condition{Type: redpandav1alpha1.ReconcilingConditionType, Status: reconciling, Reason: reason, Message: message},
+		metav1.Condition{Type: redpandav1alpha1.StalledConditionType, Status: stalled, Reason: reason, Message: message},
+	)
+	if err != nil {
+		log.Error(err, "Unable to update kstatus conditions")
+	}
+}
+
+// reportClusterConfigured reflects whether all resources of the Cluster are
+// applied
+func (r *ClusterReconciler) reportClusterConfigured(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	configured bool,
+	reason, message string,
+	log logr.Logger,
+) {
+	status := metav1.ConditionFalse
+	if configured {
+		status = metav1.ConditionTrue
+	}
+	err := r.setCondition(ctx, redpandaCluster, metav1.Condition{
+		Type:    redpandav1alpha1.ClusterConfiguredConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	if err != nil {
+		log.Error(err, "Unable to update ClusterConfigured condition")
+	}
+}
+
+// reportPaused reflects whether the reconciliation is paused by the
+// ManagedAnnotation. The condition is only added once the Cluster was paused.
+func (r *ClusterReconciler) reportPaused(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	paused bool,
+	log logr.Logger,
+) {
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.ReconciliationPausedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonPaused,
+		Message: fmt.Sprintf("Annotation %s is set to false", redpandav1alpha1.ManagedAnnotation),
+	}
+	if !paused {
+		if meta.FindStatusCondition(redpandaCluster.Status.Conditions, condition.Type) == nil {
+			return
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonResumed
+		condition.Message = "Reconciliation is resumed"
+	}
+	if err := r.setCondition(ctx, redpandaCluster, condition); err != nil {
+		log.Error(err, "Unable to update ReconciliationPaused condition")
+	}
+}
+
+// reportBrokersReady compares the ready brokers reported in the status with
+// the desired replicas
+func (r *ClusterReconciler) reportBrokersReady(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, log logr.Logger,
+) {
+	var replicas int32
+	if redpandaCluster.Spec.Replicas != nil {
+		replicas = *redpandaCluster.Spec.Replicas
+	}
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.BrokersReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "AllBrokersReady",
+		Message: fmt.Sprintf("%d brokers are ready", replicas),
+	}
+	if redpandaCluster.Status.Replicas < replicas {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "BrokersNotReady"
+		condition.Message = fmt.Sprintf("%d of %d brokers are ready", redpandaCluster.Status.Replicas, replicas)
+	}
+	if err := r.setCondition(ctx, redpandaCluster, condition); err != nil {
+		log.Error(err, "Unable to update BrokersReady condition")
+	}
+}
+
+// reportTLSReady verifies that the Secrets with the node certificates exist
+func (r *ClusterReconciler) reportTLSReady(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	nodeCerts []types.NamespacedName,
+	log logr.Logger,
+) {
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.TLSReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CertificatesIssued",
+		Message: "Node certificates are issued",
+	}
+	if len(nodeCerts) == 0 {
+		condition.Reason = "TLSDisabled"
+		condition.Message = "TLS is not enabled on any listener"
+	}
+	for _, key := range nodeCerts {
+		var secret corev1.Secret
+		err := r.Get(ctx, key, &secret)
+		if apierrors.IsNotFound(err) {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "CertificatesPending"
+			condition.Message = fmt.Sprintf("Waiting for certificate Secret %s", key.Name)
+			break
+		}
+		if err != nil {
+			log.Error(err, "Unable to fetch certificate Secret", "secret", key)
+			return
+		}
+	}
+	if err := r.setCondition(ctx, redpandaCluster, condition); err != nil {
+		log.Error(err, "Unable to update TLSReady condition")
+	}
+}
+
+// clearCondition sets the warning condition to false once the check
+// reporting it is disabled, so no stale warning is left behind. The condition
+// is not added if it was never reported.
+func (r *ClusterReconciler) clearCondition(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	conditionType, message string,
+) error {
+	if !meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, conditionType) {
+		return nil
+	}
+	return r.setCondition(ctx, redpandaCluster, metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonSkipped,
+		Message: message,
+	})
+}
+
+// setWarningCondition updates the warning condition and records a warning
+// event when the condition becomes true
+func (r *ClusterReconciler) setWarningCondition(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	condition metav1.Condition,
+) error {
+	if condition.Status == metav1.ConditionTrue &&
+		!meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, condition.Type) {
+		r.Recorder.Event(redpandaCluster, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+	return r.setCondition(ctx, redpandaCluster, condition)
+}
+
+// statusCheck reports a finding about the Cluster in its status. A failing
+// check is logged and does not fail the reconciliation.
+type statusCheck struct {
+	failure string
+	report  func(context.Context, *redpandav1alpha1.Cluster) error
+}
+
+// runStatusChecks runs the checks in order
+func (r *ClusterReconciler) runStatusChecks(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	log logr.Logger,
+	checks ...statusCheck,
+) {
+	for _, check := range checks {
+		if err := check.report(ctx, redpandaCluster); err != nil {
+			log.Info(check.failure, "error", err.Error())
+		}
+	}
+}
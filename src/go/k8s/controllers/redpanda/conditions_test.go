@@ -0,0 +1,218 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMilestoneConditions(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "milestones",
+			Namespace: "default",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "latest",
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity:         resource.MustParse("10Gi"),
+				StorageClassName: "local",
+			},
+			Superusers: []redpandav1alpha1.Superuser{{
+				Username: "admin",
+				PasswordSecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "admin-password"},
+					Key:                  "password",
+				},
+			}},
+		},
+	}
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+		Provisioner: resources.LocalVolumeProvisioner,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	reconcile := func() []metav1.Condition {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(context.Background(), key, &actual))
+		return actual.Status.Conditions
+	}
+	status := func(conditions []metav1.Condition, conditionType string) metav1.ConditionStatus {
+		condition := meta.FindStatusCondition(conditions, conditionType)
+		if condition == nil {
+			return metav1.ConditionUnknown
+		}
+		return condition.Status
+	}
+
+	// nothing is applied while the password Secret is missing
+	conditions := reconcile()
+	assert.Equal(t, metav1.ConditionFalse, status(conditions, redpandav1alpha1.ClusterConfiguredConditionType))
+	assert.Equal(t, metav1.ConditionUnknown, status(conditions, redpandav1alpha1.BrokersReadyConditionType))
+
+	// the resources are applied, but the brokers are not ready yet
+	require.NoError(t, c.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-password", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}))
+	conditions = reconcile()
+	assert.Equal(t, metav1.ConditionTrue, status(conditions, redpandav1alpha1.ClusterConfiguredConditionType))
+	assert.Equal(t, metav1.ConditionTrue, status(conditions, redpandav1alpha1.TLSReadyConditionType))
+	assert.Equal(t, "TLSDisabled", meta.FindStatusCondition(conditions, redpandav1alpha1.TLSReadyConditionType).Reason)
+	assert.Equal(t, metav1.ConditionFalse, status(conditions, redpandav1alpha1.BrokersReadyConditionType))
+
+	// the brokers become ready
+	var sts appsv1.StatefulSet
+	require.NoError(t, c.Get(context.Background(), key, &sts))
+	sts.Status.ReadyReplicas = 1
+	require.NoError(t, c.Update(context.Background(), &sts))
+	require.NoError(t, c.Create(context.Background(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "milestones-0",
+			Namespace: cluster.Namespace,
+			Labels:    labels.ForCluster(cluster),
+		},
+	}))
+	conditions = reconcile()
+	assert.Equal(t, metav1.ConditionTrue, status(conditions, redpandav1alpha1.BrokersReadyConditionType))
+	assert.Equal(t, metav1.ConditionTrue, status(conditions, redpandav1alpha1.ReadyConditionType))
+
+	// no-op reconcile keeps the conditions and their transition times
+	assert.Equal(t, conditions, reconcile())
+}
+
+// unavailableClient fails the creation of ConfigMaps like an API server that
+// is not reachable
+type unavailableClient struct {
+	client.Client
+	unavailable bool
+}
+
+func (c *unavailableClient) Create(
+	ctx context.Context, obj client.Object, opts ...client.CreateOption,
+) error {
+	if _, ok := obj.(*corev1.ConfigMap); ok && c.unavailable {
+		return apierrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestTransientFailureIsNotStalled(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "transient",
+			Namespace: "default",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "latest",
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity:         resource.MustParse("10Gi"),
+				StorageClassName: "local",
+			},
+		},
+	}
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+		Provisioner: resources.LocalVolumeProvisioner,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	c := &unavailableClient{
+		Client:      fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build(),
+		unavailable: true,
+	}
+
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	// the error is retried, so the Cluster is still reconciling
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.Error(t, err)
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	assert.False(t, meta.IsStatusConditionTrue(actual.Status.Conditions, redpandav1alpha1.StalledConditionType))
+	assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, redpandav1alpha1.ReconcilingConditionType))
+
+	c.unavailable = false
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	assert.False(t, meta.IsStatusConditionTrue(actual.Status.Conditions, redpandav1alpha1.StalledConditionType))
+	assert.True(t, meta.IsStatusConditionTrue(actual.Status.Conditions, redpandav1alpha1.ClusterConfiguredConditionType))
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportControllerConsistency compares the controller leader reported by
+// every broker and flags disagreement with ControllerInconsistency condition
+func (r *ClusterReconciler) reportControllerConsistency(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if r.AdminAPIClientFactory == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+
+	clients := make(map[string]admin.AdminAPIClient, len(redpandaCluster.Status.Nodes.Internal))
+	for _, host := range redpandaCluster.Status.Nodes.Internal {
+		c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, host)
+		if err != nil {
+			return err
+		}
+		clients[host] = c
+	}
+
+	views, err := admin.QueryControllerLeaders(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.ControllerInconsistencyConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ControllerLeaderAgreed",
+		Message: "Brokers agree on controller leader",
+	}
+	if views.Inconsistent() {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ControllerLeaderDisagreement"
+		condition.Message = fmt.Sprintf("Brokers report different controller leaders: %s", views)
+	}
+	return r.setCondition(ctx, redpandaCluster, condition)
+}
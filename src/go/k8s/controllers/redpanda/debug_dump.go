@@ -0,0 +1,80 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// completeDebugDump reports the outcome of the Job started for
+// DebugDumpAnnotation and removes the annotation once the Job finished, so
+// the Job is cleaned up by the next reconciliation
+func (r *ClusterReconciler) completeDebugDump(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	request := redpandaCluster.DebugDumpRequest()
+	if request == "" {
+		return nil
+	}
+	if !redpandaCluster.Spec.CloudStorage.Enabled {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "DebugDumpSkipped",
+			"Debug dump %s was not collected, cloud storage is not enabled", request)
+		return r.clearDebugDumpAnnotation(ctx, redpandaCluster)
+	}
+
+	var job batchv1.Job
+	err := r.Get(ctx, resources.DebugDumpKey(redpandaCluster), &job)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.Annotations[redpandav1alpha1.DebugDumpAnnotation] != request {
+		// the Job of the previous request is being replaced
+		return nil
+	}
+
+	switch {
+	case jobConditionTrue(&job, batchv1.JobComplete):
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, "DebugDumpUploaded",
+			"Debug dump %s was uploaded to bucket %s", request, redpandaCluster.Spec.CloudStorage.Bucket)
+	case jobConditionTrue(&job, batchv1.JobFailed):
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "DebugDumpFailed",
+			"Debug dump %s failed, see the logs of Job %s", request, job.Name)
+	default:
+		return nil
+	}
+	return r.clearDebugDumpAnnotation(ctx, redpandaCluster)
+}
+
+func (r *ClusterReconciler) clearDebugDumpAnnotation(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	patch := client.MergeFrom(redpandaCluster.DeepCopy())
+	delete(redpandaCluster.Annotations, redpandav1alpha1.DebugDumpAnnotation)
+	return r.Patch(ctx, redpandaCluster, patch)
+}
+
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+// fakeAdminAPI records the topics, users and ACLs created and the offset
+// resets issued through the Admin API. The broker clock is ahead of the
+// local clock by clock. Password updates fail with updateErr, offset resets
+// with resetErr. lagPolls and healthPolls count the consumer group lag and
+// under-replicated partitions requests.
+type fakeAdminAPI struct {
+	topics      []admin.Topic
+	users       map[string]string
+	versions    map[int]string
+	features    *admin.Features
+	acls        []admin.ACL
+	resets      []offsetResetCall
+	clock       time.Duration
+	err         error
+	updateErr   error
+	resetErr    error
+	aclErr      error
+	lagPolls    int
+	healthPolls int
+}
+
+type offsetResetCall struct {
+	group  string
+	to     string
+	dryRun bool
+}
+
+func (f *fakeAdminAPI) ControllerLeader(context.Context) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeAdminAPI) ConsumerGroupLags(context.Context) ([]admin.ConsumerGroupLag, error) {
+	f.lagPolls++
+	return nil, nil
+}
+
+func (f *fakeAdminAPI) DecommissionBroker(context.Context, int) error {
+	return nil
+}
+
+func (f *fakeAdminAPI) IsBrokerDecommissioned(context.Context, int) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeAdminAPI) DecommissionReplicasLeft(context.Context, int) (int, error) {
+	return 0, admin.ErrDecommissionProgressNotSupported
+}
+
+func (f *fakeAdminAPI) CreateTopic(_ context.Context, topic admin.Topic) error {
+	for _, created := range f.topics {
+		if created.Name == topic.Name {
+			return admin.ErrTopicAlreadyExists
+		}
+	}
+	f.topics = append(f.topics, topic)
+	return nil
+}
+
+func (f *fakeAdminAPI) ListUsers(context.Context) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	users := make([]string, 0, len(f.users))
+	for u := range f.users {
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func (f *fakeAdminAPI) CreateUser(_ context.Context, username, password string) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.users == nil {
+		f.users = map[string]string{}
+	}
+	if _, ok := f.users[username]; ok {
+		return admin.ErrUserAlreadyExists
+	}
+	f.users[username] = password
+	return nil
+}
+
+func (f *fakeAdminAPI) DeleteUser(_ context.Context, username string) error {
+	if f.err != nil {
+		return f.err
+	}
+	delete(f.users, username)
+	return nil
+}
+
+func (f *fakeAdminAPI) UpdateUser(_ context.Context, username, password string) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	if _, ok := f.users[username]; !ok {
+		return errors.New("user not found")
+	}
+	f.users[username] = password
+	return nil
+}
+
+func (f *fakeAdminAPI) UnderReplicatedPartitions(context.Context) (int64, error) {
+	f.healthPolls++
+	return 0, nil
+}
+
+func (f *fakeAdminAPI) BrokerVersions(context.Context) (map[int]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.versions, nil
+}
+
+// Features returns ErrFeaturesNotSupported unless the feature state is set
+func (f *fakeAdminAPI) Features(context.Context) (admin.Features, error) {
+	if f.err != nil {
+		return admin.Features{}, f.err
+	}
+	if f.features == nil {
+		return admin.Features{}, admin.ErrFeaturesNotSupported
+	}
+	return *f.features, nil
+}
+
+func (f *fakeAdminAPI) ListACLs(context.Context) ([]admin.ACL, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]admin.ACL(nil), f.acls...), nil
+}
+
+func (f *fakeAdminAPI) CreateACL(_ context.Context, acl admin.ACL) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.aclErr != nil {
+		return f.aclErr
+	}
+	f.acls = append(f.acls, acl)
+	return nil
+}
+
+func (f *fakeAdminAPI) DeleteACL(_ context.Context, acl admin.ACL) error {
+	if f.err != nil {
+		return f.err
+	}
+	for i := range f.acls {
+		if f.acls[i] == acl {
+			f.acls = append(f.acls[:i], f.acls[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *fakeAdminAPI) BrokerTime(context.Context) (time.Time, error) {
+	if f.err != nil {
+		return time.Time{}, f.err
+	}
+	return time.Now().Add(f.clock), nil
+}
+
+// ResetConsumerGroupOffsets moves every group to offset 0 of a single
+// partition of topic orders
+func (f *fakeAdminAPI) ResetConsumerGroupOffsets(
+	_ context.Context, group, to string, dryRun bool,
+) ([]admin.PartitionOffset, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.resetErr != nil {
+		return nil, f.resetErr
+	}
+	f.resets = append(f.resets, offsetResetCall{group: group, to: to, dryRun: dryRun})
+	return []admin.PartitionOffset{{Topic: "orders", Partition: 0, Offset: 0}}, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reportIssuers warns with IssuerNotFound condition when an Issuer
+// referenced by the TLS configuration doesn't exist in the namespace of the
+// cluster. Namespaced issuers can't sign certificates in other namespaces,
+// so the certificates would never be issued.
+func (r *ClusterReconciler) reportIssuers(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	tls := redpandaCluster.Spec.Configuration.TLS
+	names := map[string]bool{}
+	for _, listener := range []struct {
+		enabled   bool
+		issuerRef *cmmeta.ObjectReference
+	}{
+		{tls.KafkaAPI.Enabled, tls.KafkaAPI.IssuerRef},
+		{tls.AdminAPI.Enabled, tls.AdminAPI.IssuerRef},
+	} {
+		if !listener.enabled || listener.issuerRef == nil || listener.issuerRef.Name == "" {
+			continue
+		}
+		if listener.issuerRef.Kind == "" || listener.issuerRef.Kind == cmapiv1.IssuerKind {
+			names[listener.issuerRef.Name] = true
+		}
+	}
+	if len(names) == 0 {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.IssuerNotFoundConditionType, "No Issuer is referenced")
+	}
+
+	var missing []string
+	for name := range names {
+		var issuer cmapiv1.Issuer
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: redpandaCluster.Namespace}, &issuer)
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.IssuerNotFoundConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "IssuerFound",
+		Message: fmt.Sprintf("The referenced issuers exist in namespace %s", redpandaCluster.Namespace),
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "IssuerNotFound"
+		condition.Message = fmt.Sprintf(
+			"Issuer %s not found in namespace %s, an Issuer can only be referenced from its own namespace, use ClusterIssuer to share the issuer across namespaces",
+			strings.Join(missing, ", "), redpandaCluster.Namespace)
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
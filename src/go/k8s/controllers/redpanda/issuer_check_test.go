@@ -0,0 +1,97 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIssuerNotFound(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+	require.NoError(t, cmapiv1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "issuer",
+			Namespace: "redpanda",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "v21.4.12",
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+						Enabled:   true,
+						IssuerRef: &cmmeta.ObjectReference{Name: "local-issuer", Kind: "Issuer"},
+					},
+				},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity: resource.MustParse("10Gi"),
+			},
+		},
+	}
+	// the Issuer exists only in the namespace of cert-manager
+	shared := &cmapiv1.Issuer{ObjectMeta: metav1.ObjectMeta{Name: "local-issuer", Namespace: "cert-manager"}}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, shared).Build()
+
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	ctx := context.Background()
+	issuerNotFound := func() *metav1.Condition {
+		// the certificates are never issued with the fake client, only the
+		// condition reported before the resources are applied is verified
+		_, _ = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(ctx, key, &actual))
+		return meta.FindStatusCondition(actual.Status.Conditions, redpandav1alpha1.IssuerNotFoundConditionType)
+	}
+
+	condition := issuerNotFound()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Contains(t, condition.Message, "ClusterIssuer")
+
+	require.NoError(t, c.Create(ctx, &cmapiv1.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-issuer", Namespace: cluster.Namespace},
+	}))
+	condition = issuerNotFound()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportLocalVolumes warns with InsufficientLocalVolumes condition when the
+// storage class of the cluster is backed by local volumes and there are less
+// available persistent volumes than replicas. The brokers without a volume
+// stay Pending, the reconciliation of the cluster is not blocked.
+func (r *ClusterReconciler) reportLocalVolumes(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	available, local, err := resources.LocalVolumes(ctx, r.Client, redpandaCluster)
+	if err != nil {
+		return err
+	}
+	if !local || redpandaCluster.Spec.Replicas == nil {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.InsufficientLocalVolumesConditionType, "The storage class is not backed by local volumes")
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.InsufficientLocalVolumesConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "LocalVolumesAvailable",
+		Message: "A local persistent volume is available for every broker",
+	}
+	if replicas := int(*redpandaCluster.Spec.Replicas); replicas > available {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "InsufficientLocalVolumes"
+		condition.Message = fmt.Sprintf("%d replicas requested but only %d local persistent volumes of storage class %s are available",
+			replicas, available, redpandaCluster.Spec.Storage.StorageClassName)
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resetConsumerGroupOffsets performs the offsets reset requested with
+// ResetOffsetsAnnotation. The new offsets are committed only when the reset
+// is confirmed, otherwise they are just recorded in the status. The
+// annotations are removed once the outcome is known, so a reset is issued
+// once per request.
+func (r *ClusterReconciler) resetConsumerGroupOffsets(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	group := redpandaCluster.Annotations[redpandav1alpha1.ResetOffsetsAnnotation]
+	if group == "" || r.AdminAPIClientFactory == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil
+	}
+
+	to := redpandaCluster.Annotations[redpandav1alpha1.ResetOffsetsToAnnotation]
+	if to == "" {
+		to = redpandav1alpha1.OffsetResetEarliest
+	}
+	if to != redpandav1alpha1.OffsetResetEarliest && to != redpandav1alpha1.OffsetResetLatest {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "InvalidOffsetReset",
+			"Offsets of consumer group %s were not reset, %s must be %s or %s",
+			group, redpandav1alpha1.ResetOffsetsToAnnotation, redpandav1alpha1.OffsetResetEarliest, redpandav1alpha1.OffsetResetLatest)
+		return r.clearOffsetResetAnnotations(ctx, redpandaCluster)
+	}
+	dryRun := redpandaCluster.Annotations[redpandav1alpha1.ResetOffsetsConfirmAnnotation] != group
+
+	c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+	if err != nil {
+		return err
+	}
+	offsets, err := c.ResetConsumerGroupOffsets(ctx, group, to, dryRun)
+	if errors.Is(err, admin.ErrConsumerGroupActive) {
+		// the consumers have to be stopped first, the request is not retried
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "OffsetResetRejected",
+			"Offsets of consumer group %s were not reset, the group has active members", group)
+		return r.clearOffsetResetAnnotations(ctx, redpandaCluster)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to reset offsets of consumer group %s: %w", group, err)
+	}
+
+	status := &redpandav1alpha1.OffsetResetStatus{
+		Group:  group,
+		To:     to,
+		DryRun: dryRun,
+		Time:   metav1.Now(),
+	}
+	for _, o := range offsets {
+		status.Offsets = append(status.Offsets, redpandav1alpha1.PartitionOffset{
+			Topic:     o.Topic,
+			Partition: o.Partition,
+			Offset:    o.Offset,
+		})
+	}
+	if err = r.updateOffsetReset(ctx, redpandaCluster, status); err != nil {
+		return err
+	}
+
+	if dryRun {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, "OffsetResetDryRun",
+			"Offsets of consumer group %s computed for reset to %s, set %s to %s to commit them",
+			group, to, redpandav1alpha1.ResetOffsetsConfirmAnnotation, group)
+	} else {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeNormal, "OffsetsReset",
+			"Offsets of consumer group %s were reset to %s", group, to)
+	}
+	return r.clearOffsetResetAnnotations(ctx, redpandaCluster)
+}
+
+func (r *ClusterReconciler) updateOffsetReset(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	status *redpandav1alpha1.OffsetResetStatus,
+) error {
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.OffsetReset = status
+	})
+}
+
+func (r *ClusterReconciler) clearOffsetResetAnnotations(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	patch := client.MergeFrom(redpandaCluster.DeepCopy())
+	delete(redpandaCluster.Annotations, redpandav1alpha1.ResetOffsetsAnnotation)
+	delete(redpandaCluster.Annotations, redpandav1alpha1.ResetOffsetsToAnnotation)
+	delete(redpandaCluster.Annotations, redpandav1alpha1.ResetOffsetsConfirmAnnotation)
+	return r.Patch(ctx, redpandaCluster, patch)
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResetConsumerGroupOffsets(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		resetErr    error
+		expected    []offsetResetCall
+	}{
+		{
+			name:        "dry run without confirmation",
+			annotations: map[string]string{redpandav1alpha1.ResetOffsetsAnnotation: "billing"},
+			expected:    []offsetResetCall{{group: "billing", to: "earliest", dryRun: true}},
+		},
+		{
+			name: "confirmation of another group",
+			annotations: map[string]string{
+				redpandav1alpha1.ResetOffsetsAnnotation:        "billing",
+				redpandav1alpha1.ResetOffsetsConfirmAnnotation: "analytics",
+			},
+			expected: []offsetResetCall{{group: "billing", to: "earliest", dryRun: true}},
+		},
+		{
+			name: "confirmed reset to latest",
+			annotations: map[string]string{
+				redpandav1alpha1.ResetOffsetsAnnotation:        "billing",
+				redpandav1alpha1.ResetOffsetsToAnnotation:      "latest",
+				redpandav1alpha1.ResetOffsetsConfirmAnnotation: "billing",
+			},
+			expected: []offsetResetCall{{group: "billing", to: "latest", dryRun: false}},
+		},
+		{
+			name: "unsupported position",
+			annotations: map[string]string{
+				redpandav1alpha1.ResetOffsetsAnnotation:   "billing",
+				redpandav1alpha1.ResetOffsetsToAnnotation: "yesterday",
+			},
+		},
+		{
+			name: "active group",
+			annotations: map[string]string{
+				redpandav1alpha1.ResetOffsetsAnnotation:        "billing",
+				redpandav1alpha1.ResetOffsetsConfirmAnnotation: "billing",
+			},
+			resetErr: admin.ErrConsumerGroupActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(s))
+			require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "offsets",
+					Namespace: "default",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Image:    "vectorized/redpanda",
+					Version:  "latest",
+					Replicas: pointer.Int32Ptr(1),
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+						KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+						AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+					},
+					Storage: redpandav1alpha1.StorageSpec{
+						Capacity:         resource.MustParse("10Gi"),
+						StorageClassName: "local",
+					},
+				},
+			}
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+				Provisioner: resources.LocalVolumeProvisioner,
+			}
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+				Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+				Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+			api := &fakeAdminAPI{resetErr: tt.resetErr}
+			r := &redpandacontrollers.ClusterReconciler{
+				Client:   c,
+				Log:      ctrl.Log.WithName("test"),
+				Scheme:   s,
+				Recorder: record.NewFakeRecorder(100),
+				AdminAPIClientFactory: func(context.Context, client.Reader, *redpandav1alpha1.Cluster, string) (admin.AdminAPIClient, error) {
+					return api, nil
+				},
+			}
+			key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+			ctx := context.Background()
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			var sts appsv1.StatefulSet
+			require.NoError(t, c.Get(ctx, key, &sts))
+			sts.Status.ReadyReplicas = 1
+			require.NoError(t, c.Update(ctx, &sts))
+			require.NoError(t, c.Create(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "offsets-0",
+					Namespace: cluster.Namespace,
+					Labels:    labels.ForCluster(cluster),
+				},
+			}))
+
+			var actual redpandav1alpha1.Cluster
+			require.NoError(t, c.Get(ctx, key, &actual))
+			for k, v := range tt.annotations {
+				metav1.SetMetaDataAnnotation(&actual.ObjectMeta, k, v)
+			}
+			require.NoError(t, c.Update(ctx, &actual))
+
+			// the second reconciliation must not repeat the operation
+			for i := 0; i < 2; i++ {
+				_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expected, api.resets)
+			require.NoError(t, c.Get(ctx, key, &actual))
+			for k := range tt.annotations {
+				assert.NotContains(t, actual.Annotations, k)
+			}
+			if len(tt.expected) == 0 {
+				assert.Nil(t, actual.Status.OffsetReset)
+				return
+			}
+			require.NotNil(t, actual.Status.OffsetReset)
+			assert.Equal(t, "billing", actual.Status.OffsetReset.Group)
+			assert.Equal(t, tt.expected[0].to, actual.Status.OffsetReset.To)
+			assert.Equal(t, tt.expected[0].dryRun, actual.Status.OffsetReset.DryRun)
+			assert.Equal(t, []redpandav1alpha1.PartitionOffset{{Topic: "orders", Partition: 0, Offset: 0}},
+				actual.Status.OffsetReset.Offsets)
+		})
+	}
+}
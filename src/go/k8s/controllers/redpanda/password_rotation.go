@@ -0,0 +1,180 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"time"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// pendingPasswordKeySuffix is appended to the key of the superuser
+	// password to store the rotated password until the user is updated
+	pendingPasswordKeySuffix = ".pending"
+	// rotatedPasswordBytes is the entropy of generated passwords
+	rotatedPasswordBytes = 32
+	// passwordRotationRetryInterval is how often a failed rotation is retried
+	passwordRotationRetryInterval = time.Minute
+)
+
+// rotateSuperuserPasswords replaces the passwords of the superusers that
+// are due for rotation and returns the time until the next rotation. The
+// passwords must never be logged.
+//
+// The new password is persisted in the Secret before the SCRAM user is
+// updated, so a failed update is retried with the same password and the
+// password the user has is never lost. The referenced key is replaced only
+// after the user is updated.
+func (r *ClusterReconciler) rotateSuperuserPasswords(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (time.Duration, error) {
+	rotation := redpandaCluster.Spec.PasswordRotation
+	if rotation == nil || r.AdminAPIClientFactory == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return 0, nil
+	}
+	interval := time.Duration(rotation.IntervalHours) * time.Hour
+	now := time.Now()
+
+	lastRotations := map[string]metav1.Time{}
+	for _, s := range redpandaCluster.Status.PasswordRotations {
+		lastRotations[s.Username] = s.LastRotationTime
+	}
+
+	var c admin.AdminAPIClient
+	var rotateErr error
+	next := interval
+	statuses := []redpandav1alpha1.PasswordRotationStatus{}
+	for _, superuser := range redpandaCluster.Spec.Superusers {
+		ref := superuser.PasswordSecretKeyRef
+		if ref == nil {
+			continue
+		}
+		last, ok := lastRotations[superuser.Username]
+		if !ok {
+			// the schedule starts with the provisioned password
+			last = metav1.NewTime(now)
+		}
+		status := redpandav1alpha1.PasswordRotationStatus{Username: superuser.Username, LastRotationTime: last}
+
+		var secret corev1.Secret
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: redpandaCluster.Namespace}, &secret)
+		if err != nil {
+			rotateErr = fmt.Errorf("unable to fetch password of superuser %s: %w", superuser.Username, err)
+			statuses = append(statuses, status)
+			continue
+		}
+		_, pending := secret.Data[ref.Key+pendingPasswordKeySuffix]
+		due := last.Add(interval)
+		if !pending && now.Before(due) {
+			if until := due.Sub(now); until < next {
+				next = until
+			}
+			statuses = append(statuses, status)
+			continue
+		}
+
+		if c == nil {
+			c, err = r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+			if err != nil {
+				return passwordRotationRetryInterval, err
+			}
+		}
+		if err = r.rotatePassword(ctx, c, &secret, superuser.Username, ref.Key); err != nil {
+			rotateErr = fmt.Errorf("unable to rotate password of superuser %s: %w", superuser.Username, err)
+			statuses = append(statuses, status)
+			continue
+		}
+		status.LastRotationTime = metav1.NewTime(now)
+		statuses = append(statuses, status)
+	}
+
+	if err := r.updatePasswordRotations(ctx, redpandaCluster, statuses); err != nil {
+		return passwordRotationRetryInterval, err
+	}
+	if rotateErr != nil && passwordRotationRetryInterval < next {
+		next = passwordRotationRetryInterval
+	}
+	return next, rotateErr
+}
+
+// rotatePassword replaces the password stored under the key of the Secret.
+// A pending password left by a failed rotation is reused.
+func (r *ClusterReconciler) rotatePassword(
+	ctx context.Context,
+	c admin.AdminAPIClient,
+	secret *corev1.Secret,
+	username, key string,
+) error {
+	pendingKey := key + pendingPasswordKeySuffix
+	password, ok := secret.Data[pendingKey]
+	if !ok {
+		generated, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		password = []byte(generated)
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[pendingKey] = password
+		if err = r.Update(ctx, secret); err != nil {
+			return fmt.Errorf("unable to store pending password: %w", err)
+		}
+	}
+
+	if err := c.UpdateUser(ctx, username, string(password)); err != nil {
+		return err
+	}
+
+	// the update conflicts if the Secret changed since it was read, the
+	// pending password is then promoted by the next reconciliation
+	secret.Data[key] = password
+	delete(secret.Data, pendingKey)
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("unable to store rotated password: %w", err)
+	}
+	return nil
+}
+
+func (r *ClusterReconciler) updatePasswordRotations(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	statuses []redpandav1alpha1.PasswordRotationStatus,
+) error {
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	if reflect.DeepEqual(statuses, redpandaCluster.Status.PasswordRotations) {
+		return nil
+	}
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.PasswordRotations = statuses
+	})
+}
+
+// generatePassword returns a random password that is safe to use in
+// configuration files and URLs
+func generatePassword() (string, error) {
+	b := make([]byte, rotatedPasswordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSuperuserPasswordRotation(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	lastRotation := metav1.NewTime(time.Now().Add(-48 * time.Hour).Truncate(time.Second))
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rotation",
+			Namespace: "default",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "latest",
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity:         resource.MustParse("10Gi"),
+				StorageClassName: "local",
+			},
+			Superusers: []redpandav1alpha1.Superuser{{
+				Username: "admin",
+				PasswordSecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "admin-password"},
+					Key:                  "password",
+				},
+			}},
+			PasswordRotation: &redpandav1alpha1.PasswordRotationConfig{IntervalHours: 24},
+		},
+		Status: redpandav1alpha1.ClusterStatus{
+			PasswordRotations: []redpandav1alpha1.PasswordRotationStatus{
+				{Username: "admin", LastRotationTime: lastRotation},
+			},
+		},
+	}
+	password := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-password", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+		Provisioner: resources.LocalVolumeProvisioner,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, password, storageClass, pv).Build()
+
+	api := &fakeAdminAPI{updateErr: errors.New("connection reset")}
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+		AdminAPIClientFactory: func(context.Context, client.Reader, *redpandav1alpha1.Cluster, string) (admin.AdminAPIClient, error) {
+			return api, nil
+		},
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	secretKey := types.NamespacedName{Name: password.Name, Namespace: password.Namespace}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	var sts appsv1.StatefulSet
+	require.NoError(t, c.Get(context.Background(), key, &sts))
+	sts.Status.ReadyReplicas = 1
+	require.NoError(t, c.Update(context.Background(), &sts))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rotation-0",
+			Namespace: cluster.Namespace,
+			Labels:    labels.ForCluster(cluster),
+		},
+	}
+	require.NoError(t, c.Create(context.Background(), pod))
+
+	// the rotation is due, the new password is stored as pending before the
+	// user is updated and the current password is kept when the update fails
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"admin": "secret"}, api.users)
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	assert.Equal(t, "secret", string(secret.Data["password"]))
+	pending := string(secret.Data["password.pending"])
+	require.NotEmpty(t, pending)
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	require.Len(t, actual.Status.PasswordRotations, 1)
+	assert.True(t, lastRotation.Equal(&actual.Status.PasswordRotations[0].LastRotationTime))
+
+	// the retry updates the user with the pending password, which then
+	// replaces the current one
+	api.updateErr = nil
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"admin": pending}, api.users)
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	assert.Equal(t, map[string][]byte{"password": []byte(pending)}, secret.Data)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	require.Len(t, actual.Status.PasswordRotations, 1)
+	assert.True(t, actual.Status.PasswordRotations[0].LastRotationTime.After(lastRotation.Time))
+	assert.Greater(t, int64(result.RequeueAfter), int64(23*time.Hour))
+	assert.LessOrEqual(t, int64(result.RequeueAfter), int64(24*time.Hour))
+
+	// the password is not rotated again before the interval elapses
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"admin": pending}, api.users)
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	assert.Equal(t, pending, string(secret.Data["password"]))
+}
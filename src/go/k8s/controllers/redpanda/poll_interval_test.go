@@ -0,0 +1,141 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPollInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   func(*redpandav1alpha1.ClusterSpec)
+		polls  func(*fakeAdminAPI) int
+		polled func(*redpandav1alpha1.ClusterStatus) bool
+	}{
+		{
+			name:   "consumer lag",
+			spec:   func(spec *redpandav1alpha1.ClusterSpec) { spec.ReportConsumerLag = true },
+			polls:  func(api *fakeAdminAPI) int { return api.lagPolls },
+			polled: func(status *redpandav1alpha1.ClusterStatus) bool { return status.ConsumerLag != nil },
+		},
+		{
+			name:   "health",
+			spec:   func(spec *redpandav1alpha1.ClusterSpec) { spec.ReportHealth = true },
+			polls:  func(api *fakeAdminAPI) int { return api.healthPolls },
+			polled: func(status *redpandav1alpha1.ClusterStatus) bool { return status.Health != nil },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(s))
+			require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "poll",
+					Namespace: "default",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Image:    "vectorized/redpanda",
+					Version:  "latest",
+					Replicas: pointer.Int32Ptr(1),
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+						KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+						AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+					},
+					Storage: redpandav1alpha1.StorageSpec{
+						Capacity:         resource.MustParse("10Gi"),
+						StorageClassName: "local",
+					},
+				},
+			}
+			tt.spec(&cluster.Spec)
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+				Provisioner: resources.LocalVolumeProvisioner,
+			}
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+				Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+				Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+			api := &fakeAdminAPI{}
+			r := &redpandacontrollers.ClusterReconciler{
+				Client:   c,
+				Log:      ctrl.Log.WithName("test"),
+				Scheme:   s,
+				Recorder: record.NewFakeRecorder(100),
+				AdminAPIClientFactory: func(context.Context, client.Reader, *redpandav1alpha1.Cluster, string) (admin.AdminAPIClient, error) {
+					return api, nil
+				},
+			}
+			key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+			ctx := context.Background()
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			var sts appsv1.StatefulSet
+			require.NoError(t, c.Get(ctx, key, &sts))
+			sts.Status.ReadyReplicas = 1
+			require.NoError(t, c.Update(ctx, &sts))
+			require.NoError(t, c.Create(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "poll-0",
+					Namespace: cluster.Namespace,
+					Labels:    labels.ForCluster(cluster),
+				},
+			}))
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			assert.Equal(t, 1, tt.polls(api))
+
+			// the reconciliation triggered by the status update does not
+			// poll again
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			assert.Equal(t, 1, tt.polls(api))
+			assert.Greater(t, int64(result.RequeueAfter), int64(0))
+			assert.LessOrEqual(t, int64(result.RequeueAfter), int64(time.Minute))
+
+			var actual redpandav1alpha1.Cluster
+			require.NoError(t, c.Get(ctx, key, &actual))
+			assert.True(t, tt.polled(&actual.Status))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciliationPause(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "paused",
+			Namespace:   "default",
+			Annotations: map[string]string{redpandav1alpha1.ManagedAnnotation: "false"},
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "latest",
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity:         resource.MustParse("10Gi"),
+				StorageClassName: "local",
+			},
+		},
+	}
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+		Provisioner: resources.LocalVolumeProvisioner,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	cmKey := resources.ConfigMapKey(cluster)
+
+	reconcile := func() *metav1.Condition {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(context.Background(), key, &actual))
+		return meta.FindStatusCondition(actual.Status.Conditions, redpandav1alpha1.ReconciliationPausedConditionType)
+	}
+	setManaged := func(value string) {
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(context.Background(), key, &actual))
+		if value == "" {
+			delete(actual.Annotations, redpandav1alpha1.ManagedAnnotation)
+		} else {
+			actual.Annotations[redpandav1alpha1.ManagedAnnotation] = value
+		}
+		require.NoError(t, c.Update(context.Background(), &actual))
+	}
+
+	// nothing is created while the cluster is paused
+	condition := reconcile()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	var sts appsv1.StatefulSet
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), key, &sts)))
+	var cm corev1.ConfigMap
+	assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), cmKey, &cm)))
+
+	// resuming creates the resources
+	setManaged("true")
+	condition = reconcile()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	require.NoError(t, c.Get(context.Background(), key, &sts))
+	require.NoError(t, c.Get(context.Background(), cmKey, &cm))
+	expected := cm.Data
+
+	// manual changes are kept while the cluster is paused
+	setManaged("false")
+	cm.Data = map[string]string{"redpanda.yaml": "fixed by hand"}
+	require.NoError(t, c.Update(context.Background(), &cm))
+	condition = reconcile()
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	require.NoError(t, c.Get(context.Background(), cmKey, &cm))
+	assert.Equal(t, "fixed by hand", cm.Data["redpanda.yaml"])
+
+	// removing the annotation resumes the reconciliation
+	setManaged("")
+	condition = reconcile()
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	require.NoError(t, c.Get(context.Background(), cmKey, &cm))
+	assert.Equal(t, expected, cm.Data)
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reportResourceQuota warns with QuotaExceeded condition when a ResourceQuota
+// of the namespace doesn't leave room for the brokers added by a scale up,
+// which would be rejected at the admission and leave the StatefulSet short of
+// replicas
+func (r *ClusterReconciler) reportResourceQuota(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.Spec.VerifyResourceQuota {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.QuotaExceededConditionType, "Resource quota of the namespace is not validated")
+	}
+
+	var newPods int32
+	if redpandaCluster.Spec.Replicas != nil {
+		newPods = *redpandaCluster.Spec.Replicas
+	}
+	for _, set := range resources.BrokerStatefulSets(redpandaCluster) {
+		var sts appsv1.StatefulSet
+		err := r.Get(ctx, types.NamespacedName{Name: set.Name, Namespace: redpandaCluster.Namespace}, &sts)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if sts.Spec.Replicas != nil {
+			newPods -= *sts.Spec.Replicas
+		}
+	}
+
+	var quotas corev1.ResourceQuotaList
+	if err := r.List(ctx, &quotas, &client.ListOptions{Namespace: redpandaCluster.Namespace}); err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.QuotaExceededConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "QuotaAvailable",
+		Message: "The resource quota of the namespace leaves room for the brokers",
+	}
+	if exceeded := resources.ExceededQuotas(quotas.Items, redpandaCluster.ContainerResources(), newPods); len(exceeded) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "QuotaExceeded"
+		condition.Message = fmt.Sprintf("%d new brokers would exceed the resource quota: %s", newPods, strings.Join(exceeded, "; "))
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reportSANMismatch warns with SANMismatch condition when TLS clients would
+// reject an advertised address, because the node certificate of the listener
+// doesn't cover it. Certificates that are not issued yet are skipped.
+func (r *ClusterReconciler) reportSANMismatch(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	addresses map[types.NamespacedName][]string,
+) error {
+	if !redpandaCluster.Spec.Configuration.TLS.VerifySANs || len(addresses) == 0 {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.SANMismatchConditionType, "SANs of node certificates are not validated")
+	}
+
+	keys := make([]types.NamespacedName, 0, len(addresses))
+	for key := range addresses {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	var mismatches []string
+	for _, key := range keys {
+		var secret corev1.Secret
+		err := r.Get(ctx, key, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		missing, err := certmanager.MissingSANs(&secret, addresses[key])
+		if err != nil {
+			return fmt.Errorf("unable to parse certificate of Secret %s: %w", key, err)
+		}
+		if len(missing) > 0 {
+			mismatches = append(mismatches, fmt.Sprintf("%s doesn't cover %s", key.Name, strings.Join(missing, ", ")))
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.SANMismatchConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "SANsMatch",
+		Message: "Node certificates cover all advertised addresses",
+	}
+	if len(mismatches) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "AdvertisedAddressNotCovered"
+		condition.Message = fmt.Sprintf("Node certificate %s", strings.Join(mismatches, "; "))
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportSchedulability warns with Unschedulable condition when no node
+// accepts the brokers, e.g. because Tolerations don't match the taints of
+// the nodes selected by NodeSelector
+func (r *ClusterReconciler) reportSchedulability(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.Spec.VerifySchedulability {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.UnschedulableConditionType, "Schedulability of the brokers is not validated")
+	}
+
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return err
+	}
+	tolerations, nodeSelector := resources.BrokerPlacement(redpandaCluster)
+	schedulable := resources.SchedulableNodes(nodes.Items, tolerations, nodeSelector)
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.UnschedulableConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NodesAvailable",
+		Message: fmt.Sprintf("%d nodes accept the brokers", len(schedulable)),
+	}
+	if len(schedulable) == 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NoMatchingNodes"
+		condition.Message = fmt.Sprintf("None of %d nodes is schedulable, matches the node selector and has its taints tolerated, the brokers would stay Pending", len(nodes.Items))
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
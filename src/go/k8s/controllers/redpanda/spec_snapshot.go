@@ -0,0 +1,41 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"encoding/json"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// snapshotSpec records the spec in LastAppliedSpecAnnotation. It's called
+// only once all resources are applied, the brokers are ready and the topics,
+// superusers and ACLs are provisioned, so the annotation always holds a spec
+// that is known to work.
+func (r *ClusterReconciler) snapshotSpec(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	spec, err := json.Marshal(redpandaCluster.Spec)
+	if err != nil {
+		return err
+	}
+	if redpandaCluster.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation] == string(spec) {
+		return nil
+	}
+
+	patch := client.MergeFrom(redpandaCluster.DeepCopy())
+	if redpandaCluster.Annotations == nil {
+		redpandaCluster.Annotations = map[string]string{}
+	}
+	redpandaCluster.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation] = string(spec)
+	return r.Patch(ctx, redpandaCluster, patch)
+}
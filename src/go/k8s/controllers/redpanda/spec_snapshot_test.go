@@ -0,0 +1,170 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLastAppliedSpecSnapshot(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "snapshot",
+			Namespace: "default",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "latest",
+			Replicas: pointer.Int32Ptr(1),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity:         resource.MustParse("10Gi"),
+				StorageClassName: "local",
+			},
+			Superusers: []redpandav1alpha1.Superuser{{
+				Username: "admin",
+				PasswordSecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "admin-password"},
+					Key:                  "password",
+				},
+			}},
+		},
+	}
+	password := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-password", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+		Provisioner: resources.LocalVolumeProvisioner,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, password, storageClass, pv).Build()
+
+	// the cluster can't become Ready while the Admin API is unreachable
+	api := &fakeAdminAPI{err: errors.New("connection refused")}
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+		AdminAPIClientFactory: func(context.Context, client.Reader, *redpandav1alpha1.Cluster, string) (admin.AdminAPIClient, error) {
+			return api, nil
+		},
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	var sts appsv1.StatefulSet
+	require.NoError(t, c.Get(context.Background(), key, &sts))
+	sts.Status.ReadyReplicas = 1
+	require.NoError(t, c.Update(context.Background(), &sts))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "snapshot-0",
+			Namespace: cluster.Namespace,
+			Labels:    labels.ForCluster(cluster),
+		},
+	}
+	require.NoError(t, c.Create(context.Background(), pod))
+
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	assert.NotContains(t, actual.Annotations, redpandav1alpha1.LastAppliedSpecAnnotation)
+
+	// successful reconciliation records the spec
+	api.err = nil
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	applied, err := json.Marshal(actual.Spec)
+	require.NoError(t, err)
+	assert.Equal(t, string(applied), actual.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation])
+
+	// failed reconciliation of a changed spec keeps the last applied one
+	actual.Spec.LogLevel = "info"
+	require.NoError(t, c.Update(context.Background(), &actual))
+	api.err = errors.New("connection refused")
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	assert.Equal(t, string(applied), actual.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation])
+
+	// and records the change once it succeeds
+	api.err = nil
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	var snapshot redpandav1alpha1.ClusterSpec
+	require.NoError(t, json.Unmarshal([]byte(actual.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation]), &snapshot))
+	assert.Equal(t, "info", snapshot.LogLevel)
+	applied, err = json.Marshal(actual.Spec)
+	require.NoError(t, err)
+
+	// ready brokers don't record the spec until the ACLs are provisioned
+	actual.Spec.ACLs = []redpandav1alpha1.ACL{{
+		Principal:    "User:client",
+		ResourceType: "Topic",
+		ResourceName: "orders",
+		Operation:    "Read",
+	}}
+	require.NoError(t, c.Update(context.Background(), &actual))
+	api.aclErr = errors.New("authorization failed")
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	assert.Equal(t, string(applied), actual.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation])
+
+	api.aclErr = nil
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), key, &actual))
+	require.NoError(t, json.Unmarshal([]byte(actual.Annotations[redpandav1alpha1.LastAppliedSpecAnnotation]), &snapshot))
+	assert.Len(t, snapshot.ACLs, 1)
+}
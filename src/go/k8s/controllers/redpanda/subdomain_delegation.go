@@ -0,0 +1,49 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/dns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportSubdomainDelegation warns with SubdomainNotDelegated condition when
+// external clients won't be able to resolve the subdomain
+func (r *ClusterReconciler) reportSubdomainDelegation(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	externConn := redpandaCluster.Spec.ExternalConnectivity
+	if r.Resolver == nil || !externConn.Enabled || externConn.Subdomain == "" || externConn.SkipSubdomainValidation {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.SubdomainNotDelegatedConditionType, "Subdomain delegation is not validated")
+	}
+
+	delegated, err := dns.IsDelegated(ctx, r.Resolver, externConn.Subdomain)
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.SubdomainNotDelegatedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "SubdomainDelegated",
+		Message: fmt.Sprintf("Name servers are published for %s", externConn.Subdomain),
+	}
+	if !delegated {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NameServersNotFound"
+		condition.Message = fmt.Sprintf("No name servers are published for %s, external clients won't be able to resolve broker addresses", externConn.Subdomain)
+	}
+	return r.setCondition(ctx, redpandaCluster, condition)
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportSuperuserBootstrap confirms that SCRAM users of all superusers with
+// password exist and flags missing ones with SuperuserBootstrapFailed
+// condition. Error is returned until all superusers exist.
+func (r *ClusterReconciler) reportSuperuserBootstrap(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	var expected []string
+	for _, superuser := range redpandaCluster.Spec.Superusers {
+		if superuser.PasswordSecretKeyRef != nil {
+			expected = append(expected, superuser.Username)
+		}
+	}
+	if r.AdminAPIClientFactory == nil || len(expected) == 0 {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.SuperuserBootstrapFailedConditionType, "No superusers are provisioned")
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.SuperuserBootstrapFailedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "SuperusersExist",
+		Message: "All superusers exist",
+	}
+	missing, err := r.missingSuperusers(ctx, redpandaCluster, expected)
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "VerificationFailed"
+		condition.Message = err.Error()
+	case len(missing) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "SuperusersMissing"
+		condition.Message = fmt.Sprintf("Superusers do not exist: %s", strings.Join(missing, ", "))
+	}
+	if err = r.setCondition(ctx, redpandaCluster, condition); err != nil {
+		return err
+	}
+	if condition.Status == metav1.ConditionTrue {
+		return fmt.Errorf("%w: %s", errSuperuserBootstrapFailed, condition.Message)
+	}
+	return nil
+}
+
+func (r *ClusterReconciler) missingSuperusers(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, expected []string,
+) ([]string, error) {
+	if len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return nil, errBrokersNotReported
+	}
+	c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+	if err != nil {
+		return nil, err
+	}
+	users, err := c.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(users))
+	for _, u := range users {
+		existing[u] = true
+	}
+	var missing []string
+	for _, username := range expected {
+		if !existing[username] {
+			missing = append(missing, username)
+		}
+	}
+	return missing, nil
+}
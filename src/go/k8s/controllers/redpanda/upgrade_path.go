@@ -0,0 +1,89 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// upgradeNotFinishedRetryInterval is how often the feature state is checked
+// while the previous upgrade is not finished
+const upgradeNotFinishedRetryInterval = 30 * time.Second
+
+var errUpgradeNotFinished = errors.New("previous upgrade is not finished")
+
+// checkUpgradePath queries the feature state of the cluster with
+// PreUpgradeCheck before a new image is rolled out. The image is not rolled
+// out while the cluster version reported in the feature state is behind the
+// brokers, i.e. the previous upgrade is not finished, which is reported with
+// UnsupportedUpgradePath condition.
+func (r *ClusterReconciler) checkUpgradePath(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	c admin.AdminAPIClient,
+	log logr.Logger,
+) error {
+	if !redpandaCluster.Spec.PreUpgradeCheck {
+		return r.reportUpgradePathSupported(ctx, redpandaCluster)
+	}
+	features, err := c.Features(ctx)
+	if errors.Is(err, admin.ErrFeaturesNotSupported) {
+		log.Info("Feature state is not checked", "reason", err.Error())
+		return r.reportUpgradePathSupported(ctx, redpandaCluster)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get feature state: %w", err)
+	}
+	if !features.UpgradeInProgress() {
+		return r.reportUpgradePathSupported(ctx, redpandaCluster)
+	}
+
+	pathErr := fmt.Errorf("cluster version %d is behind broker version %d: %w",
+		features.ClusterVersion, features.NodeLatestVersion, errUpgradeNotFinished)
+	if !meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, redpandav1alpha1.UnsupportedUpgradePathConditionType) {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "UnsupportedUpgradePath",
+			"StatefulSet is not rolled out to %s: %v", redpandaCluster.Spec.Version, pathErr)
+	}
+	if err := r.setCondition(ctx, redpandaCluster, metav1.Condition{
+		Type:    redpandav1alpha1.UnsupportedUpgradePathConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "UpgradeNotFinished",
+		Message: pathErr.Error(),
+	}); err != nil {
+		log.Error(err, "Unable to set UnsupportedUpgradePath condition")
+	}
+	return pathErr
+}
+
+// reportUpgradePathSupported clears UnsupportedUpgradePath condition once
+// the previous upgrade is finished
+func (r *ClusterReconciler) reportUpgradePathSupported(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, redpandav1alpha1.UnsupportedUpgradePathConditionType) {
+		return nil
+	}
+	return r.setCondition(ctx, redpandaCluster, metav1.Condition{
+		Type:    redpandav1alpha1.UnsupportedUpgradePathConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "UpgradePathSupported",
+		Message: fmt.Sprintf("Version %s can be rolled out", redpandaCluster.Spec.Version),
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// reportUpgradePlan publishes the brokers the pending rolling upgrade
+// restarts in the status. The zone StatefulSets are upgraded one after
+// another, so their brokers are listed in the order of the StatefulSets.
+func (r *ClusterReconciler) reportUpgradePlan(
+	ctx context.Context,
+	redpandaCluster *redpandav1alpha1.Cluster,
+	statefulSets []*resources.StatefulSetResource,
+) error {
+	var plan *redpandav1alpha1.UpgradePlanStatus
+	for _, sts := range statefulSets {
+		stsPlan, err := sts.PlanUpgrade(ctx)
+		if err != nil {
+			return err
+		}
+		if stsPlan == nil || len(stsPlan.Steps) == 0 {
+			continue
+		}
+		if plan == nil {
+			plan = &redpandav1alpha1.UpgradePlanStatus{TargetImage: stsPlan.TargetImage}
+		}
+		for _, step := range stsPlan.Steps {
+			plan.Pods = append(plan.Pods, step.Pod)
+		}
+	}
+	if equality.Semantic.DeepEqual(redpandaCluster.Status.UpgradePlan, plan) {
+		return nil
+	}
+
+	return r.updateClusterStatus(ctx, redpandaCluster, func(cluster *redpandav1alpha1.Cluster) {
+		cluster.Status.UpgradePlan = plan
+	})
+}
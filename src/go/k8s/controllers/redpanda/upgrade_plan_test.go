@@ -0,0 +1,131 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpgradePlanWhilePaused(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "plan",
+			Namespace: "default",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Image:    "vectorized/redpanda",
+			Version:  "v21.4.12",
+			Replicas: pointer.Int32Ptr(2),
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+				KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+				AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+			},
+			Storage: redpandav1alpha1.StorageSpec{
+				Capacity:         resource.MustParse("10Gi"),
+				StorageClassName: "local",
+			},
+		},
+	}
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+		Provisioner: resources.LocalVolumeProvisioner,
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+	r := &redpandacontrollers.ClusterReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	ctx := context.Background()
+	reconcile := func() *redpandav1alpha1.Cluster {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(ctx, key, &actual))
+		return &actual
+	}
+
+	// the brokers run the initial version
+	reconcile()
+	var sts appsv1.StatefulSet
+	require.NoError(t, c.Get(ctx, key, &sts))
+	sts.Status.ReadyReplicas = 2
+	require.NoError(t, c.Update(ctx, &sts))
+	configHash, err := resources.ConfigHash(ctx, c, resources.ConfigMapKey(cluster))
+	require.NoError(t, err)
+	for _, name := range []string{"plan-0", "plan-1"} {
+		require.NoError(t, c.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   cluster.Namespace,
+				Labels:      labels.ForCluster(cluster),
+				Annotations: map[string]string{resources.ConfigHashAnnotation: configHash},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "redpanda", Image: "vectorized/redpanda:v21.4.12"}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}))
+	}
+	actual := reconcile()
+	assert.Nil(t, actual.Status.UpgradePlan)
+
+	// the upgrade is planned but not executed while paused
+	actual.Annotations = map[string]string{redpandav1alpha1.ManagedAnnotation: "false"}
+	actual.Spec.Version = "v21.5.1"
+	require.NoError(t, c.Update(ctx, actual))
+	actual = reconcile()
+	require.NotNil(t, actual.Status.UpgradePlan)
+	assert.Equal(t, "vectorized/redpanda:v21.5.1", actual.Status.UpgradePlan.TargetImage)
+	assert.Equal(t, []string{"plan-1", "plan-0"}, actual.Status.UpgradePlan.Pods)
+	require.NoError(t, c.Get(ctx, key, &sts))
+	assert.Equal(t, "vectorized/redpanda:v21.4.12", sts.Spec.Template.Spec.Containers[0].Image)
+
+	// the plan is removed with the pending change
+	actual.Spec.Version = "v21.4.12"
+	require.NoError(t, c.Update(ctx, actual))
+	actual = reconcile()
+	assert.Nil(t, actual.Status.UpgradePlan)
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/version"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// isVersionTransitionBlocked returns true for errors of disallowed version
+// transitions, they are not retried until the Cluster changes
+func isVersionTransitionBlocked(err error) bool {
+	return errors.Is(err, version.ErrDowngrade) || errors.Is(err, version.ErrMajorUpgrade)
+}
+
+// checkVersionTransition compares the version running on the brokers, as
+// reported by the Admin API, with the Version of the Cluster before the
+// StatefulSet is rolled out to a new image. Downgrades and upgrades skipping
+// a major version are rejected unless AllowDowngrade is set, after the
+// upgrade path is checked, see checkUpgradePath. Tags that are not release
+// versions, e.g. latest, are not checked.
+func (r *ClusterReconciler) checkVersionTransition(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster, log logr.Logger,
+) error {
+	if r.AdminAPIClientFactory == nil || len(redpandaCluster.Status.Nodes.Internal) == 0 {
+		return r.reportVersionTransitionAllowed(ctx, redpandaCluster)
+	}
+	desired, err := version.Parse(redpandaCluster.Spec.Version)
+	if err != nil {
+		log.Info("Version transition is not checked", "reason", err.Error())
+		return r.reportVersionTransitionAllowed(ctx, redpandaCluster)
+	}
+
+	changed, err := r.brokerImageChanged(ctx, redpandaCluster)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return r.reportVersionTransitionAllowed(ctx, redpandaCluster)
+	}
+
+	c, err := r.AdminAPIClientFactory(ctx, r.Client, redpandaCluster, redpandaCluster.Status.Nodes.Internal[0])
+	if err != nil {
+		return err
+	}
+	// the unfinished upgrade is not forced, it resolves itself
+	if err := r.checkUpgradePath(ctx, redpandaCluster, c, log); err != nil {
+		return err
+	}
+	versions, err := c.BrokerVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get versions of the brokers: %w", err)
+	}
+	transitionErr := checkBrokerVersions(versions, desired)
+	if transitionErr == nil {
+		return r.reportVersionTransitionAllowed(ctx, redpandaCluster)
+	}
+
+	if redpandaCluster.Spec.AllowDowngrade {
+		log.Info("Rolling out disallowed version transition", "reason", transitionErr.Error())
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "VersionTransitionForced",
+			"Rolling out %s as allowDowngrade is set: %v", redpandaCluster.Spec.Version, transitionErr)
+		return r.reportVersionTransitionAllowed(ctx, redpandaCluster)
+	}
+
+	reason := "DowngradeNotAllowed"
+	if errors.Is(transitionErr, version.ErrMajorUpgrade) {
+		reason = "MajorUpgradeNotAllowed"
+	}
+	if !meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, redpandav1alpha1.VersionTransitionBlockedConditionType) {
+		r.Recorder.Eventf(redpandaCluster, corev1.EventTypeWarning, "VersionTransitionBlocked",
+			"StatefulSet is not rolled out to %s: %v", redpandaCluster.Spec.Version, transitionErr)
+	}
+	if err := r.setCondition(ctx, redpandaCluster, metav1.Condition{
+		Type:    redpandav1alpha1.VersionTransitionBlockedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: transitionErr.Error(),
+	}); err != nil {
+		log.Error(err, "Unable to set VersionTransitionBlocked condition")
+	}
+	return transitionErr
+}
+
+// checkBrokerVersions returns the first disallowed transition of the
+// brokers ordered by node ID. Versions that can't be parsed are skipped.
+func checkBrokerVersions(versions map[int]string, desired version.Version) error {
+	ids := make([]int, 0, len(versions))
+	for id := range versions {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		current, err := version.Parse(versions[id])
+		if err != nil {
+			continue
+		}
+		if err := version.CheckTransition(current, desired); err != nil {
+			return fmt.Errorf("broker %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// brokerImageChanged returns true if any of the existing StatefulSets of the
+// brokers runs other image than the Cluster
+func (r *ClusterReconciler) brokerImageChanged(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) (bool, error) {
+	for _, set := range resources.BrokerStatefulSets(redpandaCluster) {
+		var sts appsv1.StatefulSet
+		err := r.Get(ctx, types.NamespacedName{Name: set.Name, Namespace: redpandaCluster.Namespace}, &sts)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("unable to retrieve StatefulSet %s: %w", set.Name, err)
+		}
+		if imageChanged(&sts, redpandaCluster.FullImageName()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// imageChanged returns true if the redpanda container of the StatefulSet
+// doesn't run the image
+func imageChanged(sts *appsv1.StatefulSet, image string) bool {
+	for _, container := range sts.Spec.Template.Spec.Containers {
+		if container.Name == resources.RedpandaContainerName {
+			return container.Image != image
+		}
+	}
+	return false
+}
+
+// reportVersionTransitionAllowed clears VersionTransitionBlocked and
+// UnsupportedUpgradePath conditions once the Cluster can be rolled out
+func (r *ClusterReconciler) reportVersionTransitionAllowed(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if err := r.reportUpgradePathSupported(ctx, redpandaCluster); err != nil {
+		return err
+	}
+	if !meta.IsStatusConditionTrue(redpandaCluster.Status.Conditions, redpandav1alpha1.VersionTransitionBlockedConditionType) {
+		return nil
+	}
+	return r.setCondition(ctx, redpandaCluster, metav1.Condition{
+		Type:    redpandav1alpha1.VersionTransitionBlockedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "VersionTransitionAllowed",
+		Message: fmt.Sprintf("Version %s can be rolled out", redpandaCluster.Spec.Version),
+	})
+}
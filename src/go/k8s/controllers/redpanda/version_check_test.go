@@ -0,0 +1,200 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVersionTransition(t *testing.T) {
+	upgraded := &admin.Features{ClusterVersion: 3, NodeLatestVersion: 3}
+	inProgress := &admin.Features{ClusterVersion: 2, NodeLatestVersion: 3}
+	tests := []struct {
+		name            string
+		version         string
+		allowDowngrade  bool
+		preUpgradeCheck bool
+		features        *admin.Features
+		rolledOut       bool
+		blockedReason   string
+		condition       string
+		requeue         bool
+		event           string
+	}{
+		{name: "upgrade", version: "v21.5.1", rolledOut: true},
+		{name: "no-op", version: "v21.4.12", rolledOut: true},
+		{name: "downgrade is blocked", version: "v21.4.11", blockedReason: "DowngradeNotAllowed", event: "VersionTransitionBlocked"},
+		{name: "multi-major upgrade is blocked", version: "v23.1.1", blockedReason: "MajorUpgradeNotAllowed", event: "VersionTransitionBlocked"},
+		{name: "forced downgrade", version: "v21.4.11", allowDowngrade: true, rolledOut: true, event: "VersionTransitionForced"},
+		{name: "forced multi-major upgrade", version: "v23.1.1", allowDowngrade: true, preUpgradeCheck: true, features: upgraded, rolledOut: true, event: "VersionTransitionForced"},
+		{name: "finished upgrade", version: "v21.5.1", preUpgradeCheck: true, features: upgraded, rolledOut: true},
+		{name: "next major version", version: "v22.1.1", preUpgradeCheck: true, features: upgraded, rolledOut: true},
+		{name: "previous upgrade not finished", version: "v21.5.1", preUpgradeCheck: true, features: inProgress, blockedReason: "UpgradeNotFinished", condition: redpandav1alpha1.UnsupportedUpgradePathConditionType, requeue: true, event: "UnsupportedUpgradePath"},
+		{name: "unfinished upgrade is not forced", version: "v21.5.1", allowDowngrade: true, preUpgradeCheck: true, features: inProgress, blockedReason: "UpgradeNotFinished", condition: redpandav1alpha1.UnsupportedUpgradePathConditionType, requeue: true, event: "UnsupportedUpgradePath"},
+		{name: "feature state not supported", version: "v21.5.1", preUpgradeCheck: true, rolledOut: true},
+		{name: "feature state not checked", version: "v21.5.1", features: inProgress, rolledOut: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(s))
+			require.NoError(t, redpandav1alpha1.AddToScheme(s))
+
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "versions",
+					Namespace: "default",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Image:    "vectorized/redpanda",
+					Version:  "v21.4.12",
+					Replicas: pointer.Int32Ptr(1),
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						RPCServer: redpandav1alpha1.SocketAddress{Port: 33145},
+						KafkaAPI:  redpandav1alpha1.SocketAddress{Port: 9092},
+						AdminAPI:  redpandav1alpha1.SocketAddress{Port: 9644},
+					},
+					Storage: redpandav1alpha1.StorageSpec{
+						Capacity:         resource.MustParse("10Gi"),
+						StorageClassName: "local",
+					},
+				},
+			}
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+				Provisioner: resources.LocalVolumeProvisioner,
+			}
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+				Spec:       corev1.PersistentVolumeSpec{StorageClassName: "local"},
+				Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, storageClass, pv).Build()
+
+			api := &fakeAdminAPI{
+				versions: map[int]string{0: "v21.4.12 (rev 6c1b5f6)"},
+				features: tt.features,
+			}
+			recorder := record.NewFakeRecorder(100)
+			r := &redpandacontrollers.ClusterReconciler{
+				Client:   c,
+				Log:      ctrl.Log.WithName("test"),
+				Scheme:   s,
+				Recorder: recorder,
+				AdminAPIClientFactory: func(context.Context, client.Reader, *redpandav1alpha1.Cluster, string) (admin.AdminAPIClient, error) {
+					return api, nil
+				},
+			}
+			key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+			ctx := context.Background()
+
+			// the broker runs the initial version
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			var sts appsv1.StatefulSet
+			require.NoError(t, c.Get(ctx, key, &sts))
+			sts.Status.ReadyReplicas = 1
+			require.NoError(t, c.Update(ctx, &sts))
+			configHash, err := resources.ConfigHash(ctx, c, resources.ConfigMapKey(cluster))
+			require.NoError(t, err)
+			require.NoError(t, c.Create(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "versions-0",
+					Namespace:   cluster.Namespace,
+					Labels:      labels.ForCluster(cluster),
+					Annotations: map[string]string{resources.ConfigHashAnnotation: configHash},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "redpanda", Image: "vectorized/redpanda:v21.4.12"}},
+				},
+			}))
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+
+			var actual redpandav1alpha1.Cluster
+			require.NoError(t, c.Get(ctx, key, &actual))
+			require.NotEmpty(t, actual.Status.Nodes.Internal)
+			actual.Spec.Version = tt.version
+			actual.Spec.AllowDowngrade = tt.allowDowngrade
+			actual.Spec.PreUpgradeCheck = tt.preUpgradeCheck
+			require.NoError(t, c.Update(ctx, &actual))
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			if !tt.rolledOut {
+				// only the unfinished upgrade can resolve itself
+				assert.Equal(t, tt.requeue, result.RequeueAfter > 0)
+			}
+
+			require.NoError(t, c.Get(ctx, key, &sts))
+			image := "vectorized/redpanda:v21.4.12"
+			if tt.rolledOut {
+				image = "vectorized/redpanda:" + tt.version
+			}
+			assert.Equal(t, image, sts.Spec.Template.Spec.Containers[0].Image)
+
+			require.NoError(t, c.Get(ctx, key, &actual))
+			conditionType := redpandav1alpha1.VersionTransitionBlockedConditionType
+			if tt.condition != "" {
+				conditionType = tt.condition
+			}
+			condition := meta.FindStatusCondition(actual.Status.Conditions, conditionType)
+			if tt.blockedReason == "" {
+				assert.Nil(t, condition)
+				assert.Nil(t, meta.FindStatusCondition(actual.Status.Conditions, redpandav1alpha1.UnsupportedUpgradePathConditionType))
+			} else {
+				require.NotNil(t, condition)
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				assert.Equal(t, tt.blockedReason, condition.Reason)
+				// the unfinished upgrade is waited for, the disallowed
+				// transitions stall the Cluster until it's changed
+				assert.Equal(t, !tt.requeue, meta.IsStatusConditionTrue(actual.Status.Conditions, redpandav1alpha1.StalledConditionType))
+				assert.Equal(t, tt.requeue, meta.IsStatusConditionTrue(actual.Status.Conditions, redpandav1alpha1.ReconcilingConditionType))
+			}
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			found := false
+			for _, e := range events {
+				found = found || (tt.event != "" && strings.Contains(e, tt.event))
+			}
+			assert.Equal(t, tt.event != "", found, "events: %v", events)
+		})
+	}
+}
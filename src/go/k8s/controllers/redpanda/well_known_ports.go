@@ -0,0 +1,44 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package redpanda
+
+import (
+	"context"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportWellKnownPorts warns with WellKnownPortInUse condition when a port
+// exposed outside of the Kubernetes cluster is commonly used on the nodes.
+// The port is not reserved by Kubernetes, so the webhook accepts it, but the
+// brokers may not be reachable on the nodes where it is taken.
+func (r *ClusterReconciler) reportWellKnownPorts(
+	ctx context.Context, redpandaCluster *redpandav1alpha1.Cluster,
+) error {
+	if !redpandaCluster.Spec.ExternalConnectivity.Enabled {
+		return r.clearCondition(ctx, redpandaCluster,
+			redpandav1alpha1.WellKnownPortInUseConditionType, "The brokers are not exposed outside of the Kubernetes cluster")
+	}
+
+	condition := metav1.Condition{
+		Type:    redpandav1alpha1.WellKnownPortInUseConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "PortsAvailable",
+		Message: "The exposed ports are not commonly used on the nodes",
+	}
+	if inUse := redpandaCluster.WellKnownPortsInUse(); len(inUse) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "WellKnownPortInUse"
+		condition.Message = strings.Join(inUse, "; ")
+	}
+	return r.setWarningCondition(ctx, redpandaCluster, condition)
+}
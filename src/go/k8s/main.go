@@ -10,11 +10,18 @@ package main
 
 import (
 	"flag"
+	"net"
 	"os"
+	"time"
 
 	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	redpandacontrollers "github.com/vectorizedio/redpanda/src/go/k8s/controllers/redpanda"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/cloudstorage"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -44,6 +51,10 @@ func main() {
 		probeAddr            string
 		webhookEnabled       bool
 		configuratorTag      string
+		pauseImage           string
+		clusterLabelSelector string
+		certIssuanceStagger  time.Duration
+		maxRollingRestarts   int
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -53,6 +64,17 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&webhookEnabled, "webhook-enabled", false, "Enable webhook Manager")
 	flag.StringVar(&configuratorTag, "configurator-tag", "latest", "Set the configurator tag")
+	flag.StringVar(&pauseImage, "prepull-pause-image", resources.DefaultPauseImage,
+		"Set the image keeping the Pods of the image pre-pull DaemonSet running")
+	flag.StringVar(&clusterLabelSelector, "cluster-label-selector", "",
+		"Reconcile only Cluster resources matching the label selector. "+
+			"Allows sharding clusters between operator instances.")
+	flag.IntVar(&maxRollingRestarts, "max-concurrent-rolling-restarts", 0,
+		"Maximal number of clusters running the rolling update at once, the other clusters wait for their turn. "+
+			"Avoids overwhelming shared infrastructure when many clusters are upgraded at once. Unlimited when 0.")
+	flag.DurationVar(&certIssuanceStagger, "cert-issuance-stagger", 0,
+		"Minimal interval between creating certificates of different clusters, extended by random jitter. "+
+			"Avoids hitting issuer rate limits (e.g. ACME) when many clusters are created at once. Disabled when 0.")
 
 	opts := zap.Options{
 		Development: true,
@@ -64,6 +86,12 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	clusterSelector, err := labels.Parse(clusterLabelSelector)
+	if err != nil {
+		setupLog.Error(err, "Unable to parse cluster label selector")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -77,11 +105,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	var certStagger *certmanager.IssuanceStagger
+	if certIssuanceStagger > 0 {
+		certStagger = certmanager.NewIssuanceStagger(certIssuanceStagger)
+	}
+
+	var restartLimiter *resources.RestartLimiter
+	if maxRollingRestarts > 0 {
+		restartLimiter = resources.NewRestartLimiter(maxRollingRestarts)
+	}
+
 	if err = (&redpandacontrollers.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("redpanda").WithName("Cluster"),
-		Scheme: mgr.GetScheme(),
-	}).WithConfiguratorTag(configuratorTag).SetupWithManager(mgr); err != nil {
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("redpanda").WithName("Cluster"),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("redpanda-cluster-controller"),
+		AdminAPIClientFactory: admin.NewAdminAPIClient,
+		Resolver:              net.DefaultResolver,
+		CloudStorageChecker:   cloudstorage.NewS3Checker(),
+		RESTMapper:            mgr.GetRESTMapper(),
+	}).WithConfiguratorTag(configuratorTag).WithPauseImage(pauseImage).WithClusterLabelSelector(clusterSelector).
+		WithCertIssuanceStagger(certStagger).WithRestartLimiter(restartLimiter).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "Cluster")
 		os.Exit(1)
 	}
@@ -0,0 +1,98 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// AnyHost matches connections from all hosts
+	AnyHost = "*"
+	// LiteralPattern matches the resource name exactly
+	LiteralPattern = "Literal"
+	// AllowPermission grants the operation
+	AllowPermission = "Allow"
+)
+
+// ACL is a Kafka ACL binding. The fields use the names of the Kafka
+// resource types, pattern types, operations and permissions, e.g. Topic,
+// Prefixed, Read and Allow.
+type ACL struct {
+	Principal    string
+	Host         string
+	ResourceType string
+	ResourceName string
+	PatternType  string
+	Operation    string
+	Permission   string
+}
+
+// WithDefaults returns the ACL with the optional fields set, so equal ACLs
+// can be compared
+func (a ACL) WithDefaults() ACL {
+	if a.Host == "" {
+		a.Host = AnyHost
+	}
+	if a.PatternType == "" {
+		a.PatternType = LiteralPattern
+	}
+	if a.Permission == "" {
+		a.Permission = AllowPermission
+	}
+	return a
+}
+
+func (a ACL) String() string {
+	return fmt.Sprintf("%s %s %s on %s %s:%s from %s",
+		a.Permission, a.Principal, a.Operation, a.ResourceType, a.PatternType, a.ResourceName, a.Host)
+}
+
+// SyncACLs creates the desired ACLs that don't exist yet and deletes the
+// previously provisioned ACLs that are no longer desired. ACLs created by
+// others are never deleted. The order of the ACLs doesn't matter and
+// repeated calls don't change existing ACLs.
+func SyncACLs(
+	ctx context.Context, c AdminAPIClient, desired, provisioned []ACL,
+) error {
+	acls, err := c.ListACLs(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list ACLs: %w", err)
+	}
+	existing := make(map[ACL]bool, len(acls))
+	for _, acl := range acls {
+		existing[acl.WithDefaults()] = true
+	}
+	wanted := make(map[ACL]bool, len(desired))
+	for _, acl := range desired {
+		wanted[acl.WithDefaults()] = true
+	}
+
+	for acl := range wanted {
+		if existing[acl] {
+			continue
+		}
+		if err = c.CreateACL(ctx, acl); err != nil {
+			return fmt.Errorf("unable to create ACL %s: %w", acl, err)
+		}
+	}
+
+	for _, acl := range provisioned {
+		acl = acl.WithDefaults()
+		if wanted[acl] || !existing[acl] {
+			continue
+		}
+		if err = c.DeleteACL(ctx, acl); err != nil {
+			return fmt.Errorf("unable to delete ACL %s: %w", acl, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+// aclsAdminAPI records the calls managing ACLs
+type aclsAdminAPI struct {
+	fakeAdminAPI
+	acls    []admin.ACL
+	created []admin.ACL
+	deleted []admin.ACL
+}
+
+func (f *aclsAdminAPI) ListACLs(context.Context) ([]admin.ACL, error) {
+	return f.acls, nil
+}
+
+func (f *aclsAdminAPI) CreateACL(_ context.Context, acl admin.ACL) error {
+	f.created = append(f.created, acl)
+	return nil
+}
+
+func (f *aclsAdminAPI) DeleteACL(_ context.Context, acl admin.ACL) error {
+	f.deleted = append(f.deleted, acl)
+	return nil
+}
+
+func TestSyncACLs(t *testing.T) {
+	read := admin.ACL{
+		Principal:    "User:client",
+		Host:         admin.AnyHost,
+		ResourceType: "Topic",
+		ResourceName: "orders",
+		PatternType:  admin.LiteralPattern,
+		Operation:    "Read",
+		Permission:   admin.AllowPermission,
+	}
+	write := read
+	write.Operation = "Write"
+	foreign := read
+	foreign.Principal = "User:other"
+
+	tests := []struct {
+		name        string
+		existing    []admin.ACL
+		desired     []admin.ACL
+		provisioned []admin.ACL
+		created     []admin.ACL
+		deleted     []admin.ACL
+	}{
+		{
+			name:     "add",
+			existing: []admin.ACL{foreign},
+			desired:  []admin.ACL{read},
+			created:  []admin.ACL{read},
+		},
+		{
+			name: "add with defaults",
+			desired: []admin.ACL{{
+				Principal:    "User:client",
+				ResourceType: "Topic",
+				ResourceName: "orders",
+				Operation:    "Read",
+			}},
+			created: []admin.ACL{read},
+		},
+		{
+			name:        "remove",
+			existing:    []admin.ACL{read, write, foreign},
+			desired:     []admin.ACL{read},
+			provisioned: []admin.ACL{read, write},
+			deleted:     []admin.ACL{write},
+		},
+		{
+			name:        "no-op",
+			existing:    []admin.ACL{write, read, foreign},
+			desired:     []admin.ACL{read, write},
+			provisioned: []admin.ACL{write, read},
+		},
+		{
+			name:        "removed ACL already gone",
+			existing:    []admin.ACL{foreign},
+			provisioned: []admin.ACL{read},
+		},
+		{
+			name:        "foreign ACLs are kept",
+			existing:    []admin.ACL{foreign},
+			provisioned: []admin.ACL{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &aclsAdminAPI{acls: tt.existing}
+
+			err := admin.SyncACLs(context.Background(), api, tt.desired, tt.provisioned)
+			require.NoError(t, err)
+			assert.Equal(t, tt.created, api.created)
+			assert.Equal(t, tt.deleted, api.deleted)
+		})
+	}
+}
+
+func TestSyncACLsError(t *testing.T) {
+	err := admin.SyncACLs(context.Background(), &fakeAdminAPI{err: errUnreachable}, nil, nil)
+	assert.ErrorIs(t, err, errUnreachable)
+}
@@ -0,0 +1,565 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package admin contains a client for Redpanda Admin API used by the operator
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// controllerPartitionPath is the Admin API path of the raft0 partition
+	// that holds the cluster controller
+	controllerPartitionPath = "/v1/partitions/redpanda/controller/0"
+	// brokersPath is the Admin API path listing the cluster members
+	brokersPath = "/v1/brokers"
+	// topicsPath is the Admin API path of the topics of the kafka namespace
+	topicsPath = "/v1/topics"
+	// usersPath is the Admin API path of the SCRAM users
+	usersPath = "/v1/security/users"
+	// featuresPath is the Admin API path of the cluster feature state
+	featuresPath = "/v1/features"
+	// metricsPath is the path of the Prometheus metrics served by the
+	// Admin API listener
+	metricsPath = "/metrics"
+	// underReplicatedMetric is the number of under-replicated replicas of a
+	// partition led by the broker
+	underReplicatedMetric = "vectorized_cluster_partition_under_replicated_replicas"
+
+	// NoLeader is reported by a broker that does not know the controller leader
+	NoLeader = -1
+
+	requestTimeout = 5 * time.Second
+)
+
+var errUnexpectedStatus = errors.New("unexpected Admin API response status")
+
+var errInvalidCA = errors.New("no PEM encoded CA certificate")
+
+// errNotFound is returned for the paths not served by the broker, e.g. by
+// older versions
+var errNotFound = fmt.Errorf("%w: not found", errUnexpectedStatus)
+
+var (
+	// ErrTopicAlreadyExists is returned by CreateTopic if the topic exists
+	ErrTopicAlreadyExists = errors.New("topic already exists")
+	// ErrUserAlreadyExists is returned by CreateUser if the user exists
+	ErrUserAlreadyExists = errors.New("user already exists")
+	// ErrFeaturesNotSupported is returned by Features if the broker
+	// doesn't report the feature state
+	ErrFeaturesNotSupported = errors.New("feature state is not supported by the broker")
+	// ErrDecommissionProgressNotSupported is returned by
+	// DecommissionProgress if the broker doesn't report the progress
+	ErrDecommissionProgressNotSupported = errors.New("decommission progress is not supported by the broker")
+	// ErrBrokerTimeNotReported is returned by BrokerTime if the Admin API
+	// response has no Date header
+	ErrBrokerTimeNotReported = errors.New("broker time is not reported")
+	// ErrConsumerGroupActive is returned by ResetConsumerGroupOffsets if the
+	// group has active members, their commits would override the reset
+	ErrConsumerGroupActive = errors.New("consumer group has active members")
+	// ErrUnknownOffsetReset is returned by ResetConsumerGroupOffsets if the
+	// offsets are reset to neither earliest nor latest
+	ErrUnknownOffsetReset = errors.New("offsets can be reset to earliest or latest only")
+)
+
+// AdminAPIClient is a subset of Redpanda Admin API of a single broker
+type AdminAPIClient interface {
+	// ControllerLeader returns the node ID of the controller leader as seen
+	// by the broker
+	ControllerLeader(ctx context.Context) (int, error)
+	// ConsumerGroupLags returns the lag of every consumer group, summed
+	// over all partitions the group committed offsets in
+	ConsumerGroupLags(ctx context.Context) ([]ConsumerGroupLag, error)
+	// DecommissionBroker starts moving all partitions away from the broker
+	// with the given node ID. The broker leaves the cluster once it holds
+	// no replicas.
+	DecommissionBroker(ctx context.Context, nodeID int) error
+	// IsBrokerDecommissioned returns true if the broker with the given node
+	// ID is no longer a member of the cluster
+	IsBrokerDecommissioned(ctx context.Context, nodeID int) (bool, error)
+	// DecommissionReplicasLeft returns the number of partition replicas
+	// still to be moved away from the decommissioned broker
+	DecommissionReplicasLeft(ctx context.Context, nodeID int) (int, error)
+	// CreateTopic creates the topic, ErrTopicAlreadyExists is returned if
+	// the topic exists
+	CreateTopic(ctx context.Context, topic Topic) error
+	// ListUsers returns the names of the SCRAM users
+	ListUsers(ctx context.Context) ([]string, error)
+	// CreateUser creates SCRAM user with the mechanism of the superuser,
+	// ErrUserAlreadyExists is returned if the user exists
+	CreateUser(ctx context.Context, username, password string) error
+	// DeleteUser deletes SCRAM user, deleting missing user is not an error
+	DeleteUser(ctx context.Context, username string) error
+	// UpdateUser changes the password of SCRAM user
+	UpdateUser(ctx context.Context, username, password string) error
+	// UnderReplicatedPartitions returns the number of under-replicated
+	// replicas of the partitions led by the broker
+	UnderReplicatedPartitions(ctx context.Context) (int64, error)
+	// BrokerVersions returns the Redpanda version of every cluster member
+	// by node ID
+	BrokerVersions(ctx context.Context) (map[int]string, error)
+	// Features returns the logical version of the cluster and the latest
+	// logical version supported by the broker
+	Features(ctx context.Context) (Features, error)
+	// ListACLs returns all ACLs of the cluster
+	ListACLs(ctx context.Context) ([]ACL, error)
+	// CreateACL creates the ACL, creating existing ACL is not an error
+	CreateACL(ctx context.Context, acl ACL) error
+	// DeleteACL deletes the ACL, deleting missing ACL is not an error
+	DeleteACL(ctx context.Context, acl ACL) error
+	// BrokerTime returns the time of the broker clock with one second
+	// precision
+	BrokerTime(ctx context.Context) (time.Time, error)
+	// ResetConsumerGroupOffsets moves the committed offsets of the group to
+	// the earliest or latest offsets of the partitions it consumes and
+	// returns the new offsets. Nothing is committed when dryRun is set.
+	ResetConsumerGroupOffsets(ctx context.Context, group, to string, dryRun bool) ([]PartitionOffset, error)
+}
+
+// Topic is a Kafka topic created through the Admin API
+type Topic struct {
+	Name              string `json:"topic"`
+	Partitions        int    `json:"partition_count"`
+	ReplicationFactor int    `json:"replication_factor"`
+}
+
+// Features is the feature state of the cluster. The cluster version is
+// raised to the latest version of the brokers once all of them run it.
+type Features struct {
+	ClusterVersion    int `json:"cluster_version"`
+	NodeLatestVersion int `json:"node_latest_version"`
+}
+
+// UpgradeInProgress returns true if the cluster version didn't catch up with
+// the broker yet, i.e. not all brokers were upgraded
+func (f Features) UpgradeInProgress() bool {
+	return f.ClusterVersion < f.NodeLatestVersion
+}
+
+// ConsumerGroupLag is the number of messages the consumer group is behind
+// the high watermarks
+type ConsumerGroupLag struct {
+	Group string `json:"group"`
+	Lag   int64  `json:"lag"`
+}
+
+// PartitionOffset is the committed offset of a consumer group in a partition
+type PartitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// AdminAPIClientFactory creates AdminAPIClient for the broker with the given
+// host name. It allows the Admin API to be replaced in tests.
+type AdminAPIClientFactory func(
+	ctx context.Context,
+	k8sClient client.Reader,
+	cluster *redpandav1alpha1.Cluster,
+	host string,
+) (AdminAPIClient, error)
+
+var _ AdminAPIClientFactory = NewAdminAPIClient
+
+type adminAPIClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	// the Kafka API of the broker is used for the requests not served by
+	// the Admin API, e.g. ACLs
+	host      string
+	k8sClient client.Reader
+	cluster   *redpandav1alpha1.Cluster
+}
+
+// NewAdminAPIClient creates AdminAPIClient talking to the Admin API of the
+// broker over HTTP, or HTTPS when Admin API TLS is enabled
+func NewAdminAPIClient(
+	ctx context.Context,
+	k8sClient client.Reader,
+	cluster *redpandav1alpha1.Cluster,
+	host string,
+) (AdminAPIClient, error) {
+	scheme := "http"
+	transport := &http.Transport{}
+
+	adminTLS := cluster.Spec.Configuration.TLS.AdminAPI
+	if adminTLS.Enabled {
+		scheme = "https"
+		rootCAs, err := adminAPIRootCAs(ctx, k8sClient, cluster)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: rootCAs}
+		if adminTLS.RequireClientAuth {
+			cert, err := clientCertificate(ctx, k8sClient, cluster)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	port := strconv.Itoa(cluster.Spec.Configuration.AdminAPI.Port)
+	return &adminAPIClient{
+		baseURL:    fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, port)),
+		httpClient: &http.Client{Transport: transport, Timeout: requestTimeout},
+		host:       host,
+		k8sClient:  k8sClient,
+		cluster:    cluster,
+	}, nil
+}
+
+// adminAPIRootCAs returns the CA the Admin API node certificate is verified
+// with. Nil is returned for the certificates issued without a CA in the
+// Secret, e.g. by a public issuer, which are verified with the system roots.
+func adminAPIRootCAs(
+	ctx context.Context, k8sClient client.Reader, cluster *redpandav1alpha1.Cluster,
+) (*x509.CertPool, error) {
+	var secret corev1.Secret
+	key := certmanager.AdminAPINodeCertKey(cluster)
+	if err := k8sClient.Get(ctx, key, &secret); err != nil {
+		return nil, fmt.Errorf("unable to fetch Admin API node certificate %s: %w", key, err)
+	}
+	caCert := secret.Data[cmetav1.TLSCAKey]
+	if len(caCert) == 0 {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("%w: %s", errInvalidCA, key)
+	}
+	return pool, nil
+}
+
+func clientCertificate(
+	ctx context.Context, k8sClient client.Reader, cluster *redpandav1alpha1.Cluster,
+) (tls.Certificate, error) {
+	var secret corev1.Secret
+	key := types.NamespacedName{
+		Name:      cluster.Name + "-" + certmanager.AdminAPIClientCert,
+		Namespace: cluster.Namespace,
+	}
+	if err := k8sClient.Get(ctx, key, &secret); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+}
+
+type partition struct {
+	LeaderID int `json:"leader_id"`
+}
+
+// ControllerLeader implements AdminAPIClient
+func (c *adminAPIClient) ControllerLeader(ctx context.Context) (int, error) {
+	var p partition
+	if err := c.get(ctx, controllerPartitionPath, &p); err != nil {
+		return NoLeader, err
+	}
+	return p.LeaderID, nil
+}
+
+type broker struct {
+	NodeID  int    `json:"node_id"`
+	Version string `json:"version,omitempty"`
+}
+
+// DecommissionBroker implements AdminAPIClient
+func (c *adminAPIClient) DecommissionBroker(
+	ctx context.Context, nodeID int,
+) error {
+	path := fmt.Sprintf("%s/%d/decommission", brokersPath, nodeID)
+	status, err := c.send(ctx, http.MethodPut, path, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%w: %s %d", errUnexpectedStatus, path, status)
+	}
+	return nil
+}
+
+// IsBrokerDecommissioned implements AdminAPIClient
+func (c *adminAPIClient) IsBrokerDecommissioned(
+	ctx context.Context, nodeID int,
+) (bool, error) {
+	var brokers []broker
+	if err := c.get(ctx, brokersPath, &brokers); err != nil {
+		return false, err
+	}
+	for _, b := range brokers {
+		if b.NodeID == nodeID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+type decommissionStatus struct {
+	Finished     bool `json:"finished"`
+	ReplicasLeft int  `json:"replicas_left"`
+}
+
+// DecommissionReplicasLeft implements AdminAPIClient
+func (c *adminAPIClient) DecommissionReplicasLeft(
+	ctx context.Context, nodeID int,
+) (int, error) {
+	var status decommissionStatus
+	err := c.get(ctx, fmt.Sprintf("%s/%d/decommission", brokersPath, nodeID), &status)
+	if errors.Is(err, errNotFound) {
+		return 0, ErrDecommissionProgressNotSupported
+	}
+	return status.ReplicasLeft, err
+}
+
+// BrokerTime implements AdminAPIClient. The time is taken from the Date
+// header of the response.
+func (c *adminAPIClient) BrokerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+brokersPath, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("%w: %s %s", errUnexpectedStatus, req.URL, resp.Status)
+	}
+
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return time.Time{}, ErrBrokerTimeNotReported
+	}
+	return http.ParseTime(date)
+}
+
+// BrokerVersions implements AdminAPIClient
+func (c *adminAPIClient) BrokerVersions(
+	ctx context.Context,
+) (map[int]string, error) {
+	var brokers []broker
+	if err := c.get(ctx, brokersPath, &brokers); err != nil {
+		return nil, err
+	}
+	versions := make(map[int]string, len(brokers))
+	for _, b := range brokers {
+		versions[b.NodeID] = b.Version
+	}
+	return versions, nil
+}
+
+// Features implements AdminAPIClient
+func (c *adminAPIClient) Features(ctx context.Context) (Features, error) {
+	var features Features
+	err := c.get(ctx, featuresPath, &features)
+	if errors.Is(err, errNotFound) {
+		return Features{}, ErrFeaturesNotSupported
+	}
+	return features, err
+}
+
+// CreateTopic implements AdminAPIClient
+func (c *adminAPIClient) CreateTopic(ctx context.Context, topic Topic) error {
+	status, err := c.send(ctx, http.MethodPost, topicsPath, topic)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+	case http.StatusConflict:
+		return fmt.Errorf("%s: %w", topic.Name, ErrTopicAlreadyExists)
+	default:
+		return fmt.Errorf("%w: %s %d", errUnexpectedStatus, topicsPath, status)
+	}
+}
+
+// ListUsers implements AdminAPIClient
+func (c *adminAPIClient) ListUsers(ctx context.Context) ([]string, error) {
+	var users []string
+	if err := c.get(ctx, usersPath, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+type user struct {
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Algorithm string `json:"algorithm"`
+}
+
+// CreateUser implements AdminAPIClient
+func (c *adminAPIClient) CreateUser(
+	ctx context.Context, username, password string,
+) error {
+	status, err := c.send(ctx, http.MethodPost, usersPath, user{
+		Username:  username,
+		Password:  password,
+		Algorithm: string(c.cluster.SuperuserMechanism(username)),
+	})
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+	case http.StatusConflict:
+		return fmt.Errorf("%s: %w", username, ErrUserAlreadyExists)
+	default:
+		// the request body with the password is never part of the error
+		return fmt.Errorf("%w: %s %d", errUnexpectedStatus, usersPath, status)
+	}
+}
+
+// DeleteUser implements AdminAPIClient
+func (c *adminAPIClient) DeleteUser(ctx context.Context, username string) error {
+	path := usersPath + "/" + url.PathEscape(username)
+	status, err := c.send(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return fmt.Errorf("%w: %s %d", errUnexpectedStatus, path, status)
+	}
+	return nil
+}
+
+// UpdateUser implements AdminAPIClient
+func (c *adminAPIClient) UpdateUser(
+	ctx context.Context, username, password string,
+) error {
+	path := usersPath + "/" + url.PathEscape(username)
+	status, err := c.send(ctx, http.MethodPut, path, user{
+		Username:  username,
+		Password:  password,
+		Algorithm: string(c.cluster.SuperuserMechanism(username)),
+	})
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		// the request body with the password is never part of the error
+		return fmt.Errorf("%w: %s %d", errUnexpectedStatus, path, status)
+	}
+	return nil
+}
+
+// UnderReplicatedPartitions implements AdminAPIClient
+func (c *adminAPIClient) UnderReplicatedPartitions(
+	ctx context.Context,
+) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+metricsPath, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%w: %s %d", errUnexpectedStatus, metricsPath, resp.StatusCode)
+	}
+
+	// the samples are labeled by partition, e.g.
+	// vectorized_cluster_partition_under_replicated_replicas{partition="0",topic="orders"} 1.000000
+	var total int64
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, underReplicatedMetric+"{") &&
+			!strings.HasPrefix(line, underReplicatedMetric+" ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		value, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse %s: %w", line, err)
+		}
+		total += int64(value)
+	}
+	return total, scanner.Err()
+}
+
+// send issues request with JSON encoded body and returns the response status
+func (c *adminAPIClient) send(
+	ctx context.Context, method, path string, in interface{},
+) (int, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// get decodes JSON response of the Admin API to out
+func (c *adminAPIClient) get(
+	ctx context.Context, path string, out interface{},
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", errNotFound, req.URL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s %s", errUnexpectedStatus, req.URL, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,415 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestControllerLeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/partitions/redpanda/controller/0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ns":"redpanda","topic":"controller","partition_id":0,"leader_id":2}`))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	leader, err := c.ControllerLeader(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, leader)
+}
+
+func TestAdminAPINodeCertificateVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ns":"redpanda","topic":"controller","partition_id":0,"leader_id":2}`))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+	cluster.Spec.Configuration.TLS.AdminAPI.Enabled = true
+	nodeCert := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin-api-node", Namespace: "default"},
+		Data: map[string][]byte{
+			"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+		},
+	}
+
+	// the broker is verified with the CA of the node certificate
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().WithObjects(nodeCert).Build(), cluster, host)
+	require.NoError(t, err)
+	leader, err := c.ControllerLeader(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, leader)
+
+	// the certificate is not trusted by the system roots
+	delete(nodeCert.Data, "ca.crt")
+	c, err = admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().WithObjects(nodeCert).Build(), cluster, host)
+	require.NoError(t, err)
+	_, err = c.ControllerLeader(context.Background())
+	assert.Error(t, err)
+
+	// the CA is required
+	_, err = admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	assert.Error(t, err)
+}
+
+func TestUnderReplicatedPartitions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`# HELP vectorized_cluster_partition_under_replicated_replicas Number of under replicated replicas
+# TYPE vectorized_cluster_partition_under_replicated_replicas gauge
+vectorized_cluster_partition_under_replicated_replicas{namespace="kafka",partition="0",shard="0",topic="orders"} 1.000000
+vectorized_cluster_partition_under_replicated_replicas{namespace="kafka",partition="1",shard="1",topic="orders"} 2.000000
+vectorized_cluster_partition_leader{namespace="kafka",partition="0",shard="0",topic="orders"} 1.000000
+`))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	urp, err := c.UnderReplicatedPartitions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), urp)
+}
+
+func TestDecommissionBroker(t *testing.T) {
+	decommissioned := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/brokers/2/decommission":
+			decommissioned = true
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/brokers/2/decommission":
+			_, _ = w.Write([]byte(`{"finished":false,"replicas_left":7}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/brokers":
+			if decommissioned {
+				_, _ = w.Write([]byte(`[{"node_id":0},{"node_id":1}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"node_id":0},{"node_id":1},{"node_id":2}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	done, err := c.IsBrokerDecommissioned(context.Background(), 2)
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	require.NoError(t, c.DecommissionBroker(context.Background(), 2))
+
+	left, err := c.DecommissionReplicasLeft(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 7, left)
+
+	done, err = c.IsBrokerDecommissioned(context.Background(), 2)
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	assert.Error(t, c.DecommissionBroker(context.Background(), 7))
+	_, err = c.DecommissionReplicasLeft(context.Background(), 7)
+	assert.ErrorIs(t, err, admin.ErrDecommissionProgressNotSupported)
+}
+
+func TestBrokerVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/brokers" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"node_id":0,"num_cores":1,"version":"v21.4.12 (rev 6c1b5f6)"},{"node_id":1,"num_cores":1,"version":"v21.4.11 (rev 0b1e2d3)"}]`))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	versions, err := c.BrokerVersions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{0: "v21.4.12 (rev 6c1b5f6)", 1: "v21.4.11 (rev 0b1e2d3)"}, versions)
+}
+
+func TestBrokerTime(t *testing.T) {
+	brokerTime := time.Date(2021, time.June, 1, 12, 30, 15, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/brokers" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Date", brokerTime.Format(http.TimeFormat))
+		_, _ = w.Write([]byte(`[{"node_id":0}]`))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	actual, err := c.BrokerTime(context.Background())
+	require.NoError(t, err)
+	assert.True(t, brokerTime.Equal(actual), actual)
+}
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected admin.Features
+		err      error
+	}{
+		{"upgraded", http.StatusOK, `{"cluster_version":3,"node_latest_version":3,"features":[]}`, admin.Features{ClusterVersion: 3, NodeLatestVersion: 3}, nil},
+		{"upgrade in progress", http.StatusOK, `{"cluster_version":2,"node_latest_version":3,"features":[]}`, admin.Features{ClusterVersion: 2, NodeLatestVersion: 3}, nil},
+		{"old broker", http.StatusNotFound, "", admin.Features{}, admin.ErrFeaturesNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/features" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			u, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			host, portStr, err := net.SplitHostPort(u.Host)
+			require.NoError(t, err)
+			port, err := strconv.Atoi(portStr)
+			require.NoError(t, err)
+
+			cluster := &redpandav1alpha1.Cluster{}
+			cluster.Spec.Configuration.AdminAPI.Port = port
+
+			c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+			require.NoError(t, err)
+
+			features, err := c.Features(context.Background())
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, features)
+			assert.Equal(t, tt.name == "upgrade in progress", features.UpgradeInProgress())
+		})
+	}
+}
+
+func TestCreateTopic(t *testing.T) {
+	topics := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/topics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var topic admin.Topic
+		if err := json.NewDecoder(r.Body).Decode(&topic); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if topics[topic.Name] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		topics[topic.Name] = true
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	topic := admin.Topic{Name: "audit", Partitions: 1, ReplicationFactor: 3}
+	require.NoError(t, c.CreateTopic(context.Background(), topic))
+	assert.True(t, topics["audit"])
+
+	err = c.CreateTopic(context.Background(), topic)
+	assert.True(t, errors.Is(err, admin.ErrTopicAlreadyExists))
+}
+
+func TestUsers(t *testing.T) {
+	users := map[string]bool{"client": true}
+	algorithms := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/security/users":
+			list := []string{}
+			for u := range users {
+				list = append(list, u)
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/security/users":
+			var body struct {
+				Username  string `json:"username"`
+				Password  string `json:"password"`
+				Algorithm string `json:"algorithm"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Password == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if users[body.Username] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			users[body.Username] = true
+			algorithms[body.Username] = body.Algorithm
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/security/users/client":
+			var body struct {
+				Username  string `json:"username"`
+				Password  string `json:"password"`
+				Algorithm string `json:"algorithm"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Username != "client" || body.Password == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			algorithms[body.Username] = body.Algorithm
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/security/users/admin":
+			delete(users, "admin")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.AdminAPI.Port = port
+	cluster.Spec.Superusers = []redpandav1alpha1.Superuser{{Username: "admin", Mechanism: redpandav1alpha1.SCRAMSHA512}}
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+
+	require.NoError(t, c.CreateUser(context.Background(), "admin", "secret"))
+	assert.Equal(t, "SCRAM-SHA-512", algorithms["admin"])
+	err = c.CreateUser(context.Background(), "admin", "secret")
+	assert.True(t, errors.Is(err, admin.ErrUserAlreadyExists))
+	assert.NotContains(t, err.Error(), "secret")
+
+	list, err := c.ListUsers(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"admin", "client"}, list)
+
+	require.NoError(t, c.UpdateUser(context.Background(), "client", "rotated"))
+	assert.Equal(t, "SCRAM-SHA-256", algorithms["client"], "users that are not superusers use the default mechanism")
+	err = c.UpdateUser(context.Background(), "missing", "rotated")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "rotated")
+
+	require.NoError(t, c.DeleteUser(context.Background(), "admin"))
+	assert.False(t, users["admin"])
+}
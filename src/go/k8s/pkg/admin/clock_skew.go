@@ -0,0 +1,72 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClockOffsets maps broker host to the difference between the broker clock
+// and the operator clock
+type ClockOffsets map[string]time.Duration
+
+// QueryClockOffsets asks every broker for its time. Each broker is compared
+// to the operator clock right after it responds, so the time spent querying
+// the other brokers doesn't count as skew.
+func QueryClockOffsets(
+	ctx context.Context, clients map[string]AdminAPIClient,
+) (ClockOffsets, error) {
+	offsets := make(ClockOffsets, len(clients))
+	for host, c := range clients {
+		brokerTime, err := c.BrokerTime(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get time from %s: %w", host, err)
+		}
+		offsets[host] = brokerTime.Sub(time.Now())
+	}
+	return offsets, nil
+}
+
+// Skew returns the largest difference between the clocks of two brokers
+func (o ClockOffsets) Skew() time.Duration {
+	if len(o) == 0 {
+		return 0
+	}
+	var min, max time.Duration
+	first := true
+	for _, offset := range o {
+		if first || offset < min {
+			min = offset
+		}
+		if first || offset > max {
+			max = offset
+		}
+		first = false
+	}
+	return max - min
+}
+
+// String returns the offsets sorted by broker host
+func (o ClockOffsets) String() string {
+	hosts := make([]string, 0, len(o))
+	for host := range o {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	offsets := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		offsets = append(offsets, fmt.Sprintf("%s=%s", host, o[host].Round(time.Second)))
+	}
+	return strings.Join(offsets, ", ")
+}
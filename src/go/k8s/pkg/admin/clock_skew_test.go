@@ -0,0 +1,62 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+func TestQueryClockOffsets(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		clients map[string]admin.AdminAPIClient
+		skew    time.Duration
+	}{
+		{"synchronized clocks", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{now: now},
+			"cluster-1": &fakeAdminAPI{now: now},
+		}, 0},
+		{"broker ahead", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{now: now},
+			"cluster-1": &fakeAdminAPI{now: now.Add(30 * time.Second)},
+			"cluster-2": &fakeAdminAPI{now: now},
+		}, 30 * time.Second},
+		{"brokers ahead and behind", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{now: now.Add(-10 * time.Second)},
+			"cluster-1": &fakeAdminAPI{now: now.Add(5 * time.Second)},
+		}, 15 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offsets, err := admin.QueryClockOffsets(context.Background(), tt.clients)
+			require.NoError(t, err)
+			assert.Len(t, offsets, len(tt.clients))
+			// the local clock moves between the queries
+			assert.InDelta(t, tt.skew.Seconds(), offsets.Skew().Seconds(), 1)
+		})
+	}
+}
+
+func TestQueryClockOffsetsError(t *testing.T) {
+	clients := map[string]admin.AdminAPIClient{
+		"cluster-0": &fakeAdminAPI{now: time.Now()},
+		"cluster-1": &fakeAdminAPI{err: errUnreachable},
+	}
+	_, err := admin.QueryClockOffsets(context.Background(), clients)
+	assert.True(t, errors.Is(err, errUnreachable))
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var errNoBrokers = errors.New("no brokers to query")
+
+// MaxConsumerGroupLag returns the consumer group that is the most behind.
+// The brokers are asked in turn until one of them responds, as any broker
+// can report the lag of all groups. Zero value is returned when there are
+// no consumer groups.
+func MaxConsumerGroupLag(
+	ctx context.Context, clients []AdminAPIClient,
+) (ConsumerGroupLag, error) {
+	lastErr := errNoBrokers
+	for _, c := range clients {
+		lags, err := c.ConsumerGroupLags(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var maxLag ConsumerGroupLag
+		for _, lag := range lags {
+			if lag.Lag > maxLag.Lag || maxLag.Group == "" {
+				maxLag = lag
+			}
+		}
+		return maxLag, nil
+	}
+	return ConsumerGroupLag{}, fmt.Errorf("unable to get consumer group lag: %w", lastErr)
+}
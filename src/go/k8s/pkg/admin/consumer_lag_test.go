@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+func TestMaxConsumerGroupLag(t *testing.T) {
+	lags := []admin.ConsumerGroupLag{
+		{Group: "billing", Lag: 12},
+		{Group: "analytics", Lag: 4096},
+		{Group: "audit", Lag: 0},
+	}
+
+	t.Run("max lag", func(t *testing.T) {
+		maxLag, err := admin.MaxConsumerGroupLag(context.Background(), []admin.AdminAPIClient{
+			&fakeAdminAPI{lags: lags},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, admin.ConsumerGroupLag{Group: "analytics", Lag: 4096}, maxLag)
+	})
+
+	t.Run("unreachable broker is skipped", func(t *testing.T) {
+		maxLag, err := admin.MaxConsumerGroupLag(context.Background(), []admin.AdminAPIClient{
+			&fakeAdminAPI{err: errUnreachable},
+			&fakeAdminAPI{lags: lags},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4096), maxLag.Lag)
+	})
+
+	t.Run("no consumer groups", func(t *testing.T) {
+		maxLag, err := admin.MaxConsumerGroupLag(context.Background(), []admin.AdminAPIClient{
+			&fakeAdminAPI{},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, admin.ConsumerGroupLag{}, maxLag)
+	})
+
+	t.Run("all brokers unreachable", func(t *testing.T) {
+		_, err := admin.MaxConsumerGroupLag(context.Background(), []admin.AdminAPIClient{
+			&fakeAdminAPI{err: errUnreachable},
+		})
+		assert.ErrorIs(t, err, errUnreachable)
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ControllerLeaderViews maps broker host to the controller leader node ID
+// reported by that broker
+type ControllerLeaderViews map[string]int
+
+// QueryControllerLeaders asks all brokers at once for their view of the
+// controller leader, each within requestTimeout. Any failing broker fails
+// the query, as partial views can't prove the brokers agree.
+func QueryControllerLeaders(
+	ctx context.Context, clients map[string]AdminAPIClient,
+) (ControllerLeaderViews, error) {
+	type view struct {
+		host   string
+		leader int
+		err    error
+	}
+	// buffered, so the remaining queries don't block after a failure
+	results := make(chan view, len(clients))
+	for host, c := range clients {
+		go func(host string, c AdminAPIClient) {
+			brokerCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+			defer cancel()
+			leader, err := c.ControllerLeader(brokerCtx)
+			results <- view{host, leader, err}
+		}(host, c)
+	}
+
+	views := make(ControllerLeaderViews, len(clients))
+	for range clients {
+		v := <-results
+		if v.err != nil {
+			return nil, fmt.Errorf("unable to get controller leader from %s: %w", v.host, v.err)
+		}
+		views[v.host] = v.leader
+	}
+	return views, nil
+}
+
+// Inconsistent returns true if brokers report different controller leaders.
+// Brokers without a known leader, e.g. during an election, are ignored.
+func (v ControllerLeaderViews) Inconsistent() bool {
+	leader := NoLeader
+	for _, l := range v {
+		if l == NoLeader {
+			continue
+		}
+		if leader != NoLeader && leader != l {
+			return true
+		}
+		leader = l
+	}
+	return false
+}
+
+// String returns the views sorted by broker host
+func (v ControllerLeaderViews) String() string {
+	hosts := make([]string, 0, len(v))
+	for host := range v {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	views := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		views = append(views, fmt.Sprintf("%s=%d", host, v[host]))
+	}
+	return strings.Join(views, ", ")
+}
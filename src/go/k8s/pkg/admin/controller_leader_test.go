@@ -0,0 +1,181 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+var errUnreachable = errors.New("broker unreachable")
+
+type fakeAdminAPI struct {
+	leader int
+	lags   []admin.ConsumerGroupLag
+	urp    int64
+	now    time.Time
+	err    error
+}
+
+func (f *fakeAdminAPI) ControllerLeader(context.Context) (int, error) {
+	return f.leader, f.err
+}
+
+func (f *fakeAdminAPI) ConsumerGroupLags(context.Context) ([]admin.ConsumerGroupLag, error) {
+	return f.lags, f.err
+}
+
+func (f *fakeAdminAPI) DecommissionBroker(context.Context, int) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) IsBrokerDecommissioned(context.Context, int) (bool, error) {
+	return false, f.err
+}
+
+func (f *fakeAdminAPI) DecommissionReplicasLeft(context.Context, int) (int, error) {
+	return 0, f.err
+}
+
+func (f *fakeAdminAPI) CreateTopic(context.Context, admin.Topic) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) ListUsers(context.Context) ([]string, error) {
+	return nil, f.err
+}
+
+func (f *fakeAdminAPI) CreateUser(context.Context, string, string) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) DeleteUser(context.Context, string) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) UpdateUser(context.Context, string, string) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) UnderReplicatedPartitions(context.Context) (int64, error) {
+	return f.urp, f.err
+}
+
+func (f *fakeAdminAPI) BrokerVersions(context.Context) (map[int]string, error) {
+	return nil, f.err
+}
+
+func TestQueryControllerLeaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		clients      map[string]admin.AdminAPIClient
+		inconsistent bool
+	}{
+		{"brokers agree", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{leader: 1},
+			"cluster-1": &fakeAdminAPI{leader: 1},
+			"cluster-2": &fakeAdminAPI{leader: 1},
+		}, false},
+		{"brokers disagree", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{leader: 0},
+			"cluster-1": &fakeAdminAPI{leader: 1},
+			"cluster-2": &fakeAdminAPI{leader: 1},
+		}, true},
+		{"broker without leader is ignored", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{leader: admin.NoLeader},
+			"cluster-1": &fakeAdminAPI{leader: 2},
+			"cluster-2": &fakeAdminAPI{leader: 2},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			views, err := admin.QueryControllerLeaders(context.Background(), tt.clients)
+			require.NoError(t, err)
+			assert.Len(t, views, len(tt.clients))
+			assert.Equal(t, tt.inconsistent, views.Inconsistent(), views.String())
+		})
+	}
+}
+
+func TestQueryControllerLeadersError(t *testing.T) {
+	clients := map[string]admin.AdminAPIClient{
+		"cluster-0": &fakeAdminAPI{leader: 0},
+		"cluster-1": &fakeAdminAPI{err: errUnreachable},
+	}
+	_, err := admin.QueryControllerLeaders(context.Background(), clients)
+	assert.True(t, errors.Is(err, errUnreachable))
+}
+
+// barrierAdminAPI answers only once all brokers of the barrier are asked
+type barrierAdminAPI struct {
+	fakeAdminAPI
+	barrier *sync.WaitGroup
+}
+
+func (f *barrierAdminAPI) ControllerLeader(ctx context.Context) (int, error) {
+	f.barrier.Done()
+	done := make(chan struct{})
+	go func() {
+		f.barrier.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return f.leader, nil
+	case <-ctx.Done():
+		return admin.NoLeader, ctx.Err()
+	}
+}
+
+func TestQueryControllerLeadersConcurrently(t *testing.T) {
+	var barrier sync.WaitGroup
+	barrier.Add(3)
+	clients := map[string]admin.AdminAPIClient{
+		"cluster-0": &barrierAdminAPI{fakeAdminAPI{leader: 1}, &barrier},
+		"cluster-1": &barrierAdminAPI{fakeAdminAPI{leader: 1}, &barrier},
+		"cluster-2": &barrierAdminAPI{fakeAdminAPI{leader: 1}, &barrier},
+	}
+	// the brokers asked one after another would time out
+	views, err := admin.QueryControllerLeaders(context.Background(), clients)
+	require.NoError(t, err)
+	assert.Len(t, views, 3)
+	assert.False(t, views.Inconsistent())
+}
+
+func (f *fakeAdminAPI) Features(context.Context) (admin.Features, error) {
+	return admin.Features{}, f.err
+}
+
+func (f *fakeAdminAPI) ListACLs(context.Context) ([]admin.ACL, error) {
+	return nil, f.err
+}
+
+func (f *fakeAdminAPI) CreateACL(context.Context, admin.ACL) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) DeleteACL(context.Context, admin.ACL) error {
+	return f.err
+}
+
+func (f *fakeAdminAPI) BrokerTime(context.Context) (time.Time, error) {
+	return f.now, f.err
+}
+
+func (f *fakeAdminAPI) ResetConsumerGroupOffsets(context.Context, string, string, bool) ([]admin.PartitionOffset, error) {
+	return nil, f.err
+}
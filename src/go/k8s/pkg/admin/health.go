@@ -0,0 +1,43 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+)
+
+// SummarizeHealth polls every broker and aggregates the responses. A broker
+// is healthy when it reports both the controller leader and its
+// under-replicated partitions. The time of the poll is left to the caller.
+func SummarizeHealth(
+	ctx context.Context, clients map[string]AdminAPIClient,
+) redpandav1alpha1.ClusterHealthSummary {
+	var summary redpandav1alpha1.ClusterHealthSummary
+	for _, c := range clients {
+		leader, err := c.ControllerLeader(ctx)
+		if err != nil {
+			summary.UnhealthyBrokers++
+			continue
+		}
+		urp, err := c.UnderReplicatedPartitions(ctx)
+		if err != nil {
+			summary.UnhealthyBrokers++
+			continue
+		}
+		summary.HealthyBrokers++
+		summary.UnderReplicatedPartitions += urp
+		if leader != NoLeader {
+			summary.ControllerLeaderPresent = true
+		}
+	}
+	return summary
+}
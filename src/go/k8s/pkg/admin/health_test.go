@@ -0,0 +1,58 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+func TestSummarizeHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		clients  map[string]admin.AdminAPIClient
+		expected redpandav1alpha1.ClusterHealthSummary
+	}{
+		{"all brokers healthy", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{leader: 1},
+			"cluster-1": &fakeAdminAPI{leader: 1, urp: 2},
+			"cluster-2": &fakeAdminAPI{leader: 1, urp: 1},
+		}, redpandav1alpha1.ClusterHealthSummary{
+			HealthyBrokers:            3,
+			ControllerLeaderPresent:   true,
+			UnderReplicatedPartitions: 3,
+		}},
+		{"unreachable broker", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{leader: 0, urp: 4},
+			"cluster-1": &fakeAdminAPI{err: errUnreachable},
+		}, redpandav1alpha1.ClusterHealthSummary{
+			HealthyBrokers:            1,
+			UnhealthyBrokers:          1,
+			ControllerLeaderPresent:   true,
+			UnderReplicatedPartitions: 4,
+		}},
+		{"no controller leader", map[string]admin.AdminAPIClient{
+			"cluster-0": &fakeAdminAPI{leader: admin.NoLeader},
+			"cluster-1": &fakeAdminAPI{leader: admin.NoLeader},
+		}, redpandav1alpha1.ClusterHealthSummary{
+			HealthyBrokers: 2,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := admin.SummarizeHealth(context.Background(), tt.clients)
+			assert.Equal(t, tt.expected, summary)
+		})
+	}
+}
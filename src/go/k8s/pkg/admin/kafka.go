@@ -0,0 +1,117 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/Shopify/sarama"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var errNoSASLCredentials = errors.New("no SCRAM-SHA-256 superuser with password to authenticate with")
+
+// kafkaAdmin connects to the internal Kafka API listener of the broker
+func (c *adminAPIClient) kafkaAdmin(ctx context.Context) (sarama.ClusterAdmin, error) {
+	conf, err := c.kafkaConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sarama.NewClusterAdmin([]string{c.kafkaAddr()}, conf)
+}
+
+// kafkaClient connects to the internal Kafka API listener of the broker, for
+// the requests that are not covered by sarama.ClusterAdmin
+func (c *adminAPIClient) kafkaClient(ctx context.Context) (sarama.Client, error) {
+	conf, err := c.kafkaConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sarama.NewClient([]string{c.kafkaAddr()}, conf)
+}
+
+func (c *adminAPIClient) kafkaAddr() string {
+	return net.JoinHostPort(c.host, strconv.Itoa(c.cluster.InternalKafkaAPIListener().Port))
+}
+
+// kafkaConfig returns the configuration of the Kafka API clients. The
+// operator authenticates as the first SCRAM-SHA-256 superuser with password
+// when SASL is enabled.
+func (c *adminAPIClient) kafkaConfig(ctx context.Context) (*sarama.Config, error) {
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V2_4_0_0
+	conf.ClientID = "operator"
+	conf.Admin.Timeout = requestTimeout
+	conf.Net.DialTimeout = requestTimeout
+
+	listener := c.cluster.InternalKafkaAPIListener()
+	if listener.TLS {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true} // nolint:gosec // brokers are not verified by other operator clients either
+		if listener.RequireClientAuth {
+			var secret corev1.Secret
+			key := types.NamespacedName{
+				Name:      c.cluster.Name + "-" + certmanager.OperatorClientCert,
+				Namespace: c.cluster.Namespace,
+			}
+			if err := c.k8sClient.Get(ctx, key, &secret); err != nil {
+				return nil, err
+			}
+			cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = tlsConfig
+	}
+
+	if c.cluster.Spec.EnableSASL {
+		username, password, err := c.saslCredentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conf.Net.SASL.Enable = true
+		conf.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		conf.Net.SASL.User = username
+		conf.Net.SASL.Password = password
+		conf.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{}
+		}
+	}
+
+	return conf, nil
+}
+
+func (c *adminAPIClient) saslCredentials(
+	ctx context.Context,
+) (username, password string, err error) {
+	for _, superuser := range c.cluster.Spec.Superusers {
+		ref := superuser.PasswordSecretKeyRef
+		// the SCRAM client of the operator supports SHA-256 only
+		if ref == nil || c.cluster.SuperuserMechanism(superuser.Username) != redpandav1alpha1.SCRAMSHA256 {
+			continue
+		}
+		var secret corev1.Secret
+		err := c.k8sClient.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: c.cluster.Namespace}, &secret)
+		if err != nil {
+			return "", "", err
+		}
+		return superuser.Username, string(secret.Data[ref.Key]), nil
+	}
+	return "", "", errNoSASLCredentials
+}
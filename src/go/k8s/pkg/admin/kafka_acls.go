@@ -0,0 +1,193 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Shopify/sarama"
+)
+
+var errUnknownACLField = errors.New("unknown ACL field")
+
+var (
+	aclResourceTypes = map[string]sarama.AclResourceType{
+		"Topic":           sarama.AclResourceTopic,
+		"Group":           sarama.AclResourceGroup,
+		"Cluster":         sarama.AclResourceCluster,
+		"TransactionalID": sarama.AclResourceTransactionalID,
+	}
+	aclPatternTypes = map[string]sarama.AclResourcePatternType{
+		"Literal":  sarama.AclPatternLiteral,
+		"Prefixed": sarama.AclPatternPrefixed,
+	}
+	aclOperations = map[string]sarama.AclOperation{
+		"All":             sarama.AclOperationAll,
+		"Read":            sarama.AclOperationRead,
+		"Write":           sarama.AclOperationWrite,
+		"Create":          sarama.AclOperationCreate,
+		"Delete":          sarama.AclOperationDelete,
+		"Alter":           sarama.AclOperationAlter,
+		"Describe":        sarama.AclOperationDescribe,
+		"ClusterAction":   sarama.AclOperationClusterAction,
+		"DescribeConfigs": sarama.AclOperationDescribeConfigs,
+		"AlterConfigs":    sarama.AclOperationAlterConfigs,
+		"IdempotentWrite": sarama.AclOperationIdempotentWrite,
+	}
+	aclPermissions = map[string]sarama.AclPermissionType{
+		"Allow": sarama.AclPermissionAllow,
+		"Deny":  sarama.AclPermissionDeny,
+	}
+)
+
+// ListACLs implements AdminAPIClient
+func (c *adminAPIClient) ListACLs(ctx context.Context) ([]ACL, error) {
+	ca, err := c.kafkaAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ca.Close()
+
+	resources, err := ca.ListAcls(sarama.AclFilter{
+		Version:                   1,
+		ResourceType:              sarama.AclResourceAny,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		Operation:                 sarama.AclOperationAny,
+		PermissionType:            sarama.AclPermissionAny,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var acls []ACL
+	for _, r := range resources {
+		for _, a := range r.Acls {
+			acls = append(acls, ACL{
+				Principal:    a.Principal,
+				Host:         a.Host,
+				ResourceType: resourceTypeName(r.ResourceType),
+				ResourceName: r.ResourceName,
+				PatternType:  patternTypeName(r.ResourcePatternType),
+				Operation:    operationName(a.Operation),
+				Permission:   permissionName(a.PermissionType),
+			})
+		}
+	}
+	return acls, nil
+}
+
+// CreateACL implements AdminAPIClient
+func (c *adminAPIClient) CreateACL(ctx context.Context, acl ACL) error {
+	resource, binding, err := toSaramaACL(acl)
+	if err != nil {
+		return err
+	}
+	ca, err := c.kafkaAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer ca.Close()
+	return ca.CreateACL(resource, binding)
+}
+
+// DeleteACL implements AdminAPIClient
+func (c *adminAPIClient) DeleteACL(ctx context.Context, acl ACL) error {
+	resource, binding, err := toSaramaACL(acl)
+	if err != nil {
+		return err
+	}
+	ca, err := c.kafkaAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer ca.Close()
+	_, err = ca.DeleteACL(sarama.AclFilter{
+		Version:                   1,
+		ResourceType:              resource.ResourceType,
+		ResourceName:              &resource.ResourceName,
+		ResourcePatternTypeFilter: resource.ResourcePatternType,
+		Principal:                 &binding.Principal,
+		Host:                      &binding.Host,
+		Operation:                 binding.Operation,
+		PermissionType:            binding.PermissionType,
+	}, false)
+	return err
+}
+
+func toSaramaACL(acl ACL) (sarama.Resource, sarama.Acl, error) {
+	acl = acl.WithDefaults()
+	resourceType, ok := aclResourceTypes[acl.ResourceType]
+	if !ok {
+		return sarama.Resource{}, sarama.Acl{}, fmt.Errorf("%w: resource type %s", errUnknownACLField, acl.ResourceType)
+	}
+	patternType, ok := aclPatternTypes[acl.PatternType]
+	if !ok {
+		return sarama.Resource{}, sarama.Acl{}, fmt.Errorf("%w: pattern type %s", errUnknownACLField, acl.PatternType)
+	}
+	operation, ok := aclOperations[acl.Operation]
+	if !ok {
+		return sarama.Resource{}, sarama.Acl{}, fmt.Errorf("%w: operation %s", errUnknownACLField, acl.Operation)
+	}
+	permission, ok := aclPermissions[acl.Permission]
+	if !ok {
+		return sarama.Resource{}, sarama.Acl{}, fmt.Errorf("%w: permission %s", errUnknownACLField, acl.Permission)
+	}
+	return sarama.Resource{
+		ResourceType:        resourceType,
+		ResourceName:        acl.ResourceName,
+		ResourcePatternType: patternType,
+	}, sarama.Acl{
+		Principal:      acl.Principal,
+		Host:           acl.Host,
+		Operation:      operation,
+		PermissionType: permission,
+	}, nil
+}
+
+// The name lookups below return values unknown to the operator as numbers
+// so they never match desired ACLs.
+
+func resourceTypeName(value sarama.AclResourceType) string {
+	for name, v := range aclResourceTypes {
+		if v == value {
+			return name
+		}
+	}
+	return strconv.Itoa(int(value))
+}
+
+func patternTypeName(value sarama.AclResourcePatternType) string {
+	for name, v := range aclPatternTypes {
+		if v == value {
+			return name
+		}
+	}
+	return strconv.Itoa(int(value))
+}
+
+func operationName(value sarama.AclOperation) string {
+	for name, v := range aclOperations {
+		if v == value {
+			return name
+		}
+	}
+	return strconv.Itoa(int(value))
+}
+
+func permissionName(value sarama.AclPermissionType) string {
+	for name, v := range aclPermissions {
+		if v == value {
+			return name
+		}
+	}
+	return strconv.Itoa(int(value))
+}
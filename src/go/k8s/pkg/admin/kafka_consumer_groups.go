@@ -0,0 +1,281 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+)
+
+// partitionOffsets are offsets by topic and partition
+type partitionOffsets map[string]map[int32]int64
+
+func (o partitionOffsets) set(topic string, partition int32, offset int64) {
+	if o[topic] == nil {
+		o[topic] = make(map[int32]int64)
+	}
+	o[topic][partition] = offset
+}
+
+// ConsumerGroupLags implements AdminAPIClient. The Admin API doesn't report
+// the lag, so it is computed over the Kafka API from the committed offsets of
+// the groups and the high watermarks of the partitions. Partitions without
+// committed offset don't count.
+func (c *adminAPIClient) ConsumerGroupLags(
+	ctx context.Context,
+) ([]ConsumerGroupLag, error) {
+	kc, err := c.kafkaClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := sarama.NewClusterAdminFromClient(kc)
+	if err != nil {
+		_ = kc.Close()
+		return nil, err
+	}
+	defer ca.Close()
+
+	groups, err := ca.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	committed := make(map[string]partitionOffsets, len(names))
+	consumed := make(map[string][]int32)
+	for _, group := range names {
+		offsets, err := committedOffsets(ca, group)
+		if err != nil {
+			return nil, err
+		}
+		committed[group] = offsets
+		for topic, partitions := range offsets {
+			for partition := range partitions {
+				if !containsPartition(consumed[topic], partition) {
+					consumed[topic] = append(consumed[topic], partition)
+				}
+			}
+		}
+	}
+	highWatermarks, err := listOffsets(kc, consumed, sarama.OffsetNewest)
+	if err != nil {
+		return nil, err
+	}
+
+	lags := make([]ConsumerGroupLag, 0, len(names))
+	for _, group := range names {
+		lag := ConsumerGroupLag{Group: group}
+		for topic, partitions := range committed[group] {
+			for partition, offset := range partitions {
+				// the watermark can be behind the commit of a consumer that
+				// read from a new leader
+				if behind := highWatermarks[topic][partition] - offset; behind > 0 {
+					lag.Lag += behind
+				}
+			}
+		}
+		lags = append(lags, lag)
+	}
+	return lags, nil
+}
+
+// ResetConsumerGroupOffsets implements AdminAPIClient. The offsets are
+// committed over the Kafka API on behalf of the group, like a consumer
+// outside of the group membership does, so the group must have no members.
+// Only the partitions with committed offsets are reset.
+func (c *adminAPIClient) ResetConsumerGroupOffsets(
+	ctx context.Context, group, to string, dryRun bool,
+) ([]PartitionOffset, error) {
+	var time int64
+	switch to {
+	case redpandav1alpha1.OffsetResetEarliest:
+		time = sarama.OffsetOldest
+	case redpandav1alpha1.OffsetResetLatest:
+		time = sarama.OffsetNewest
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownOffsetReset, to)
+	}
+
+	kc, err := c.kafkaClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := sarama.NewClusterAdminFromClient(kc)
+	if err != nil {
+		_ = kc.Close()
+		return nil, err
+	}
+	defer ca.Close()
+
+	descriptions, err := ca.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range descriptions {
+		if d.Err != sarama.ErrNoError {
+			return nil, fmt.Errorf("unable to describe consumer group %s: %w", group, d.Err)
+		}
+		if len(d.Members) > 0 {
+			return nil, fmt.Errorf("%s: %w", group, ErrConsumerGroupActive)
+		}
+	}
+
+	committed, err := committedOffsets(ca, group)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make(map[string][]int32, len(committed))
+	for topic, offsets := range committed {
+		for partition := range offsets {
+			partitions[topic] = append(partitions[topic], partition)
+		}
+	}
+	targets, err := listOffsets(kc, partitions, time)
+	if err != nil {
+		return nil, err
+	}
+	var offsets []PartitionOffset
+	for topic, byPartition := range targets {
+		for partition, offset := range byPartition {
+			offsets = append(offsets, PartitionOffset{Topic: topic, Partition: partition, Offset: offset})
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+	if dryRun || len(offsets) == 0 {
+		return offsets, nil
+	}
+
+	coordinator, err := kc.Coordinator(group)
+	if err != nil {
+		return nil, err
+	}
+	req := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: sarama.GroupGenerationUndefined,
+		RetentionTime:           -1,
+	}
+	for _, o := range offsets {
+		req.AddBlock(o.Topic, o.Partition, o.Offset, 0, "")
+	}
+	resp, err := coordinator.CommitOffset(req)
+	if err != nil {
+		return nil, err
+	}
+	for topic, errs := range resp.Errors {
+		for partition, kerr := range errs {
+			switch kerr {
+			case sarama.ErrNoError:
+			case sarama.ErrUnknownMemberId, sarama.ErrIllegalGeneration, sarama.ErrRebalanceInProgress:
+				// a consumer joined the group since it was described
+				return nil, fmt.Errorf("%s: %w", group, ErrConsumerGroupActive)
+			default:
+				return nil, fmt.Errorf("unable to commit offset of consumer group %s in %s/%d: %w", group, topic, partition, kerr)
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// committedOffsets returns the offsets committed by the consumer group in
+// all partitions
+func committedOffsets(
+	ca sarama.ClusterAdmin, group string,
+) (partitionOffsets, error) {
+	resp, err := ca.ListConsumerGroupOffsets(group, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("unable to fetch offsets of consumer group %s: %w", group, resp.Err)
+	}
+	offsets := make(partitionOffsets)
+	for topic, blocks := range resp.Blocks {
+		for partition, block := range blocks {
+			if block.Err != sarama.ErrNoError {
+				return nil, fmt.Errorf("unable to fetch offset of consumer group %s in %s/%d: %w", group, topic, partition, block.Err)
+			}
+			// -1 stands for no committed offset
+			if block.Offset >= 0 {
+				offsets.set(topic, partition, block.Offset)
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// listOffsets returns the earliest or the latest offsets of the partitions,
+// with one request per partition leader
+func listOffsets(
+	kc sarama.Client, partitions map[string][]int32, time int64,
+) (partitionOffsets, error) {
+	requests := make(map[*sarama.Broker]*sarama.OffsetRequest)
+	led := make(map[*sarama.Broker]map[string][]int32)
+	for topic, ids := range partitions {
+		for _, partition := range ids {
+			leader, err := kc.Leader(topic, partition)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find leader of %s/%d: %w", topic, partition, err)
+			}
+			if _, ok := requests[leader]; !ok {
+				requests[leader] = &sarama.OffsetRequest{Version: 1}
+				led[leader] = make(map[string][]int32)
+			}
+			requests[leader].AddBlock(topic, partition, time, 1)
+			led[leader][topic] = append(led[leader][topic], partition)
+		}
+	}
+
+	offsets := make(partitionOffsets)
+	for leader, req := range requests {
+		resp, err := leader.GetAvailableOffsets(req)
+		if err != nil {
+			return nil, err
+		}
+		for topic, ids := range led[leader] {
+			for _, partition := range ids {
+				block := resp.GetBlock(topic, partition)
+				if block == nil {
+					return nil, fmt.Errorf("unable to list offsets of %s/%d: %w", topic, partition, sarama.ErrIncompleteResponse)
+				}
+				if block.Err != sarama.ErrNoError {
+					return nil, fmt.Errorf("unable to list offsets of %s/%d: %w", topic, partition, block.Err)
+				}
+				if len(block.Offsets) != 1 {
+					return nil, fmt.Errorf("unable to list offsets of %s/%d: %w", topic, partition, sarama.ErrOffsetOutOfRange)
+				}
+				offsets.set(topic, partition, block.Offsets[0])
+			}
+		}
+	}
+	return offsets, nil
+}
+
+func containsPartition(partitions []int32, partition int32) bool {
+	for _, p := range partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newKafkaAPIClient returns AdminAPIClient that reaches the Kafka API of the
+// mock broker
+func newKafkaAPIClient(t *testing.T, mb *sarama.MockBroker) admin.AdminAPIClient {
+	t.Helper()
+
+	host, portStr, err := net.SplitHostPort(mb.Addr())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	cluster := &redpandav1alpha1.Cluster{}
+	cluster.Spec.Configuration.KafkaAPI.Port = port
+
+	c, err := admin.NewAdminAPIClient(context.Background(), fake.NewClientBuilder().Build(), cluster, host)
+	require.NoError(t, err)
+	return c
+}
+
+func TestConsumerGroupLags(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("orders", 0, mb.BrokerID()).
+			SetLeader("orders", 1, mb.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "billing", mb).
+			SetCoordinator(sarama.CoordinatorGroup, "analytics", mb),
+		"ListGroupsRequest": sarama.NewMockListGroupsResponse(t).
+			AddGroup("billing", "consumer").
+			AddGroup("analytics", "consumer"),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("billing", "orders", 0, 90, "", sarama.ErrNoError).
+			// no committed offset
+			SetOffset("billing", "orders", 1, -1, "", sarama.ErrNoError).
+			SetOffset("analytics", "orders", 0, 10, "", sarama.ErrNoError).
+			SetOffset("analytics", "orders", 1, 50, "", sarama.ErrNoError),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset("orders", 0, sarama.OffsetNewest, 100).
+			SetOffset("orders", 1, sarama.OffsetNewest, 60),
+	})
+
+	c := newKafkaAPIClient(t, mb)
+	lags, err := c.ConsumerGroupLags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []admin.ConsumerGroupLag{
+		{Group: "analytics", Lag: 100},
+		{Group: "billing", Lag: 10},
+	}, lags)
+}
+
+func TestResetConsumerGroupOffsets(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("orders", 0, mb.BrokerID()).
+			SetLeader("orders", 1, mb.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "billing", mb).
+			SetCoordinator(sarama.CoordinatorGroup, "analytics", mb),
+		"DescribeGroupsRequest": sarama.NewMockDescribeGroupsResponse(t).
+			AddGroupDescription("billing", &sarama.GroupDescription{
+				GroupId: "billing", State: "Empty", ProtocolType: "consumer",
+			}).
+			AddGroupDescription("analytics", &sarama.GroupDescription{
+				GroupId: "analytics", State: "Stable", ProtocolType: "consumer",
+				Members: map[string]*sarama.GroupMemberDescription{
+					"consumer-1": {ClientId: "consumer", ClientHost: "/10.0.0.1"},
+				},
+			}),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("billing", "orders", 0, 90, "", sarama.ErrNoError).
+			SetOffset("billing", "orders", 1, 40, "", sarama.ErrNoError),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset("orders", 0, sarama.OffsetOldest, 5).
+			SetOffset("orders", 1, sarama.OffsetOldest, 0),
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+	})
+	commits := func() []*sarama.OffsetCommitRequest {
+		var requests []*sarama.OffsetCommitRequest
+		for _, rr := range mb.History() {
+			if req, ok := rr.Request.(*sarama.OffsetCommitRequest); ok {
+				requests = append(requests, req)
+			}
+		}
+		return requests
+	}
+
+	c := newKafkaAPIClient(t, mb)
+	expected := []admin.PartitionOffset{
+		{Topic: "orders", Partition: 0, Offset: 5},
+		{Topic: "orders", Partition: 1, Offset: 0},
+	}
+
+	// nothing is committed by dry run
+	offsets, err := c.ResetConsumerGroupOffsets(context.Background(), "billing", "earliest", true)
+	require.NoError(t, err)
+	assert.Equal(t, expected, offsets)
+	assert.Empty(t, commits())
+
+	offsets, err = c.ResetConsumerGroupOffsets(context.Background(), "billing", "earliest", false)
+	require.NoError(t, err)
+	assert.Equal(t, expected, offsets)
+	require.Len(t, commits(), 1)
+	commit := commits()[0]
+	assert.Equal(t, "billing", commit.ConsumerGroup)
+	assert.Equal(t, int32(sarama.GroupGenerationUndefined), commit.ConsumerGroupGeneration)
+	assert.Empty(t, commit.ConsumerID)
+	for _, o := range expected {
+		offset, _, err := commit.Offset(o.Topic, o.Partition)
+		require.NoError(t, err)
+		assert.Equal(t, o.Offset, offset)
+	}
+
+	// members of the group would override the reset
+	_, err = c.ResetConsumerGroupOffsets(context.Background(), "analytics", "latest", false)
+	assert.ErrorIs(t, err, admin.ErrConsumerGroupActive)
+	_, err = c.ResetConsumerGroupOffsets(context.Background(), "billing", "newest", false)
+	assert.ErrorIs(t, err, admin.ErrUnknownOffsetReset)
+	assert.Len(t, commits(), 1)
+}
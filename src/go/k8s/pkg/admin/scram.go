@@ -0,0 +1,142 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errSCRAM = errors.New("SCRAM authentication failed")
+
+// scramClient implements SCRAM-SHA-256 client of RFC 5802 used by sarama
+// for SASL authentication. Redpanda doesn't support the PLAIN mechanism.
+type scramClient struct {
+	username    string
+	password    string
+	clientNonce string
+	clientFirst string
+	serverSig   []byte
+	step        int
+	done        bool
+}
+
+// Begin implements sarama.SCRAMClient
+func (s *scramClient) Begin(userName, password, _ string) error {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	s.username = userName
+	s.password = password
+	s.clientNonce = base64.RawStdEncoding.EncodeToString(nonce)
+	s.step = 0
+	s.done = false
+	return nil
+}
+
+// Step implements sarama.SCRAMClient
+func (s *scramClient) Step(challenge string) (string, error) {
+	s.step++
+	switch s.step {
+	case 1:
+		name := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s.username)
+		s.clientFirst = "n=" + name + ",r=" + s.clientNonce
+		return "n,," + s.clientFirst, nil
+	case 2:
+		return s.clientFinal(challenge)
+	case 3:
+		attrs := scramAttributes(challenge)
+		if e, ok := attrs["e"]; ok {
+			return "", fmt.Errorf("%w: %s", errSCRAM, e)
+		}
+		sig, err := base64.StdEncoding.DecodeString(attrs["v"])
+		if err != nil || !hmac.Equal(sig, s.serverSig) {
+			return "", fmt.Errorf("%w: invalid server signature", errSCRAM)
+		}
+		s.done = true
+		return "", nil
+	default:
+		return "", fmt.Errorf("%w: unexpected challenge", errSCRAM)
+	}
+}
+
+// Done implements sarama.SCRAMClient
+func (s *scramClient) Done() bool {
+	return s.done
+}
+
+func (s *scramClient) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttributes(serverFirst)
+	nonce := attrs["r"]
+	if !strings.HasPrefix(nonce, s.clientNonce) {
+		return "", fmt.Errorf("%w: invalid server nonce", errSCRAM)
+	}
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid salt", errSCRAM)
+	}
+	iterations, err := strconv.Atoi(attrs["i"])
+	if err != nil || iterations < 1 {
+		return "", fmt.Errorf("%w: invalid iteration count", errSCRAM)
+	}
+
+	salted := pbkdf2SHA256([]byte(s.password), salt, iterations)
+	clientKey := hmacSHA256(salted, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	withoutProof := "c=biws,r=" + nonce
+	authMessage := []byte(s.clientFirst + "," + serverFirst + "," + withoutProof)
+
+	proof := hmacSHA256(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSig = hmacSHA256(hmacSHA256(salted, []byte("Server Key")), authMessage)
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+func scramAttributes(message string) map[string]string {
+	attrs := map[string]string{}
+	for _, attr := range strings.Split(message, ",") {
+		if len(attr) > 2 && attr[1] == '=' {
+			attrs[attr[:1]] = attr[2:]
+		}
+	}
+	return attrs
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data) // nolint:errcheck // hash writes never fail
+	return mac.Sum(nil)
+}
+
+// pbkdf2SHA256 derives a single block key, which is the size of the
+// SCRAM-SHA-256 salted password
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	block := make([]byte, 4)
+	binary.BigEndian.PutUint32(block, 1)
+	u := hmacSHA256(password, append(append([]byte{}, salt...), block...))
+	key := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256(password, u)
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
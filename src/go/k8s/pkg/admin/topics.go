@@ -0,0 +1,28 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// EnsureTopics creates the topics that don't exist yet. Existing topics are
+// not modified, so it's safe to call it on every reconciliation.
+func EnsureTopics(ctx context.Context, c AdminAPIClient, topics []Topic) error {
+	for _, topic := range topics {
+		err := c.CreateTopic(ctx, topic)
+		if err != nil && !errors.Is(err, ErrTopicAlreadyExists) {
+			return fmt.Errorf("unable to create topic %s: %w", topic.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+// topicsAdminAPI keeps the topics created through the Admin API
+type topicsAdminAPI struct {
+	fakeAdminAPI
+	topics map[string]admin.Topic
+}
+
+func (f *topicsAdminAPI) CreateTopic(_ context.Context, topic admin.Topic) error {
+	if f.err != nil {
+		return f.err
+	}
+	if _, ok := f.topics[topic.Name]; ok {
+		return admin.ErrTopicAlreadyExists
+	}
+	f.topics[topic.Name] = topic
+	return nil
+}
+
+func TestEnsureTopics(t *testing.T) {
+	existing := admin.Topic{Name: "audit", Partitions: 1, ReplicationFactor: 1}
+	api := &topicsAdminAPI{topics: map[string]admin.Topic{"audit": existing}}
+	topics := []admin.Topic{
+		{Name: "dead-letter", Partitions: 3, ReplicationFactor: 3},
+		{Name: "audit", Partitions: 6, ReplicationFactor: 3},
+	}
+
+	require.NoError(t, admin.EnsureTopics(context.Background(), api, topics))
+	assert.Equal(t, topics[0], api.topics["dead-letter"])
+	assert.Equal(t, existing, api.topics["audit"], "existing topic is not modified")
+
+	// repeated bootstrap is a no-op
+	require.NoError(t, admin.EnsureTopics(context.Background(), api, topics))
+	assert.Len(t, api.topics, 2)
+
+	api.err = errUnreachable
+	err := admin.EnsureTopics(context.Background(), api, topics)
+	assert.True(t, errors.Is(err, errUnreachable))
+}
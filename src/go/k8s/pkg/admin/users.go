@@ -0,0 +1,56 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SyncUsers creates the desired users, mapped to their passwords, that
+// don't exist yet and deletes the previously provisioned users that are no
+// longer desired. Users created by others are never deleted and passwords
+// of existing users are not changed.
+func SyncUsers(
+	ctx context.Context,
+	c AdminAPIClient,
+	desired map[string]string,
+	provisioned []string,
+) error {
+	users, err := c.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list users: %w", err)
+	}
+	existing := make(map[string]bool, len(users))
+	for _, u := range users {
+		existing[u] = true
+	}
+
+	for username, password := range desired {
+		if existing[username] {
+			continue
+		}
+		err = c.CreateUser(ctx, username, password)
+		if err != nil && !errors.Is(err, ErrUserAlreadyExists) {
+			return fmt.Errorf("unable to create user %s: %w", username, err)
+		}
+	}
+
+	for _, username := range provisioned {
+		if _, ok := desired[username]; ok || !existing[username] {
+			continue
+		}
+		if err = c.DeleteUser(ctx, username); err != nil {
+			return fmt.Errorf("unable to delete user %s: %w", username, err)
+		}
+	}
+	return nil
+}
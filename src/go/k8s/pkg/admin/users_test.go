@@ -0,0 +1,95 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package admin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/admin"
+)
+
+// usersAdminAPI records the calls managing SCRAM users
+type usersAdminAPI struct {
+	fakeAdminAPI
+	users   map[string]string
+	created []string
+	deleted []string
+}
+
+func (f *usersAdminAPI) ListUsers(context.Context) ([]string, error) {
+	users := make([]string, 0, len(f.users))
+	for u := range f.users {
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func (f *usersAdminAPI) CreateUser(_ context.Context, username, password string) error {
+	if _, ok := f.users[username]; ok {
+		return admin.ErrUserAlreadyExists
+	}
+	f.users[username] = password
+	f.created = append(f.created, username)
+	return nil
+}
+
+func (f *usersAdminAPI) DeleteUser(_ context.Context, username string) error {
+	delete(f.users, username)
+	f.deleted = append(f.deleted, username)
+	return nil
+}
+
+func TestSyncUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    map[string]string
+		desired     map[string]string
+		provisioned []string
+		created     []string
+		deleted     []string
+	}{
+		{
+			name:     "add",
+			existing: map[string]string{"client": "other"},
+			desired:  map[string]string{"admin": "secret"},
+			created:  []string{"admin"},
+		},
+		{
+			name:        "remove",
+			existing:    map[string]string{"admin": "secret", "client": "other"},
+			provisioned: []string{"admin"},
+			deleted:     []string{"admin"},
+		},
+		{
+			name:        "no-op",
+			existing:    map[string]string{"admin": "secret", "client": "other"},
+			desired:     map[string]string{"admin": "changed"},
+			provisioned: []string{"admin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := map[string]string{}
+			for u, p := range tt.existing {
+				users[u] = p
+			}
+			api := &usersAdminAPI{users: users}
+
+			err := admin.SyncUsers(context.Background(), api, tt.desired, tt.provisioned)
+			require.NoError(t, err)
+			assert.Equal(t, tt.created, api.created)
+			assert.Equal(t, tt.deleted, api.deleted)
+			assert.Contains(t, api.users, "client", "users created by others are kept")
+		})
+	}
+}
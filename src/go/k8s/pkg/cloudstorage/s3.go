@@ -0,0 +1,177 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package cloudstorage contains checks of the object store used by the
+// Redpanda tiered storage (data archiving)
+package cloudstorage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	serviceName      = "s3"
+	// hex encoded SHA256 of the empty HEAD request body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	dateFormat       = "20060102"
+	timestampFormat  = "20060102T150405Z"
+	requestTimeout   = 10 * time.Second
+)
+
+var (
+	// ErrAccessDenied is returned when the object store rejects the credentials
+	ErrAccessDenied = errors.New("access to the bucket is denied")
+	// ErrBucketNotFound is returned when the bucket does not exist
+	ErrBucketNotFound = errors.New("bucket does not exist")
+	// ErrUnexpectedStatus is returned for any other non-successful response
+	ErrUnexpectedStatus = errors.New("unexpected response from the object store")
+)
+
+// Bucket describes the object store bucket and the credentials used to
+// access it
+type Bucket struct {
+	Name      string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Endpoint overrides the default s3.<region>.amazonaws.com host
+	Endpoint string
+	// Port overrides the default port of the scheme
+	Port       int
+	DisableTLS bool
+}
+
+// Checker verifies that the bucket can be reached with the given credentials
+type Checker interface {
+	HeadBucket(ctx context.Context, bucket Bucket) error
+}
+
+// S3Checker issues a signed (AWS Signature Version 4) HEAD bucket request
+// to an S3 compatible object store
+type S3Checker struct {
+	Client *http.Client
+	now    func() time.Time
+}
+
+var _ Checker = &S3Checker{}
+
+// NewS3Checker creates S3Checker with a client that gives up after
+// requestTimeout
+func NewS3Checker() *S3Checker {
+	return &S3Checker{
+		Client: &http.Client{Timeout: requestTimeout},
+		now:    time.Now,
+	}
+}
+
+// HeadBucket returns nil when the object store responds with success to
+// HEAD bucket request
+func (c *S3Checker) HeadBucket(ctx context.Context, bucket Bucket) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, bucketURL(bucket), nil)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	sign(req, bucket, now().UTC())
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("bucket %s: %w", bucket.Name, ErrAccessDenied)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("bucket %s: %w", bucket.Name, ErrBucketNotFound)
+	default:
+		return fmt.Errorf("bucket %s, status %d: %w", bucket.Name, resp.StatusCode, ErrUnexpectedStatus)
+	}
+}
+
+func bucketURL(bucket Bucket) string {
+	scheme := "https"
+	if bucket.DisableTLS {
+		scheme = "http"
+	}
+	host := bucket.Endpoint
+	if host == "" {
+		host = fmt.Sprintf("s3.%s.amazonaws.com", bucket.Region)
+	}
+	if bucket.Port != 0 {
+		host = host + ":" + strconv.Itoa(bucket.Port)
+	}
+	// path style addressing works for both AWS and S3 compatible stores
+	return fmt.Sprintf("%s://%s/%s", scheme, host, bucket.Name)
+}
+
+// sign adds AWS Signature Version 4 headers to the request without body
+func sign(req *http.Request, bucket Bucket, t time.Time) {
+	timestamp := t.Format(timestampFormat)
+	date := t.Format(dateFormat)
+
+	req.Header.Set("X-Amz-Date", timestamp)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.URL.Host, emptyPayloadHash, timestamp)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, bucket.Region, serviceName, "aws4_request"}, "/")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		timestamp,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+bucket.SecretKey), date)
+	key = hmacSHA256(key, bucket.Region)
+	key = hmacSHA256(key, serviceName)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, bucket.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data)) // nolint:errcheck // hash writes never fail
+	return h.Sum(nil)
+}
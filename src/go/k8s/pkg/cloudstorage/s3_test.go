@@ -0,0 +1,87 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cloudstorage_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/cloudstorage"
+)
+
+// mockObjectStore accepts HEAD requests for the "archive" bucket signed
+// with the "valid" access key
+func mockObjectStore(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.NotEmpty(t, r.Header.Get("X-Amz-Date"))
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=valid/") ||
+			!strings.Contains(auth, "/us-west-1/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/archive" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func bucketFor(t *testing.T, server *httptest.Server) cloudstorage.Bucket {
+	t.Helper()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return cloudstorage.Bucket{
+		Name:       "archive",
+		Region:     "us-west-1",
+		AccessKey:  "valid",
+		SecretKey:  "secret",
+		Endpoint:   u.Hostname(),
+		Port:       port,
+		DisableTLS: true,
+	}
+}
+
+func TestHeadBucket(t *testing.T) {
+	server := mockObjectStore(t)
+	defer server.Close()
+	checker := cloudstorage.NewS3Checker()
+
+	t.Run("reachable", func(t *testing.T) {
+		err := checker.HeadBucket(context.Background(), bucketFor(t, server))
+		assert.NoError(t, err)
+	})
+
+	t.Run("auth failure", func(t *testing.T) {
+		bucket := bucketFor(t, server)
+		bucket.AccessKey = "invalid"
+		err := checker.HeadBucket(context.Background(), bucket)
+		assert.ErrorIs(t, err, cloudstorage.ErrAccessDenied)
+	})
+
+	t.Run("missing bucket", func(t *testing.T) {
+		bucket := bucketFor(t, server)
+		bucket.Name = "other"
+		err := checker.HeadBucket(context.Background(), bucket)
+		assert.ErrorIs(t, err, cloudstorage.ErrBucketNotFound)
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package dns contains DNS checks of the names used by Redpanda listeners
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// Resolver looks up name server records. net.Resolver implements it.
+type Resolver interface {
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+}
+
+var _ Resolver = net.DefaultResolver
+
+// IsDelegated returns true when the subdomain is a delegated DNS zone, i.e.
+// there are name server records published for it. A missing record means
+// that external clients won't be able to resolve broker addresses.
+func IsDelegated(
+	ctx context.Context, resolver Resolver, subdomain string,
+) (bool, error) {
+	zone := strings.TrimSuffix(subdomain, ".") + "."
+	ns, err := resolver.LookupNS(ctx, zone)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(ns) > 0, nil
+}
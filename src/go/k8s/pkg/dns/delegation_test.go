@@ -0,0 +1,64 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package dns_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/dns"
+)
+
+type stubResolver struct {
+	zones map[string][]*net.NS
+	err   error
+}
+
+func (r *stubResolver) LookupNS(_ context.Context, name string) ([]*net.NS, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	ns, ok := r.zones[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return ns, nil
+}
+
+func TestIsDelegated(t *testing.T) {
+	resolver := &stubResolver{zones: map[string][]*net.NS{
+		"redpanda.example.com.": {{Host: "ns1.example.com."}},
+	}}
+
+	tests := []struct {
+		name      string
+		subdomain string
+		delegated bool
+	}{
+		{"delegated zone", "redpanda.example.com", true},
+		{"delegated zone with trailing dot", "redpanda.example.com.", true},
+		{"zone without name servers", "other.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delegated, err := dns.IsDelegated(context.Background(), resolver, tt.subdomain)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.delegated, delegated)
+		})
+	}
+}
+
+func TestIsDelegatedResolverFailure(t *testing.T) {
+	resolver := &stubResolver{err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}
+	_, err := dns.IsDelegated(context.Background(), resolver, "redpanda.example.com")
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package metrics contains Prometheus metrics exposed by the operator
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ReconcileResultSuccess labels reconciliations that finished without error
+	ReconcileResultSuccess = "success"
+	// ReconcileResultError labels reconciliations that returned an error
+	ReconcileResultError = "error"
+	// ReconcileResultRequeue labels reconciliations that asked to be requeued
+	ReconcileResultRequeue = "requeue"
+)
+
+// ReconcileDuration tracks how long the reconciliation of a Cluster takes
+var ReconcileDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "redpanda_operator",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the Cluster reconciliation labeled by result",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	},
+	[]string{"result"},
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(ReconcileDuration)
+}
+
+// ObserveReconcile records the duration of a single reconciliation
+func ObserveReconcile(result ctrl.Result, err error, duration time.Duration) {
+	ReconcileDuration.WithLabelValues(reconcileResult(result, err)).Observe(duration.Seconds())
+}
+
+func reconcileResult(result ctrl.Result, err error) string {
+	switch {
+	case err != nil:
+		return ReconcileResultError
+	case result.Requeue || result.RequeueAfter > 0:
+		return ReconcileResultRequeue
+	default:
+		return ReconcileResultSuccess
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/metrics"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var errReconcile = errors.New("reconcile failure")
+
+func TestObserveReconcile(t *testing.T) {
+	tests := []struct {
+		name           string
+		result         ctrl.Result
+		err            error
+		expectedResult string
+	}{
+		{"success", ctrl.Result{}, nil, metrics.ReconcileResultSuccess},
+		{"error", ctrl.Result{}, errReconcile, metrics.ReconcileResultError},
+		{"requeue", ctrl.Result{Requeue: true}, nil, metrics.ReconcileResultRequeue},
+		{"requeue after", ctrl.Result{RequeueAfter: time.Second}, nil, metrics.ReconcileResultRequeue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := sampleCount(t, tt.expectedResult)
+			metrics.ObserveReconcile(tt.result, tt.err, time.Second)
+			assert.Equal(t, before+1, sampleCount(t, tt.expectedResult))
+		})
+	}
+}
+
+func sampleCount(t *testing.T, result string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	h, ok := metrics.ReconcileDuration.WithLabelValues(result).(prometheus.Histogram)
+	require.True(t, ok)
+	require.NoError(t, h.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package networking holds the Service port names and the broker Service
+// addresses shared by the operator and the configurator. It depends only on
+// the Kubernetes API types, so the configurator doesn't pull in the
+// reconcilers.
+package networking
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// KafkaPortName is name of kafka port in Service definition
+	KafkaPortName = "kafka"
+	// AdminPortName is name of admin port in Service definition
+	AdminPortName = "admin"
+)
+
+// ErrBrokerServiceNotReady is returned when the node port or the load
+// balancer address of a broker Service is not assigned yet
+var ErrBrokerServiceNotReady = errors.New("the broker service address is not assigned yet")
+
+// BrokerServiceName returns the name of the Service exposing the broker Pod
+func BrokerServiceName(podName string) string {
+	return podName + "-external"
+}
+
+// BrokerServiceAddress returns the host and port advertised by a broker
+// exposed through the Service. The nodeIP is advertised for the Service of
+// type NodePort and the load balancer ingress otherwise.
+func BrokerServiceAddress(
+	svc *corev1.Service, portName, nodeIP string,
+) (string, int32, error) {
+	var port *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Name == portName {
+			port = &svc.Spec.Ports[i]
+		}
+	}
+	if port == nil {
+		return "", 0, fmt.Errorf("port %s of Service %s: %w", portName, svc.Name, ErrBrokerServiceNotReady)
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return ingress.IP, port.Port, nil
+			}
+			if ingress.Hostname != "" {
+				return ingress.Hostname, port.Port, nil
+			}
+		}
+		return "", 0, fmt.Errorf("load balancer of Service %s: %w", svc.Name, ErrBrokerServiceNotReady)
+	}
+
+	if port.NodePort == 0 {
+		return "", 0, fmt.Errorf("node port %s of Service %s: %w", portName, svc.Name, ErrBrokerServiceNotReady)
+	}
+	return nodeIP, port.NodePort, nil
+}
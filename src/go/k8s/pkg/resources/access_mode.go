@@ -0,0 +1,118 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// blockAccessModes are supported by provisioners of block devices that
+	// can be attached to a single node only
+	blockAccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+
+	// provisionerAccessModes lists access modes supported by well known
+	// provisioners. Provisioners missing here are not validated.
+	provisionerAccessModes = map[string][]corev1.PersistentVolumeAccessMode{
+		LocalVolumeProvisioner:     blockAccessModes,
+		"kubernetes.io/aws-ebs":    blockAccessModes,
+		"ebs.csi.aws.com":          blockAccessModes,
+		"kubernetes.io/gce-pd":     {corev1.ReadWriteOnce, corev1.ReadOnlyMany},
+		"pd.csi.storage.gke.io":    {corev1.ReadWriteOnce, corev1.ReadOnlyMany},
+		"kubernetes.io/azure-disk": blockAccessModes,
+		"disk.csi.azure.com":       blockAccessModes,
+		"kubernetes.io/cinder":     blockAccessModes,
+		"cinder.csi.openstack.org": blockAccessModes,
+		"kubernetes.io/azure-file": {corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany},
+		"file.csi.azure.com":       {corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany},
+		"efs.csi.aws.com":          {corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany},
+	}
+)
+
+var _ Reconciler = &AccessModeValidator{}
+
+// AccessModeValidator is part of the reconciliation of redpanda.vectorized.io CRD.
+// It verifies that the provisioner of the storage class supports the access
+// modes of the data directory, as pods hang in Pending state otherwise.
+type AccessModeValidator struct {
+	k8sclient.Client
+	pandaCluster *redpandav1alpha1.Cluster
+	recorder     record.EventRecorder
+	logger       logr.Logger
+}
+
+// NewAccessModeValidator creates AccessModeValidator
+func NewAccessModeValidator(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	recorder record.EventRecorder,
+	logger logr.Logger,
+) *AccessModeValidator {
+	return &AccessModeValidator{
+		client,
+		pandaCluster,
+		recorder,
+		logger.WithValues("Reconciler", "access-mode"),
+	}
+}
+
+// Ensure rejects the reconciliation when a requested access mode is not
+// supported by the provisioner of the storage class
+func (r *AccessModeValidator) Ensure(ctx context.Context) error {
+	className := r.pandaCluster.Spec.Storage.StorageClassName
+	if className == "" || len(r.pandaCluster.Spec.Storage.AccessModes) == 0 {
+		return nil
+	}
+
+	var sc storagev1.StorageClass
+	err := r.Get(ctx, types.NamespacedName{Name: className}, &sc)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to fetch StorageClass %s: %w", className, err)
+	}
+	supported, known := provisionerAccessModes[sc.Provisioner]
+	if !known {
+		return nil
+	}
+
+	for _, mode := range r.pandaCluster.Spec.Storage.AccessModes {
+		if containsAccessMode(supported, mode) {
+			continue
+		}
+		msg := fmt.Sprintf("access mode %s is not supported by provisioner %s of storage class %s",
+			mode, sc.Provisioner, className)
+		r.recorder.Event(r.pandaCluster, corev1.EventTypeWarning, "UnsupportedAccessMode", msg)
+		return &RequeueAfterError{RequeueAfter: requeueDuration, Msg: msg}
+	}
+	return nil
+}
+
+func containsAccessMode(
+	modes []corev1.PersistentVolumeAccessMode, mode corev1.PersistentVolumeAccessMode,
+) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
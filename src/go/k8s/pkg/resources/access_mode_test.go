@@ -0,0 +1,69 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAccessModeValidator(t *testing.T) {
+	cluster := pandaCluster()
+
+	class := func(provisioner string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: cluster.Spec.Storage.StorageClassName},
+			Provisioner: provisioner,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		class       *storagev1.StorageClass
+		accessModes []corev1.PersistentVolumeAccessMode
+		expectError bool
+	}{
+		{"default access mode", class("ebs.csi.aws.com"), nil, false},
+		{"read write once on block storage", class("ebs.csi.aws.com"), []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, false},
+		{"read write many on block storage", class("ebs.csi.aws.com"), []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, true},
+		{"read write many on local volumes", class(res.LocalVolumeProvisioner), []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, true},
+		{"read only many on persistent disk", class("pd.csi.storage.gke.io"), []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadOnlyMany}, false},
+		{"read write many on file storage", class("efs.csi.aws.com"), []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, false},
+		{"unknown provisioner is not checked", class("example.com/custom"), []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(tt.class).Build()
+			cl := cluster.DeepCopy()
+			cl.Spec.Storage.AccessModes = tt.accessModes
+			recorder := record.NewFakeRecorder(10)
+
+			err := res.NewAccessModeValidator(c, cl, recorder, ctrl.Log.WithName("test")).Ensure(context.Background())
+			if !tt.expectError {
+				assert.NoError(t, err)
+				assert.Len(t, recorder.Events, 0)
+				return
+			}
+			var requeueErr *res.RequeueAfterError
+			assert.True(t, errors.As(err, &requeueErr))
+			assert.Len(t, recorder.Events, 1)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const adminExternalSuffix = "-admin-external"
+
+var _ Resource = &AdminNodePortServiceResource{}
+
+// AdminNodePortServiceResource is part of the reconciliation of
+// redpanda.vectorized.io CRD that assigns a port on each node to expose the
+// Admin API when the Kafka API is not exposed
+type AdminNodePortServiceResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewAdminNodePortService creates AdminNodePortServiceResource
+func NewAdminNodePortService(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *AdminNodePortServiceResource {
+	return &AdminNodePortServiceResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", serviceKind(), "ServiceType", "AdminNodePort"),
+	}
+}
+
+// Ensure will manage kubernetes v1.Service exposing the Admin API. Like the
+// node port Service of the Kafka API, the Service is never updated, so the
+// assigned node port doesn't change.
+func (r *AdminNodePortServiceResource) Ensure(ctx context.Context) error {
+	if !r.pandaCluster.SeparateExternalAdmin() {
+		return r.cleanup(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+
+	_, err = CreateIfNotExists(ctx, r, obj, r.logger)
+	return err
+}
+
+func (r *AdminNodePortServiceResource) cleanup(ctx context.Context) error {
+	var svc corev1.Service
+	err := r.Get(ctx, r.Key(), &svc)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	r.logger.Info("Removing external Admin API Service", "name", svc.Name)
+	if err := r.Delete(ctx, &svc); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete external Admin API Service: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *AdminNodePortServiceResource) obj() (k8sclient.Object, error) {
+	adminPort := r.pandaCluster.Spec.Configuration.AdminAPI.Port
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			// the node port is published as the host port of the brokers,
+			// see NodePortServiceResource
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       AdminPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(adminPort),
+					TargetPort: intstr.FromInt(adminPort),
+				},
+			},
+			Selector: nil,
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, svc, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *AdminNodePortServiceResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + adminExternalSuffix, Namespace: r.pandaCluster.Namespace}
+}
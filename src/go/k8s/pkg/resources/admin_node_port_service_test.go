@@ -0,0 +1,56 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdminNodePortService(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	// removing the disabled Service needs the delete verb
+	c := newRBACClient(t, fake.NewClientBuilder().Build())
+	adminSvc := res.NewAdminNodePortService(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+
+	// the Admin API is not exposed by default
+	require.NoError(t, adminSvc.Ensure(ctx))
+	var svc corev1.Service
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, adminSvc.Key(), &svc)))
+
+	cluster.Spec.ExternalAdmin = redpandav1alpha1.ExternalAdminConfig{Enabled: true}
+	require.NoError(t, adminSvc.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, adminSvc.Key(), &svc))
+	assert.Equal(t, "cluster-admin-external", svc.Name)
+	assert.Equal(t, corev1.ServiceTypeNodePort, svc.Spec.Type)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyTypeLocal, svc.Spec.ExternalTrafficPolicy)
+	require.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, res.AdminPortName, svc.Spec.Ports[0].Name)
+	assert.Equal(t, int32(cluster.Spec.Configuration.AdminAPI.Port), svc.Spec.Ports[0].Port)
+
+	// the external connectivity exposes the Admin API along with the Kafka
+	// API
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	require.NoError(t, adminSvc.Ensure(ctx))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, adminSvc.Key(), &svc)))
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	advertisedSuffix = "-advertised"
+
+	// AdvertisedListenerLabel marks the EndpointSlices with the advertised
+	// addresses of the brokers, the value is the lower case listener name
+	AdvertisedListenerLabel = "redpanda.vectorized.io/advertised-listener"
+	// endpointSliceManagedByValue tells the EndpointSlice controller of
+	// Kubernetes to leave the slices to the operator
+	endpointSliceManagedByValue = "redpanda.vectorized.io"
+)
+
+var _ Resource = &AdvertisedEndpointsResource{}
+
+// AdvertisedEndpointsResource publishes the advertised Kafka API addresses
+// of the brokers, as reported in the Cluster status, as EndpointSlices for
+// service discovery tools. The slices are not attached to any Service, so
+// they don't change the DNS records or the routing of the cluster Services.
+type AdvertisedEndpointsResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewAdvertisedEndpoints creates AdvertisedEndpointsResource
+func NewAdvertisedEndpoints(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *AdvertisedEndpointsResource {
+	return &AdvertisedEndpointsResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", endpointSliceKind()),
+	}
+}
+
+// Ensure will manage discovery/v1beta1.EndpointSlices with the advertised
+// addresses of every Kafka API listener. The addresses of a listener are
+// split into one slice per port, as brokers exposed by their own Service can
+// advertise different ports. Slices of ports and listeners that are not
+// advertised anymore are removed.
+func (r *AdvertisedEndpointsResource) Ensure(ctx context.Context) error {
+	keep := map[string]bool{}
+	if r.pandaCluster.Spec.PublishAdvertisedEndpoints {
+		objs, err := r.objs()
+		if err != nil {
+			return fmt.Errorf("unable to construct object: %w", err)
+		}
+		for _, obj := range objs {
+			keep[obj.GetName()] = true
+			created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+			if err != nil {
+				return err
+			}
+			if created {
+				continue
+			}
+			var slice discoveryv1beta1.EndpointSlice
+			err = r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, &slice)
+			if err != nil {
+				return fmt.Errorf("error while fetching EndpointSlice resource: %w", err)
+			}
+			if err := Update(ctx, &slice, obj, r.Client, r.logger); err != nil {
+				return err
+			}
+		}
+	}
+	return r.cleanup(ctx, keep)
+}
+
+// cleanup removes the EndpointSlices of the cluster that are not kept
+func (r *AdvertisedEndpointsResource) cleanup(ctx context.Context, keep map[string]bool) error {
+	var slices discoveryv1beta1.EndpointSliceList
+	err := r.List(ctx, &slices, &k8sclient.ListOptions{
+		LabelSelector: labels.ForCluster(r.pandaCluster).AsClientSelector(),
+		Namespace:     r.pandaCluster.Namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list EndpointSlices: %w", err)
+	}
+	for i := range slices.Items {
+		slice := &slices.Items[i]
+		if _, ok := slice.Labels[AdvertisedListenerLabel]; !ok || keep[slice.Name] {
+			continue
+		}
+		r.logger.Info("Removing advertised EndpointSlice", "name", slice.Name)
+		if err := r.Delete(ctx, slice); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete EndpointSlice %s: %w", slice.Name, err)
+		}
+	}
+	return nil
+}
+
+// objs returns the EndpointSlices of the advertised addresses
+func (r *AdvertisedEndpointsResource) objs() ([]k8sclient.Object, error) {
+	nodes := r.pandaCluster.Status.Nodes
+	addresses := map[string][]string{redpandav1alpha1.InternalListener: internalBootstrapAddresses(r.pandaCluster)}
+	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+		addresses[redpandav1alpha1.ExternalListener] = nodes.External
+	}
+
+	var objs []k8sclient.Object
+	for _, listener := range []string{redpandav1alpha1.InternalListener, redpandav1alpha1.ExternalListener} {
+		byPort, err := groupByPort(addresses[listener])
+		if err != nil {
+			return nil, fmt.Errorf("advertised %s addresses: %w", listener, err)
+		}
+		ports := make([]int, 0, len(byPort))
+		for port := range byPort {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		for _, port := range ports {
+			slice, err := r.slice(listener, port, byPort[port])
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, slice)
+		}
+	}
+	return objs, nil
+}
+
+func (r *AdvertisedEndpointsResource) slice(
+	listener string, port int, hosts []string,
+) (k8sclient.Object, error) {
+	objLabels := labels.ForCluster(r.pandaCluster).AsSet()
+	objLabels[AdvertisedListenerLabel] = strings.ToLower(listener)
+	objLabels[discoveryv1beta1.LabelManagedBy] = endpointSliceManagedByValue
+
+	endpoints := make([]discoveryv1beta1.Endpoint, 0, len(hosts))
+	for _, host := range hosts {
+		endpoints = append(endpoints, discoveryv1beta1.Endpoint{
+			// FQDN addresses can't end with the root label
+			Addresses: []string{strings.TrimSuffix(host, ".")},
+		})
+	}
+	name := KafkaPortName
+	protocol := corev1.ProtocolTCP
+	portNumber := int32(port)
+
+	slice := &discoveryv1beta1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.pandaCluster.Namespace,
+			Name: fmt.Sprintf("%s%s-%s-%d",
+				r.pandaCluster.Name, advertisedSuffix, strings.ToLower(listener), port),
+			Labels: objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "EndpointSlice",
+			APIVersion: "discovery.k8s.io/v1beta1",
+		},
+		AddressType: addressType(hosts),
+		Endpoints:   endpoints,
+		Ports: []discoveryv1beta1.EndpointPort{
+			{Name: &name, Protocol: &protocol, Port: &portNumber},
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, slice, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return slice, nil
+}
+
+// groupByPort splits host:port addresses into the hosts of every port
+func groupByPort(addresses []string) (map[int][]string, error) {
+	byPort := map[int][]string{}
+	for _, address := range addresses {
+		host, portStr, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port of %s: %w", address, err)
+		}
+		byPort[port] = append(byPort[port], host)
+	}
+	return byPort, nil
+}
+
+// addressType returns the IP family when all hosts are IP addresses of the
+// same family and FQDN otherwise
+func addressType(hosts []string) discoveryv1beta1.AddressType {
+	ipv4, ipv6 := 0, 0
+	for _, host := range hosts {
+		ip := net.ParseIP(host)
+		switch {
+		case ip == nil:
+		case ip.To4() != nil:
+			ipv4++
+		default:
+			ipv6++
+		}
+	}
+	switch {
+	case ipv4 == len(hosts):
+		return discoveryv1beta1.AddressTypeIPv4
+	case ipv6 == len(hosts):
+		return discoveryv1beta1.AddressTypeIPv6
+	default:
+		return discoveryv1beta1.AddressTypeFQDN
+	}
+}
+
+func endpointSliceKind() string {
+	var slice discoveryv1beta1.EndpointSlice
+	return slice.Kind
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdvertisedEndpoints(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.PublishAdvertisedEndpoints = true
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Status.Nodes = redpandav1alpha1.NodesList{
+		Internal: []string{"cluster-0.cluster.default.svc.cluster.local."},
+		External: []string{"10.0.0.1:30001"},
+	}
+
+	c := fake.NewClientBuilder().Build()
+	endpoints := res.NewAdvertisedEndpoints(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, endpoints.Ensure(ctx))
+
+	slices := listAdvertisedEndpoints(t, c, cluster.Namespace)
+	require.Len(t, slices, 2)
+	internal := slices["cluster-advertised-internal-123"]
+	assert.Equal(t, discoveryv1beta1.AddressTypeFQDN, internal.AddressType)
+	assert.Equal(t, [][]string{{"cluster-0.cluster.default.svc.cluster.local"}}, sliceAddresses(internal))
+	assert.Equal(t, int32(123), *internal.Ports[0].Port)
+	assert.Equal(t, "internal", internal.Labels[res.AdvertisedListenerLabel])
+	assert.Equal(t, "redpanda.vectorized.io", internal.Labels[discoveryv1beta1.LabelManagedBy])
+	external := slices["cluster-advertised-external-30001"]
+	assert.Equal(t, discoveryv1beta1.AddressTypeIPv4, external.AddressType)
+	assert.Equal(t, [][]string{{"10.0.0.1"}}, sliceAddresses(external))
+
+	// scale up, the brokers exposed by their own Service advertise
+	// different ports
+	cluster.Status.Nodes = redpandav1alpha1.NodesList{
+		Internal: []string{
+			"cluster-0.cluster.default.svc.cluster.local.",
+			"cluster-1.cluster.default.svc.cluster.local.",
+		},
+		External: []string{"0.example.com:30002", "1.example.com:30003"},
+	}
+	require.NoError(t, endpoints.Ensure(ctx))
+
+	slices = listAdvertisedEndpoints(t, c, cluster.Namespace)
+	require.Len(t, slices, 3)
+	assert.Equal(t, [][]string{
+		{"cluster-0.cluster.default.svc.cluster.local"},
+		{"cluster-1.cluster.default.svc.cluster.local"},
+	}, sliceAddresses(slices["cluster-advertised-internal-123"]))
+	assert.Equal(t, [][]string{{"0.example.com"}}, sliceAddresses(slices["cluster-advertised-external-30002"]))
+	assert.Equal(t, discoveryv1beta1.AddressTypeFQDN, slices["cluster-advertised-external-30002"].AddressType)
+	assert.Equal(t, [][]string{{"1.example.com"}}, sliceAddresses(slices["cluster-advertised-external-30003"]))
+
+	// the slices are removed when they are not requested anymore
+	cluster.Spec.PublishAdvertisedEndpoints = false
+	require.NoError(t, endpoints.Ensure(ctx))
+	assert.Empty(t, listAdvertisedEndpoints(t, c, cluster.Namespace))
+}
+
+func listAdvertisedEndpoints(
+	t *testing.T, c client.Client, namespace string,
+) map[string]discoveryv1beta1.EndpointSlice {
+	var list discoveryv1beta1.EndpointSliceList
+	require.NoError(t, c.List(context.Background(), &list, client.InNamespace(namespace)))
+	slices := map[string]discoveryv1beta1.EndpointSlice{}
+	for i := range list.Items {
+		slices[list.Items[i].Name] = list.Items[i]
+	}
+	return slices
+}
+
+func sliceAddresses(slice discoveryv1beta1.EndpointSlice) [][]string {
+	var result [][]string
+	for _, endpoint := range slice.Endpoints {
+		result = append(result, endpoint.Addresses)
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	bootstrapSuffix = "-bootstrap"
+
+	// InternalBootstrapKey is the ConfigMap key with comma separated Kafka API
+	// addresses reachable from within the Kubernetes cluster
+	InternalBootstrapKey = "internal"
+	// ExternalBootstrapKey is the ConfigMap key with comma separated Kafka API
+	// addresses reachable from outside of the Kubernetes cluster
+	ExternalBootstrapKey = "external"
+)
+
+var _ Resource = &BootstrapConfigMapResource{}
+
+// BootstrapConfigMapResource publishes ready to use Kafka bootstrap broker
+// lists derived from the nodes reported in the Cluster status
+type BootstrapConfigMapResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewBootstrapConfigMap creates BootstrapConfigMapResource
+func NewBootstrapConfigMap(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *BootstrapConfigMapResource {
+	return &BootstrapConfigMapResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", configMapKind(), "ConfigMap", "bootstrap"),
+	}
+}
+
+// Ensure will manage kubernetes v1.ConfigMap with bootstrap broker list
+func (r *BootstrapConfigMapResource) Ensure(ctx context.Context) error {
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var cm corev1.ConfigMap
+	err = r.Get(ctx, r.Key(), &cm)
+	if err != nil {
+		return fmt.Errorf("error while fetching ConfigMap resource: %w", err)
+	}
+	return Update(ctx, &cm, obj, r.Client, r.logger)
+}
+
+// obj returns resource managed client.Object
+func (r *BootstrapConfigMapResource) obj() (k8sclient.Object, error) {
+	nodes := r.pandaCluster.Status.Nodes
+	data := map[string]string{
+		InternalBootstrapKey: strings.Join(internalBootstrapAddresses(r.pandaCluster), ","),
+	}
+	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+		data[ExternalBootstrapKey] = strings.Join(nodes.External, ",")
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		Data: data,
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, cm, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
+
+// internalBootstrapAddresses returns the addresses of the Internal Kafka API
+// listener of the brokers. The internal node list contains only host names,
+// the port is the one the listener advertises.
+func internalBootstrapAddresses(
+	pandaCluster *redpandav1alpha1.Cluster,
+) []string {
+	port := strconv.Itoa(pandaCluster.InternalKafkaAPIListener().ClientPort())
+	hosts := pandaCluster.Status.Nodes.Internal
+	addresses := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addresses = append(addresses, net.JoinHostPort(host, port))
+	}
+	return addresses
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *BootstrapConfigMapResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + bootstrapSuffix, Namespace: r.pandaCluster.Namespace}
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapConfigMap(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	cluster.Status.Nodes = redpandav1alpha1.NodesList{
+		Internal: []string{"cluster-0.cluster.default.svc.cluster.local."},
+		External: []string{"10.0.0.1:30001"},
+	}
+
+	c := fake.NewClientBuilder().Build()
+	bootstrap := res.NewBootstrapConfigMap(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, bootstrap.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), bootstrap.Key(), &actual))
+	assert.Equal(t, "cluster-0.cluster.default.svc.cluster.local.:123", actual.Data[res.InternalBootstrapKey])
+	assert.Equal(t, "10.0.0.1:30001", actual.Data[res.ExternalBootstrapKey])
+
+	// scale up
+	cluster.Status.Nodes = redpandav1alpha1.NodesList{
+		Internal: []string{
+			"cluster-0.cluster.default.svc.cluster.local.",
+			"cluster-1.cluster.default.svc.cluster.local.",
+		},
+		External: []string{"10.0.0.1:30001", "10.0.0.2:30001"},
+	}
+	require.NoError(t, bootstrap.Ensure(context.Background()))
+
+	require.NoError(t, c.Get(context.Background(), bootstrap.Key(), &actual))
+	assert.Equal(t, "cluster-0.cluster.default.svc.cluster.local.:123,cluster-1.cluster.default.svc.cluster.local.:123", actual.Data[res.InternalBootstrapKey])
+	assert.Equal(t, "10.0.0.1:30001,10.0.0.2:30001", actual.Data[res.ExternalBootstrapKey])
+
+	// the internal listener advertises another port
+	cluster.Spec.Configuration.AdvertisedKafkaAPIPorts = &redpandav1alpha1.AdvertisedKafkaAPIPorts{Internal: 19092}
+	require.NoError(t, bootstrap.Ensure(context.Background()))
+
+	require.NoError(t, c.Get(context.Background(), bootstrap.Key(), &actual))
+	assert.Equal(t, "cluster-0.cluster.default.svc.cluster.local.:19092,cluster-1.cluster.default.svc.cluster.local.:19092", actual.Data[res.InternalBootstrapKey])
+}
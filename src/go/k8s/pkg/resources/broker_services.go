@@ -0,0 +1,226 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/networking"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// brokerServiceLabel marks the Services exposing a single broker, the value
+// is the name of the broker Pod
+const brokerServiceLabel = "redpanda.vectorized.io/external-broker"
+
+var _ Reconciler = &BrokerServicesResource{}
+
+// BrokerServicesResource is part of the reconciliation of redpanda.vectorized.io CRD
+// that exposes each broker through its own Service when the external
+// connectivity Type is NodePort or LoadBalancer
+type BrokerServicesResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewBrokerServices creates BrokerServicesResource
+func NewBrokerServices(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *BrokerServicesResource {
+	return &BrokerServicesResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", serviceKind(), "ServiceType", "Broker"),
+	}
+}
+
+// Ensure will manage a kubernetes v1.Service for each broker. The brokers
+// advertise the address of their Service, so the reconciliation is requeued
+// until the node ports or the load balancer addresses are assigned. Existing
+// Services are updated, keeping their node ports.
+func (r *BrokerServicesResource) Ensure(ctx context.Context) error {
+	if !r.pandaCluster.Spec.ExternalConnectivity.PerBrokerServices() {
+		return r.cleanup(ctx, nil)
+	}
+
+	podNames := BrokerPodNames(r.pandaCluster)
+	for _, podName := range podNames {
+		obj, err := r.obj(podName)
+		if err != nil {
+			return fmt.Errorf("unable to construct object: %w", err)
+		}
+		created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+		if err != nil {
+			return err
+		}
+		if created {
+			continue
+		}
+		var svc corev1.Service
+		if err := r.Get(ctx, r.Key(podName), &svc); err != nil {
+			return fmt.Errorf("error while fetching Service resource: %w", err)
+		}
+		modified := obj.(*corev1.Service)
+		keepAllocatedFields(&svc, modified)
+		if err := Update(ctx, &svc, modified, r.Client, r.logger); err != nil {
+			return err
+		}
+	}
+	if err := r.cleanup(ctx, podNames); err != nil {
+		return err
+	}
+
+	for _, podName := range podNames {
+		var svc corev1.Service
+		if err := r.Get(ctx, r.Key(podName), &svc); err != nil {
+			return fmt.Errorf("error while fetching Service resource: %w", err)
+		}
+		// the node IP is known only after the broker is scheduled
+		if _, _, err := networking.BrokerServiceAddress(&svc, KafkaPortName, ""); errors.Is(err, networking.ErrBrokerServiceNotReady) {
+			return &RequeueAfterError{
+				RequeueAfter: requeueDuration,
+				Msg:          fmt.Sprintf("waiting for the address of Service %s", svc.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// cleanup removes the Services of the brokers that are not in podNames
+func (r *BrokerServicesResource) cleanup(ctx context.Context, podNames []string) error {
+	var services corev1.ServiceList
+	err := r.List(ctx, &services, &k8sclient.ListOptions{
+		LabelSelector: labels.ForCluster(r.pandaCluster).AsClientSelector(),
+		Namespace:     r.pandaCluster.Namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list Services: %w", err)
+	}
+	keep := map[string]bool{}
+	for _, podName := range podNames {
+		keep[r.Key(podName).Name] = true
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if _, ok := svc.Labels[brokerServiceLabel]; !ok || keep[svc.Name] {
+			continue
+		}
+		r.logger.Info("Removing broker Service", "name", svc.Name)
+		if err := r.Delete(ctx, svc); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete Service %s: %w", svc.Name, err)
+		}
+	}
+	return nil
+}
+
+// keepAllocatedFields copies the fields allocated by the API server to the
+// modified Service, so the update doesn't change the node ports the brokers
+// advertise
+func keepAllocatedFields(current, modified *corev1.Service) {
+	modified.Spec.ClusterIP = current.Spec.ClusterIP
+	modified.Spec.HealthCheckNodePort = current.Spec.HealthCheckNodePort
+	nodePorts := make(map[string]int32, len(current.Spec.Ports))
+	for _, port := range current.Spec.Ports {
+		nodePorts[port.Name] = port.NodePort
+	}
+	for i := range modified.Spec.Ports {
+		modified.Spec.Ports[i].NodePort = nodePorts[modified.Spec.Ports[i].Name]
+	}
+}
+
+// obj returns resource managed client.Object
+func (r *BrokerServicesResource) obj(podName string) (k8sclient.Object, error) {
+	externalKafkaPort := calculateExternalPort(r.pandaCluster.Spec.Configuration.KafkaAPI.Port)
+	adminPort := r.pandaCluster.Spec.Configuration.AdminAPI.Port
+
+	svcType := corev1.ServiceTypeNodePort
+	var annotations map[string]string
+	if r.pandaCluster.Spec.ExternalConnectivity.Type == redpandav1alpha1.ExternalConnectivityLoadBalancer {
+		svcType = corev1.ServiceTypeLoadBalancer
+		// only the Services of type LoadBalancer provision a cloud load
+		// balancer to tag
+		annotations = LoadBalancerAnnotations(
+			r.pandaCluster.Spec.ExternalConnectivity.CloudProvider,
+			r.pandaCluster.Spec.ExternalConnectivity.LoadBalancerTags)
+	}
+
+	objLabels := labels.ForCluster(r.pandaCluster).AsSet()
+	objLabels[brokerServiceLabel] = podName
+	selector := labels.ForCluster(r.pandaCluster).AsSet()
+	selector[appsv1.StatefulSetPodNameLabel] = podName
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   r.pandaCluster.Namespace,
+			Name:        networking.BrokerServiceName(podName),
+			Labels:      objLabels,
+			Annotations: annotations,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type: svcType,
+			// the traffic is not forwarded to other nodes, so the broker sees
+			// the client address
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       KafkaPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(externalKafkaPort),
+					TargetPort: intstr.FromInt(externalKafkaPort),
+				},
+				{
+					Name:       AdminPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(adminPort),
+					TargetPort: intstr.FromInt(adminPort),
+				},
+			},
+			Selector: selector,
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, svc, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Key returns namespace/name of the Service exposing the broker Pod
+func (r *BrokerServicesResource) Key(podName string) types.NamespacedName {
+	return types.NamespacedName{
+		Name:      networking.BrokerServiceName(podName),
+		Namespace: r.pandaCluster.Namespace,
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/networking"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBrokerServices(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name       string
+		connType   redpandav1alpha1.ExternalConnectivityType
+		svcType    corev1.ServiceType
+		assign     func(svc *corev1.Service)
+		advertised string
+	}{
+		{
+			name:     "node port",
+			connType: redpandav1alpha1.ExternalConnectivityNodePort,
+			svcType:  corev1.ServiceTypeNodePort,
+			assign: func(svc *corev1.Service) {
+				svc.Spec.Ports[0].NodePort = 31001
+				svc.Spec.Ports[1].NodePort = 31002
+			},
+			advertised: "203.0.113.10:31001",
+		},
+		{
+			name:     "load balancer",
+			connType: redpandav1alpha1.ExternalConnectivityLoadBalancer,
+			svcType:  corev1.ServiceTypeLoadBalancer,
+			assign: func(svc *corev1.Service) {
+				svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "198.51.100.7"}}
+			},
+			advertised: "198.51.100.7:124",
+		},
+		{
+			name:     "subdomain",
+			connType: redpandav1alpha1.ExternalConnectivitySubdomain,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cluster := pandaCluster()
+			cluster.Spec.Replicas = pointer.Int32Ptr(2)
+			cluster.Spec.Configuration.AdminAPI.Port = 9644
+			cluster.Spec.ExternalConnectivity = redpandav1alpha1.ExternalConnectivityConfig{
+				Enabled: true,
+				Type:    tt.connType,
+			}
+
+			// the cleanup of removed brokers needs the delete verb
+			c := newRBACClient(t, fake.NewClientBuilder().Build())
+			services := res.NewBrokerServices(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+
+			// the brokers are exposed by the shared node port Service
+			if tt.svcType == "" {
+				require.NoError(t, services.Ensure(ctx))
+				var svc corev1.Service
+				assert.True(t, apierrors.IsNotFound(c.Get(ctx, services.Key(cluster.Name+"-0"), &svc)))
+				return
+			}
+
+			// the reconciliation waits for the address of the brokers
+			err := services.Ensure(ctx)
+			var requeue *res.RequeueAfterError
+			require.True(t, errors.As(err, &requeue))
+
+			for ordinal := int32(0); ordinal < 2; ordinal++ {
+				var svc corev1.Service
+				podName := fmt.Sprintf("%s-%d", cluster.Name, ordinal)
+				require.NoError(t, c.Get(ctx, services.Key(podName), &svc))
+				assert.Equal(t, tt.svcType, svc.Spec.Type)
+				assert.Equal(t, podName+"-external", svc.Name)
+				assert.Equal(t, podName, svc.Spec.Selector[appsv1.StatefulSetPodNameLabel])
+				assert.Equal(t, []corev1.ServicePort{
+					{Name: res.KafkaPortName, Protocol: corev1.ProtocolTCP, Port: 124, TargetPort: intstr.FromInt(124)},
+					{Name: res.AdminPortName, Protocol: corev1.ProtocolTCP, Port: 9644, TargetPort: intstr.FromInt(9644)},
+				}, svc.Spec.Ports)
+
+				tt.assign(&svc)
+				require.NoError(t, c.Update(ctx, &svc))
+			}
+			require.NoError(t, services.Ensure(ctx))
+
+			// the broker advertises the address of its Service
+			var svc corev1.Service
+			require.NoError(t, c.Get(ctx, services.Key(cluster.Name+"-0"), &svc))
+			host, port, err := networking.BrokerServiceAddress(&svc, networking.KafkaPortName, "203.0.113.10")
+			require.NoError(t, err)
+			assert.Equal(t, tt.advertised, fmt.Sprintf("%s:%d", host, port))
+
+			// Services of removed brokers are deleted
+			cluster.Spec.Replicas = pointer.Int32Ptr(1)
+			require.NoError(t, services.Ensure(ctx))
+			assert.True(t, apierrors.IsNotFound(c.Get(ctx, services.Key(cluster.Name+"-1"), &svc)))
+		})
+	}
+}
+
+func TestBrokerServicesUpdate(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(1)
+	cluster.Spec.Configuration.AdminAPI.Port = 9644
+	cluster.Spec.ExternalConnectivity = redpandav1alpha1.ExternalConnectivityConfig{
+		Enabled: true,
+		Type:    redpandav1alpha1.ExternalConnectivityNodePort,
+	}
+
+	c := newRBACClient(t, fake.NewClientBuilder().Build())
+	services := res.NewBrokerServices(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(services.Ensure(ctx), &requeue))
+
+	var svc corev1.Service
+	require.NoError(t, c.Get(ctx, services.Key(cluster.Name+"-0"), &svc))
+	svc.Spec.Ports[0].NodePort = 31001
+	svc.Spec.Ports[1].NodePort = 31002
+	require.NoError(t, c.Update(ctx, &svc))
+
+	// the existing Service follows the spec and keeps the node port the
+	// broker advertises
+	cluster.Spec.Configuration.AdminAPI.Port = 9645
+	require.NoError(t, services.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, services.Key(cluster.Name+"-0"), &svc))
+	assert.Equal(t, int32(9645), svc.Spec.Ports[1].Port)
+	assert.Equal(t, int32(31001), svc.Spec.Ports[0].NodePort)
+	assert.Equal(t, int32(31002), svc.Spec.Ports[1].NodePort)
+
+	// tags added after the load balancer Service was created are applied
+	cluster.Spec.ExternalConnectivity.Type = redpandav1alpha1.ExternalConnectivityLoadBalancer
+	cluster.Spec.ExternalConnectivity.CloudProvider = redpandav1alpha1.CloudProviderAWS
+	cluster.Spec.ExternalConnectivity.LoadBalancerTags = map[string]string{"team": "streaming"}
+	require.True(t, errors.As(services.Ensure(ctx), &requeue))
+	require.NoError(t, c.Get(ctx, services.Key(cluster.Name+"-0"), &svc))
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	assert.Equal(t, "team=streaming", svc.Annotations[res.AWSLoadBalancerTagsAnnotation])
+}
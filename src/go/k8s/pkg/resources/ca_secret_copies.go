@@ -0,0 +1,261 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	caSecretSuffix = "-ca"
+
+	// CASourceNamespaceLabel marks the copies of the CA certificate with the
+	// namespace of the cluster they belong to. Owner references can't point
+	// to another namespace, so the copies are found by the label and the
+	// instance label of the cluster instead.
+	CASourceNamespaceLabel = "redpanda.vectorized.io/source-namespace"
+
+	// CASecretCopiesFinalizer keeps the deleted cluster until the copies of
+	// its CA certificate in the other namespaces are deleted
+	CASecretCopiesFinalizer = "redpanda.vectorized.io/ca-secret-copies"
+)
+
+var _ Resource = &CASecretCopiesResource{}
+
+// CASecretCopiesResource copies the CA certificate of the Kafka API to the
+// namespaces listed in CASecretNamespaces, so clients running outside of the
+// namespace of the cluster can verify the brokers. Only ca.crt is copied, the
+// node certificate and its private key stay in the namespace of the cluster.
+type CASecretCopiesResource struct {
+	k8sclient.Client
+	pandaCluster *redpandav1alpha1.Cluster
+	nodeCertKey  types.NamespacedName
+	logger       logr.Logger
+}
+
+// NewCASecretCopies creates CASecretCopiesResource. The CA is read from the
+// node certificate Secret.
+func NewCASecretCopies(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	nodeCertKey types.NamespacedName,
+	logger logr.Logger,
+) *CASecretCopiesResource {
+	return &CASecretCopiesResource{
+		client,
+		pandaCluster,
+		nodeCertKey,
+		logger.WithValues("Kind", "Secret", "Secret", "ca-copies"),
+	}
+}
+
+// Ensure will manage kubernetes v1.Secret with the CA certificate in every
+// listed namespace. The copies follow the rotation of the certificate, the
+// node certificate Secret is watched by the controller. Secrets of the same
+// name that are not copies of this cluster are left alone. The cluster has
+// CASecretCopiesFinalizer as long as it has copies.
+func (r *CASecretCopiesResource) Ensure(ctx context.Context) error {
+	kafkaTLS := r.pandaCluster.Spec.Configuration.TLS.KafkaAPI
+	keep := map[string]bool{}
+	if kafkaTLS.Enabled {
+		for _, namespace := range kafkaTLS.CASecretNamespaces {
+			keep[namespace] = true
+		}
+	}
+
+	ca, err := r.caCertificate(ctx, len(keep) > 0)
+	if err != nil {
+		return err
+	}
+	// certificates are copied once cert-manager issues them
+	if len(ca) > 0 {
+		if err := updateCASecretCopiesFinalizer(ctx, r, r.pandaCluster, true); err != nil {
+			return err
+		}
+		for _, namespace := range kafkaTLS.CASecretNamespaces {
+			if err := r.ensureCopy(ctx, namespace, ca); err != nil {
+				return err
+			}
+		}
+	}
+	if err := removeCASecretCopies(ctx, r, r.pandaCluster, keep, r.logger); err != nil {
+		return err
+	}
+	if len(keep) == 0 {
+		return updateCASecretCopiesFinalizer(ctx, r, r.pandaCluster, false)
+	}
+	return nil
+}
+
+// caCertificate returns ca.crt of the node certificate, empty if it is not
+// issued yet
+func (r *CASecretCopiesResource) caCertificate(
+	ctx context.Context, required bool,
+) ([]byte, error) {
+	if !required {
+		return nil, nil
+	}
+	var node corev1.Secret
+	err := r.Get(ctx, r.nodeCertKey, &node)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching Secret %s: %w", r.nodeCertKey, err)
+	}
+	return node.Data[cmetav1.TLSCAKey], nil
+}
+
+func (r *CASecretCopiesResource) ensureCopy(
+	ctx context.Context, namespace string, ca []byte,
+) error {
+	obj := r.obj(namespace, ca)
+	var secret corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{Name: obj.Name, Namespace: namespace}, &secret)
+	if apierrors.IsNotFound(err) {
+		r.logger.Info(fmt.Sprintf("Secret %s/%s did not exist, was created", namespace, obj.Name))
+		return r.Create(ctx, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching Secret %s/%s: %w", namespace, obj.Name, err)
+	}
+	if !r.isCopy(&secret) {
+		r.logger.Info("Secret is not a copy of the CA certificate of the cluster, leaving it unchanged",
+			"namespace", namespace, "name", secret.Name)
+		return nil
+	}
+	if bytes.Equal(secret.Data[cmetav1.TLSCAKey], ca) && len(secret.Data) == 1 {
+		return nil
+	}
+	r.logger.Info("CA certificate changed, updating Secret", "namespace", namespace)
+	secret.Data = obj.Data
+	return r.Update(ctx, &secret)
+}
+
+// isCopy returns true if the Secret was created by the resource
+func (r *CASecretCopiesResource) isCopy(secret *corev1.Secret) bool {
+	return secret.Labels[labels.InstanceKey] == r.pandaCluster.Name &&
+		secret.Labels[CASourceNamespaceLabel] == r.pandaCluster.Namespace
+}
+
+// obj returns the copy of the CA certificate in the namespace
+func (r *CASecretCopiesResource) obj(namespace string, ca []byte) *corev1.Secret {
+	objLabels := labels.ForCluster(r.pandaCluster).AsSet()
+	objLabels[CASourceNamespaceLabel] = r.pandaCluster.Namespace
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      r.Key().Name,
+			Labels:    objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{cmetav1.TLSCAKey: ca},
+	}
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+// The copies have the same name in every namespace, the namespace of the
+// cluster doesn't get one.
+func (r *CASecretCopiesResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + caSecretSuffix, Namespace: r.pandaCluster.Namespace}
+}
+
+// FinalizeCASecretCopies deletes the copies of the CA certificate of the
+// deleted cluster and removes CASecretCopiesFinalizer. Owner references
+// can't point to another namespace, so the copies are not garbage collected
+// together with the cluster.
+func FinalizeCASecretCopies(
+	ctx context.Context,
+	c k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	logger logr.Logger,
+) error {
+	if !controllerutil.ContainsFinalizer(pandaCluster, CASecretCopiesFinalizer) {
+		return nil
+	}
+	if err := removeCASecretCopies(ctx, c, pandaCluster, nil, logger); err != nil {
+		return err
+	}
+	return updateCASecretCopiesFinalizer(ctx, c, pandaCluster, false)
+}
+
+// updateCASecretCopiesFinalizer adds or removes CASecretCopiesFinalizer of
+// the cluster
+func updateCASecretCopiesFinalizer(
+	ctx context.Context,
+	c k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	copied bool,
+) error {
+	if controllerutil.ContainsFinalizer(pandaCluster, CASecretCopiesFinalizer) == copied {
+		return nil
+	}
+	patch := k8sclient.MergeFrom(pandaCluster.DeepCopy())
+	if copied {
+		controllerutil.AddFinalizer(pandaCluster, CASecretCopiesFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(pandaCluster, CASecretCopiesFinalizer)
+	}
+	if err := c.Patch(ctx, pandaCluster, patch); err != nil {
+		return fmt.Errorf("unable to update finalizers of Cluster: %w", err)
+	}
+	return nil
+}
+
+// removeCASecretCopies deletes the copies of the CA certificate of the
+// cluster outside of the kept namespaces
+func removeCASecretCopies(
+	ctx context.Context,
+	c k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	keep map[string]bool,
+	logger logr.Logger,
+) error {
+	var secrets corev1.SecretList
+	err := c.List(ctx, &secrets, &k8sclient.ListOptions{
+		LabelSelector: k8slabels.SelectorFromSet(k8slabels.Set{
+			labels.InstanceKey:     pandaCluster.Name,
+			CASourceNamespaceLabel: pandaCluster.Namespace,
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list CA certificate copies: %w", err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Name != pandaCluster.Name+caSecretSuffix || keep[secret.Namespace] {
+			continue
+		}
+		logger.Info("Removing CA certificate copy", "namespace", secret.Namespace)
+		if err := c.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCASecretCopies(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+	cluster.Spec.Configuration.TLS.KafkaAPI.CASecretNamespaces = []string{"clients", "apps"}
+
+	nodeCert := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-redpanda", Namespace: "default"},
+		Data: map[string][]byte{
+			"ca.crt":  []byte("first CA"),
+			"tls.crt": []byte("node certificate"),
+			"tls.key": []byte("node key"),
+		},
+	}
+	foreign := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-ca", Namespace: "apps"},
+		Data:       map[string][]byte{"ca.crt": []byte("other CA")},
+	}
+	c := fake.NewClientBuilder().WithObjects(cluster, nodeCert, foreign).Build()
+	copies := res.NewCASecretCopies(c, cluster, types.NamespacedName{Name: nodeCert.Name, Namespace: nodeCert.Namespace}, ctrl.Log.WithName("test"))
+
+	caCopy := func(namespace string) (*corev1.Secret, error) {
+		var secret corev1.Secret
+		err := c.Get(ctx, types.NamespacedName{Name: "cluster-ca", Namespace: namespace}, &secret)
+		return &secret, err
+	}
+
+	// only the CA certificate is copied
+	require.NoError(t, copies.Ensure(ctx))
+	secret, err := caCopy("clients")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"ca.crt": []byte("first CA")}, secret.Data)
+	assert.Equal(t, "default", secret.Labels[res.CASourceNamespaceLabel])
+	assert.Empty(t, secret.OwnerReferences, "owner can't be in another namespace")
+	// Secret that is not a copy of the cluster is left alone
+	secret, err = caCopy("apps")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other CA"), secret.Data["ca.crt"])
+	assert.Contains(t, cluster.Finalizers, res.CASecretCopiesFinalizer)
+
+	// the copies follow the rotation of the CA
+	nodeCert.Data["ca.crt"] = []byte("rotated CA")
+	require.NoError(t, c.Update(ctx, nodeCert))
+	require.NoError(t, copies.Ensure(ctx))
+	secret, err = caCopy("clients")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rotated CA"), secret.Data["ca.crt"])
+
+	// copies are removed from namespaces that are not listed anymore
+	cluster.Spec.Configuration.TLS.KafkaAPI.CASecretNamespaces = []string{"apps", "monitoring"}
+	require.NoError(t, copies.Ensure(ctx))
+	_, err = caCopy("clients")
+	assert.True(t, apierrors.IsNotFound(err))
+	secret, err = caCopy("monitoring")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rotated CA"), secret.Data["ca.crt"])
+
+	// and once the cluster is deleted
+	require.NoError(t, res.FinalizeCASecretCopies(ctx, c, cluster, ctrl.Log.WithName("test")))
+	_, err = caCopy("monitoring")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = caCopy("apps")
+	assert.NoError(t, err, "Secret that is not a copy is kept")
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, &actual))
+	assert.NotContains(t, actual.Finalizers, res.CASecretCopiesFinalizer)
+}
+
+func TestCASecretCopiesFinalizerRemoved(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	// the finalizer is removed together with the last copy
+	cluster := pandaCluster()
+	cluster.Finalizers = []string{res.CASecretCopiesFinalizer}
+	caCopy := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-ca",
+			Namespace: "clients",
+			Labels: map[string]string{
+				labels.InstanceKey:         cluster.Name,
+				res.CASourceNamespaceLabel: cluster.Namespace,
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(cluster, caCopy).Build()
+	copies := res.NewCASecretCopies(c, cluster, types.NamespacedName{Name: "cluster-redpanda", Namespace: "default"}, ctrl.Log.WithName("test"))
+	require.NoError(t, copies.Ensure(ctx))
+
+	err := c.Get(ctx, types.NamespacedName{Name: caCopy.Name, Namespace: caCopy.Namespace}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err))
+	var actual redpandav1alpha1.Cluster
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, &actual))
+	assert.Empty(t, actual.Finalizers)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CertificateHashAnnotation is set on the Pod template of the brokers. It
+// changes when a node certificate is renewed, so the brokers are restarted
+// with the new certificate.
+const CertificateHashAnnotation = "redpanda.vectorized.io/certificate-hash"
+
+// CertificateHash returns a hash of the certificates stored in the Secrets.
+// The hash doesn't depend on the order of the keys. Secrets that don't
+// exist yet are skipped and an empty string is returned if none exists.
+func CertificateHash(
+	ctx context.Context, c k8sclient.Reader, keys ...types.NamespacedName,
+) (string, error) {
+	sorted := make([]types.NamespacedName, 0, len(keys))
+	for _, key := range keys {
+		if key.Name != "" {
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	hash := sha256.New()
+	found := false
+	for _, key := range sorted {
+		var secret corev1.Secret
+		err := c.Get(ctx, key, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to fetch certificate Secret %s: %w", key, err)
+		}
+		found = true
+		hash.Write([]byte(key.String()))
+		hash.Write(secret.Data[corev1.TLSCertKey])
+	}
+	if !found {
+		return "", nil
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
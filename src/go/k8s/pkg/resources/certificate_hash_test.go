@@ -0,0 +1,72 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func certSecret(name, cert, key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte(cert),
+			corev1.TLSPrivateKeyKey: []byte(key),
+		},
+	}
+}
+
+func TestCertificateHash(t *testing.T) {
+	ctx := context.Background()
+	kafka := types.NamespacedName{Name: "cluster-redpanda", Namespace: "default"}
+	adminAPI := types.NamespacedName{Name: "cluster-admin-api-node", Namespace: "default"}
+	c := fake.NewClientBuilder().WithObjects(
+		certSecret(kafka.Name, "kafka-cert", "kafka-key"),
+		certSecret(adminAPI.Name, "admin-cert", "admin-key"),
+	).Build()
+
+	hash, err := res.CertificateHash(ctx, c, kafka, adminAPI)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+
+	// the hash is stable
+	again, err := res.CertificateHash(ctx, c, adminAPI, kafka, types.NamespacedName{})
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	// and changes only with the certificate
+	var secret corev1.Secret
+	require.NoError(t, c.Get(ctx, kafka, &secret))
+	secret.Labels = map[string]string{"renewed": "false"}
+	require.NoError(t, c.Update(ctx, &secret))
+	unchanged, err := res.CertificateHash(ctx, c, kafka, adminAPI)
+	require.NoError(t, err)
+	assert.Equal(t, hash, unchanged)
+
+	secret.Data[corev1.TLSCertKey] = []byte("renewed-kafka-cert")
+	require.NoError(t, c.Update(ctx, &secret))
+	renewed, err := res.CertificateHash(ctx, c, kafka, adminAPI)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, renewed)
+
+	// no hash without certificates
+	none, err := res.CertificateHash(ctx, c, types.NamespacedName{Name: "missing", Namespace: "default"})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
@@ -11,6 +11,7 @@ package certmanager
 
 import (
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -21,11 +22,45 @@ const (
 	AdminAPIClientCert = "admin-api-client"
 	// AdminAPINodeCert cert name - node certificate for Admin API
 	AdminAPINodeCert = "admin-api-node"
+	// MetricsClientCert cert name - client certificate for scraping the
+	// metrics served by Admin API
+	MetricsClientCert = "metrics-client"
 )
 
 // AdminAPINodeCert returns the namespaced name for the Admin API certificate used by node
 func (r *PkiReconciler) AdminAPINodeCert() types.NamespacedName {
-	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + AdminAPINodeCert, Namespace: r.pandaCluster.Namespace}
+	return AdminAPINodeCertKey(r.pandaCluster)
+}
+
+// AdminAPINodeCertKey returns the namespaced name of the Secret with the
+// certificate the brokers present on the Admin API
+func AdminAPINodeCertKey(
+	pandaCluster *redpandav1alpha1.Cluster,
+) types.NamespacedName {
+	if isNodeCertShared(pandaCluster) {
+		return NodeCertKey(pandaCluster)
+	}
+	return types.NamespacedName{Name: pandaCluster.Name + "-" + AdminAPINodeCert, Namespace: pandaCluster.Namespace}
+}
+
+// MetricsClientCert returns the namespaced name of the client certificate
+// used for scraping the metrics, empty if the Admin API doesn't require
+// client authentication
+func (r *PkiReconciler) MetricsClientCert() types.NamespacedName {
+	tlsConfig := r.pandaCluster.Spec.Configuration.TLS
+	if !tlsConfig.Metrics.Enabled || !tlsConfig.AdminAPI.RequireClientAuth {
+		return types.NamespacedName{}
+	}
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + MetricsClientCert, Namespace: r.pandaCluster.Namespace}
+}
+
+// AdminAPIClientCommonName returns the CN of the client certificate for
+// calling the Admin API, empty if the Admin API doesn't require client auth
+func (r *PkiReconciler) AdminAPIClientCommonName() string {
+	if !r.pandaCluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth {
+		return ""
+	}
+	return string(NewCommonName(r.pandaCluster.Name, AdminAPIClientCert))
 }
 
 func (r *PkiReconciler) prepareAdminAPI(
@@ -33,27 +68,30 @@ func (r *PkiReconciler) prepareAdminAPI(
 ) []resources.Resource {
 	toApply := []resources.Resource{}
 
-	// Redpanda cluster certificate for Admin API - to be provided to each broker
-	cn := NewCommonName(r.pandaCluster.Name, AdminAPINodeCert)
-	certsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
-
-	dnsName := r.internalFQDN
-	externConn := r.pandaCluster.Spec.ExternalConnectivity
-	if externConn.Enabled && externConn.Subdomain != "" {
-		dnsName = externConn.Subdomain
+	// Kafka API node certificate is reused when it is shared between listeners
+	if !r.sharedNodeCert() {
+		// Redpanda cluster certificate for Admin API - to be provided to each broker
+		cn := NewCommonName(r.pandaCluster.Name, AdminAPINodeCert)
+		certsKey := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, AdminAPINodeCert), Namespace: r.pandaCluster.Namespace}
+		nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, issuerRef, r.adminAPINodeCertDNSNames(), cn, false, r.logger)
+		toApply = append(toApply, nodeCert)
 	}
 
-	nodeCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, issuerRef, dnsName, cn, false, r.logger)
-	toApply = append(toApply, nodeCert)
-
-	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth {
+	if cn := r.AdminAPIClientCommonName(); cn != "" {
 		// Certificate for calling the Admin API on any broker
-		cn := NewCommonName(r.pandaCluster.Name, AdminAPIClientCert)
-		clientCertsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
-		adminClientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, clientCertsKey, issuerRef, cn, false, r.logger)
+		clientCertsKey := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, AdminAPIClientCert), Namespace: r.pandaCluster.Namespace}
+		adminClientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, clientCertsKey, issuerRef, CommonName(cn), false, r.logger)
 
 		toApply = append(toApply, adminClientCert)
 	}
 
+	if key := r.MetricsClientCert(); key.Name != "" {
+		// Certificate presented by Prometheus when scraping the metrics
+		cn := NewCommonName(r.pandaCluster.Name, MetricsClientCert)
+		metricsClientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, key, issuerRef, cn, false, r.logger)
+
+		toApply = append(toApply, metricsClientCert)
+	}
+
 	return toApply
 }
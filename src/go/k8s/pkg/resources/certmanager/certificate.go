@@ -40,26 +40,27 @@ type CertificateResource struct {
 	pandaCluster *redpandav1alpha1.Cluster
 	key          types.NamespacedName
 	issuerRef    *cmetav1.ObjectReference
-	fqdn         string
+	dnsNames     []string
 	commonName   CommonName
 	isCA         bool
 	logger       logr.Logger
 }
 
-// NewNodeCertificate creates certificate with given FQDN that is either internal or external
+// NewNodeCertificate creates certificate with wildcard SANs for the given
+// domains, e.g. internal FQDN and external subdomain
 func NewNodeCertificate(
 	client k8sclient.Client,
 	scheme *runtime.Scheme,
 	pandaCluster *redpandav1alpha1.Cluster,
 	key types.NamespacedName,
 	issuerRef *cmetav1.ObjectReference,
-	fqdn string,
+	dnsNames []string,
 	commonName CommonName,
 	isCA bool,
 	logger logr.Logger,
 ) *CertificateResource {
 	return &CertificateResource{
-		client, scheme, pandaCluster, key, issuerRef, fqdn, commonName, isCA, logger.WithValues("Kind", certificateKind()),
+		client, scheme, pandaCluster, key, issuerRef, dnsNames, commonName, isCA, logger.WithValues("Kind", certificateKind()),
 	}
 }
 
@@ -75,7 +76,7 @@ func NewCertificate(
 	logger logr.Logger,
 ) *CertificateResource {
 	return &CertificateResource{
-		client, scheme, pandaCluster, key, issuerRef, "", commonName, isCA, logger.WithValues("Kind", certificateKind()),
+		client, scheme, pandaCluster, key, issuerRef, nil, commonName, isCA, logger.WithValues("Kind", certificateKind()),
 	}
 }
 
@@ -106,12 +107,14 @@ func (r *CertificateResource) obj() (k8sclient.Object, error) {
 		},
 	}
 
-	if r.fqdn != "" {
-		name := "*." + strings.TrimSuffix(r.fqdn, ".")
-		cert.Spec.CommonName = string(r.commonName)
-		cert.Spec.DNSNames = []string{name}
-	} else {
-		cert.Spec.CommonName = string(r.commonName)
+	cert.Spec.CommonName = string(r.commonName)
+	if string(r.commonName) != r.key.Name {
+		// the common name of long cluster names is shortened, the full
+		// name keeps the identity of the certificate
+		cert.Spec.DNSNames = append(cert.Spec.DNSNames, r.key.Name)
+	}
+	for _, dnsName := range r.dnsNames {
+		cert.Spec.DNSNames = append(cert.Spec.DNSNames, "*."+strings.TrimSuffix(dnsName, "."))
 	}
 
 	err := controllerutil.SetControllerReference(r.pandaCluster, cert, r.scheme)
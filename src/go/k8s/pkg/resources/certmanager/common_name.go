@@ -9,12 +9,17 @@
 
 package certmanager
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
 
 // cert-manager has limit of 64 bytes on the common name of certificate
 const (
 	nameLimit       = 64
 	separatorLength = 1 // we use - as separator
+	hashLength      = 8
 )
 
 // CommonName is certificate CN that is shortened to 64 chars
@@ -23,11 +28,33 @@ type CommonName string
 // NewCommonName ensures the name does not exceed the limit of 64 bytes. It always
 // shortens the cluster name and keeps the whole suffix.
 // Suffix and name will be separated with -
+//
+// Shortened names get a hash of the full name between the cluster name and
+// the suffix, so clusters with a common prefix don't share the common name.
+// The result depends only on the arguments, so the certificates are not
+// reissued between reconciliations.
 func NewCommonName(clusterName, suffix string) CommonName {
-	suffixLength := len(suffix)
-	maxClusterNameLength := nameLimit - suffixLength - separatorLength
+	fullName := certificateName(clusterName, suffix)
+	if len(fullName) <= nameLimit {
+		return CommonName(fullName)
+	}
+	sum := sha256.Sum256([]byte(fullName))
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+
+	maxClusterNameLength := nameLimit - len(suffix) - hashLength - 2*separatorLength
+	if maxClusterNameLength < 1 {
+		// the suffixes of the operator are never that long
+		return CommonName(fullName[:nameLimit-hashLength-separatorLength] + "-" + hash)
+	}
 	if len(clusterName) > maxClusterNameLength {
 		clusterName = clusterName[:maxClusterNameLength]
 	}
-	return CommonName(fmt.Sprintf("%s-%s", clusterName, suffix))
+	return CommonName(fmt.Sprintf("%s-%s-%s", clusterName, hash, suffix))
+}
+
+// certificateName is the name of the Certificate and its Secret. It's the
+// full identity of the certificate, which is added to the SANs when the
+// common name is shortened.
+func certificateName(clusterName, suffix string) string {
+	return fmt.Sprintf("%s-%s", clusterName, suffix)
 }
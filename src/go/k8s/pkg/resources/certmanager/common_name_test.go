@@ -11,6 +11,7 @@ package certmanager_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,8 +26,7 @@ func TestCommonName(t *testing.T) {
 		expectedCommonName string
 	}{
 		{"short name and suffix", "cluster", "suffix", "cluster-suffix"},
-		{"long name and suffix", "thisisverylongnamethatishittingthemaximal64characterlimitofnames", "suffix", "thisisverylongnamethatishittingthemaximal64characterlimit-suffix"},
-		{"long name and long suffix", "thisisverylongnamethatishittingthemaximal64characterlimitofnames", "thisisverylongsuffixthathas40chars123456", "thisisverylongnamethati-thisisverylongsuffixthathas40chars123456"},
+		{"name and suffix at the limit", "thisisverylongnamethatishittingthemaximal64characterlimit", "suffix", "thisisverylongnamethatishittingthemaximal64characterlimit-suffix"},
 	}
 
 	for _, tt := range tests {
@@ -34,3 +34,30 @@ func TestCommonName(t *testing.T) {
 		assert.Equal(t, tt.expectedCommonName, string(cn), fmt.Sprintf("%s: expecting common name to be equal", tt.testName))
 	}
 }
+
+func TestCommonNameOfLongClusterName(t *testing.T) {
+	tests := []struct {
+		testName    string
+		clusterName string
+		suffix      string
+	}{
+		{"long name and suffix", "thisisverylongnamethatishittingthemaximal64characterlimitofnames", "suffix"},
+		{"long name and long suffix", "thisisverylongnamethatishittingthemaximal64characterlimitofnames", "thisisverylongsuffixthathas40chars123456"},
+		{"short name and too long suffix", "cluster", strings.Repeat("s", 70)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			cn := certmanager.NewCommonName(tt.clusterName, tt.suffix)
+			assert.LessOrEqual(t, len(cn), 64)
+			assert.Equal(t, cn, certmanager.NewCommonName(tt.clusterName, tt.suffix), "common name must be stable")
+			if len(tt.suffix) < 50 {
+				assert.True(t, strings.HasSuffix(string(cn), "-"+tt.suffix), "suffix is kept")
+			}
+
+			// clusters sharing the prefix don't share the common name
+			other := certmanager.NewCommonName(tt.clusterName+"2", tt.suffix)
+			assert.NotEqual(t, cn, other)
+		})
+	}
+}
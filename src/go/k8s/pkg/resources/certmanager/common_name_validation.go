@@ -0,0 +1,83 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ClientCommonNames returns the CNs of the client certificates issued by the
+// operator, keyed by the Secrets of the certificates
+func (r *PkiReconciler) ClientCommonNames() map[types.NamespacedName]string {
+	names := make(map[types.NamespacedName]string)
+	tlsConfig := r.pandaCluster.Spec.Configuration.TLS
+	if tlsConfig.KafkaAPI.Enabled && tlsConfig.KafkaAPI.RequireClientAuth {
+		names[r.UserClientCert()] = string(NewCommonName(r.pandaCluster.Name, UserClientCert))
+		names[r.OperatorClientCert()] = string(NewCommonName(r.pandaCluster.Name, OperatorClientCert))
+		adminClientKey := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, AdminClientCert), Namespace: r.pandaCluster.Namespace}
+		names[adminClientKey] = string(NewCommonName(r.pandaCluster.Name, AdminClientCert))
+	}
+	if tlsConfig.AdminAPI.Enabled {
+		if cn := r.AdminAPIClientCommonName(); cn != "" {
+			key := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, AdminAPIClientCert), Namespace: r.pandaCluster.Namespace}
+			names[key] = cn
+		}
+		if key := r.MetricsClientCert(); key.Name != "" {
+			names[key] = string(NewCommonName(r.pandaCluster.Name, MetricsClientCert))
+		}
+	}
+	return names
+}
+
+// CertificateCommonName returns the CN of the certificate in tls.crt of the
+// Secret
+func CertificateCommonName(secret *corev1.Secret) (string, error) {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return "", errMissingCertificate
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	return leaf.Subject.CommonName, nil
+}
+
+// ConflictingCommonNames returns the node certificates that share the CN
+// with a client certificate. Redpanda maps the CN of a certificate to the
+// principal, so a broker would be authorized as the client, or the client
+// as a broker.
+func ConflictingCommonNames(
+	nodeNames, clientNames map[types.NamespacedName]string,
+) []string {
+	clients := make(map[string][]string)
+	for key, cn := range clientNames {
+		clients[cn] = append(clients[cn], key.Name)
+	}
+
+	var conflicts []string
+	for key, cn := range nodeNames {
+		if cn == "" || len(clients[cn]) == 0 {
+			continue
+		}
+		sort.Strings(clients[cn])
+		for _, client := range clients[cn] {
+			conflicts = append(conflicts, fmt.Sprintf("%s and %s share CN %s", key.Name, client, cn))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConflictingCommonNames(t *testing.T) {
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI: redpandav1alpha1.KafkaAPITLS{Enabled: true, RequireClientAuth: true},
+					AdminAPI: redpandav1alpha1.AdminAPITLS{Enabled: true, RequireClientAuth: true},
+				},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	pki := certmanager.NewPki(fake.NewClientBuilder().Build(), cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+
+	clientNames := pki.ClientCommonNames()
+	assert.Equal(t, map[types.NamespacedName]string{
+		{Name: "cluster-user-client", Namespace: "default"}:      "cluster-user-client",
+		{Name: "cluster-operator-client", Namespace: "default"}:  "cluster-operator-client",
+		{Name: "cluster-admin-client", Namespace: "default"}:     "cluster-admin-client",
+		{Name: "cluster-admin-api-client", Namespace: "default"}: "cluster-admin-api-client",
+	}, clientNames)
+
+	tests := []struct {
+		name              string
+		nodeCommonName    string
+		expectedConflicts []string
+	}{
+		{"generated node certificate", "cluster-redpanda", nil},
+		{"node certificate without CN", "", nil},
+		{
+			"node certificate with CN of client certificate",
+			"cluster-operator-client",
+			[]string{"cluster-redpanda and cluster-operator-client share CN cluster-operator-client"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					corev1.TLSCertKey: generateCertificateWithCommonName(t, tt.nodeCommonName),
+				},
+			}
+			cn, err := certmanager.CertificateCommonName(secret)
+			require.NoError(t, err)
+			assert.Equal(t, tt.nodeCommonName, cn)
+
+			nodeNames := map[types.NamespacedName]string{pki.NodeCert(): cn}
+			assert.Equal(t, tt.expectedConflicts, certmanager.ConflictingCommonNames(nodeNames, clientNames))
+		})
+	}
+
+	// no client certificates are issued without client authentication
+	cluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth = false
+	cluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth = false
+	assert.Empty(t, pki.ClientCommonNames())
+}
+
+func generateCertificateWithCommonName(t *testing.T, cn string) []byte {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
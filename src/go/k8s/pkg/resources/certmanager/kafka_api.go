@@ -11,12 +11,15 @@ package certmanager
 
 import (
 	"context"
+	"fmt"
 
 	cmmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
@@ -29,6 +32,8 @@ const (
 	AdminClientCert = "admin-client"
 	// RedpandaNodeCert cert name - node certificate
 	RedpandaNodeCert = "redpanda"
+	// RedpandaExternalNodeCert cert name - node certificate of the external listener
+	RedpandaExternalNodeCert = "redpanda-external"
 )
 
 // OperatorClientCert returns the namespaced name for the client certificate
@@ -37,6 +42,12 @@ func (r *PkiReconciler) OperatorClientCert() types.NamespacedName {
 	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + OperatorClientCert, Namespace: r.pandaCluster.Namespace}
 }
 
+// UserClientCert returns the namespaced name for the client certificate
+// used by applications to call the Kafka API
+func (r *PkiReconciler) UserClientCert() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + UserClientCert, Namespace: r.pandaCluster.Namespace}
+}
+
 // AdminCert returns the namespaced name for the certificate used by an administrator to query the Kafka API
 func (r *PkiReconciler) AdminCert() types.NamespacedName {
 	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + OperatorClientCert, Namespace: r.pandaCluster.Namespace}
@@ -44,13 +55,27 @@ func (r *PkiReconciler) AdminCert() types.NamespacedName {
 
 // NodeCert returns the namespaced name for Redpanda's node certificate
 func (r *PkiReconciler) NodeCert() types.NamespacedName {
-	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef != nil {
+	return NodeCertKey(r.pandaCluster)
+}
+
+// NodeCertKey returns the namespaced name of the Secret with the node
+// certificate of the cluster
+func NodeCertKey(
+	pandaCluster *redpandav1alpha1.Cluster,
+) types.NamespacedName {
+	if pandaCluster.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef != nil {
 		return types.NamespacedName{
-			Name:      r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef.Name,
-			Namespace: r.pandaCluster.Namespace,
+			Name:      pandaCluster.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef.Name,
+			Namespace: pandaCluster.Namespace,
 		}
 	}
-	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + RedpandaNodeCert, Namespace: r.pandaCluster.Namespace}
+	return types.NamespacedName{Name: pandaCluster.Name + "-" + RedpandaNodeCert, Namespace: pandaCluster.Namespace}
+}
+
+// ExternalNodeCert returns the namespaced name for the certificate of the
+// external Kafka API listener. It's issued only if SeparateExternalCert is set.
+func (r *PkiReconciler) ExternalNodeCert() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + RedpandaExternalNodeCert, Namespace: r.pandaCluster.Namespace}
 }
 
 func (r *PkiReconciler) prepareKafkaAPI(
@@ -58,28 +83,32 @@ func (r *PkiReconciler) prepareKafkaAPI(
 ) ([]resources.Resource, error) {
 	toApply := []resources.Resource{}
 
-	externalIssuerRef := r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.IssuerRef
 	nodeSecretRef := r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.NodeSecretRef
 
 	if nodeSecretRef == nil {
 		// Redpanda cluster certificate for Kafka API - to be provided to each broker
 		cn := NewCommonName(r.pandaCluster.Name, RedpandaNodeCert)
-		certsKey := types.NamespacedName{Name: string(cn), Namespace: r.pandaCluster.Namespace}
-		nodeIssuerRef := issuerRef
-		if externalIssuerRef != nil {
-			// if external issuer is provided, we will use it to generate node certificates
-			nodeIssuerRef = externalIssuerRef
-		}
+		certsKey := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, RedpandaNodeCert), Namespace: r.pandaCluster.Namespace}
+		redpandaCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, issuerRef, r.nodeCertDNSNames(), cn, false, r.logger)
 
-		dnsName := r.internalFQDN
-		externConn := r.pandaCluster.Spec.ExternalConnectivity
-		if externConn.Enabled && externConn.Subdomain != "" {
-			dnsName = externConn.Subdomain
-		}
+		toApply = append(toApply, redpandaCert)
 
-		redpandaCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, certsKey, nodeIssuerRef, dnsName, cn, false, r.logger)
+		if r.pandaCluster.SeparateExternalCert() {
+			// external listener certificate - mirrors the node certificate, but covers the subdomain only
+			externalCn := NewCommonName(r.pandaCluster.Name, RedpandaExternalNodeCert)
+			externalKey := r.ExternalNodeCert()
+			externalCert := NewNodeCertificate(r.Client, r.scheme, r.pandaCluster, externalKey, issuerRef,
+				[]string{r.pandaCluster.Spec.ExternalConnectivity.Subdomain}, externalCn, false, r.logger)
 
-		toApply = append(toApply, redpandaCert)
+			toApply = append(toApply, externalCert)
+		}
+	}
+
+	if nodeSecretRef != nil {
+		// the user provided Secret is validated before any listener gets configured with it
+		if err := r.validateSecret(ctx, types.NamespacedName{Name: nodeSecretRef.Name, Namespace: nodeSecretRef.Namespace}); err != nil {
+			return nil, err
+		}
 	}
 
 	if nodeSecretRef != nil && nodeSecretRef.Namespace != r.pandaCluster.Namespace {
@@ -91,17 +120,17 @@ func (r *PkiReconciler) prepareKafkaAPI(
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
 		// Certificate for external clients to call the Kafka API on any broker in this Redpanda cluster
 		userClientCn := NewCommonName(r.pandaCluster.Name, UserClientCert)
-		userClientKey := types.NamespacedName{Name: string(userClientCn), Namespace: r.pandaCluster.Namespace}
+		userClientKey := r.UserClientCert()
 		externalClientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, userClientKey, issuerRef, userClientCn, false, r.logger)
 
 		// Certificate for operator to call the Kafka API on any broker in this Redpanda cluster
 		operatorClientCn := NewCommonName(r.pandaCluster.Name, OperatorClientCert)
-		operatorClientKey := types.NamespacedName{Name: string(operatorClientCn), Namespace: r.pandaCluster.Namespace}
+		operatorClientKey := r.OperatorClientCert()
 		internalClientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, operatorClientKey, issuerRef, operatorClientCn, false, r.logger)
 
 		// Certificate for admin to call the Kafka API on any broker in this Redpanda cluster
 		adminClientCn := NewCommonName(r.pandaCluster.Name, AdminClientCert)
-		adminClientKey := types.NamespacedName{Name: string(adminClientCn), Namespace: r.pandaCluster.Namespace}
+		adminClientKey := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, AdminClientCert), Namespace: r.pandaCluster.Namespace}
 		adminClientCert := NewCertificate(r.Client, r.scheme, r.pandaCluster, adminClientKey, issuerRef, adminClientCn, false, r.logger)
 
 		toApply = append(toApply, externalClientCert, internalClientCert, adminClientCert)
@@ -137,6 +166,31 @@ func (r *PkiReconciler) copyNodeSecretToLocalNamespace(
 			corev1.TLSPrivateKeyKey: tlsKey,
 		},
 	}
-	_, err = resources.CreateIfNotExists(ctx, r, caSecret, r.logger)
-	return err
+	if err = controllerutil.SetControllerReference(r.pandaCluster, caSecret, r.scheme); err != nil {
+		return err
+	}
+	created, err := resources.CreateIfNotExists(ctx, r, caSecret, r.logger)
+	if err != nil || created {
+		return err
+	}
+	return r.ensureControllerReference(ctx, r.NodeCert())
+}
+
+// ensureControllerReference patches Secret created by previous versions
+// of the operator, so it is garbage collected together with the cluster
+func (r *PkiReconciler) ensureControllerReference(
+	ctx context.Context, key types.NamespacedName,
+) error {
+	var secret corev1.Secret
+	if err := r.Get(ctx, key, &secret); err != nil {
+		return err
+	}
+	if metav1.GetControllerOf(&secret) != nil {
+		return nil
+	}
+	if err := controllerutil.SetControllerReference(r.pandaCluster, &secret, r.scheme); err != nil {
+		return err
+	}
+	r.logger.Info(fmt.Sprintf("Adding owner reference to Secret %s", key))
+	return r.Update(ctx, &secret)
 }
@@ -0,0 +1,91 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	cmmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+)
+
+const (
+	// KafkaAPIListenerTLS is the API reported for Kafka API listeners
+	KafkaAPIListenerTLS = "kafka"
+	// AdminAPIListenerTLS is the API reported for the Admin API listener
+	AdminAPIListenerTLS = "admin"
+)
+
+// ListenerTLS summarizes the TLS configuration of each listener the way it
+// is rendered in the redpanda.yaml of the brokers
+func (r *PkiReconciler) ListenerTLS() []redpandav1alpha1.ListenerTLSStatus {
+	tlsConfig := r.pandaCluster.Spec.Configuration.TLS
+
+	var summary []redpandav1alpha1.ListenerTLSStatus
+	for _, l := range r.pandaCluster.KafkaAPIListeners() {
+		listener := redpandav1alpha1.ListenerTLSStatus{
+			API:      KafkaAPIListenerTLS,
+			Listener: l.Name,
+		}
+		if l.TLS {
+			listener.Enabled = true
+			listener.RequireClientAuth = l.RequireClientAuth
+			listener.Issuer = r.kafkaIssuer()
+			listener.CertSecret = r.NodeCert().Name
+			if l.Name == redpandav1alpha1.ExternalListener && r.pandaCluster.SeparateExternalCert() {
+				listener.CertSecret = r.ExternalNodeCert().Name
+			}
+		}
+		summary = append(summary, listener)
+	}
+
+	admin := redpandav1alpha1.ListenerTLSStatus{API: AdminAPIListenerTLS}
+	if tlsConfig.AdminAPI.Enabled {
+		admin.Enabled = true
+		admin.RequireClientAuth = tlsConfig.AdminAPI.RequireClientAuth
+		admin.Issuer = r.adminIssuer()
+		admin.CertSecret = r.AdminAPINodeCert().Name
+	}
+	return append(summary, admin)
+}
+
+// kafkaIssuer returns the issuer of the Kafka API node certificate, empty if
+// the certificate is provided by the user
+func (r *PkiReconciler) kafkaIssuer() string {
+	kafkaTLS := r.pandaCluster.Spec.Configuration.TLS.KafkaAPI
+	if kafkaTLS.NodeSecretRef != nil {
+		return ""
+	}
+	return r.issuerOf(kafkaAPI, kafkaTLS.IssuerRef)
+}
+
+// adminIssuer returns the issuer of the Admin API node certificate
+func (r *PkiReconciler) adminIssuer() string {
+	if r.sharedNodeCert() {
+		return r.kafkaIssuer()
+	}
+	return r.issuerOf(adminAPI, r.pandaCluster.Spec.Configuration.TLS.AdminAPI.IssuerRef)
+}
+
+// issuerOf mirrors prepareRoot, which falls back to the self-signed chain of
+// the API when no external issuer is referenced
+func (r *PkiReconciler) issuerOf(
+	prefix string, externalIssuerRef *cmmetav1.ObjectReference,
+) string {
+	ref := externalIssuerRef
+	if ref == nil {
+		ref = &cmmetav1.ObjectReference{
+			Name: r.issuerNamespacedName(prefix + "-" + "root-issuer").Name,
+			Kind: issuerKind(),
+		}
+	}
+	if ref.Kind == "" {
+		return ref.Name
+	}
+	return ref.Kind + "/" + ref.Name
+}
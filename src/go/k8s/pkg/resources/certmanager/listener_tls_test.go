@@ -0,0 +1,196 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager_test
+
+import (
+	"context"
+	"testing"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListenerTLS(t *testing.T) {
+	type renderedTLS struct {
+		Name              string `yaml:"name"`
+		Enabled           bool   `yaml:"enabled"`
+		RequireClientAuth bool   `yaml:"require_client_auth"`
+	}
+
+	tests := []struct {
+		name     string
+		tls      redpandav1alpha1.TLSConfig
+		external redpandav1alpha1.ExternalConnectivityConfig
+		expected []redpandav1alpha1.ListenerTLSStatus
+	}{
+		{
+			name: "tls disabled",
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal"},
+				{API: "admin"},
+			},
+		},
+		{
+			name: "one-way tls",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{Enabled: true},
+			},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal", Enabled: true, Issuer: "cluster-kafka-root-issuer", CertSecret: "cluster-redpanda"},
+				{API: "admin"},
+			},
+		},
+		{
+			name: "mutual tls with external issuer",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+					Enabled:           true,
+					RequireClientAuth: true,
+					IssuerRef:         &cmmeta.ObjectReference{Name: "kafka-issuer", Kind: "ClusterIssuer"},
+				},
+			},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal", Enabled: true, RequireClientAuth: true, Issuer: "ClusterIssuer/kafka-issuer", CertSecret: "cluster-redpanda"},
+				{API: "admin"},
+			},
+		},
+		{
+			name: "tls on external listener",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{Enabled: true},
+			},
+			external: redpandav1alpha1.ExternalConnectivityConfig{Enabled: true},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal"},
+				{API: "kafka", Listener: "External", Enabled: true, Issuer: "cluster-kafka-root-issuer", CertSecret: "cluster-redpanda"},
+				{API: "admin"},
+			},
+		},
+		{
+			name: "separate external certificate",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{Enabled: true, SeparateExternalCert: true},
+			},
+			external: redpandav1alpha1.ExternalConnectivityConfig{Enabled: true, Subdomain: "redpanda.example.com"},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal", Enabled: true, Issuer: "cluster-kafka-root-issuer", CertSecret: "cluster-redpanda"},
+				{API: "kafka", Listener: "External", Enabled: true, Issuer: "cluster-kafka-root-issuer", CertSecret: "cluster-redpanda-external"},
+				{API: "admin"},
+			},
+		},
+		{
+			name: "user provided node certificate",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+					Enabled:       true,
+					NodeSecretRef: &corev1.ObjectReference{Name: "my-cert", Namespace: "default"},
+				},
+			},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal", Enabled: true, CertSecret: "my-cert"},
+				{API: "admin"},
+			},
+		},
+		{
+			name: "admin api mutual tls",
+			tls: redpandav1alpha1.TLSConfig{
+				AdminAPI: redpandav1alpha1.AdminAPITLS{Enabled: true, RequireClientAuth: true},
+			},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal"},
+				{API: "admin", Enabled: true, RequireClientAuth: true, Issuer: "cluster-admin-root-issuer", CertSecret: "cluster-admin-api-node"},
+			},
+		},
+		{
+			name: "shared node certificate",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI:       redpandav1alpha1.KafkaAPITLS{Enabled: true},
+				AdminAPI:       redpandav1alpha1.AdminAPITLS{Enabled: true},
+				SharedNodeCert: true,
+			},
+			expected: []redpandav1alpha1.ListenerTLSStatus{
+				{API: "kafka", Listener: "Internal", Enabled: true, Issuer: "cluster-kafka-root-issuer", CertSecret: "cluster-redpanda"},
+				{API: "admin", Enabled: true, Issuer: "cluster-kafka-root-issuer", CertSecret: "cluster-redpanda"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(scheme))
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster",
+					Namespace: "default",
+					UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						KafkaAPI: redpandav1alpha1.SocketAddress{Port: 9092},
+						AdminAPI: redpandav1alpha1.SocketAddress{Port: 9644},
+						TLS:      tt.tls,
+					},
+					ExternalConnectivity: tt.external,
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+			summary := pki.ListenerTLS()
+			assert.Equal(t, tt.expected, summary)
+
+			cm := resources.NewConfigMap(c, cluster, scheme, "cluster.default.svc.cluster.local.", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+			require.NoError(t, cm.Ensure(context.Background()))
+			var actual corev1.ConfigMap
+			require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+			var cfg struct {
+				Redpanda struct {
+					KafkaAPITLS []renderedTLS `yaml:"kafka_api_tls"`
+					AdminAPITLS renderedTLS   `yaml:"admin_api_tls"`
+				} `yaml:"redpanda"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+
+			// every listener reported with TLS is rendered with the same
+			// settings, and no other listener is
+			var expectedKafka []renderedTLS
+			for _, listener := range summary {
+				switch {
+				case !listener.Enabled:
+				case listener.API == certmanager.AdminAPIListenerTLS:
+					assert.Equal(t, renderedTLS{Enabled: true, RequireClientAuth: listener.RequireClientAuth}, cfg.Redpanda.AdminAPITLS)
+				default:
+					expectedKafka = append(expectedKafka, renderedTLS{
+						Name:              listener.Listener,
+						Enabled:           true,
+						RequireClientAuth: listener.RequireClientAuth,
+					})
+				}
+			}
+			assert.Equal(t, expectedKafka, cfg.Redpanda.KafkaAPITLS)
+			if !tt.tls.AdminAPI.Enabled {
+				assert.Equal(t, renderedTLS{}, cfg.Redpanda.AdminAPITLS)
+			}
+		})
+	}
+}
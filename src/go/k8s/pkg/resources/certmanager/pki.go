@@ -12,13 +12,19 @@ package certmanager
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -34,6 +40,8 @@ type PkiReconciler struct {
 	scheme       *runtime.Scheme
 	pandaCluster *redpandav1alpha1.Cluster
 	internalFQDN string
+	stagger      *IssuanceStagger
+	recorder     record.EventRecorder
 	logger       logr.Logger
 }
 
@@ -46,13 +54,38 @@ func NewPki(
 	logger logr.Logger,
 ) *PkiReconciler {
 	return &PkiReconciler{
-		client, scheme, pandaCluster, fqdn, logger.WithValues("Reconciler", "pki"),
+		client, scheme, pandaCluster, fqdn, nil, nil, logger.WithValues("Reconciler", "pki"),
 	}
 }
 
+// WithIssuanceStagger delays creation of certificates of the cluster when
+// certificates of other clusters were just created
+func (r *PkiReconciler) WithIssuanceStagger(
+	stagger *IssuanceStagger,
+) *PkiReconciler {
+	r.stagger = stagger
+	return r
+}
+
+// WithRecorder records an event on the Cluster for every created
+// certificate
+func (r *PkiReconciler) WithRecorder(
+	recorder record.EventRecorder,
+) *PkiReconciler {
+	r.recorder = recorder
+	return r
+}
+
+// prepareRoot returns the self-signed issuer chain of the API and the
+// reference to its leaf issuer. The chain is not created when the API
+// references an external issuer, which then issues all its certificates.
 func (r *PkiReconciler) prepareRoot(
-	prefix string,
+	prefix string, externalIssuerRef *cmmetav1.ObjectReference,
 ) ([]resources.Resource, *cmmetav1.ObjectReference) {
+	if externalIssuerRef != nil {
+		return nil, externalIssuerRef
+	}
+
 	toApply := []resources.Resource{}
 
 	selfSignedIssuer := NewIssuer(r.Client,
@@ -63,7 +96,7 @@ func (r *PkiReconciler) prepareRoot(
 		r.logger)
 
 	rootCn := NewCommonName(r.pandaCluster.Name, prefix+"-root-certificate")
-	rootKey := types.NamespacedName{Name: string(rootCn), Namespace: r.pandaCluster.Namespace}
+	rootKey := types.NamespacedName{Name: certificateName(r.pandaCluster.Name, prefix+"-root-certificate"), Namespace: r.pandaCluster.Namespace}
 	rootCertificate := NewCertificate(r.Client,
 		r.scheme,
 		r.pandaCluster,
@@ -90,8 +123,10 @@ func (r *PkiReconciler) prepareRoot(
 func (r *PkiReconciler) Ensure(ctx context.Context) error {
 	toApply := []resources.Resource{}
 
+	var kafkaIssuerRef *cmmetav1.ObjectReference
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
-		toApplyRootKafka, kafkaIssuerRef := r.prepareRoot(kafkaAPI)
+		var toApplyRootKafka []resources.Resource
+		toApplyRootKafka, kafkaIssuerRef = r.prepareRoot(kafkaAPI, r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.IssuerRef)
 		toApplyKafka, err := r.prepareKafkaAPI(ctx, kafkaIssuerRef)
 		if err != nil {
 			return err
@@ -101,9 +136,23 @@ func (r *PkiReconciler) Ensure(ctx context.Context) error {
 	}
 
 	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
-		toApplyRootAdmin, adminIssuerRef := r.prepareRoot(adminAPI)
-		toApply = append(toApply, toApplyRootAdmin...)
-		toApply = append(toApply, r.prepareAdminAPI(adminIssuerRef)...)
+		if r.sharedNodeCert() {
+			// node and client certificates have to be signed by the same CA
+			// that is distributed with the shared node certificate
+			toApply = append(toApply, r.prepareAdminAPI(kafkaIssuerRef)...)
+		} else {
+			toApplyRootAdmin, adminIssuerRef := r.prepareRoot(adminAPI, r.pandaCluster.Spec.Configuration.TLS.AdminAPI.IssuerRef)
+			toApply = append(toApply, toApplyRootAdmin...)
+			toApply = append(toApply, r.prepareAdminAPI(adminIssuerRef)...)
+		}
+	}
+
+	pending, err := r.pendingCertificates(ctx, toApply)
+	if err != nil {
+		return err
+	}
+	if err = r.staggerIssuance(pending); err != nil {
+		return err
 	}
 
 	for _, res := range toApply {
@@ -112,10 +161,144 @@ func (r *PkiReconciler) Ensure(ctx context.Context) error {
 			r.logger.Error(err, "Failed to reconcile pki")
 		}
 	}
+	r.reportCreatedCertificates(ctx, pending)
 
+	return r.validateNodeCertificates(ctx)
+}
+
+// staggerIssuance requeues the reconciliation when certificates have to be
+// created, but the issuance slot of the cluster has not come yet
+func (r *PkiReconciler) staggerIssuance(pending []types.NamespacedName) error {
+	if r.stagger == nil || len(pending) == 0 {
+		return nil
+	}
+	delay := r.stagger.Delay(types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}, time.Now())
+	if delay > 0 {
+		return &resources.RequeueAfterError{RequeueAfter: delay,
+			Msg: fmt.Sprintf("certificate issuance is staggered by %s", delay)}
+	}
 	return nil
 }
 
+// pendingCertificates returns the certificates that are not created yet
+func (r *PkiReconciler) pendingCertificates(
+	ctx context.Context, toApply []resources.Resource,
+) ([]types.NamespacedName, error) {
+	var pending []types.NamespacedName
+	for _, res := range toApply {
+		if _, ok := res.(*CertificateResource); !ok {
+			continue
+		}
+		var cert cmapiv1.Certificate
+		err := r.Get(ctx, res.Key(), &cert)
+		if apierrors.IsNotFound(err) {
+			pending = append(pending, res.Key())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// reportCreatedCertificates records an event for the pending certificates
+// that exist now
+func (r *PkiReconciler) reportCreatedCertificates(
+	ctx context.Context, pending []types.NamespacedName,
+) {
+	if r.recorder == nil {
+		return
+	}
+	for _, key := range pending {
+		var cert cmapiv1.Certificate
+		if err := r.Get(ctx, key, &cert); err != nil {
+			continue
+		}
+		r.recorder.Eventf(r.pandaCluster, corev1.EventTypeNormal, "CertificateCreated",
+			"Certificate %s was created", key.Name)
+	}
+}
+
+// NodeCertificates returns the Secrets with the node certificates of the
+// listeners with TLS enabled
+func (r *PkiReconciler) NodeCertificates() []types.NamespacedName {
+	var certs []types.NamespacedName
+	tlsConfig := r.pandaCluster.Spec.Configuration.TLS
+	if tlsConfig.KafkaAPI.Enabled {
+		certs = append(certs, r.NodeCert())
+	}
+	if r.pandaCluster.SeparateExternalCert() {
+		certs = append(certs, r.ExternalNodeCert())
+	}
+	if tlsConfig.AdminAPI.Enabled && !r.sharedNodeCert() {
+		certs = append(certs, r.AdminAPINodeCert())
+	}
+	return certs
+}
+
+// validateNodeCertificates verifies the node certificates issued by cert-manager
+func (r *PkiReconciler) validateNodeCertificates(ctx context.Context) error {
+	tlsConfig := r.pandaCluster.Spec.Configuration.TLS
+	if tlsConfig.KafkaAPI.Enabled && tlsConfig.KafkaAPI.NodeSecretRef == nil {
+		if err := r.validateSecret(ctx, r.NodeCert()); err != nil {
+			return err
+		}
+	}
+	if r.pandaCluster.SeparateExternalCert() {
+		if err := r.validateSecret(ctx, r.ExternalNodeCert()); err != nil {
+			return err
+		}
+	}
+	if tlsConfig.AdminAPI.Enabled && !r.sharedNodeCert() {
+		if err := r.validateSecret(ctx, r.AdminAPINodeCert()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sharedNodeCert returns true if Kafka API node certificate is used
+// by Admin API listener as well
+func (r *PkiReconciler) sharedNodeCert() bool {
+	return isNodeCertShared(r.pandaCluster)
+}
+
+func isNodeCertShared(pandaCluster *redpandav1alpha1.Cluster) bool {
+	tlsConfig := pandaCluster.Spec.Configuration.TLS
+	return tlsConfig.SharedNodeCert && tlsConfig.KafkaAPI.Enabled && tlsConfig.AdminAPI.Enabled
+}
+
+// nodeCertDNSNames returns the domains covered by node certificates. The
+// external subdomain is left out when it has a certificate of its own.
+func (r *PkiReconciler) nodeCertDNSNames() []string {
+	dnsNames := []string{r.internalFQDN}
+	externConn := r.pandaCluster.Spec.ExternalConnectivity
+	if externConn.Enabled && externConn.Subdomain != "" && !r.pandaCluster.SeparateExternalCert() {
+		dnsNames = append(dnsNames, externConn.Subdomain)
+	}
+	// the shared certificate is presented on the Admin API as well
+	if r.sharedNodeCert() {
+		dnsNames = append(dnsNames, r.externalAdminDNSNames()...)
+	}
+	return dnsNames
+}
+
+// adminAPINodeCertDNSNames returns the domains covered by the Admin API node
+// certificate
+func (r *PkiReconciler) adminAPINodeCertDNSNames() []string {
+	return append(r.nodeCertDNSNames(), r.externalAdminDNSNames()...)
+}
+
+// externalAdminDNSNames returns the subdomain of the Admin API exposed
+// without the Kafka API
+func (r *PkiReconciler) externalAdminDNSNames() []string {
+	if !r.pandaCluster.SeparateExternalAdmin() || r.pandaCluster.Spec.ExternalAdmin.Subdomain == "" {
+		return nil
+	}
+	return []string{r.pandaCluster.Spec.ExternalAdmin.Subdomain}
+}
+
 func (r *PkiReconciler) issuerNamespacedName(name string) types.NamespacedName {
 	return types.NamespacedName{Name: r.pandaCluster.Name + "-" + name, Namespace: r.pandaCluster.Namespace}
 }
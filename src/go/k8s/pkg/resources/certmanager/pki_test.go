@@ -0,0 +1,538 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPkiIssuerRefPerAPI(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster",
+			Namespace: "default",
+			UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+						Enabled: true,
+						IssuerRef: &cmmeta.ObjectReference{
+							Name: "kafka-issuer",
+							Kind: "ClusterIssuer",
+						},
+					},
+					AdminAPI: redpandav1alpha1.AdminAPITLS{
+						Enabled: true,
+						IssuerRef: &cmmeta.ObjectReference{
+							Name: "admin-issuer",
+							Kind: "Issuer",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, pki.Ensure(context.Background()))
+
+	tests := []struct {
+		name           string
+		certificate    string
+		expectedIssuer cmmeta.ObjectReference
+	}{
+		{"kafka api node certificate", "cluster-redpanda", *cluster.Spec.Configuration.TLS.KafkaAPI.IssuerRef},
+		{"admin api node certificate", "cluster-admin-api-node", *cluster.Spec.Configuration.TLS.AdminAPI.IssuerRef},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cert cmapiv1.Certificate
+			err := c.Get(context.Background(), types.NamespacedName{Name: tt.certificate, Namespace: cluster.Namespace}, &cert)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIssuer, cert.Spec.IssuerRef)
+		})
+	}
+}
+
+func TestPkiExternalIssuer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	kafkaIssuer := cmmeta.ObjectReference{Name: "vault", Kind: "ClusterIssuer"}
+	adminIssuer := cmmeta.ObjectReference{Name: "admin-issuer", Kind: "Issuer"}
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster",
+			Namespace: "default",
+			UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+						Enabled:           true,
+						IssuerRef:         &kafkaIssuer,
+						RequireClientAuth: true,
+					},
+					AdminAPI: redpandav1alpha1.AdminAPITLS{
+						Enabled:           true,
+						IssuerRef:         &adminIssuer,
+						RequireClientAuth: true,
+					},
+				},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, pki.Ensure(context.Background()))
+
+	var certs cmapiv1.CertificateList
+	require.NoError(t, c.List(context.Background(), &certs, client.InNamespace(cluster.Namespace)))
+	issuers := map[string]cmmeta.ObjectReference{}
+	for i := range certs.Items {
+		issuers[certs.Items[i].Name] = certs.Items[i].Spec.IssuerRef
+	}
+	assert.Equal(t, map[string]cmmeta.ObjectReference{
+		"cluster-redpanda":         kafkaIssuer,
+		"cluster-user-client":      kafkaIssuer,
+		"cluster-operator-client":  kafkaIssuer,
+		"cluster-admin-client":     kafkaIssuer,
+		"cluster-admin-api-node":   adminIssuer,
+		"cluster-admin-api-client": adminIssuer,
+	}, issuers)
+
+	// the self-signed issuer chain is not created
+	var issuerList cmapiv1.IssuerList
+	require.NoError(t, c.List(context.Background(), &issuerList, client.InNamespace(cluster.Namespace)))
+	assert.Empty(t, issuerList.Items)
+}
+
+func TestPkiSharedNodeCert(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster",
+			Namespace: "default",
+			UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI:       redpandav1alpha1.KafkaAPITLS{Enabled: true},
+					AdminAPI:       redpandav1alpha1.AdminAPITLS{Enabled: true},
+					SharedNodeCert: true,
+				},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, pki.Ensure(context.Background()))
+
+	assert.Equal(t, pki.NodeCert(), pki.AdminAPINodeCert())
+
+	var certs cmapiv1.CertificateList
+	require.NoError(t, c.List(context.Background(), &certs, client.InNamespace(cluster.Namespace)))
+	var nodeCerts []cmapiv1.Certificate
+	for i := range certs.Items {
+		if len(certs.Items[i].Spec.DNSNames) > 0 {
+			nodeCerts = append(nodeCerts, certs.Items[i])
+		}
+	}
+	require.Len(t, nodeCerts, 1)
+	assert.Equal(t, pki.NodeCert().Name, nodeCerts[0].Name)
+	assert.Equal(t, []string{"*.cluster.default.svc.cluster.local"}, nodeCerts[0].Spec.DNSNames)
+}
+
+func TestPkiNodeSecretOwnerReference(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster",
+			Namespace: "default",
+			UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+						Enabled: true,
+						NodeSecretRef: &corev1.ObjectReference{
+							Name:      "node-secret",
+							Namespace: "other",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	crt, key := generateCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-secret",
+			Namespace: "other",
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       crt,
+			corev1.TLSPrivateKeyKey: key,
+		},
+	}
+	// copy created by operator version that did not set owner references
+	orphan := source.DeepCopy()
+	orphan.Namespace = cluster.Namespace
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+	}{
+		{"secret is copied", []client.Object{source.DeepCopy()}},
+		{"existing copy is adopted", []client.Object{source.DeepCopy(), orphan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.existing...).Build()
+			pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+			require.NoError(t, pki.Ensure(context.Background()))
+
+			var secret corev1.Secret
+			require.NoError(t, c.Get(context.Background(), pki.NodeCert(), &secret))
+			owner := metav1.GetControllerOf(&secret)
+			require.NotNil(t, owner)
+			assert.Equal(t, cluster.UID, owner.UID)
+			assert.Equal(t, cluster.Name, owner.Name)
+		})
+	}
+}
+
+func TestPkiKafkaNodeCertDNSNames(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	internal := "*.cluster.default.svc.cluster.local"
+	external := "*.redpanda.example.com"
+	tests := []struct {
+		name                 string
+		externalConnectivity bool
+		separateExternalCert bool
+		expectedCerts        map[string][]string
+	}{
+		{"internal only", false, false, map[string][]string{
+			"cluster-redpanda": {internal},
+		}},
+		{"dual SAN", true, false, map[string][]string{
+			"cluster-redpanda": {internal, external},
+		}},
+		{"separate external certificate", true, true, map[string][]string{
+			"cluster-redpanda":          {internal},
+			"cluster-redpanda-external": {external},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster",
+					Namespace: "default",
+					UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						TLS: redpandav1alpha1.TLSConfig{
+							KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+								Enabled:              true,
+								SeparateExternalCert: tt.separateExternalCert,
+							},
+						},
+					},
+					ExternalConnectivity: redpandav1alpha1.ExternalConnectivityConfig{
+						Enabled:   tt.externalConnectivity,
+						Subdomain: "redpanda.example.com",
+					},
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+			require.NoError(t, pki.Ensure(context.Background()))
+
+			var certs cmapiv1.CertificateList
+			require.NoError(t, c.List(context.Background(), &certs, client.InNamespace(cluster.Namespace)))
+			nodeCerts := map[string][]string{}
+			for i := range certs.Items {
+				if len(certs.Items[i].Spec.DNSNames) > 0 {
+					nodeCerts[certs.Items[i].Name] = certs.Items[i].Spec.DNSNames
+				}
+			}
+			assert.Equal(t, tt.expectedCerts, nodeCerts)
+		})
+	}
+}
+
+func TestPkiAdminAPINodeCertDNSNames(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	internal := "*.cluster.default.svc.cluster.local"
+	admin := "*.admin.example.com"
+	tests := []struct {
+		name           string
+		externalAdmin  bool
+		sharedNodeCert bool
+		expectedCerts  map[string][]string
+	}{
+		{"internal only", false, false, map[string][]string{
+			"cluster-redpanda":       {internal},
+			"cluster-admin-api-node": {internal},
+		}},
+		{"external admin", true, false, map[string][]string{
+			"cluster-redpanda":       {internal},
+			"cluster-admin-api-node": {internal, admin},
+		}},
+		{"external admin with shared node certificate", true, true, map[string][]string{
+			"cluster-redpanda": {internal, admin},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster",
+					Namespace: "default",
+					UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						TLS: redpandav1alpha1.TLSConfig{
+							KafkaAPI:       redpandav1alpha1.KafkaAPITLS{Enabled: true},
+							AdminAPI:       redpandav1alpha1.AdminAPITLS{Enabled: true},
+							SharedNodeCert: tt.sharedNodeCert,
+						},
+					},
+					ExternalAdmin: redpandav1alpha1.ExternalAdminConfig{
+						Enabled:   tt.externalAdmin,
+						Subdomain: "admin.example.com",
+					},
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+			require.NoError(t, pki.Ensure(context.Background()))
+
+			var certs cmapiv1.CertificateList
+			require.NoError(t, c.List(context.Background(), &certs, client.InNamespace(cluster.Namespace)))
+			nodeCerts := map[string][]string{}
+			for i := range certs.Items {
+				if len(certs.Items[i].Spec.DNSNames) > 0 {
+					nodeCerts[certs.Items[i].Name] = certs.Items[i].Spec.DNSNames
+				}
+			}
+			assert.Equal(t, tt.expectedCerts, nodeCerts)
+		})
+	}
+}
+
+func TestPkiMetricsClientCert(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	adminIssuer := cmmeta.ObjectReference{Name: "admin-issuer", Kind: "Issuer"}
+	tests := []struct {
+		name              string
+		metrics           bool
+		requireClientAuth bool
+		expectCert        bool
+	}{
+		{"metrics TLS with client authentication", true, true, true},
+		{"metrics TLS without client authentication", true, false, false},
+		{"metrics TLS disabled", false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster",
+					Namespace: "default",
+					UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+				},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						TLS: redpandav1alpha1.TLSConfig{
+							AdminAPI: redpandav1alpha1.AdminAPITLS{
+								Enabled:           true,
+								IssuerRef:         &adminIssuer,
+								RequireClientAuth: tt.requireClientAuth,
+							},
+							Metrics: redpandav1alpha1.MetricsTLS{Enabled: tt.metrics},
+						},
+					},
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+			require.NoError(t, pki.Ensure(context.Background()))
+
+			var cert cmapiv1.Certificate
+			err := c.Get(context.Background(), types.NamespacedName{Name: "cluster-metrics-client", Namespace: cluster.Namespace}, &cert)
+			if !tt.expectCert {
+				assert.True(t, apierrors.IsNotFound(err), "expecting no certificate, got %v", err)
+				assert.Empty(t, pki.MetricsClientCert().Name)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, adminIssuer, cert.Spec.IssuerRef)
+			assert.Equal(t, "cluster-metrics-client", cert.Spec.CommonName)
+			assert.Equal(t, cert.Spec.SecretName, pki.MetricsClientCert().Name)
+		})
+	}
+}
+
+func TestPkiLongClusterName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.Repeat("a", 55),
+			Namespace: "default",
+			UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					KafkaAPI: redpandav1alpha1.KafkaAPITLS{
+						Enabled:           true,
+						RequireClientAuth: true,
+					},
+					AdminAPI: redpandav1alpha1.AdminAPITLS{
+						Enabled:           true,
+						RequireClientAuth: true,
+					},
+				},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, pki.Ensure(context.Background()))
+
+	var certs cmapiv1.CertificateList
+	require.NoError(t, c.List(context.Background(), &certs, client.InNamespace(cluster.Namespace)))
+	names := map[string]bool{}
+	for i := range certs.Items {
+		cert := certs.Items[i]
+		names[cert.Name] = true
+		assert.LessOrEqual(t, len(cert.Spec.CommonName), 64, cert.Name)
+		assert.Equal(t, cert.Name, cert.Spec.SecretName)
+		if len(cert.Name) > 64 {
+			assert.Contains(t, cert.Spec.DNSNames, cert.Name, "full name is kept in the SANs")
+		} else {
+			assert.Equal(t, cert.Name, cert.Spec.CommonName)
+		}
+	}
+
+	// the Secrets mounted to the brokers and used by the operator are issued
+	for _, key := range []types.NamespacedName{
+		pki.NodeCert(), pki.UserClientCert(), pki.OperatorClientCert(), pki.AdminAPINodeCert(),
+	} {
+		assert.True(t, names[key.Name], key.Name)
+	}
+
+	// reconciling again doesn't change the certificates
+	require.NoError(t, pki.Ensure(context.Background()))
+	var again cmapiv1.CertificateList
+	require.NoError(t, c.List(context.Background(), &again, client.InNamespace(cluster.Namespace)))
+	assert.Equal(t, certs.Items, again.Items)
+}
+
+func TestAdminAPIClientCommonName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	require.NoError(t, cmapiv1.AddToScheme(scheme))
+
+	cluster := &redpandav1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster",
+			Namespace: "default",
+			UID:       "ff2770aa-c919-43f0-8b4a-30cb7cfdaf79",
+		},
+		Spec: redpandav1alpha1.ClusterSpec{
+			Configuration: redpandav1alpha1.RedpandaConfig{
+				TLS: redpandav1alpha1.TLSConfig{
+					AdminAPI: redpandav1alpha1.AdminAPITLS{Enabled: true},
+				},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+	assert.Empty(t, pki.AdminAPIClientCommonName())
+
+	// the reported CN is the one of the issued client certificate
+	cluster.Spec.Configuration.TLS.AdminAPI.RequireClientAuth = true
+	pki = certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+	require.NoError(t, pki.Ensure(context.Background()))
+	var cert cmapiv1.Certificate
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "cluster-admin-api-client", Namespace: "default"}, &cert))
+	assert.Equal(t, cert.Spec.CommonName, pki.AdminAPIClientCommonName())
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AdvertisedAddresses returns the addresses advertised by the TLS listeners
+// grouped by the Secret of the node certificate presented on them
+func (r *PkiReconciler) AdvertisedAddresses(
+	nodes redpandav1alpha1.NodesList,
+) map[types.NamespacedName][]string {
+	addresses := make(map[types.NamespacedName][]string)
+	for _, l := range r.pandaCluster.KafkaAPIListeners() {
+		if !l.TLS {
+			continue
+		}
+		switch {
+		case l.Name == redpandav1alpha1.ExternalListener && r.pandaCluster.SeparateExternalCert():
+			addresses[r.ExternalNodeCert()] = append(addresses[r.ExternalNodeCert()], nodes.External...)
+		case l.Name == redpandav1alpha1.ExternalListener:
+			addresses[r.NodeCert()] = append(addresses[r.NodeCert()], nodes.External...)
+		default:
+			addresses[r.NodeCert()] = append(addresses[r.NodeCert()], nodes.Internal...)
+		}
+	}
+	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
+		key := r.AdminAPINodeCert()
+		addresses[key] = append(addresses[key], nodes.Internal...)
+		addresses[key] = append(addresses[key], nodes.ExternalAdmin...)
+	}
+	return addresses
+}
+
+// MissingSANs returns the addresses that are not covered by the certificate
+// in tls.crt of the Secret. Ports are ignored, addresses are matched against
+// both DNS and IP SANs.
+func MissingSANs(secret *corev1.Secret, addresses []string) ([]string, error) {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return nil, errMissingCertificate
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		host := address
+		if h, _, err := net.SplitHostPort(address); err == nil {
+			host = h
+		}
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		if err := leaf.VerifyHostname(host); err != nil {
+			missing = append(missing, host)
+		}
+	}
+	return missing, nil
+}
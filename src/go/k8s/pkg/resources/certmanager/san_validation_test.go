@@ -0,0 +1,176 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMissingSANs(t *testing.T) {
+	crt := generateCertificateWithSANs(t,
+		[]string{"*.cluster.default.svc.cluster.local", "*.redpanda.example.com"},
+		[]net.IP{net.ParseIP("10.0.0.1")})
+
+	tests := []struct {
+		name      string
+		addresses []string
+		expected  []string
+	}{
+		{
+			name: "all addresses covered",
+			addresses: []string{
+				"cluster-0.cluster.default.svc.cluster.local.",
+				"0.redpanda.example.com:30001",
+				"10.0.0.1:30001",
+			},
+		},
+		{
+			name: "address outside of the wildcard",
+			addresses: []string{
+				"cluster-0.cluster.default.svc.cluster.local.",
+				"0.kafka.example.com:30001",
+			},
+			expected: []string{"0.kafka.example.com"},
+		},
+		{
+			name:      "node IP without SAN",
+			addresses: []string{"10.0.0.2:30001", "10.0.0.2:30002", "10.0.0.1:30001"},
+			expected:  []string{"10.0.0.2"},
+		},
+		{
+			name:      "wildcard covers a single label only",
+			addresses: []string{"a.0.redpanda.example.com:30001"},
+			expected:  []string{"a.0.redpanda.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: crt}}
+			missing, err := certmanager.MissingSANs(secret, tt.addresses)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, missing)
+		})
+	}
+
+	_, err := certmanager.MissingSANs(&corev1.Secret{}, []string{"10.0.0.1"})
+	assert.Error(t, err)
+}
+
+func TestAdvertisedAddresses(t *testing.T) {
+	nodes := redpandav1alpha1.NodesList{
+		Internal:      []string{"cluster-0.cluster.default.svc.cluster.local."},
+		External:      []string{"0.redpanda.example.com:30001"},
+		ExternalAdmin: []string{"0.redpanda.example.com:30002"},
+	}
+	nodeCert := types.NamespacedName{Name: "cluster-redpanda", Namespace: "default"}
+	externalCert := types.NamespacedName{Name: "cluster-redpanda-external", Namespace: "default"}
+	adminCert := types.NamespacedName{Name: "cluster-admin-api-node", Namespace: "default"}
+
+	tests := []struct {
+		name     string
+		tls      redpandav1alpha1.TLSConfig
+		expected map[types.NamespacedName][]string
+	}{
+		{
+			name:     "tls disabled",
+			expected: map[types.NamespacedName][]string{},
+		},
+		{
+			name: "tls on external listener",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{Enabled: true},
+			},
+			expected: map[types.NamespacedName][]string{
+				nodeCert: {"0.redpanda.example.com:30001"},
+			},
+		},
+		{
+			name: "separate external certificate",
+			tls: redpandav1alpha1.TLSConfig{
+				KafkaAPI: redpandav1alpha1.KafkaAPITLS{Enabled: true, SeparateExternalCert: true},
+			},
+			expected: map[types.NamespacedName][]string{
+				nodeCert:     {"cluster-0.cluster.default.svc.cluster.local."},
+				externalCert: {"0.redpanda.example.com:30001"},
+			},
+		},
+		{
+			name: "admin api tls",
+			tls: redpandav1alpha1.TLSConfig{
+				AdminAPI: redpandav1alpha1.AdminAPITLS{Enabled: true},
+			},
+			expected: map[types.NamespacedName][]string{
+				adminCert: {"cluster-0.cluster.default.svc.cluster.local.", "0.redpanda.example.com:30002"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &redpandav1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+				Spec: redpandav1alpha1.ClusterSpec{
+					Configuration: redpandav1alpha1.RedpandaConfig{
+						KafkaAPI: redpandav1alpha1.SocketAddress{Port: 9092},
+						TLS:      tt.tls,
+					},
+					ExternalConnectivity: redpandav1alpha1.ExternalConnectivityConfig{
+						Enabled:   true,
+						Subdomain: "redpanda.example.com",
+					},
+				},
+			}
+			scheme := runtime.NewScheme()
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			pki := certmanager.NewPki(c, cluster, "cluster.default.svc.cluster.local.", scheme, ctrl.Log.WithName("test"))
+			assert.Equal(t, tt.expected, pki.AdvertisedAddresses(nodes))
+		})
+	}
+}
+
+func generateCertificateWithSANs(
+	t *testing.T, dnsNames []string, ips []net.IP,
+) []byte {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redpanda"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
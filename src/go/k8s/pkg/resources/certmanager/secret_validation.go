@@ -0,0 +1,95 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	errMissingCertificate = errors.New("secret does not contain certificate")
+	errMissingKey         = errors.New("secret does not contain private key")
+	errExpiredCertificate = errors.New("certificate is expired")
+	errNotYetValid        = errors.New("certificate is not valid yet")
+)
+
+// InvalidCertificateError is returned when a TLS Secret that is about to be
+// used by a listener contains unusable certificate material
+type InvalidCertificateError struct {
+	Secret types.NamespacedName
+	Err    error
+}
+
+func (e *InvalidCertificateError) Error() string {
+	return fmt.Sprintf("secret %s contains invalid certificate: %v", e.Secret, e.Err)
+}
+
+func (e *InvalidCertificateError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateCertificateSecret verifies that the tls.crt from the Secret parses,
+// matches tls.key and is valid at the given point in time
+func ValidateCertificateSecret(secret *corev1.Secret, now time.Time) error {
+	crt := secret.Data[corev1.TLSCertKey]
+	key := secret.Data[corev1.TLSPrivateKeyKey]
+	if len(crt) == 0 {
+		return errMissingCertificate
+	}
+	if len(key) == 0 {
+		return errMissingKey
+	}
+
+	// X509KeyPair parses both the chain and the key and checks that
+	// the public key of the leaf matches the private key
+	pair, err := tls.X509KeyPair(crt, key)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("%w: not after %s", errExpiredCertificate, leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("%w: not before %s", errNotYetValid, leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// validateSecret fetches the Secret and validates its content. Missing
+// Secrets are skipped as cert-manager might not have issued them yet.
+func (r *PkiReconciler) validateSecret(
+	ctx context.Context, key types.NamespacedName,
+) error {
+	var secret corev1.Secret
+	if err := r.Get(ctx, key, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := ValidateCertificateSecret(&secret, time.Now()); err != nil {
+		return &InvalidCertificateError{Secret: key, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateCertificateSecret(t *testing.T) {
+	now := time.Now()
+
+	validCrt, validKey := generateCertificate(t, now.Add(-time.Hour), now.Add(time.Hour))
+	_, otherKey := generateCertificate(t, now.Add(-time.Hour), now.Add(time.Hour))
+	expiredCrt, expiredKey := generateCertificate(t, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	tests := []struct {
+		name        string
+		crt         []byte
+		key         []byte
+		expectError bool
+	}{
+		{"valid certificate", validCrt, validKey, false},
+		{"certificate does not match key", validCrt, otherKey, true},
+		{"expired certificate", expiredCrt, expiredKey, true},
+		{"missing certificate", nil, validKey, true},
+		{"missing key", validCrt, nil, true},
+		{"garbage certificate", []byte("not a certificate"), validKey, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       tt.crt,
+					corev1.TLSPrivateKeyKey: tt.key,
+				},
+			}
+			err := certmanager.ValidateCertificateSecret(secret, now)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func generateCertificate(
+	t *testing.T, notBefore, notAfter time.Time,
+) (crt, key []byte) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redpanda"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+
+	crt = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return crt, key
+}
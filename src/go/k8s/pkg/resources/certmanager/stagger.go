@@ -0,0 +1,88 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// IssuanceStagger spreads certificate issuance of many clusters over time,
+// so issuers with rate limits (e.g. ACME) are not flooded with requests when
+// the operator creates certificates of all clusters at once. A single
+// instance is shared by all clusters reconciled by the operator.
+type IssuanceStagger struct {
+	mu       sync.Mutex
+	interval time.Duration
+	// next is the earliest time the following cluster can be issued
+	next time.Time
+	// slots holds the issuance time reserved for delayed clusters
+	slots map[types.NamespacedName]time.Time
+}
+
+// NewIssuanceStagger creates IssuanceStagger that spaces issuance of
+// subsequent clusters by interval extended with up to interval/2 of jitter
+func NewIssuanceStagger(interval time.Duration) *IssuanceStagger {
+	return &IssuanceStagger{
+		interval: interval,
+		slots:    make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// Delay returns how long the cluster has to wait before its certificates
+// are issued. Zero means the certificates can be issued right away.
+func (s *IssuanceStagger) Delay(
+	cluster types.NamespacedName, now time.Time,
+) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slot, ok := s.slots[cluster]; ok {
+		if !now.Before(slot) {
+			delete(s.slots, cluster)
+			return 0
+		}
+		return slot.Sub(now)
+	}
+
+	slot := now
+	if s.next.After(now) {
+		slot = s.next
+	}
+	s.next = slot.Add(s.interval + s.jitter())
+	if !slot.After(now) {
+		return 0
+	}
+	s.slots[cluster] = slot
+	return slot.Sub(now)
+}
+
+// Release frees the slot reserved for the cluster when the cluster is
+// deleted before its certificates are issued
+func (s *IssuanceStagger) Release(cluster types.NamespacedName) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.slots, cluster)
+}
+
+func (s *IssuanceStagger) jitter() time.Duration {
+	maxJitter := int64(s.interval / 2)
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(maxJitter)) // nolint:gosec // jitter doesn't need crypto rand
+}
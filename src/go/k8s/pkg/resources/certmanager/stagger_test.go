@@ -0,0 +1,76 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package certmanager_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources/certmanager"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIssuanceStagger(t *testing.T) {
+	interval := time.Minute
+	stagger := certmanager.NewIssuanceStagger(interval)
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	clusters := []types.NamespacedName{
+		{Name: "first", Namespace: "default"},
+		{Name: "second", Namespace: "default"},
+		{Name: "third", Namespace: "default"},
+	}
+
+	// all clusters request certificates at the same time
+	issuance := make([]time.Time, len(clusters))
+	for i, cluster := range clusters {
+		issuance[i] = now.Add(stagger.Delay(cluster, now))
+	}
+
+	assert.Equal(t, now, issuance[0], "first cluster is not delayed")
+	for i := 1; i < len(issuance); i++ {
+		spacing := issuance[i].Sub(issuance[i-1])
+		assert.GreaterOrEqual(t, int64(spacing), int64(interval))
+		assert.Less(t, int64(spacing), int64(interval+interval/2))
+	}
+
+	// requeued cluster keeps its slot
+	halfway := now.Add(issuance[1].Sub(now) / 2)
+	assert.Equal(t, issuance[1].Sub(halfway), stagger.Delay(clusters[1], halfway))
+
+	// and is not delayed once the slot comes
+	assert.Zero(t, stagger.Delay(clusters[1], issuance[1]))
+	assert.Zero(t, stagger.Delay(clusters[2], issuance[2].Add(time.Second)))
+
+	// clusters requesting certificates after the stagger elapsed are not delayed
+	later := issuance[2].Add(2 * interval)
+	assert.Zero(t, stagger.Delay(types.NamespacedName{Name: "fourth", Namespace: "default"}, later))
+}
+
+func TestIssuanceStaggerRelease(t *testing.T) {
+	interval := time.Minute
+	stagger := certmanager.NewIssuanceStagger(interval)
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	first := types.NamespacedName{Name: "first", Namespace: "default"}
+	deleted := types.NamespacedName{Name: "deleted", Namespace: "default"}
+
+	assert.Zero(t, stagger.Delay(first, now))
+	delay := stagger.Delay(deleted, now)
+	assert.GreaterOrEqual(t, int64(delay), int64(interval))
+
+	// the cluster recreated under the same name after the deletion gets a
+	// new slot instead of the released one
+	stagger.Release(deleted)
+	assert.Greater(t, int64(stagger.Delay(deleted, now)), int64(delay))
+
+	var disabled *certmanager.IssuanceStagger
+	disabled.Release(deleted)
+}
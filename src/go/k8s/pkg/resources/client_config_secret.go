@@ -0,0 +1,297 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	clientConfigSuffix = "-client-config"
+
+	// InternalAdminKey is the client config Secret key with comma separated
+	// Admin API addresses reachable from within the Kubernetes cluster
+	InternalAdminKey = "admin-internal"
+	// ExternalAdminKey is the client config Secret key with comma separated
+	// Admin API addresses reachable from outside of the Kubernetes cluster
+	ExternalAdminKey = "admin-external"
+
+	// propertiesSuffix is appended to the lower case listener name in the
+	// key of the Java client properties file
+	propertiesSuffix = ".properties"
+	// jsonSuffix is appended to the lower case listener name in the key of
+	// the librdkafka client configuration
+	jsonSuffix = ".json"
+)
+
+var _ Resource = &ClientConfigSecretResource{}
+
+// ClientConfigSecretResource publishes everything applications need to
+// connect to the cluster: the Kafka and Admin API addresses, the CA of the
+// Kafka API and the client certificate when client auth is required. The
+// client configuration of every Kafka API listener is also rendered as Java
+// properties and librdkafka JSON, with the certificates inlined as PEM. The
+// SASL credentials are left to the application, the operator doesn't own
+// any user besides the superusers.
+type ClientConfigSecretResource struct {
+	k8sclient.Client
+	scheme        *runtime.Scheme
+	pandaCluster  *redpandav1alpha1.Cluster
+	nodeCertKey   types.NamespacedName
+	clientCertKey types.NamespacedName
+	logger        logr.Logger
+}
+
+// NewClientConfigSecret creates ClientConfigSecretResource. The CA is read
+// from the node certificate Secret and the client certificate from the user
+// client certificate Secret.
+func NewClientConfigSecret(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	nodeCertKey types.NamespacedName,
+	clientCertKey types.NamespacedName,
+	logger logr.Logger,
+) *ClientConfigSecretResource {
+	return &ClientConfigSecretResource{
+		client,
+		scheme,
+		pandaCluster,
+		nodeCertKey,
+		clientCertKey,
+		logger.WithValues("Kind", "Secret", "Secret", "client-config"),
+	}
+}
+
+// Ensure will manage kubernetes v1.Secret with the client configuration.
+// CreateIfNotExists and Update are not used, because they would copy the
+// private key to the last applied annotation and log it in the diff.
+func (r *ClientConfigSecretResource) Ensure(ctx context.Context) error {
+	obj, err := r.obj(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	var secret corev1.Secret
+	err = r.Get(ctx, r.Key(), &secret)
+	if apierrors.IsNotFound(err) {
+		r.logger.Info(fmt.Sprintf("Secret %s did not exist, was created", obj.Name))
+		return r.Create(ctx, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching Secret resource: %w", err)
+	}
+	if reflect.DeepEqual(secret.Data, obj.Data) {
+		return nil
+	}
+	r.logger.Info("Client configuration changed, updating Secret")
+	secret.Data = obj.Data
+	return r.Update(ctx, &secret)
+}
+
+// obj returns resource managed client.Object
+func (r *ClientConfigSecretResource) obj(
+	ctx context.Context,
+) (*corev1.Secret, error) {
+	nodes := r.pandaCluster.Status.Nodes
+	config := r.pandaCluster.Spec.Configuration
+
+	data := map[string][]byte{
+		InternalBootstrapKey: []byte(strings.Join(internalBootstrapAddresses(r.pandaCluster), ",")),
+		InternalAdminKey:     []byte(joinHostPorts(nodes.Internal, config.AdminAPI.Port)),
+	}
+	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+		data[ExternalBootstrapKey] = []byte(strings.Join(nodes.External, ","))
+		data[ExternalAdminKey] = []byte(strings.Join(nodes.ExternalAdmin, ","))
+	}
+
+	// certificates are added once cert-manager issues them, the Secret
+	// watch triggers the reconciliation then
+	if config.TLS.KafkaAPI.Enabled {
+		node, err := r.certificateSecret(ctx, r.nodeCertKey)
+		if err != nil {
+			return nil, err
+		}
+		if ca, ok := node[cmetav1.TLSCAKey]; ok {
+			data[cmetav1.TLSCAKey] = ca
+		}
+	}
+	if config.TLS.KafkaAPI.Enabled && config.TLS.KafkaAPI.RequireClientAuth {
+		client, err := r.certificateSecret(ctx, r.clientCertKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if value, ok := client[key]; ok {
+				data[key] = value
+			}
+		}
+	}
+
+	for _, l := range r.pandaCluster.KafkaAPIListeners() {
+		var bootstrap string
+		switch {
+		case l.Name == redpandav1alpha1.InternalListener:
+			bootstrap = string(data[InternalBootstrapKey])
+		case l.Name == redpandav1alpha1.ExternalListener && r.pandaCluster.Spec.ExternalConnectivity.Enabled:
+			bootstrap = string(data[ExternalBootstrapKey])
+		default:
+			continue
+		}
+		settings := r.listenerSettings(l, bootstrap, data)
+		name := strings.ToLower(l.Name)
+		data[name+propertiesSuffix] = renderProperties(settings.java)
+		rendered, err := json.MarshalIndent(settings.librdkafka, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("unable to render %s client configuration: %w", name, err)
+		}
+		data[name+jsonSuffix] = rendered
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    labels.ForCluster(r.pandaCluster),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, secret, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// clientSettings holds the client configuration of a listener in the
+// property names of the Java client and of librdkafka
+type clientSettings struct {
+	java       [][2]string
+	librdkafka map[string]string
+}
+
+func (s *clientSettings) set(javaName, librdkafkaName, value string) {
+	s.java = append(s.java, [2]string{javaName, value})
+	if librdkafkaName != "" {
+		s.librdkafka[librdkafkaName] = value
+	}
+}
+
+// listenerSettings returns the client configuration of the listener. The
+// certificates are added once they are issued, the CA alone is enough to
+// verify the brokers, the private key of the CA is never published.
+func (r *ClientConfigSecretResource) listenerSettings(
+	l redpandav1alpha1.ListenerSpec, bootstrap string, data map[string][]byte,
+) clientSettings {
+	settings := clientSettings{librdkafka: map[string]string{}}
+	settings.set("bootstrap.servers", "bootstrap.servers", bootstrap)
+	protocol := "PLAINTEXT"
+	if l.TLS {
+		protocol = "SSL"
+	}
+	if r.pandaCluster.Spec.EnableSASL {
+		protocol = "SASL_" + protocol
+	}
+	settings.set("security.protocol", "security.protocol", protocol)
+	if r.pandaCluster.Spec.EnableSASL {
+		// the first enabled mechanism, clients of superusers with another
+		// mechanism have to override it
+		mechanism := string(r.pandaCluster.EnabledSASLMechanisms()[0])
+		settings.set("sasl.mechanism", "sasl.mechanism", mechanism)
+	}
+	if !l.TLS {
+		return settings
+	}
+	if ca, ok := data[cmetav1.TLSCAKey]; ok {
+		settings.set("ssl.truststore.type", "", "PEM")
+		settings.set("ssl.truststore.certificates", "ssl.ca.pem", string(ca))
+	}
+	cert, certOk := data[corev1.TLSCertKey]
+	key, keyOk := data[corev1.TLSPrivateKeyKey]
+	if l.RequireClientAuth && certOk && keyOk {
+		settings.set("ssl.keystore.type", "", "PEM")
+		settings.set("ssl.keystore.certificate.chain", "ssl.certificate.pem", string(cert))
+		settings.set("ssl.keystore.key", "ssl.key.pem", string(key))
+	}
+	return settings
+}
+
+// renderProperties renders the settings in the Java properties format,
+// the line breaks of PEM values are escaped
+func renderProperties(settings [][2]string) []byte {
+	escaper := strings.NewReplacer(`\`, `\\`, "\r", "", "\n", `\n`)
+	var b strings.Builder
+	for _, setting := range settings {
+		fmt.Fprintf(&b, "%s=%s\n", setting[0], escaper.Replace(setting[1]))
+	}
+	return []byte(b.String())
+}
+
+// certificateSecret returns the data of the certificate Secret, nil if it
+// doesn't exist yet
+func (r *ClientConfigSecretResource) certificateSecret(
+	ctx context.Context, key types.NamespacedName,
+) (map[string][]byte, error) {
+	var secret corev1.Secret
+	err := r.Get(ctx, key, &secret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch certificate Secret %s: %w", key, err)
+	}
+	return secret.Data, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ClientConfigSecretResource) Key() types.NamespacedName {
+	return ClientConfigSecretKey(r.pandaCluster)
+}
+
+// ClientConfigSecretKey returns the namespaced name of the client config
+// Secret of the cluster
+func ClientConfigSecretKey(
+	pandaCluster *redpandav1alpha1.Cluster,
+) types.NamespacedName {
+	return types.NamespacedName{Name: pandaCluster.Name + clientConfigSuffix, Namespace: pandaCluster.Namespace}
+}
+
+// joinHostPorts returns comma separated addresses of the hosts, the internal
+// node list contains only host names
+func joinHostPorts(hosts []string, port int) string {
+	addresses := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addresses = append(addresses, fmt.Sprintf("%s:%d", host, port))
+	}
+	return strings.Join(addresses, ",")
+}
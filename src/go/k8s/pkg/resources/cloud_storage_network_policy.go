@@ -0,0 +1,185 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	cloudStorageEgressSuffix = "-cloud-storage-egress"
+	dnsPort                  = 53
+)
+
+var _ Resource = &CloudStorageNetworkPolicyResource{}
+
+// CloudStorageNetworkPolicyResource is part of the reconciliation of
+// redpanda.vectorized.io CRD allowing the egress traffic of the brokers to
+// the cloud storage
+type CloudStorageNetworkPolicyResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewCloudStorageNetworkPolicy creates CloudStorageNetworkPolicyResource
+func NewCloudStorageNetworkPolicy(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *CloudStorageNetworkPolicyResource {
+	return &CloudStorageNetworkPolicyResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", networkPolicyKind()),
+	}
+}
+
+// Ensure will manage networking/v1.NetworkPolicy of the cloud storage. The
+// NetworkPolicy is removed when the cloud storage or the policy is disabled.
+func (r *CloudStorageNetworkPolicyResource) Ensure(ctx context.Context) error {
+	cloudStorage := r.pandaCluster.Spec.CloudStorage
+	if !cloudStorage.Enabled || cloudStorage.EgressNetworkPolicy == nil {
+		return r.cleanup(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var policy networkingv1.NetworkPolicy
+	err = r.Get(ctx, r.Key(), &policy)
+	if err != nil {
+		return fmt.Errorf("error while fetching NetworkPolicy resource: %w", err)
+	}
+	return Update(ctx, &policy, obj, r.Client, r.logger)
+}
+
+func (r *CloudStorageNetworkPolicyResource) cleanup(ctx context.Context) error {
+	var policy networkingv1.NetworkPolicy
+	err := r.Get(ctx, r.Key(), &policy)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching NetworkPolicy resource: %w", err)
+	}
+	r.logger.Info("Removing cloud storage egress NetworkPolicy", "name", policy.Name)
+	if err := r.Delete(ctx, &policy); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete cloud storage egress NetworkPolicy: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *CloudStorageNetworkPolicyResource) obj() (k8sclient.Object, error) {
+	objLabels := labels.ForCluster(r.pandaCluster)
+	cloudStorage := r.pandaCluster.Spec.CloudStorage
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	port := intstr.FromInt(r.pandaCluster.CloudStorageEndpointPort())
+
+	var peers []networkingv1.NetworkPolicyPeer
+	for _, cidr := range cloudStorage.EgressNetworkPolicy.CIDRs {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+	endpointIP := net.ParseIP(cloudStorage.APIEndpoint)
+	if endpointIP != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: hostCIDR(endpointIP)},
+		})
+	}
+	// the policy selects the brokers, so the egress they need besides the
+	// object store has to be allowed too: the other brokers of the cluster
+	// and DNS, which the brokers use to resolve each other
+	dns := intstr.FromInt(dnsPort)
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &port}},
+			To:    peers,
+		},
+		{
+			To: []networkingv1.NetworkPolicyPeer{{PodSelector: objLabels.AsAPISelector()}},
+		},
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dns},
+				{Protocol: &tcp, Port: &dns},
+			},
+		},
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    objLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *objLabels.AsAPISelector(),
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, policy, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *CloudStorageNetworkPolicyResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + cloudStorageEgressSuffix, Namespace: r.pandaCluster.Namespace}
+}
+
+// hostCIDR returns the CIDR matching the single IP address
+func hostCIDR(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+	return ip.String() + "/128"
+}
+
+func networkPolicyKind() string {
+	var policy networkingv1.NetworkPolicy
+	return policy.Kind
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCloudStorageNetworkPolicy(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name          string
+		cloudStorage  redpandav1alpha1.CloudStorageConfig
+		expectedPort  int
+		expectedCIDRs []string
+	}{
+		{
+			name: "host name endpoint",
+			cloudStorage: redpandav1alpha1.CloudStorageConfig{
+				Enabled:             true,
+				APIEndpoint:         "s3.us-west-1.amazonaws.com",
+				EgressNetworkPolicy: &redpandav1alpha1.CloudStorageEgressPolicy{CIDRs: []string{"52.92.0.0/17"}},
+			},
+			expectedPort:  443,
+			expectedCIDRs: []string{"52.92.0.0/17"},
+		},
+		{
+			name: "IP endpoint with custom port",
+			cloudStorage: redpandav1alpha1.CloudStorageConfig{
+				Enabled:             true,
+				APIEndpoint:         "10.0.0.15",
+				APIEndpointPort:     9000,
+				DisableTLS:          true,
+				EgressNetworkPolicy: &redpandav1alpha1.CloudStorageEgressPolicy{},
+			},
+			expectedPort:  9000,
+			expectedCIDRs: []string{"10.0.0.15/32"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.CloudStorage = tt.cloudStorage
+
+			c := fake.NewClientBuilder().Build()
+			policy := res.NewCloudStorageNetworkPolicy(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+			require.NoError(t, policy.Ensure(context.Background()))
+
+			var actual networkingv1.NetworkPolicy
+			require.NoError(t, c.Get(context.Background(), policy.Key(), &actual))
+			assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}, actual.Spec.PolicyTypes)
+			assert.Equal(t, "redpanda", actual.Spec.PodSelector.MatchLabels["app.kubernetes.io/name"])
+
+			require.NotEmpty(t, actual.Spec.Egress)
+			storage := actual.Spec.Egress[0]
+			require.Len(t, storage.Ports, 1)
+			assert.Equal(t, corev1.ProtocolTCP, *storage.Ports[0].Protocol)
+			assert.Equal(t, intstr.FromInt(tt.expectedPort), *storage.Ports[0].Port)
+			var cidrs []string
+			for _, peer := range storage.To {
+				cidrs = append(cidrs, peer.IPBlock.CIDR)
+			}
+			assert.Equal(t, tt.expectedCIDRs, cidrs)
+
+			// the brokers reach each other and resolve the host names
+			require.Len(t, actual.Spec.Egress, 3)
+			require.Len(t, actual.Spec.Egress[1].To, 1)
+			assert.Equal(t, actual.Spec.PodSelector, *actual.Spec.Egress[1].To[0].PodSelector)
+			assert.Empty(t, actual.Spec.Egress[1].Ports)
+			assert.Equal(t, intstr.FromInt(53), *actual.Spec.Egress[2].Ports[0].Port)
+			assert.Empty(t, actual.Spec.Egress[2].To)
+
+			// NetworkPolicy is removed when it's not requested anymore
+			cluster.Spec.CloudStorage.EgressNetworkPolicy = nil
+			require.NoError(t, policy.Ensure(context.Background()))
+			err := c.Get(context.Background(), policy.Key(), &actual)
+			assert.True(t, apierrors.IsNotFound(err))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"path/filepath"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	cloudStorageTokenName = "cloud-storage-token"
+	cloudStorageTokenDir  = "/var/run/secrets/redpanda.vectorized.io/cloud-storage"
+	cloudStorageTokenPath = "token"
+
+	// WebIdentityTokenFileEnv points the object store SDK at the projected
+	// token
+	WebIdentityTokenFileEnv = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	// RoleARNEnv is the role assumed with the projected token
+	RoleARNEnv = "AWS_ROLE_ARN"
+)
+
+// cloudStorageTokenVolumes returns the projected service account token
+// volume, nil if the cloud storage uses static credentials
+func cloudStorageTokenVolumes(
+	pandaCluster *redpandav1alpha1.Cluster,
+) []corev1.Volume {
+	token := pandaCluster.Spec.CloudStorage.ProjectedToken
+	if !pandaCluster.Spec.CloudStorage.Enabled || token == nil {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: cloudStorageTokenName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience:          token.Audience,
+								ExpirationSeconds: token.ExpirationSeconds,
+								Path:              cloudStorageTokenPath,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func cloudStorageTokenVolumeMounts(
+	pandaCluster *redpandav1alpha1.Cluster,
+) []corev1.VolumeMount {
+	if len(cloudStorageTokenVolumes(pandaCluster)) == 0 {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      cloudStorageTokenName,
+			MountPath: cloudStorageTokenDir,
+			ReadOnly:  true,
+		},
+	}
+}
+
+// cloudStorageTokenEnv returns the environment of the web identity flow
+func cloudStorageTokenEnv(
+	pandaCluster *redpandav1alpha1.Cluster,
+) []corev1.EnvVar {
+	if len(cloudStorageTokenVolumes(pandaCluster)) == 0 {
+		return nil
+	}
+	env := []corev1.EnvVar{
+		{
+			Name:  WebIdentityTokenFileEnv,
+			Value: filepath.Join(cloudStorageTokenDir, cloudStorageTokenPath),
+		},
+	}
+	if roleARN := pandaCluster.Spec.CloudStorage.ProjectedToken.RoleARN; roleARN != "" {
+		env = append(env, corev1.EnvVar{Name: RoleARNEnv, Value: roleARN})
+	}
+	return env
+}
+
+// cloudStorageTokenServiceAccount returns the service account of the
+// brokers, the token is issued for it and the role trusts it. Empty string
+// is the default service account of the namespace.
+func cloudStorageTokenServiceAccount(
+	pandaCluster *redpandav1alpha1.Cluster,
+) string {
+	if len(cloudStorageTokenVolumes(pandaCluster)) == 0 ||
+		!pandaCluster.Spec.ExternalConnectivity.Enabled {
+		return ""
+	}
+	// see ServiceAccountResource.Key
+	return pandaCluster.Name
+}
@@ -11,6 +11,7 @@ package resources
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
@@ -53,8 +54,17 @@ func (r *ClusterRoleResource) Ensure(ctx context.Context) error {
 	if !r.pandaCluster.Spec.ExternalConnectivity.Enabled {
 		return nil
 	}
-	_, err := CreateIfNotExists(ctx, r, r.obj(), r.logger)
-	return err
+	obj := r.obj()
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var cr v1.ClusterRole
+	err = r.Get(ctx, r.Key(), &cr)
+	if err != nil {
+		return fmt.Errorf("error while fetching ClusterRole resource: %w", err)
+	}
+	return Update(ctx, &cr, obj, r.Client, r.logger)
 }
 
 // obj returns resource managed client.Object
@@ -78,6 +88,12 @@ func (r *ClusterRoleResource) obj() k8sclient.Object {
 				APIGroups: []string{corev1.GroupName},
 				Resources: []string{"nodes"},
 			},
+			{
+				// the brokers advertise the address of their Service
+				Verbs:     []string{"get"},
+				APIGroups: []string{corev1.GroupName},
+				Resources: []string{"services"},
+			},
 		},
 	}
 }
@@ -0,0 +1,98 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigHashAnnotation is set on the Pod template of the brokers. It changes
+// with the redpanda configuration the brokers read at startup only, so Pods
+// running the previous configuration can be told apart, e.g. after an
+// interrupted rolling update.
+const ConfigHashAnnotation = "redpanda.vectorized.io/config-hash"
+
+// ConfigHash returns a hash of the data stored in the ConfigMap. The cluster
+// properties of the redpanda configuration are left out, changing them
+// doesn't restart the brokers. An empty string is returned if the ConfigMap
+// doesn't exist yet.
+func ConfigHash(
+	ctx context.Context, c k8sclient.Reader, key types.NamespacedName,
+) (string, error) {
+	var cm corev1.ConfigMap
+	err := c.Get(ctx, key, &cm)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch ConfigMap %s: %w", key, err)
+	}
+
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, k := range keys {
+		data := cm.Data[k]
+		if k == configFile {
+			if data, err = withoutClusterProperties(data); err != nil {
+				return "", fmt.Errorf("unable to parse %s of ConfigMap %s: %w", k, key, err)
+			}
+		}
+		hash.Write([]byte(k))
+		hash.Write([]byte(data))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// withoutClusterProperties returns the redpanda configuration without the
+// cluster properties set by the operator
+func withoutClusterProperties(data string) (string, error) {
+	var cfg config.Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		return "", err
+	}
+	cr := &cfg.Redpanda
+	cr.CloudStorageApiEndpoint = nil
+	cr.CloudStorageEnabled = nil
+	cr.CloudStorageAccessKey = nil
+	cr.CloudStorageSecretKey = nil
+	cr.CloudStorageRegion = nil
+	cr.CloudStorageBucket = nil
+	cr.CloudStorageReconciliationIntervalMs = nil
+	cr.CloudStorageMaxConnections = nil
+	cr.CloudStorageDisableTls = nil
+	cr.CloudStorageApiEndpointPort = nil
+	cr.CloudStorageTrustFile = nil
+	cr.Superusers = nil
+	cr.EnableSASL = nil
+	cr.GroupTopicPartitions = nil
+	// the additional configuration of the spec
+	cr.Other = nil
+	out, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const hashedConfig = `redpanda:
+  data_directory: /var/lib/redpanda/data
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+    name: kafka
+  superusers:
+  - admin
+  log_segment_size: 536870912
+`
+
+func TestConfigHash(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "cluster-base", Namespace: "default"}
+	c := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Data:       map[string]string{"redpanda.yaml": hashedConfig},
+	}).Build()
+
+	hash, err := res.ConfigHash(ctx, c, key)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+
+	update := func(data string) string {
+		var cm corev1.ConfigMap
+		require.NoError(t, c.Get(ctx, key, &cm))
+		cm.Data["redpanda.yaml"] = data
+		require.NoError(t, c.Update(ctx, &cm))
+		updated, err := res.ConfigHash(ctx, c, key)
+		require.NoError(t, err)
+		return updated
+	}
+
+	// cluster properties don't restart the brokers
+	assert.Equal(t, hash, update(`redpanda:
+  data_directory: /var/lib/redpanda/data
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9092
+    name: kafka
+  superusers:
+  - admin
+  - ops
+  log_segment_size: 1073741824
+`))
+
+	// listeners are read at startup only
+	assert.NotEqual(t, hash, update(`redpanda:
+  data_directory: /var/lib/redpanda/data
+  kafka_api:
+  - address: 0.0.0.0
+    port: 9093
+    name: kafka
+  superusers:
+  - admin
+`))
+
+	// no hash without ConfigMap
+	none, err := res.ConfigHash(ctx, c, types.NamespacedName{Name: "missing", Namespace: "default"})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
@@ -13,6 +13,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 
 	"github.com/go-logr/logr"
 	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
@@ -20,10 +22,12 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,8 +37,9 @@ const (
 	baseSuffix    = "-base"
 	dataDirectory = "/var/lib/redpanda/data"
 
-	tlsDir   = "/etc/tls/certs"
-	tlsDirCA = "/etc/tls/certs/ca"
+	tlsDir         = "/etc/tls/certs"
+	tlsDirCA       = "/etc/tls/certs/ca"
+	tlsExternalDir = "/etc/tls/certs/external"
 
 	tlsAdminDir = "/etc/tls/certs/admin"
 )
@@ -52,6 +57,7 @@ type ConfigMapResource struct {
 	pandaCluster *redpandav1alpha1.Cluster
 
 	serviceFQDN string
+	recorder    record.EventRecorder
 	logger      logr.Logger
 }
 
@@ -61,6 +67,7 @@ func NewConfigMap(
 	pandaCluster *redpandav1alpha1.Cluster,
 	scheme *runtime.Scheme,
 	serviceFQDN string,
+	recorder record.EventRecorder,
 	logger logr.Logger,
 ) *ConfigMapResource {
 	return &ConfigMapResource{
@@ -68,6 +75,7 @@ func NewConfigMap(
 		scheme,
 		pandaCluster,
 		serviceFQDN,
+		recorder,
 		logger.WithValues("Kind", configMapKind()),
 	}
 }
@@ -79,15 +87,46 @@ func (r *ConfigMapResource) Ensure(ctx context.Context) error {
 		return fmt.Errorf("unable to construct object: %w", err)
 	}
 	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
-	if err != nil || created {
+	if err != nil {
 		return err
 	}
+	if created {
+		return r.reportRecreation(ctx)
+	}
 	var cm corev1.ConfigMap
 	err = r.Get(ctx, r.Key(), &cm)
 	if err != nil {
 		return fmt.Errorf("error while fetching ConfigMap resource: %w", err)
 	}
-	return Update(ctx, &cm, obj, r.Client, r.logger)
+	changed := !reflect.DeepEqual(cm.Data, obj.(*corev1.ConfigMap).Data)
+	if err = Update(ctx, &cm, obj, r.Client, r.logger); err != nil {
+		return err
+	}
+	if changed {
+		r.recorder.Eventf(r.pandaCluster, corev1.EventTypeNormal, "ConfigUpdated",
+			"Configuration in ConfigMap %s was updated", r.Key().Name)
+	}
+	return nil
+}
+
+// reportRecreation records an event when the ConfigMap was created while
+// the brokers already reference it, e.g. after it was deleted by hand
+func (r *ConfigMapResource) reportRecreation(ctx context.Context) error {
+	var sets appsv1.StatefulSetList
+	err := r.List(ctx, &sets, &k8sclient.ListOptions{
+		LabelSelector: labels.ForCluster(r.pandaCluster).AsClientSelector(),
+		Namespace:     r.pandaCluster.Namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list StatefulSets: %w", err)
+	}
+	if len(sets.Items) == 0 {
+		return nil
+	}
+	r.logger.Info(fmt.Sprintf("ConfigMap %s was missing and has been recreated", r.Key()))
+	r.recorder.Eventf(r.pandaCluster, corev1.EventTypeWarning, "ConfigMapRecreated",
+		"ConfigMap %s was missing and has been recreated from the Cluster spec", r.Key().Name)
+	return nil
 }
 
 // obj returns resource managed client.Object
@@ -134,26 +173,28 @@ func (r *ConfigMapResource) createConfiguration(
 	c := r.pandaCluster.Spec.Configuration
 	cr := &cfgRpk.Redpanda
 
-	cr.KafkaApi = []config.NamedSocketAddress{
-		{
-			SocketAddress: config.SocketAddress{
-				Address: "0.0.0.0",
-				Port:    c.KafkaAPI.Port,
-			},
-			Name: "Internal",
-		},
-	}
-
-	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+	listeners := r.pandaCluster.KafkaAPIListeners()
+	for _, l := range listeners {
 		cr.KafkaApi = append(cr.KafkaApi, config.NamedSocketAddress{
 			SocketAddress: config.SocketAddress{
 				Address: "0.0.0.0",
-				Port:    calculateExternalPort(c.KafkaAPI.Port),
+				Port:    l.Port,
 			},
-			Name: "External",
+			Name: l.Name,
 		})
 	}
 
+	// the addresses are registered by the configurator, which keeps the
+	// rendered ports
+	for _, l := range listeners {
+		if l.AdvertisedPort != 0 {
+			cr.AdvertisedKafkaApi = append(cr.AdvertisedKafkaApi, config.NamedSocketAddress{
+				SocketAddress: config.SocketAddress{Port: l.AdvertisedPort},
+				Name:          l.Name,
+			})
+		}
+	}
+
 	cr.RPCServer.Port = clusterCRPortOrRPKDefault(c.RPCServer.Port, cr.RPCServer.Port)
 	cr.AdvertisedRPCAPI = &config.SocketAddress{
 		Address: "0.0.0.0",
@@ -163,25 +204,9 @@ func (r *ConfigMapResource) createConfiguration(
 	cr.AdminApi.Port = clusterCRPortOrRPKDefault(c.AdminAPI.Port, cr.AdminApi.Port)
 	cr.DeveloperMode = c.DeveloperMode
 	cr.Directory = dataDirectory
-	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
-		// If external connectivity is enabled the TLS config will be applied to the external listener,
-		// otherwise TLS will be applied to the internal listener. // TODO support multiple TLS configs
-		name := "Internal"
-		if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
-			name = "External"
-		}
-		tls := config.ServerTLS{
-			Name:              name,
-			KeyFile:           fmt.Sprintf("%s/%s", tlsDir, corev1.TLSPrivateKeyKey), // tls.key
-			CertFile:          fmt.Sprintf("%s/%s", tlsDir, corev1.TLSCertKey),       // tls.crt
-			Enabled:           true,
-			RequireClientAuth: r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth,
-		}
-		if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
-			tls.TruststoreFile = fmt.Sprintf("%s/%s", tlsDirCA, cmetav1.TLSCAKey)
-		}
-		cr.KafkaApiTLS = []config.ServerTLS{
-			tls,
+	for _, l := range listeners {
+		if l.TLS {
+			cr.KafkaApiTLS = append(cr.KafkaApiTLS, r.listenerTLS(l))
 		}
 	}
 	if r.pandaCluster.Spec.Configuration.TLS.AdminAPI.Enabled {
@@ -197,6 +222,10 @@ func (r *ConfigMapResource) createConfiguration(
 	}
 
 	if r.pandaCluster.Spec.CloudStorage.Enabled {
+		r.prepareCloudStorage(cr)
+	}
+	// brokers using the projected token don't need the static credentials
+	if r.pandaCluster.CloudStorageStaticCredentials() {
 		secretName := types.NamespacedName{
 			Name:      r.pandaCluster.Spec.CloudStorage.SecretKeyRef.Name,
 			Namespace: r.pandaCluster.Spec.CloudStorage.SecretKeyRef.Namespace,
@@ -209,7 +238,8 @@ func (r *ConfigMapResource) createConfiguration(
 		if secretKeyStr == "" {
 			return nil, fmt.Errorf("secret name %s, ns %s: %w", secretName.Name, secretName.Namespace, errCloudStorageSecretKeyCannotBeEmpty)
 		}
-		r.prepareCloudStorage(cr, secretKeyStr)
+		cr.CloudStorageAccessKey = pointer.StringPtr(r.pandaCluster.Spec.CloudStorage.AccessKey)
+		cr.CloudStorageSecretKey = pointer.StringPtr(secretKeyStr)
 	}
 
 	for _, user := range r.pandaCluster.Spec.Superusers {
@@ -218,6 +248,7 @@ func (r *ConfigMapResource) createConfiguration(
 
 	if r.pandaCluster.Spec.EnableSASL {
 		cr.EnableSASL = pointer.BoolPtr(true)
+		prepareSASLMechanisms(cr, r.pandaCluster.Spec.SASLMechanisms)
 	}
 
 	partitions := r.pandaCluster.Spec.Configuration.GroupTopicPartitions
@@ -225,12 +256,18 @@ func (r *ConfigMapResource) createConfiguration(
 		cr.GroupTopicPartitions = &partitions
 	}
 
-	replicas := *r.pandaCluster.Spec.Replicas
-	for i := int32(0); i < replicas; i++ {
+	if quotas := r.pandaCluster.Spec.Configuration.ClientQuotas; quotas != nil {
+		prepareClientQuotas(cr, quotas)
+	}
+
+	r.prepareAdditionalConfiguration(cr)
+
+	// the brokers of all zone StatefulSets share the configuration
+	for _, podName := range BrokerPodNames(r.pandaCluster) {
 		cr.SeedServers = append(cr.SeedServers, config.SeedServer{
 			Host: config.SocketAddress{
 				// Example address: cluster-sample-0.cluster-sample.default.svc.cluster.local
-				Address: fmt.Sprintf("%s-%d.%s", r.pandaCluster.Name, i, r.serviceFQDN),
+				Address: fmt.Sprintf("%s.%s", podName, r.serviceFQDN),
 				Port:    clusterCRPortOrRPKDefault(c.RPCServer.Port, cr.RPCServer.Port),
 			},
 		})
@@ -239,6 +276,101 @@ func (r *ConfigMapResource) createConfiguration(
 	return cfgRpk, nil
 }
 
+// listenerTLS returns the TLS configuration of the listener. The External
+// listener is served with the certificate for its domain when it has one.
+func (r *ConfigMapResource) listenerTLS(
+	l redpandav1alpha1.ListenerSpec,
+) config.ServerTLS {
+	certDir := tlsDir
+	if l.Name == redpandav1alpha1.ExternalListener && r.pandaCluster.SeparateExternalCert() {
+		certDir = tlsExternalDir
+	}
+	tls := config.ServerTLS{
+		Name:              l.Name,
+		KeyFile:           fmt.Sprintf("%s/%s", certDir, corev1.TLSPrivateKeyKey), // tls.key
+		CertFile:          fmt.Sprintf("%s/%s", certDir, corev1.TLSCertKey),       // tls.crt
+		Enabled:           true,
+		RequireClientAuth: l.RequireClientAuth,
+	}
+	if l.RequireClientAuth {
+		tls.TruststoreFile = fmt.Sprintf("%s/%s", tlsDirCA, cmetav1.TLSCAKey)
+	}
+	return tls
+}
+
+// prepareAdditionalConfiguration renders the additional properties as YAML
+// scalars. Properties managed by the operator are ignored, as the webhook
+// may not be deployed.
+func (r *ConfigMapResource) prepareAdditionalConfiguration(
+	cr *config.RedpandaConfig,
+) {
+	additional := r.pandaCluster.Spec.Configuration.AdditionalConfiguration
+	if len(additional) == 0 {
+		return
+	}
+	if cr.Other == nil {
+		cr.Other = map[string]interface{}{}
+	}
+	keys := make([]string, 0, len(additional))
+	for key := range additional {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, rendered := cr.Other[key]; rendered || redpandav1alpha1.IsOperatorManagedProperty(key) {
+			r.logger.Info("Ignoring additional configuration property managed by the operator", "property", key)
+			continue
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(additional[key]), &value); err != nil || value == nil {
+			value = additional[key]
+		}
+		cr.Other[key] = value
+	}
+}
+
+// prepareSASLMechanisms renders the SASL mechanisms, which are not covered
+// by rpk config schema. Redpanda defaults to SCRAM-SHA-256 when they are not
+// set.
+func prepareSASLMechanisms(
+	cr *config.RedpandaConfig, mechanisms []redpandav1alpha1.SASLMechanism,
+) {
+	if len(mechanisms) == 0 {
+		return
+	}
+	if cr.Other == nil {
+		cr.Other = map[string]interface{}{}
+	}
+	names := make([]string, 0, len(mechanisms))
+	for _, mechanism := range mechanisms {
+		names = append(names, string(mechanism))
+	}
+	cr.Other["sasl_mechanisms"] = names
+}
+
+// prepareClientQuotas renders properties not covered by rpk config schema
+func prepareClientQuotas(
+	cr *config.RedpandaConfig, quotas *redpandav1alpha1.ClientQuotas,
+) {
+	if cr.Other == nil {
+		cr.Other = map[string]interface{}{}
+	}
+	if quotas.DefaultByteRate != nil {
+		cr.Other["target_quota_byte_rate"] = *quotas.DefaultByteRate
+	}
+	if len(quotas.Groups) > 0 {
+		groups := make([]map[string]interface{}, 0, len(quotas.Groups))
+		for _, group := range quotas.Groups {
+			groups = append(groups, map[string]interface{}{
+				"group_name":     group.Name,
+				"clients_prefix": group.ClientIDPrefix,
+				"quota":          group.ByteRate,
+			})
+		}
+		cr.Other["kafka_client_group_byte_rate_quota"] = groups
+	}
+}
+
 // calculateExternalPort can calculate external Kafka API port based on the internal Kafka API port
 func calculateExternalPort(kafkaInternalPort int) int {
 	if kafkaInternalPort < 0 || kafkaInternalPort > 65535 {
@@ -247,14 +379,12 @@ func calculateExternalPort(kafkaInternalPort int) int {
 	return kafkaInternalPort + 1
 }
 
-func (r *ConfigMapResource) prepareCloudStorage(
-	cr *config.RedpandaConfig, secretKeyStr string,
-) {
+// prepareCloudStorage sets the cloud storage properties other than the
+// static credentials
+func (r *ConfigMapResource) prepareCloudStorage(cr *config.RedpandaConfig) {
 	cr.CloudStorageEnabled = pointer.BoolPtr(r.pandaCluster.Spec.CloudStorage.Enabled)
-	cr.CloudStorageAccessKey = pointer.StringPtr(r.pandaCluster.Spec.CloudStorage.AccessKey)
 	cr.CloudStorageRegion = pointer.StringPtr(r.pandaCluster.Spec.CloudStorage.Region)
 	cr.CloudStorageBucket = pointer.StringPtr(r.pandaCluster.Spec.CloudStorage.Bucket)
-	cr.CloudStorageSecretKey = pointer.StringPtr(secretKeyStr)
 	cr.CloudStorageDisableTls = pointer.BoolPtr(r.pandaCluster.Spec.CloudStorage.DisableTLS)
 
 	interval := r.pandaCluster.Spec.CloudStorage.ReconcilicationIntervalMs
@@ -277,6 +407,13 @@ func (r *ConfigMapResource) prepareCloudStorage(
 	if trustfile != "" {
 		cr.CloudStorageTrustFile = &trustfile
 	}
+	// the cache size is not covered by rpk config schema
+	if cacheSize := r.pandaCluster.Spec.CloudStorage.CacheSize; cacheSize != nil {
+		if cr.Other == nil {
+			cr.Other = map[string]interface{}{}
+		}
+		cr.Other["cloud_storage_cache_size"] = cacheSize.Value()
+	}
 }
 
 func (r *ConfigMapResource) getSecretValue(
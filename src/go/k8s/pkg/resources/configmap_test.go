@@ -0,0 +1,386 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestConfigMapOwnerReference(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+
+	// ConfigMap created without owner reference, e.g. manually
+	orphan := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      res.ConfigMapKey(cluster).Name,
+			Namespace: res.ConfigMapKey(cluster).Namespace,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+	}{
+		{"config map is created", nil},
+		{"existing config map is adopted", []client.Object{orphan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(tt.existing...).Build()
+			cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+			require.NoError(t, cm.Ensure(context.Background()))
+
+			var actual corev1.ConfigMap
+			require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+			owner := metav1.GetControllerOf(&actual)
+			require.NotNil(t, owner)
+			assert.Equal(t, cluster.UID, owner.UID)
+			assert.Equal(t, cluster.Name, owner.Name)
+		})
+	}
+}
+
+func TestConfigMapRecreation(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+
+	c := fake.NewClientBuilder().Build()
+	recorder := record.NewFakeRecorder(10)
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", recorder, ctrl.Log.WithName("test"))
+	require.NoError(t, cm.Ensure(context.Background()))
+	assert.Len(t, recorder.Events, 0, "initial creation should not be reported")
+
+	// brokers of a zone StatefulSet reference the ConfigMap
+	sts := &v1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.Name + "-zone-a",
+			Namespace: cluster.Namespace,
+			Labels:    labels.ForCluster(cluster),
+		},
+	}
+	require.NoError(t, c.Create(context.Background(), sts))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	require.NoError(t, c.Delete(context.Background(), &actual))
+
+	require.NoError(t, cm.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+	assert.Contains(t, actual.Data, "redpanda.yaml")
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "ConfigMapRecreated")
+}
+
+func TestConfigMapUpdateEvent(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+
+	c := fake.NewClientBuilder().Build()
+	recorder := record.NewFakeRecorder(10)
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", recorder, ctrl.Log.WithName("test"))
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	// unchanged configuration is not reported
+	require.NoError(t, cm.Ensure(context.Background()))
+	assert.Len(t, recorder.Events, 0)
+
+	cluster.Spec.Configuration.DeveloperMode = !cluster.Spec.Configuration.DeveloperMode
+	require.NoError(t, cm.Ensure(context.Background()))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "ConfigUpdated")
+}
+
+func TestConfigMapClientQuotas(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.ClientQuotas = &redpandav1alpha1.ClientQuotas{
+		DefaultByteRate: pointer.Int64Ptr(1048576),
+		Groups: []redpandav1alpha1.ClientGroupQuota{
+			{Name: "batch", ClientIDPrefix: "batch-", ByteRate: 1024},
+		},
+	}
+
+	c := fake.NewClientBuilder().Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+
+	var cfg struct {
+		Redpanda struct {
+			TargetQuotaByteRate int64 `yaml:"target_quota_byte_rate"`
+			GroupQuotas         []struct {
+				GroupName     string `yaml:"group_name"`
+				ClientsPrefix string `yaml:"clients_prefix"`
+				Quota         int64  `yaml:"quota"`
+			} `yaml:"kafka_client_group_byte_rate_quota"`
+		} `yaml:"redpanda"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	assert.EqualValues(t, 1048576, cfg.Redpanda.TargetQuotaByteRate)
+	require.Len(t, cfg.Redpanda.GroupQuotas, 1)
+	assert.Equal(t, "batch", cfg.Redpanda.GroupQuotas[0].GroupName)
+	assert.Equal(t, "batch-", cfg.Redpanda.GroupQuotas[0].ClientsPrefix)
+	assert.EqualValues(t, 1024, cfg.Redpanda.GroupQuotas[0].Quota)
+}
+
+func TestConfigMapAdvertisedKafkaAPIPorts(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	type listener struct {
+		Port int    `yaml:"port"`
+		Name string `yaml:"name"`
+	}
+	tests := []struct {
+		name       string
+		mutate     func(cluster *redpandav1alpha1.Cluster)
+		advertised []listener
+	}{
+		{
+			name: "derived listeners",
+			mutate: func(cluster *redpandav1alpha1.Cluster) {
+				cluster.Spec.Configuration.AdvertisedKafkaAPIPorts = &redpandav1alpha1.AdvertisedKafkaAPIPorts{
+					Internal: 19092,
+					External: 443,
+				}
+			},
+			advertised: []listener{
+				{Port: 19092, Name: redpandav1alpha1.InternalListener},
+				{Port: 443, Name: redpandav1alpha1.ExternalListener},
+			},
+		},
+		{
+			name: "declared listeners",
+			mutate: func(cluster *redpandav1alpha1.Cluster) {
+				cluster.Spec.Configuration.Listeners = []redpandav1alpha1.ListenerSpec{
+					{Name: redpandav1alpha1.InternalListener, Port: 123},
+					{Name: redpandav1alpha1.ExternalListener, Port: 124, AdvertisedPort: 443, External: true},
+					{Name: "Replication", Port: 9094, AdvertisedPort: 19094},
+				}
+			},
+			advertised: []listener{
+				{Port: 443, Name: redpandav1alpha1.ExternalListener},
+				{Port: 19094, Name: "Replication"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.ExternalConnectivity.Enabled = true
+			tt.mutate(cluster)
+
+			c := fake.NewClientBuilder().Build()
+			cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+			require.NoError(t, cm.Ensure(context.Background()))
+
+			var actual corev1.ConfigMap
+			require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+
+			var cfg struct {
+				Redpanda struct {
+					KafkaAPI           []listener `yaml:"kafka_api"`
+					AdvertisedKafkaAPI []listener `yaml:"advertised_kafka_api"`
+				} `yaml:"redpanda"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+			assert.Equal(t, redpandav1alpha1.InternalListener, cfg.Redpanda.KafkaAPI[0].Name)
+			assert.Equal(t, 123, cfg.Redpanda.KafkaAPI[0].Port)
+			assert.Equal(t, tt.advertised, cfg.Redpanda.AdvertisedKafkaAPI)
+		})
+	}
+}
+
+func TestConfigMapListeners(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	tests := []struct {
+		name   string
+		mutate func(cluster *redpandav1alpha1.Cluster)
+		golden string
+	}{
+		{
+			name: "default listeners with TLS",
+			mutate: func(cluster *redpandav1alpha1.Cluster) {
+				cluster.Spec.ExternalConnectivity.Enabled = true
+				cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+			},
+			golden: "default_listeners.golden",
+		},
+		{
+			name: "declared listeners",
+			mutate: func(cluster *redpandav1alpha1.Cluster) {
+				cluster.Spec.ExternalConnectivity.Enabled = true
+				cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+				cluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth = true
+				cluster.Spec.Configuration.Listeners = []redpandav1alpha1.ListenerSpec{
+					{Name: redpandav1alpha1.InternalListener, Port: 123, TLS: true, RequireClientAuth: true},
+					{Name: redpandav1alpha1.ExternalListener, Port: 124, TLS: true, External: true},
+					{Name: "Replication", Port: 9094},
+					{Name: "Tooling", Port: 9095, TLS: true},
+				}
+			},
+			golden: "declared_listeners.golden",
+		},
+		{
+			name: "declared listeners with separate external certificate",
+			mutate: func(cluster *redpandav1alpha1.Cluster) {
+				cluster.Spec.ExternalConnectivity.Enabled = true
+				cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+				cluster.Spec.Configuration.TLS.KafkaAPI.SeparateExternalCert = true
+				cluster.Spec.Configuration.Listeners = []redpandav1alpha1.ListenerSpec{
+					{Name: redpandav1alpha1.InternalListener, Port: 123},
+					{Name: redpandav1alpha1.ExternalListener, Port: 124, TLS: true, External: true},
+					{Name: "Replication", Port: 9094, TLS: true},
+				}
+			},
+			golden: "separate_external_cert_listeners.golden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			tt.mutate(cluster)
+
+			c := fake.NewClientBuilder().Build()
+			cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+			require.NoError(t, cm.Ensure(context.Background()))
+
+			var actual corev1.ConfigMap
+			require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+
+			var cfg struct {
+				Redpanda struct {
+					KafkaAPI    []config.NamedSocketAddress `yaml:"kafka_api"`
+					KafkaAPITLS []config.ServerTLS          `yaml:"kafka_api_tls"`
+				} `yaml:"redpanda"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+			rendered, err := yaml.Marshal(&cfg.Redpanda)
+			require.NoError(t, err)
+
+			goldenPath := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, ioutil.WriteFile(goldenPath, rendered, 0600))
+			}
+			expected, err := ioutil.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(rendered))
+		})
+	}
+}
+
+func TestConfigMapCloudStorageProjectedToken(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+	cluster.Spec.CloudStorage = redpandav1alpha1.CloudStorageConfig{
+		Enabled: true,
+		Bucket:  "archive",
+		Region:  "us-west-1",
+		ProjectedToken: &redpandav1alpha1.CloudStorageProjectedToken{
+			Audience: "sts.amazonaws.com",
+		},
+	}
+
+	// no credential Secret is needed
+	c := fake.NewClientBuilder().Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+
+	var cfg struct {
+		Redpanda map[string]interface{} `yaml:"redpanda"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	assert.Equal(t, true, cfg.Redpanda["cloud_storage_enabled"])
+	assert.Equal(t, "archive", cfg.Redpanda["cloud_storage_bucket"])
+	assert.NotContains(t, cfg.Redpanda, "cloud_storage_access_key")
+	assert.NotContains(t, cfg.Redpanda, "cloud_storage_secret_key")
+}
+
+func TestConfigMapCloudStorageCacheSize(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cacheSize := resource.MustParse("20Gi")
+	cluster := pandaCluster()
+	cluster.Spec.CloudStorage = redpandav1alpha1.CloudStorageConfig{
+		Enabled:        true,
+		Bucket:         "archive",
+		Region:         "us-west-1",
+		ProjectedToken: &redpandav1alpha1.CloudStorageProjectedToken{Audience: "sts.amazonaws.com"},
+		CacheSize:      &cacheSize,
+	}
+
+	c := fake.NewClientBuilder().Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+
+	var cfg struct {
+		Redpanda map[string]interface{} `yaml:"redpanda"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	assert.Equal(t, 21474836480, cfg.Redpanda["cloud_storage_cache_size"])
+}
+
+func TestConfigMapAdditionalConfiguration(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.AdditionalConfiguration = map[string]string{
+		"log_segment_size":           "536870912",
+		"auto_create_topics_enabled": "true",
+		"default_topic_cleanup":      "compact",
+		"node_id":                    "7",
+	}
+
+	c := fake.NewClientBuilder().Build()
+	cm := res.NewConfigMap(c, cluster, scheme.Scheme, "cluster.local", record.NewFakeRecorder(10), ctrl.Log.WithName("test"))
+	require.NoError(t, cm.Ensure(context.Background()))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), cm.Key(), &actual))
+
+	var cfg struct {
+		Redpanda map[string]interface{} `yaml:"redpanda"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(actual.Data["redpanda.yaml"]), &cfg))
+	assert.Equal(t, 536870912, cfg.Redpanda["log_segment_size"])
+	assert.Equal(t, true, cfg.Redpanda["auto_create_topics_enabled"])
+	assert.Equal(t, "compact", cfg.Redpanda["default_topic_cleanup"])
+	assert.Equal(t, 0, cfg.Redpanda["node_id"], "operator managed property is not overridden")
+}
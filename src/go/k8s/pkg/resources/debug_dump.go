@@ -0,0 +1,411 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	debugDumpSuffix    = "-debug-dump"
+	debugDumpComponent = "debug-dump"
+	debugDumpPrefix    = "debug-dump"
+	debugDumpDir       = "/debug-dump"
+	debugDumpVolume    = "debug-dump"
+	debugDumpConfigDir = "/etc/redpanda"
+	debugDumpFailed    = debugDumpDir + "/failed.txt"
+	debugDumpLogLines  = "1000"
+	debugDumpBackoff   = 2
+
+	// collectScript writes the configuration and the state of the brokers
+	// and topics to the dump directory. Failing commands don't stop the
+	// collection, their output is part of the diagnostics and they are
+	// listed in the failed file, see uploadDumpScript.
+	collectScript = rpkClientFlags + `
+run() {
+  out="$1"
+  shift
+  "$@" > "` + debugDumpDir + `/$out" 2>&1 || echo "$out" >> ` + debugDumpFailed + `
+}
+run ` + configFile + ` cat ` + debugDumpConfigDir + `/` + configFile + `
+run cluster-info.txt rpk cluster info --brokers "$BROKERS" "$@"
+run topics.txt rpk topic list --brokers "$BROKERS" "$@"
+if ! grep -qx topics.txt ` + debugDumpFailed + ` 2>/dev/null; then
+  for topic in $(awk 'NR>1 {print $1}' ` + debugDumpDir + `/topics.txt); do
+    run "topic-$topic.txt" rpk topic describe "$topic" --brokers "$BROKERS" "$@"
+  done
+fi`
+
+	// collectLogsScript writes the recent logs of the brokers, read through
+	// the Kubernetes API, to the dump directory
+	collectLogsScript = `sa=/var/run/secrets/kubernetes.io/serviceaccount
+for pod in $PODS; do
+  curl -sS --fail --cacert "$sa/ca.crt" -H "Authorization: Bearer $(cat "$sa/token")" \
+    "https://kubernetes.default.svc/api/v1/namespaces/$NAMESPACE/pods/$pod/log?container=` + RedpandaContainerName + `&tailLines=` + debugDumpLogLines + `" \
+    > "` + debugDumpDir + `/logs-$pod.txt" 2>&1 || echo "logs-$pod.txt" >> ` + debugDumpFailed + `
+done`
+
+	// uploadDumpScript copies the dump to the bucket under the key of the
+	// request. The Job fails after the upload if any diagnostics were not
+	// collected.
+	uploadDumpScript = `set -e
+aws s3 cp --recursive ` + debugDumpDir + ` "s3://$BUCKET/$PREFIX/$REQUEST" $ENDPOINT_ARGS
+if [ -s ` + debugDumpFailed + ` ]; then
+  echo "Diagnostics not collected:" $(cat ` + debugDumpFailed + `) >&2
+  exit 1
+fi`
+)
+
+var _ Resource = &DebugDumpResource{}
+
+// DebugDumpResource is part of the reconciliation of redpanda.vectorized.io CRD
+// running a one-shot Job that uploads diagnostics of the cluster to the cloud
+// storage bucket when DebugDumpAnnotation is set
+type DebugDumpResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	serviceFQDN  string
+	// nodeCertSecretKey provides the CA of the brokers
+	nodeCertSecretKey types.NamespacedName
+	// clientCertSecretKey authenticates the collection when the Kafka API
+	// requires client auth
+	clientCertSecretKey types.NamespacedName
+	logger              logr.Logger
+}
+
+// NewDebugDump creates DebugDumpResource
+func NewDebugDump(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	nodeCertSecretKey types.NamespacedName,
+	clientCertSecretKey types.NamespacedName,
+	logger logr.Logger,
+) *DebugDumpResource {
+	return &DebugDumpResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		nodeCertSecretKey,
+		clientCertSecretKey,
+		logger.WithValues("Kind", jobKind()),
+	}
+}
+
+// Ensure creates the batch/v1.Job collecting the diagnostics of the
+// requested dump, along with the Role allowing the Job to read the logs of
+// the brokers. A Job of a previous request is replaced and the Job is
+// removed once the annotation is cleared.
+func (r *DebugDumpResource) Ensure(ctx context.Context) error {
+	request := r.pandaCluster.DebugDumpRequest()
+	if request == "" || !r.pandaCluster.Spec.CloudStorage.Enabled {
+		return r.cleanup(ctx)
+	}
+
+	logAccess, err := r.logAccessObjs()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	for _, obj := range logAccess {
+		if _, err := CreateIfNotExists(ctx, r, obj, r.logger); err != nil {
+			return err
+		}
+	}
+
+	var job batchv1.Job
+	err = r.Get(ctx, r.Key(), &job)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error while fetching Job resource: %w", err)
+	}
+	if err == nil {
+		if job.Annotations[redpandav1alpha1.DebugDumpAnnotation] == request {
+			return nil
+		}
+		if err := r.delete(ctx, &job); err != nil {
+			return err
+		}
+	}
+
+	obj, err := r.obj(request)
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	_, err = CreateIfNotExists(ctx, r, obj, r.logger)
+	return err
+}
+
+func (r *DebugDumpResource) cleanup(ctx context.Context) error {
+	for _, obj := range []k8sclient.Object{
+		&batchv1.Job{},
+		&rbacv1.RoleBinding{},
+		&rbacv1.Role{},
+		&corev1.ServiceAccount{},
+	} {
+		err := r.Get(ctx, r.Key(), obj)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error while fetching debug dump resource %T: %w", obj, err)
+		}
+		if err := r.delete(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DebugDumpResource) delete(ctx context.Context, obj k8sclient.Object) error {
+	r.logger.Info("Removing debug dump resource", "name", obj.GetName(), "type", fmt.Sprintf("%T", obj))
+	// the pods of the Job are removed along with it
+	propagation := metav1.DeletePropagationBackground
+	err := r.Delete(ctx, obj, &k8sclient.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete debug dump resource %T: %w", obj, err)
+	}
+	return nil
+}
+
+// serviceAccountName returns the ServiceAccount the Job reads the logs of
+// the brokers with. The upload authenticated with the projected token has to
+// run as the ServiceAccount of the brokers, otherwise the Job has its own.
+func (r *DebugDumpResource) serviceAccountName() string {
+	if name := cloudStorageTokenServiceAccount(r.pandaCluster); name != "" {
+		return name
+	}
+	return r.Key().Name
+}
+
+// logAccessObjs returns the Role allowing the Job to read the logs of the
+// brokers, bound to the ServiceAccount of the Job
+func (r *DebugDumpResource) logAccessObjs() ([]k8sclient.Object, error) {
+	objectMeta := metav1.ObjectMeta{
+		Name:      r.Key().Name,
+		Namespace: r.Key().Namespace,
+	}
+	objs := []k8sclient.Object{
+		&rbacv1.Role{
+			ObjectMeta: *objectMeta.DeepCopy(),
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Role",
+				APIVersion: "rbac.authorization.k8s.io/v1",
+			},
+			Rules: []rbacv1.PolicyRule{
+				{
+					Verbs:     []string{"get"},
+					APIGroups: []string{corev1.GroupName},
+					Resources: []string{"pods/log"},
+				},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: *objectMeta.DeepCopy(),
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "RoleBinding",
+				APIVersion: "rbac.authorization.k8s.io/v1",
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     r.Key().Name,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      r.serviceAccountName(),
+					Namespace: r.Key().Namespace,
+				},
+			},
+		},
+	}
+	if r.serviceAccountName() == r.Key().Name {
+		objs = append([]k8sclient.Object{&corev1.ServiceAccount{
+			ObjectMeta: *objectMeta.DeepCopy(),
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "ServiceAccount",
+				APIVersion: "v1",
+			},
+		}}, objs...)
+	}
+	for _, obj := range objs {
+		if err := controllerutil.SetControllerReference(r.pandaCluster, obj, r.scheme); err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+// obj returns resource managed client.Object
+func (r *DebugDumpResource) obj(request string) (k8sclient.Object, error) {
+	uploaderImage := defaultUploaderImage
+	if r.pandaCluster.Spec.MetadataBackup != nil && r.pandaCluster.Spec.MetadataBackup.UploaderImage != "" {
+		uploaderImage = r.pandaCluster.Spec.MetadataBackup.UploaderImage
+	}
+
+	// the pods must not be selected as brokers of the cluster
+	dumpLabels := labels.CommonLabels{}
+	for k, v := range labels.ForCluster(r.pandaCluster) {
+		dumpLabels[k] = v
+	}
+	dumpLabels[labels.ComponentKey] = debugDumpComponent
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      debugDumpVolume,
+			MountPath: debugDumpDir,
+		},
+	}
+	collectEnv := []corev1.EnvVar{rpkBrokers(r.pandaCluster, r.serviceFQDN)}
+	collectMounts, collectVolumes, tlsEnv := rpkClientTLS(r.pandaCluster, r.nodeCertSecretKey, r.clientCertSecretKey)
+	collectEnv = append(collectEnv, tlsEnv...)
+	collectEnv = append(collectEnv, rpkClientSASL(r.pandaCluster)...)
+	backoffLimit := int32(debugDumpBackoff)
+	// the dump doesn't wait for the resources of a new cluster, missing
+	// configuration is reported in the diagnostics
+	optional := true
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    dumpLabels,
+			Annotations: map[string]string{
+				redpandav1alpha1.DebugDumpAnnotation: request,
+			},
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: dumpLabels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					// the collection has to finish before the upload starts
+					InitContainers: []corev1.Container{
+						{
+							Name:    "collect",
+							Image:   r.pandaCluster.FullImageName(),
+							Command: []string{"/bin/sh", "-c", collectScript},
+							Env:     collectEnv,
+							VolumeMounts: append(append([]corev1.VolumeMount{
+								{
+									Name:      "configmap-dir",
+									MountPath: debugDumpConfigDir,
+								},
+							}, volumeMounts...), collectMounts...),
+						},
+						{
+							Name:    "logs",
+							Image:   uploaderImage,
+							Command: []string{"/bin/sh", "-c", collectLogsScript},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "NAMESPACE",
+									Value: r.pandaCluster.Namespace,
+								},
+								{
+									Name:  "PODS",
+									Value: strings.Join(BrokerPodNames(r.pandaCluster), " "),
+								},
+							},
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:    "upload",
+							Image:   uploaderImage,
+							Command: []string{"/bin/sh", "-c", uploadDumpScript},
+							Env: append([]corev1.EnvVar{
+								{
+									Name:  "PREFIX",
+									Value: debugDumpPrefix,
+								},
+								{
+									Name:  "REQUEST",
+									Value: request,
+								},
+							}, uploaderEnv(r.pandaCluster)...),
+							VolumeMounts: append(volumeMounts, cloudStorageTokenVolumeMounts(r.pandaCluster)...),
+						},
+					},
+					Volumes: append(append([]corev1.Volume{
+						{
+							Name: debugDumpVolume,
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: "configmap-dir",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: ConfigMapKey(r.pandaCluster).Name,
+									},
+									Optional: &optional,
+								},
+							},
+						},
+					}, collectVolumes...), cloudStorageTokenVolumes(r.pandaCluster)...),
+					ServiceAccountName: r.serviceAccountName(),
+					Tolerations:        r.pandaCluster.Spec.Tolerations,
+					NodeSelector:       r.pandaCluster.Spec.NodeSelector,
+				},
+			},
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, job, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *DebugDumpResource) Key() types.NamespacedName {
+	return DebugDumpKey(r.pandaCluster)
+}
+
+// DebugDumpKey returns the namespaced name of the debug dump Job
+func DebugDumpKey(pandaCluster *redpandav1alpha1.Cluster) types.NamespacedName {
+	return types.NamespacedName{Name: pandaCluster.Name + debugDumpSuffix, Namespace: pandaCluster.Namespace}
+}
+
+func jobKind() string {
+	var job batchv1.Job
+	return job.Kind
+}
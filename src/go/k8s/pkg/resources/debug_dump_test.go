@@ -0,0 +1,114 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDebugDump(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.CloudStorage = redpandav1alpha1.CloudStorageConfig{
+		Enabled:      true,
+		AccessKey:    "access",
+		Region:       "us-west-1",
+		Bucket:       "archive",
+		SecretKeyRef: corev1.ObjectReference{Name: "secret", Namespace: "default"},
+	}
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+
+	c := fake.NewClientBuilder().Build()
+	dump := res.NewDebugDump(c, cluster, scheme.Scheme, "cluster.default.svc.cluster.local",
+		types.NamespacedName{Name: "cluster-redpanda", Namespace: "default"},
+		types.NamespacedName{Name: "cluster-operator-client", Namespace: "default"},
+		ctrl.Log.WithName("test"))
+
+	// nothing is collected until requested
+	require.NoError(t, dump.Ensure(ctx))
+	var job batchv1.Job
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, dump.Key(), &job)))
+
+	cluster.Annotations = map[string]string{redpandav1alpha1.DebugDumpAnnotation: "ticket-1"}
+	require.NoError(t, dump.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, dump.Key(), &job))
+	assert.Equal(t, "ticket-1", job.Annotations[redpandav1alpha1.DebugDumpAnnotation])
+
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+	require.Len(t, podSpec.InitContainers, 2)
+	collect := podSpec.InitContainers[0]
+	assert.Equal(t, "image:latest", collect.Image)
+	assert.Equal(t, []string{"/bin/sh", "-c"}, collect.Command[:2])
+	assert.Contains(t, collect.Command[2], "redpanda.yaml")
+	assert.Contains(t, collect.Command[2], "rpk cluster info")
+	assert.Contains(t, collect.Command[2], "rpk topic describe")
+	assert.Contains(t, collect.Command[2], "--tls-truststore")
+	assert.NotContains(t, collect.Command[2], "exit 0")
+	assert.Contains(t, collect.Env, corev1.EnvVar{Name: "BROKERS", Value: "cluster.default.svc.cluster.local:123"})
+	assert.Contains(t, collect.Env, corev1.EnvVar{Name: "TLS_TRUSTSTORE", Value: "/etc/tls/certs/ca/ca.crt"})
+
+	logs := podSpec.InitContainers[1]
+	assert.Contains(t, logs.Command[2], "/log?container=redpanda")
+	assert.Contains(t, logs.Env, corev1.EnvVar{Name: "PODS", Value: "cluster-0"})
+	assert.Equal(t, dump.Key().Name, podSpec.ServiceAccountName)
+	var role rbacv1.Role
+	require.NoError(t, c.Get(ctx, dump.Key(), &role))
+	assert.Equal(t, []string{"pods/log"}, role.Rules[0].Resources)
+	var binding rbacv1.RoleBinding
+	require.NoError(t, c.Get(ctx, dump.Key(), &binding))
+	assert.Equal(t, dump.Key().Name, binding.Subjects[0].Name)
+	var sa corev1.ServiceAccount
+	require.NoError(t, c.Get(ctx, dump.Key(), &sa))
+
+	require.Len(t, podSpec.Containers, 1)
+	upload := podSpec.Containers[0]
+	assert.Contains(t, upload.Command[2], "aws s3 cp")
+	assert.Contains(t, upload.Env, corev1.EnvVar{Name: "BUCKET", Value: "archive"})
+	assert.Contains(t, upload.Env, corev1.EnvVar{Name: "PREFIX", Value: "debug-dump"})
+	assert.Contains(t, upload.Env, corev1.EnvVar{Name: "REQUEST", Value: "ticket-1"})
+
+	// the same request doesn't start another Job
+	resourceVersion := job.ResourceVersion
+	require.NoError(t, dump.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, dump.Key(), &job))
+	assert.Equal(t, resourceVersion, job.ResourceVersion)
+
+	// a new request replaces the Job
+	cluster.Annotations[redpandav1alpha1.DebugDumpAnnotation] = "ticket-2"
+	require.NoError(t, dump.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, dump.Key(), &job))
+	assert.Equal(t, "ticket-2", job.Annotations[redpandav1alpha1.DebugDumpAnnotation])
+
+	// Job and its access to the logs are removed once the annotation is
+	// cleared
+	delete(cluster.Annotations, redpandav1alpha1.DebugDumpAnnotation)
+	require.NoError(t, dump.Ensure(ctx))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, dump.Key(), &job)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, dump.Key(), &role)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, dump.Key(), &binding)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, dump.Key(), &sa)))
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyExtras merges the extra volumes, volume mounts and environment of
+// the Cluster into the pod spec. Volumes, mounts and variables managed by
+// the operator take precedence, colliding extras are rejected by the
+// webhook and skipped here.
+func (r *StatefulSetResource) applyExtras(podSpec *corev1.PodSpec) {
+	spec := r.pandaCluster.Spec
+	podSpec.Volumes = mergeVolumes(podSpec.Volumes, spec.ExtraVolumes)
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == RedpandaContainerName {
+			applyContainerExtras(&podSpec.Containers[i], spec.ExtraVolumeMounts, spec.ExtraEnv)
+		}
+	}
+	if !spec.ExtraInInitContainers {
+		return
+	}
+	for i := range podSpec.InitContainers {
+		applyContainerExtras(&podSpec.InitContainers[i], spec.ExtraVolumeMounts, spec.ExtraEnv)
+	}
+}
+
+func applyContainerExtras(
+	container *corev1.Container, mounts []corev1.VolumeMount, env []corev1.EnvVar,
+) {
+	container.VolumeMounts = mergeVolumeMounts(container.VolumeMounts, mounts)
+	container.Env = mergeEnv(container.Env, env)
+}
+
+func mergeVolumes(managed, extra []corev1.Volume) []corev1.Volume {
+	names := make(map[string]bool, len(managed))
+	for _, v := range managed {
+		names[v.Name] = true
+	}
+	for _, v := range extra {
+		if names[v.Name] {
+			continue
+		}
+		names[v.Name] = true
+		managed = append(managed, v)
+	}
+	return managed
+}
+
+// mergeVolumeMounts skips extra mounts of the managed volumes or at the
+// path of a managed mount
+func mergeVolumeMounts(managed, extra []corev1.VolumeMount) []corev1.VolumeMount {
+	names := make(map[string]bool, len(managed))
+	paths := make(map[string]bool, len(managed))
+	for _, m := range managed {
+		names[m.Name] = true
+		paths[m.MountPath] = true
+	}
+	for _, m := range extra {
+		if names[m.Name] || paths[m.MountPath] {
+			continue
+		}
+		paths[m.MountPath] = true
+		managed = append(managed, m)
+	}
+	return managed
+}
+
+func mergeEnv(managed, extra []corev1.EnvVar) []corev1.EnvVar {
+	names := make(map[string]bool, len(managed))
+	for _, e := range managed {
+		names[e.Name] = true
+	}
+	for _, e := range extra {
+		if names[e.Name] {
+			continue
+		}
+		names[e.Name] = true
+		managed = append(managed, e)
+	}
+	return managed
+}
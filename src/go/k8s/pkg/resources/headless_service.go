@@ -30,6 +30,10 @@ var _ Resource = &HeadlessServiceResource{}
 const (
 	externalDNSHostname  = "external-dns.alpha.kubernetes.io/hostname"
 	externalDNSUseHostIP = "external-dns.alpha.kubernetes.io/use-external-host-ip"
+
+	// headlessServiceLabel tells the headless Service apart from the
+	// Services of the individual brokers sharing the cluster labels
+	headlessServiceLabel = "redpanda.vectorized.io/headless"
 )
 
 // HeadlessServiceResource is part of the reconciliation of redpanda.vectorized.io CRD
@@ -69,8 +73,16 @@ func (r *HeadlessServiceResource) Ensure(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("unable to construct object: %w", err)
 	}
-	_, err = CreateIfNotExists(ctx, r, obj, r.logger)
-	return err
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var svc corev1.Service
+	err = r.Get(ctx, r.Key(), &svc)
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	return Update(ctx, &svc, obj, r.Client, r.logger)
 }
 
 // obj returns resource managed client.Object
@@ -86,11 +98,13 @@ func (r *HeadlessServiceResource) obj() (k8sclient.Object, error) {
 	}
 
 	objLabels := labels.ForCluster(r.pandaCluster)
+	svcLabels := labels.ForCluster(r.pandaCluster).AsSet()
+	svcLabels[headlessServiceLabel] = "true"
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:   r.Key().Namespace,
 			Name:        r.Key().Name,
-			Labels:      objLabels,
+			Labels:      svcLabels,
 			Annotations: r.getAnnotation(),
 		},
 		TypeMeta: metav1.TypeMeta{
@@ -98,10 +112,11 @@ func (r *HeadlessServiceResource) obj() (k8sclient.Object, error) {
 			APIVersion: "v1",
 		},
 		Spec: corev1.ServiceSpec{
-			Type:      corev1.ServiceTypeClusterIP,
-			ClusterIP: corev1.ClusterIPNone,
-			Ports:     ports,
-			Selector:  objLabels.AsAPISelector().MatchLabels,
+			Type:            corev1.ServiceTypeClusterIP,
+			ClusterIP:       corev1.ClusterIPNone,
+			Ports:           ports,
+			Selector:        objLabels.AsAPISelector().MatchLabels,
+			SessionAffinity: r.pandaCluster.Spec.SessionAffinity,
 		},
 	}
 
@@ -128,11 +143,11 @@ func serviceKind() string {
 // It can be used to communicate between namespaces if the network policy
 // allows it.
 func (r *HeadlessServiceResource) HeadlessServiceFQDN() string {
-	// TODO Retrieve cluster domain dynamically and remove hardcoded cluster.local
-	return fmt.Sprintf("%s%c%s.svc.cluster.local.",
+	return fmt.Sprintf("%s%c%s.svc.%s.",
 		r.Key().Name,
 		'.',
-		r.Key().Namespace)
+		r.Key().Namespace,
+		r.pandaCluster.ClusterDomain())
 }
 
 func (r *HeadlessServiceResource) getAnnotation() map[string]string {
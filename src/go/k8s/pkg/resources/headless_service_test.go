@@ -0,0 +1,46 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHeadlessServiceSessionAffinity(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	cluster := pandaCluster()
+	ports := []res.NamedServicePort{{Name: res.KafkaPortName, Port: 123}}
+
+	c := fake.NewClientBuilder().Build()
+	svc := res.NewHeadlessService(c, cluster, scheme.Scheme, ports, ctrl.Log.WithName("test"))
+	require.NoError(t, svc.Ensure(context.Background()))
+
+	var actual corev1.Service
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.Empty(t, actual.Spec.SessionAffinity)
+	assert.Equal(t, corev1.ClusterIPNone, actual.Spec.ClusterIP)
+
+	// the existing Service is updated
+	cluster.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+	require.NoError(t, svc.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), svc.Key(), &actual))
+	assert.Equal(t, corev1.ServiceAffinityClientIP, actual.Spec.SessionAffinity)
+	assert.Equal(t, corev1.ClusterIPNone, actual.Spec.ClusterIP)
+}
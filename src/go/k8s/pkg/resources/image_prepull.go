@@ -0,0 +1,148 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	prePullSuffix        = "-image-prepull"
+	prePullComponent     = "image-prepull"
+	prePullContainerName = "prepull"
+)
+
+// DefaultPauseImage keeps the DaemonSet Pods running once the image is
+// pulled, unless other image is set with WithPauseImage
+const DefaultPauseImage = "k8s.gcr.io/pause:3.2"
+
+// ImagePrePullKey returns the namespaced name of the DaemonSet that caches
+// the new Redpanda image on the nodes before the rolling update
+func (r *StatefulSetResource) ImagePrePullKey() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + prePullSuffix, Namespace: r.pandaCluster.Namespace}
+}
+
+// ensureImagePrePulled creates a DaemonSet that pulls the image on every node
+// the brokers can be scheduled on. The rolling update is held until all
+// DaemonSet Pods are ready, i.e. the image is in the node cache.
+func (r *StatefulSetResource) ensureImagePrePulled(
+	ctx context.Context, image string,
+) error {
+	if !r.pandaCluster.Spec.PrePullOnUpgrade {
+		return nil
+	}
+
+	ds, err := r.prePullDaemonSet(image)
+	if err != nil {
+		return err
+	}
+	created, err := CreateIfNotExists(ctx, r, ds, r.logger)
+	if err != nil {
+		return err
+	}
+	if !created {
+		var existing appsv1.DaemonSet
+		if err := r.Get(ctx, r.ImagePrePullKey(), &existing); err != nil {
+			return fmt.Errorf("error while fetching image pre-pull DaemonSet: %w", err)
+		}
+		if err := Update(ctx, &existing, ds, r.Client, r.logger); err != nil {
+			return err
+		}
+		if existing.Generation == existing.Status.ObservedGeneration &&
+			existing.Status.UpdatedNumberScheduled == existing.Status.DesiredNumberScheduled &&
+			existing.Status.NumberReady == existing.Status.DesiredNumberScheduled {
+			return nil
+		}
+	}
+
+	return &RequeueAfterError{RequeueAfter: requeueDuration,
+		Msg: fmt.Sprintf("wait for image %s to be pulled on nodes", image)}
+}
+
+// cleanupImagePrePull removes the pre-pull DaemonSet once the update is done
+func (r *StatefulSetResource) cleanupImagePrePull(ctx context.Context) error {
+	var ds appsv1.DaemonSet
+	err := r.Get(ctx, r.ImagePrePullKey(), &ds)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching image pre-pull DaemonSet: %w", err)
+	}
+	r.logger.Info("Removing image pre-pull DaemonSet", "name", ds.Name)
+	if err := r.Delete(ctx, &ds); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete image pre-pull DaemonSet: %w", err)
+	}
+	return nil
+}
+
+func (r *StatefulSetResource) prePullDaemonSet(
+	image string,
+) (*appsv1.DaemonSet, error) {
+	// the pods must not be selected as brokers of the cluster
+	prePullLabels := labels.CommonLabels{}
+	for k, v := range labels.ForCluster(r.pandaCluster) {
+		prePullLabels[k] = v
+	}
+	prePullLabels[labels.ComponentKey] = prePullComponent
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.ImagePrePullKey().Name,
+			Namespace: r.ImagePrePullKey().Namespace,
+			Labels:    prePullLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: prePullLabels.AsAPISelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: prePullLabels.AsAPISelector().MatchLabels,
+				},
+				Spec: corev1.PodSpec{
+					// pulling the image is the only purpose of the init container
+					InitContainers: []corev1.Container{
+						{
+							Name:            prePullContainerName,
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"true"},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  "pause",
+							Image: r.pauseImage,
+						},
+					},
+					Tolerations:  r.pandaCluster.Spec.Tolerations,
+					NodeSelector: r.pandaCluster.Spec.NodeSelector,
+				},
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(r.pandaCluster, ds, r.scheme); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestImagePrePullOnUpgrade(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	// without replicas the rolling update completes right after the pre-pull
+	cluster.Spec.Replicas = pointer.Int32Ptr(0)
+	existingSts := stsFromCluster(cluster)
+
+	cluster.Spec.Version = "new"
+	cluster.Spec.PrePullOnUpgrade = true
+
+	c := fake.NewClientBuilder().WithObjects(cluster, existingSts).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test")).WithPauseImage("registry.example.com/pause:3.2")
+
+	// rolling update waits for the image to be pulled
+	err := sts.Ensure(context.Background())
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(err, &requeue), "expecting requeue, got %v", err)
+
+	var ds appsv1.DaemonSet
+	require.NoError(t, c.Get(context.Background(), sts.ImagePrePullKey(), &ds))
+	require.Len(t, ds.Spec.Template.Spec.InitContainers, 1)
+	assert.Equal(t, "image:new", ds.Spec.Template.Spec.InitContainers[0].Image)
+	require.Len(t, ds.Spec.Template.Spec.Containers, 1)
+	assert.Equal(t, "registry.example.com/pause:3.2", ds.Spec.Template.Spec.Containers[0].Image)
+
+	actualSts := &appsv1.StatefulSet{}
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actualSts))
+	assert.Equal(t, "image:latest", actualSts.Spec.Template.Spec.Containers[0].Image,
+		"expecting StatefulSet not to be updated before the image is pulled")
+
+	// image is pulled on all nodes
+	ds.Status.DesiredNumberScheduled = 3
+	ds.Status.UpdatedNumberScheduled = 3
+	ds.Status.NumberReady = 3
+	require.NoError(t, c.Status().Update(context.Background(), &ds))
+
+	require.NoError(t, sts.Ensure(context.Background()))
+
+	err = c.Get(context.Background(), sts.ImagePrePullKey(), &ds)
+	assert.True(t, apierrors.IsNotFound(err), "expecting pre-pull DaemonSet to be removed, got %v", err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+)
+
+const (
+	// AWSLoadBalancerTagsAnnotation is used by AWS cloud provider to tag
+	// ELB/NLB created for the Service
+	AWSLoadBalancerTagsAnnotation = "service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags"
+	// AzureLoadBalancerTagsAnnotation is used by Azure cloud provider to tag
+	// public IP created for the Service
+	AzureLoadBalancerTagsAnnotation = "service.beta.kubernetes.io/azure-pip-tags"
+)
+
+// LoadBalancerAnnotations renders cost-allocation tags into annotations
+// following the convention of the given cloud provider. Both AWS and Azure
+// expect comma separated key=value pairs.
+func LoadBalancerAnnotations(
+	provider string, tags map[string]string,
+) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var key string
+	switch provider {
+	case redpandav1alpha1.CloudProviderAWS:
+		key = AWSLoadBalancerTagsAnnotation
+	case redpandav1alpha1.CloudProviderAzure:
+		key = AzureLoadBalancerTagsAnnotation
+	default:
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return map[string]string{key: strings.Join(pairs, ",")}
+}
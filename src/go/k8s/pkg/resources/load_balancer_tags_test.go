@@ -0,0 +1,84 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLoadBalancerAnnotations(t *testing.T) {
+	tags := map[string]string{
+		"team":        "streaming",
+		"cost-center": "1234",
+	}
+
+	tests := []struct {
+		provider string
+		expected map[string]string
+	}{
+		{redpandav1alpha1.CloudProviderAWS, map[string]string{
+			res.AWSLoadBalancerTagsAnnotation: "cost-center=1234,team=streaming",
+		}},
+		{redpandav1alpha1.CloudProviderAzure, map[string]string{
+			res.AzureLoadBalancerTagsAnnotation: "cost-center=1234,team=streaming",
+		}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			assert.Equal(t, tt.expected, res.LoadBalancerAnnotations(tt.provider, tags))
+		})
+	}
+}
+
+func TestBrokerServicesLoadBalancerTags(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		connType redpandav1alpha1.ExternalConnectivityType
+		expected string
+	}{
+		{redpandav1alpha1.ExternalConnectivityLoadBalancer, "team=streaming"},
+		// there is no load balancer to tag
+		{redpandav1alpha1.ExternalConnectivityNodePort, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.connType), func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.ExternalConnectivity = redpandav1alpha1.ExternalConnectivityConfig{
+				Enabled:          true,
+				Type:             tt.connType,
+				CloudProvider:    redpandav1alpha1.CloudProviderAWS,
+				LoadBalancerTags: map[string]string{"team": "streaming"},
+			}
+
+			c := fake.NewClientBuilder().Build()
+			services := res.NewBrokerServices(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+			// the brokers have no address yet
+			var requeue *res.RequeueAfterError
+			require.True(t, errors.As(services.Ensure(context.Background()), &requeue))
+
+			var actual corev1.Service
+			require.NoError(t, c.Get(context.Background(), services.Key(cluster.Name+"-0"), &actual))
+			assert.Equal(t, tt.expected, actual.Annotations[res.AWSLoadBalancerTagsAnnotation])
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LocalVolumeProvisioner is the provisioner of storage classes backed by
+// statically provisioned local persistent volumes
+const LocalVolumeProvisioner = "kubernetes.io/no-provisioner"
+
+// LocalVolumes counts the local persistent volumes available to the brokers
+// of the cluster. It returns false when the storage class of the cluster is
+// not backed by statically provisioned local volumes, the number of brokers
+// is not limited by the volumes then.
+func LocalVolumes(
+	ctx context.Context,
+	c k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+) (available int, local bool, err error) {
+	className := pandaCluster.Spec.Storage.StorageClassName
+	if className == "" {
+		return 0, false, nil
+	}
+
+	var sc storagev1.StorageClass
+	err = c.Get(ctx, types.NamespacedName{Name: className}, &sc)
+	if apierrors.IsNotFound(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to fetch StorageClass %s: %w", className, err)
+	}
+	if sc.Provisioner != LocalVolumeProvisioner {
+		return 0, false, nil
+	}
+
+	available, err = availableVolumes(ctx, c, pandaCluster, className)
+	return available, true, err
+}
+
+// availableVolumes counts persistent volumes of the storage class that are
+// either unclaimed or already claimed by this cluster. The claims of the
+// cluster are selected by labels, as the claim names of a cluster can be a
+// prefix of the claim names of another cluster, e.g. cluster and cluster-a.
+func availableVolumes(
+	ctx context.Context,
+	c k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	className string,
+) (int, error) {
+	var pvcs corev1.PersistentVolumeClaimList
+	err := c.List(ctx, &pvcs, &k8sclient.ListOptions{
+		LabelSelector: labels.ForCluster(pandaCluster).AsClientSelector(),
+		Namespace:     pandaCluster.Namespace,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch PersistentVolumeClaimList resource: %w", err)
+	}
+	claims := make(map[string]bool, len(pvcs.Items))
+	for i := range pvcs.Items {
+		if strings.HasPrefix(pvcs.Items[i].Name, datadirName+"-") {
+			claims[pvcs.Items[i].Name] = true
+		}
+	}
+
+	var pvs corev1.PersistentVolumeList
+	if err := c.List(ctx, &pvs); err != nil {
+		return 0, fmt.Errorf("unable to list PersistentVolumes: %w", err)
+	}
+
+	count := 0
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Spec.StorageClassName != className {
+			continue
+		}
+		switch {
+		case pv.Status.Phase == corev1.VolumeAvailable && pv.Spec.ClaimRef == nil:
+			count++
+		case pv.Spec.ClaimRef != nil &&
+			pv.Spec.ClaimRef.Namespace == pandaCluster.Namespace &&
+			claims[pv.Spec.ClaimRef.Name]:
+			count++
+		}
+	}
+	return count, nil
+}
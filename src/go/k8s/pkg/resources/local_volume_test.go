@@ -0,0 +1,89 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLocalVolumes(t *testing.T) {
+	cluster := pandaCluster()
+
+	localClass := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: cluster.Spec.Storage.StorageClassName},
+		Provisioner: res.LocalVolumeProvisioner,
+	}
+	dynamicClass := localClass.DeepCopy()
+	dynamicClass.Provisioner = "kubernetes.io/aws-ebs"
+
+	pv := func(name string, claim *corev1.ObjectReference) *corev1.PersistentVolume {
+		phase := corev1.VolumeAvailable
+		if claim != nil {
+			phase = corev1.VolumeBound
+		}
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName: cluster.Spec.Storage.StorageClassName,
+				ClaimRef:         claim,
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: phase},
+		}
+	}
+	pvc := func(name string, clusterLabels labels.CommonLabels) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cluster.Namespace, Labels: clusterLabels},
+		}
+	}
+	// the claim names of cluster are a prefix of the claim names of cluster-a
+	prefixed := cluster.DeepCopy()
+	prefixed.Name = cluster.Name + "-a"
+	volumes := []client.Object{
+		pv("pv-0", &corev1.ObjectReference{Namespace: cluster.Namespace, Name: fmt.Sprintf("datadir-%s-0", cluster.Name)}),
+		pvc(fmt.Sprintf("datadir-%s-0", cluster.Name), labels.ForCluster(cluster)),
+		pv("pv-1", nil),
+		pv("pv-other", &corev1.ObjectReference{Namespace: cluster.Namespace, Name: "datadir-other-0"}),
+		pv("pv-prefixed", &corev1.ObjectReference{Namespace: cluster.Namespace, Name: fmt.Sprintf("datadir-%s-0", prefixed.Name)}),
+		pvc(fmt.Sprintf("datadir-%s-0", prefixed.Name), labels.ForCluster(prefixed)),
+	}
+
+	tests := []struct {
+		name      string
+		class     client.Object
+		available int
+		local     bool
+	}{
+		{"local volumes", localClass, 2, true},
+		{"dynamic provisioning is not checked", dynamicClass, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(append(volumes, tt.class)...).Build()
+
+			available, local, err := res.LocalVolumes(context.Background(), c, cluster)
+			require.NoError(t, err)
+			assert.Equal(t, tt.local, local)
+			assert.Equal(t, tt.available, available)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	metadataBackupSuffix    = "-metadata-backup"
+	metadataBackupComponent = "metadata-backup"
+	defaultBackupPrefix     = "metadata-backup"
+	defaultUploaderImage    = "amazon/aws-cli"
+	backupDir               = "/backup"
+	backupVolumeName        = "backup"
+
+	// exportScript writes the cluster info and the definitions and configs
+	// of all topics to the backup directory as JSON
+	exportScript = `set -e
+` + rpkClientFlags + `
+rpk cluster info --brokers "$BROKERS" --format json "$@" > ` + backupDir + `/cluster-info.json
+rpk topic list --brokers "$BROKERS" --format json "$@" > ` + backupDir + `/topics.json
+for topic in $(grep -o '"name": *"[^"]*"' ` + backupDir + `/topics.json | sed 's/.*"\([^"]*\)"$/\1/'); do
+  rpk topic describe "$topic" --print-all --brokers "$BROKERS" --format json "$@" > "` + backupDir + `/topic-$topic.json"
+done`
+
+	// uploadScript copies the export to the bucket under a timestamped key
+	uploadScript = `set -e
+aws s3 cp --recursive ` + backupDir + ` "s3://$BUCKET/$PREFIX/$(date -u +%Y%m%dT%H%M%SZ)" $ENDPOINT_ARGS`
+)
+
+var _ Resource = &MetadataBackupResource{}
+
+// MetadataBackupResource is part of the reconciliation of redpanda.vectorized.io CRD
+// scheduling periodic exports of the cluster metadata to the cloud storage
+type MetadataBackupResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	serviceFQDN  string
+	// nodeCertSecretKey provides the CA of the brokers
+	nodeCertSecretKey types.NamespacedName
+	// clientCertSecretKey authenticates the export when the Kafka API
+	// requires client auth
+	clientCertSecretKey types.NamespacedName
+	logger              logr.Logger
+}
+
+// NewMetadataBackup creates MetadataBackupResource
+func NewMetadataBackup(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	serviceFQDN string,
+	nodeCertSecretKey types.NamespacedName,
+	clientCertSecretKey types.NamespacedName,
+	logger logr.Logger,
+) *MetadataBackupResource {
+	return &MetadataBackupResource{
+		client,
+		scheme,
+		pandaCluster,
+		serviceFQDN,
+		nodeCertSecretKey,
+		clientCertSecretKey,
+		logger.WithValues("Kind", cronJobKind()),
+	}
+}
+
+// Ensure will manage batch/v1beta1.CronJob exporting the cluster metadata.
+// The CronJob is removed when the backup is not configured.
+func (r *MetadataBackupResource) Ensure(ctx context.Context) error {
+	if r.pandaCluster.Spec.MetadataBackup == nil {
+		return r.cleanup(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var cronJob batchv1beta1.CronJob
+	err = r.Get(ctx, r.Key(), &cronJob)
+	if err != nil {
+		return fmt.Errorf("error while fetching CronJob resource: %w", err)
+	}
+	return Update(ctx, &cronJob, obj, r.Client, r.logger)
+}
+
+func (r *MetadataBackupResource) cleanup(ctx context.Context) error {
+	var cronJob batchv1beta1.CronJob
+	err := r.Get(ctx, r.Key(), &cronJob)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching CronJob resource: %w", err)
+	}
+	r.logger.Info("Removing metadata backup CronJob", "name", cronJob.Name)
+	if err := r.Delete(ctx, &cronJob); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete metadata backup CronJob: %w", err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object
+func (r *MetadataBackupResource) obj() (k8sclient.Object, error) {
+	backup := r.pandaCluster.Spec.MetadataBackup
+
+	prefix := backup.Prefix
+	if prefix == "" {
+		prefix = defaultBackupPrefix
+	}
+	uploaderImage := backup.UploaderImage
+	if uploaderImage == "" {
+		uploaderImage = defaultUploaderImage
+	}
+
+	// the pods must not be selected as brokers of the cluster
+	backupLabels := labels.CommonLabels{}
+	for k, v := range labels.ForCluster(r.pandaCluster) {
+		backupLabels[k] = v
+	}
+	backupLabels[labels.ComponentKey] = metadataBackupComponent
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      backupVolumeName,
+			MountPath: backupDir,
+		},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: backupVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	exportEnv := []corev1.EnvVar{rpkBrokers(r.pandaCluster, r.serviceFQDN)}
+	exportMounts, exportVolumes, tlsEnv := rpkClientTLS(r.pandaCluster, r.nodeCertSecretKey, r.clientCertSecretKey)
+	exportEnv = append(exportEnv, tlsEnv...)
+	exportEnv = append(exportEnv, rpkClientSASL(r.pandaCluster)...)
+	volumes = append(volumes, exportVolumes...)
+
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    backupLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: "batch/v1beta1",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          backup.Schedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: backupLabels,
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: backupLabels,
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyOnFailure,
+							// the export has to finish before the upload starts
+							InitContainers: []corev1.Container{
+								{
+									Name:         "export",
+									Image:        r.pandaCluster.FullImageName(),
+									Command:      []string{"/bin/sh", "-c", exportScript},
+									Env:          exportEnv,
+									VolumeMounts: append(volumeMounts, exportMounts...),
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:    "upload",
+									Image:   uploaderImage,
+									Command: []string{"/bin/sh", "-c", uploadScript},
+									Env: append([]corev1.EnvVar{
+										{
+											Name:  "PREFIX",
+											Value: prefix,
+										},
+									}, uploaderEnv(r.pandaCluster)...),
+									VolumeMounts: append(volumeMounts, cloudStorageTokenVolumeMounts(r.pandaCluster)...),
+								},
+							},
+							Volumes:            append(volumes, cloudStorageTokenVolumes(r.pandaCluster)...),
+							ServiceAccountName: cloudStorageTokenServiceAccount(r.pandaCluster),
+							Tolerations:        r.pandaCluster.Spec.Tolerations,
+							NodeSelector:       r.pandaCluster.Spec.NodeSelector,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, cronJob, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cronJob, nil
+}
+
+// uploaderEnv returns the environment of the AWS CLI uploading to the cloud
+// storage bucket. The CLI authenticates with the static credentials or the
+// projected token, the same way as the brokers.
+func uploaderEnv(pandaCluster *redpandav1alpha1.Cluster) []corev1.EnvVar {
+	cloudStorage := pandaCluster.Spec.CloudStorage
+	env := []corev1.EnvVar{
+		{
+			Name:  "BUCKET",
+			Value: cloudStorage.Bucket,
+		},
+		{
+			Name:  "ENDPOINT_ARGS",
+			Value: endpointArgs(cloudStorage),
+		},
+		{
+			Name:  "AWS_DEFAULT_REGION",
+			Value: cloudStorage.Region,
+		},
+	}
+	if !pandaCluster.CloudStorageStaticCredentials() {
+		return append(env, cloudStorageTokenEnv(pandaCluster)...)
+	}
+	return append(env,
+		corev1.EnvVar{
+			Name:  "AWS_ACCESS_KEY_ID",
+			Value: cloudStorage.AccessKey,
+		},
+		corev1.EnvVar{
+			Name: "AWS_SECRET_ACCESS_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cloudStorage.SecretKeyRef.Name,
+					},
+					Key: cloudStorage.SecretKeyRef.Name,
+				},
+			},
+		})
+}
+
+// endpointArgs points AWS CLI to the custom API endpoint of S3 compatible
+// object stores
+func endpointArgs(cloudStorage redpandav1alpha1.CloudStorageConfig) string {
+	if cloudStorage.APIEndpoint == "" {
+		return ""
+	}
+	scheme := "https"
+	if cloudStorage.DisableTLS {
+		scheme = "http"
+	}
+	host := cloudStorage.APIEndpoint
+	if cloudStorage.APIEndpointPort != 0 {
+		host = host + ":" + strconv.Itoa(cloudStorage.APIEndpointPort)
+	}
+	return fmt.Sprintf("--endpoint-url %s://%s", scheme, host)
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *MetadataBackupResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + metadataBackupSuffix, Namespace: r.pandaCluster.Namespace}
+}
+
+func cronJobKind() string {
+	var cronJob batchv1beta1.CronJob
+	return cronJob.Kind
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMetadataBackup(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.CloudStorage = redpandav1alpha1.CloudStorageConfig{
+		Enabled:      true,
+		AccessKey:    "access",
+		Region:       "us-west-1",
+		Bucket:       "archive",
+		SecretKeyRef: corev1.ObjectReference{Name: "secret", Namespace: "default"},
+	}
+	cluster.Spec.MetadataBackup = &redpandav1alpha1.MetadataBackupConfig{
+		Schedule: "0 */6 * * *",
+	}
+
+	c := fake.NewClientBuilder().Build()
+	backup := res.NewMetadataBackup(c, cluster, scheme.Scheme, "cluster.default.svc.cluster.local",
+		types.NamespacedName{Name: "cluster-redpanda", Namespace: "default"},
+		types.NamespacedName{Name: "cluster-operator-client", Namespace: "default"},
+		ctrl.Log.WithName("test"))
+	require.NoError(t, backup.Ensure(context.Background()))
+
+	var cronJob batchv1beta1.CronJob
+	require.NoError(t, c.Get(context.Background(), backup.Key(), &cronJob))
+	assert.Equal(t, "0 */6 * * *", cronJob.Spec.Schedule)
+	assert.Equal(t, batchv1beta1.ForbidConcurrent, cronJob.Spec.ConcurrencyPolicy)
+
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	require.Len(t, podSpec.InitContainers, 1)
+	export := podSpec.InitContainers[0]
+	assert.Equal(t, "image:latest", export.Image)
+	assert.Equal(t, []string{"/bin/sh", "-c"}, export.Command[:2])
+	assert.Contains(t, export.Command[2], "rpk cluster info")
+	assert.Contains(t, export.Command[2], "rpk topic describe")
+	assert.Contains(t, export.Command[2], "--format json")
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "BROKERS", Value: "cluster.default.svc.cluster.local:123"})
+	assert.Len(t, export.Env, 1)
+	assert.Len(t, podSpec.Volumes, 1)
+
+	require.Len(t, podSpec.Containers, 1)
+	upload := podSpec.Containers[0]
+	assert.Contains(t, upload.Command[2], "aws s3 cp")
+	assert.Contains(t, upload.Env, corev1.EnvVar{Name: "BUCKET", Value: "archive"})
+	assert.Contains(t, upload.Env, corev1.EnvVar{Name: "PREFIX", Value: "metadata-backup"})
+	assert.Contains(t, upload.Env, corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", Value: "access"})
+
+	// the export authenticates with the client cert and the superuser
+	cluster.Spec.Configuration.TLS.KafkaAPI.Enabled = true
+	cluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth = true
+	cluster.Spec.EnableSASL = true
+	passwordRef := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "admin-password"},
+		Key:                  "password",
+	}
+	cluster.Spec.Superusers = []redpandav1alpha1.Superuser{
+		{Username: "bootstrap"},
+		{Username: "admin", PasswordSecretKeyRef: passwordRef},
+	}
+	require.NoError(t, backup.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), backup.Key(), &cronJob))
+	podSpec = cronJob.Spec.JobTemplate.Spec.Template.Spec
+	export = podSpec.InitContainers[0]
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "TLS_TRUSTSTORE", Value: "/etc/tls/certs/ca/ca.crt"})
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "TLS_CERT", Value: "/etc/tls/certs/client/tls.crt"})
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "TLS_KEY", Value: "/etc/tls/certs/client/tls.key"})
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "SASL_USER", Value: "admin"})
+	assert.Contains(t, export.Env, corev1.EnvVar{
+		Name:      "SASL_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: passwordRef},
+	})
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "SASL_MECHANISM", Value: "SCRAM-SHA-256"})
+	secrets := make(map[string]string)
+	for _, v := range podSpec.Volumes {
+		if v.Secret != nil {
+			secrets[v.Name] = v.Secret.SecretName
+		}
+	}
+	assert.Equal(t, map[string]string{"tlsca": "cluster-redpanda", "tlscert": "cluster-operator-client"}, secrets)
+	assert.Len(t, export.VolumeMounts, 3)
+	// the upload doesn't get the credentials of the cluster
+	assert.Len(t, podSpec.Containers[0].VolumeMounts, 1)
+
+	// the internal listener is plain text with external connectivity
+	cluster.Spec.ExternalConnectivity.Enabled = true
+	require.NoError(t, backup.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), backup.Key(), &cronJob))
+	assert.Len(t, cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes, 1)
+
+	// a declared internal listener is exported with its own TLS
+	cluster.Spec.Configuration.Listeners = []redpandav1alpha1.ListenerSpec{
+		{Name: redpandav1alpha1.InternalListener, Port: 123, TLS: true},
+		{Name: redpandav1alpha1.ExternalListener, Port: 124, TLS: true, RequireClientAuth: true, External: true},
+	}
+	require.NoError(t, backup.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), backup.Key(), &cronJob))
+	export = cronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0]
+	assert.Contains(t, export.Env, corev1.EnvVar{Name: "TLS_TRUSTSTORE", Value: "/etc/tls/certs/ca/ca.crt"})
+	assert.NotContains(t, export.Env, corev1.EnvVar{Name: "TLS_CERT", Value: "/etc/tls/certs/client/tls.crt"})
+	assert.Len(t, cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes, 2)
+	cluster.Spec.Configuration.Listeners = nil
+
+	// the upload authenticates with the token of the brokers instead of
+	// the static credentials
+	cluster.Spec.CloudStorage.ProjectedToken = &redpandav1alpha1.CloudStorageProjectedToken{
+		Audience: "sts.amazonaws.com",
+		RoleARN:  "arn:aws:iam::123456789012:role/redpanda",
+	}
+	require.NoError(t, backup.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), backup.Key(), &cronJob))
+	podSpec = cronJob.Spec.JobTemplate.Spec.Template.Spec
+	upload = podSpec.Containers[0]
+	env := make(map[string]string)
+	for _, e := range upload.Env {
+		env[e.Name] = e.Value
+	}
+	assert.NotContains(t, env, "AWS_ACCESS_KEY_ID")
+	assert.NotContains(t, env, "AWS_SECRET_ACCESS_KEY")
+	assert.Equal(t, "arn:aws:iam::123456789012:role/redpanda", env[res.RoleARNEnv])
+	require.Len(t, upload.VolumeMounts, 2)
+	assert.Equal(t, upload.VolumeMounts[1].MountPath+"/token", env[res.WebIdentityTokenFileEnv])
+	require.Len(t, podSpec.Volumes, 2)
+	assert.NotNil(t, podSpec.Volumes[1].Projected)
+	assert.Equal(t, cluster.Name, podSpec.ServiceAccountName)
+	cluster.Spec.CloudStorage.ProjectedToken = nil
+
+	// schedule change is applied
+	cluster.Spec.MetadataBackup.Schedule = "@daily"
+	require.NoError(t, backup.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), backup.Key(), &cronJob))
+	assert.Equal(t, "@daily", cronJob.Spec.Schedule)
+
+	// CronJob is removed when the backup is disabled
+	cluster.Spec.MetadataBackup = nil
+	require.NoError(t, backup.Ensure(context.Background()))
+	err := c.Get(context.Background(), backup.Key(), &cronJob)
+	assert.True(t, apierrors.IsNotFound(err))
+}
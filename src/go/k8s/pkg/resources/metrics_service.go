@@ -0,0 +1,152 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// MetricsPortName is name of metrics port in Service definition
+	MetricsPortName = "metrics"
+
+	// metricsServiceLabel tells the metrics Service apart from the other
+	// Services sharing the cluster labels
+	metricsServiceLabel = "redpanda.vectorized.io/metrics"
+)
+
+var _ Resource = &MetricsServiceResource{}
+
+// MetricsServiceResource is part of the reconciliation of redpanda.vectorized.io CRD
+// exposing only the port the brokers serve the metrics on when
+// SeparateMetricsService is set
+type MetricsServiceResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewMetricsService creates MetricsServiceResource
+func NewMetricsService(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *MetricsServiceResource {
+	return &MetricsServiceResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", serviceKind(), "ServiceType", "Metrics"),
+	}
+}
+
+// Ensure will manage the metrics Service. The Service is removed when it's
+// disabled.
+func (r *MetricsServiceResource) Ensure(ctx context.Context) error {
+	if !r.pandaCluster.Spec.SeparateMetricsService {
+		return r.cleanup(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var svc corev1.Service
+	err = r.Get(ctx, r.Key(), &svc)
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	return Update(ctx, &svc, obj, r.Client, r.logger)
+}
+
+func (r *MetricsServiceResource) cleanup(ctx context.Context) error {
+	var svc corev1.Service
+	err := r.Get(ctx, r.Key(), &svc)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching Service resource: %w", err)
+	}
+	if _, ok := svc.Labels[metricsServiceLabel]; !ok {
+		// the Service is not managed by the operator
+		return nil
+	}
+	r.logger.Info("Removing metrics Service", "name", svc.Name)
+	if err := r.Delete(ctx, &svc); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete Service %s: %w", svc.Name, err)
+	}
+	return nil
+}
+
+// obj returns resource managed client.Object. Redpanda serves the metrics on
+// the Admin API listener, so the metrics port is the Admin API port. The
+// Service is headless, so the scraper reaches every broker.
+func (r *MetricsServiceResource) obj() (k8sclient.Object, error) {
+	adminPort := r.pandaCluster.Spec.Configuration.AdminAPI.Port
+	svcLabels := labels.ForCluster(r.pandaCluster).AsSet()
+	svcLabels[metricsServiceLabel] = "true"
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    svcLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       MetricsPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(adminPort),
+					TargetPort: intstr.FromInt(adminPort),
+				},
+			},
+			Selector: labels.ForCluster(r.pandaCluster).AsAPISelector().MatchLabels,
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, svc, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *MetricsServiceResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name + "-metrics", Namespace: r.pandaCluster.Namespace}
+}
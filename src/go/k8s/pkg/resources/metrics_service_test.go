@@ -0,0 +1,62 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMetricsService(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	cluster := pandaCluster()
+	cluster.Spec.Configuration.AdminAPI.Port = 9644
+
+	// removing the disabled Service needs the delete verb
+	c := newRBACClient(t, fake.NewClientBuilder().Build())
+	svc := res.NewMetricsService(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+
+	// the Service is not created by default
+	require.NoError(t, svc.Ensure(ctx))
+	var actual corev1.Service
+	err := c.Get(ctx, svc.Key(), &actual)
+	assert.True(t, apierrors.IsNotFound(err), "expecting no metrics Service, got %v", err)
+
+	cluster.Spec.SeparateMetricsService = true
+	require.NoError(t, svc.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, svc.Key(), &actual))
+	assert.Equal(t, cluster.Name+"-metrics", actual.Name)
+	assert.Equal(t, corev1.ClusterIPNone, actual.Spec.ClusterIP)
+	assert.Equal(t, "true", actual.Labels["redpanda.vectorized.io/metrics"])
+	assert.Equal(t, []corev1.ServicePort{{
+		Name:       res.MetricsPortName,
+		Protocol:   corev1.ProtocolTCP,
+		Port:       9644,
+		TargetPort: intstr.FromInt(9644),
+	}}, actual.Spec.Ports)
+
+	// disabled Service is removed
+	cluster.Spec.SeparateMetricsService = false
+	require.NoError(t, svc.Ensure(ctx))
+	err = c.Get(ctx, svc.Key(), &actual)
+	assert.True(t, apierrors.IsNotFound(err), "expecting metrics Service to be removed, got %v", err)
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ Resource = &PodDisruptionBudgetResource{}
+
+// PodDisruptionBudgetResource is part of the reconciliation of redpanda.vectorized.io CRD
+// limiting voluntary disruptions of the brokers
+type PodDisruptionBudgetResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	logger       logr.Logger
+}
+
+// NewPodDisruptionBudget creates PodDisruptionBudgetResource
+func NewPodDisruptionBudget(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	logger logr.Logger,
+) *PodDisruptionBudgetResource {
+	return &PodDisruptionBudgetResource{
+		client,
+		scheme,
+		pandaCluster,
+		logger.WithValues("Kind", podDisruptionBudgetKind()),
+	}
+}
+
+// Ensure will manage policy/v1beta1.PodDisruptionBudget of the brokers.
+// The budget is removed when it's disabled.
+func (r *PodDisruptionBudgetResource) Ensure(ctx context.Context) error {
+	if r.mode() == redpandav1alpha1.PodDisruptionBudgetDisabled {
+		return r.cleanup(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	var pdb policyv1beta1.PodDisruptionBudget
+	err = r.Get(ctx, r.Key(), &pdb)
+	if err != nil {
+		return fmt.Errorf("error while fetching PodDisruptionBudget resource: %w", err)
+	}
+	return Update(ctx, &pdb, obj, r.Client, r.logger)
+}
+
+func (r *PodDisruptionBudgetResource) cleanup(ctx context.Context) error {
+	var pdb policyv1beta1.PodDisruptionBudget
+	err := r.Get(ctx, r.Key(), &pdb)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching PodDisruptionBudget resource: %w", err)
+	}
+	r.logger.Info("Removing PodDisruptionBudget", "name", pdb.Name)
+	if err := r.Delete(ctx, &pdb); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete PodDisruptionBudget: %w", err)
+	}
+	return nil
+}
+
+func (r *PodDisruptionBudgetResource) mode() redpandav1alpha1.PodDisruptionBudgetMode {
+	if r.pandaCluster.Spec.PodDisruptionBudget == nil || r.pandaCluster.Spec.PodDisruptionBudget.Mode == "" {
+		return redpandav1alpha1.PodDisruptionBudgetQuorum
+	}
+	return r.pandaCluster.Spec.PodDisruptionBudget.Mode
+}
+
+// minAvailable returns the number of brokers that can't be evicted. In
+// DuringUpgrade mode the budget is relaxed to the quorum, like in Quorum
+// mode, unless a rolling upgrade is in progress. MinAvailable from the spec
+// takes precedence.
+func (r *PodDisruptionBudgetResource) minAvailable() int32 {
+	if config := r.pandaCluster.Spec.PodDisruptionBudget; config != nil && config.MinAvailable != nil {
+		return *config.MinAvailable
+	}
+
+	var replicas int32
+	if r.pandaCluster.Spec.Replicas != nil {
+		replicas = *r.pandaCluster.Spec.Replicas
+	}
+	switch r.mode() {
+	case redpandav1alpha1.PodDisruptionBudgetDuringUpgrade:
+		if r.pandaCluster.Status.Upgrading {
+			return replicas
+		}
+		return quorum(replicas)
+	case redpandav1alpha1.PodDisruptionBudgetAlways:
+		return replicas
+	default:
+		return quorum(replicas)
+	}
+}
+
+// quorum returns the majority of the brokers
+func quorum(replicas int32) int32 {
+	if replicas == 0 {
+		return 0
+	}
+	return replicas/2 + 1
+}
+
+// obj returns resource managed client.Object
+func (r *PodDisruptionBudgetResource) obj() (k8sclient.Object, error) {
+	minAvailable := intstr.FromInt(int(r.minAvailable()))
+	clusterLabels := labels.ForCluster(r.pandaCluster)
+
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.Key().Namespace,
+			Name:      r.Key().Name,
+			Labels:    clusterLabels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1beta1",
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     clusterLabels.AsAPISelector(),
+		},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, pdb, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdb, nil
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *PodDisruptionBudgetResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+}
+
+func podDisruptionBudgetKind() string {
+	var pdb policyv1beta1.PodDisruptionBudget
+	return pdb.Kind
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodDisruptionBudgetDuringUpgrade(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(5)
+	cluster.Spec.PodDisruptionBudget = &redpandav1alpha1.PodDisruptionBudgetConfig{
+		Mode: redpandav1alpha1.PodDisruptionBudgetDuringUpgrade,
+	}
+
+	c := fake.NewClientBuilder().Build()
+	pdb := res.NewPodDisruptionBudget(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+
+	minAvailable := func() int {
+		var actual policyv1beta1.PodDisruptionBudget
+		require.NoError(t, c.Get(context.Background(), pdb.Key(), &actual))
+		return actual.Spec.MinAvailable.IntValue()
+	}
+
+	// the quorum is kept outside of upgrades
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 3, minAvailable())
+
+	// the budget is tightened during the rolling upgrade
+	cluster.Status.Upgrading = true
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 5, minAvailable())
+
+	// and relaxed after the upgrade completes
+	cluster.Status.Upgrading = false
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 3, minAvailable())
+
+	// the budget is tight all the time in the Always mode
+	cluster.Spec.PodDisruptionBudget.Mode = redpandav1alpha1.PodDisruptionBudgetAlways
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 5, minAvailable())
+
+	// and removed when disabled
+	cluster.Spec.PodDisruptionBudget.Mode = redpandav1alpha1.PodDisruptionBudgetDisabled
+	require.NoError(t, pdb.Ensure(context.Background()))
+	var actual policyv1beta1.PodDisruptionBudget
+	err := c.Get(context.Background(), pdb.Key(), &actual)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestPodDisruptionBudgetQuorum(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		replicas     int32
+		minAvailable int
+	}{
+		{1, 1},
+		{3, 2},
+		{5, 3},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d replicas", tt.replicas), func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.Replicas = pointer.Int32Ptr(tt.replicas)
+
+			c := fake.NewClientBuilder().Build()
+			pdb := res.NewPodDisruptionBudget(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+			require.NoError(t, pdb.Ensure(context.Background()))
+
+			var actual policyv1beta1.PodDisruptionBudget
+			require.NoError(t, c.Get(context.Background(), pdb.Key(), &actual))
+			assert.Equal(t, tt.minAvailable, actual.Spec.MinAvailable.IntValue())
+
+			// the budget is garbage collected with the cluster
+			owner := metav1.GetControllerOf(&actual)
+			require.NotNil(t, owner)
+			assert.Equal(t, cluster.UID, owner.UID)
+			assert.Equal(t, cluster.Name, owner.Name)
+		})
+	}
+}
+
+func TestPodDisruptionBudgetReplicasChange(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+
+	c := fake.NewClientBuilder().Build()
+	pdb := res.NewPodDisruptionBudget(c, cluster, scheme.Scheme, ctrl.Log.WithName("test"))
+
+	minAvailable := func() int {
+		var actual policyv1beta1.PodDisruptionBudget
+		require.NoError(t, c.Get(context.Background(), pdb.Key(), &actual))
+		return actual.Spec.MinAvailable.IntValue()
+	}
+
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 2, minAvailable())
+
+	// the budget follows the replicas
+	cluster.Spec.Replicas = pointer.Int32Ptr(5)
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 3, minAvailable())
+
+	// unless it's overridden
+	cluster.Spec.PodDisruptionBudget = &redpandav1alpha1.PodDisruptionBudgetConfig{
+		MinAvailable: pointer.Int32Ptr(4),
+	}
+	require.NoError(t, pdb.Ensure(context.Background()))
+	assert.Equal(t, 4, minAvailable())
+}
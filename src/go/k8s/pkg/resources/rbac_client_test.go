@@ -0,0 +1,156 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// rbacClient rejects the requests that the ClusterRole of the operator in
+// config/rbac/role.yaml doesn't grant with Forbidden, like the API server
+// does. Status updates are not checked.
+type rbacClient struct {
+	k8sclient.Client
+	rules []rbacv1.PolicyRule
+}
+
+func newRBACClient(t *testing.T, c k8sclient.Client) k8sclient.Client {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(filepath.Join("..", "..", "config", "rbac", "role.yaml"))
+	require.NoError(t, err)
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("---"))
+	var role rbacv1.ClusterRole
+	require.NoError(t, yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)).Decode(&role))
+	require.NotEmpty(t, role.Rules)
+	return &rbacClient{c, role.Rules}
+}
+
+func (c *rbacClient) Get(
+	ctx context.Context, key k8sclient.ObjectKey, obj k8sclient.Object,
+) error {
+	if err := c.authorize(obj, "get", key.Name); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *rbacClient) List(
+	ctx context.Context, list k8sclient.ObjectList, opts ...k8sclient.ListOption,
+) error {
+	if err := c.authorize(list, "list", ""); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *rbacClient) Create(
+	ctx context.Context, obj k8sclient.Object, opts ...k8sclient.CreateOption,
+) error {
+	if err := c.authorize(obj, "create", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *rbacClient) Update(
+	ctx context.Context, obj k8sclient.Object, opts ...k8sclient.UpdateOption,
+) error {
+	if err := c.authorize(obj, "update", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *rbacClient) Patch(
+	ctx context.Context,
+	obj k8sclient.Object,
+	patch k8sclient.Patch,
+	opts ...k8sclient.PatchOption,
+) error {
+	if err := c.authorize(obj, "patch", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *rbacClient) Delete(
+	ctx context.Context, obj k8sclient.Object, opts ...k8sclient.DeleteOption,
+) error {
+	if err := c.authorize(obj, "delete", obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *rbacClient) DeleteAllOf(
+	ctx context.Context, obj k8sclient.Object, opts ...k8sclient.DeleteAllOfOption,
+) error {
+	if err := c.authorize(obj, "deletecollection", ""); err != nil {
+		return err
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *rbacClient) authorize(obj runtime.Object, verb, name string) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return err
+	}
+	resource := schema.GroupResource{Group: gvk.Group, Resource: pluralResource(gvk.Kind)}
+	for _, rule := range c.rules {
+		if contains(rule.APIGroups, resource.Group) &&
+			contains(rule.Resources, resource.Resource) &&
+			contains(rule.Verbs, verb) {
+			return nil
+		}
+	}
+	return apierrors.NewForbidden(resource, name,
+		fmt.Errorf("verb %s is not granted by config/rbac/role.yaml", verb))
+}
+
+// pluralResource returns the resource of the kind, or of the items of the
+// list kind
+func pluralResource(kind string) string {
+	resource := strings.ToLower(strings.TrimSuffix(kind, "List"))
+	switch {
+	case strings.HasSuffix(resource, "y"):
+		return strings.TrimSuffix(resource, "y") + "ies"
+	case strings.HasSuffix(resource, "s"):
+		return resource + "es"
+	default:
+		return resource + "s"
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == rbacv1.ResourceAll {
+			return true
+		}
+	}
+	return false
+}
@@ -13,9 +13,12 @@ package resources
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/networking"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -25,9 +28,9 @@ import (
 
 const (
 	// KafkaPortName is name of kafka port in Service definition
-	KafkaPortName = "kafka"
+	KafkaPortName = networking.KafkaPortName
 	// AdminPortName is name of admin port in Service definition
-	AdminPortName = "admin"
+	AdminPortName = networking.AdminPortName
 )
 
 // NamedServicePort allows to pass name ports, e.g., to service resources
@@ -36,6 +39,21 @@ type NamedServicePort struct {
 	Port int
 }
 
+// AdditionalListenerPorts returns the ports of the Kafka API listeners other
+// than the Internal and the External listener, named after the listeners
+func AdditionalListenerPorts(
+	pandaCluster *redpandav1alpha1.Cluster,
+) []NamedServicePort {
+	var ports []NamedServicePort
+	for _, l := range pandaCluster.KafkaAPIListeners() {
+		if l.Name == redpandav1alpha1.InternalListener || l.Name == redpandav1alpha1.ExternalListener {
+			continue
+		}
+		ports = append(ports, NamedServicePort{Name: strings.ToLower(l.Name), Port: l.Port})
+	}
+	return ports
+}
+
 // Resource decompose the reconciliation loop to specific kubernetes objects
 type Resource interface {
 	Reconciler
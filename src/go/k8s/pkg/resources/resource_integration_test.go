@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/deprecated/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -108,6 +109,7 @@ func TestEnsure_ConfigMap(t *testing.T) {
 		cluster,
 		scheme.Scheme,
 		"cluster.local",
+		record.NewFakeRecorder(10),
 		ctrl.Log.WithName("test"))
 
 	err := cm.Ensure(context.Background())
@@ -0,0 +1,97 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ExceededQuotas returns the ResourceQuotas that don't leave room for the
+// new broker Pods with the given container resources. Requests default to
+// the limits like in the Pod admission. Quotas with scopes are skipped, as
+// well as the storage of the data directories, which is claimed only by
+// brokers that don't reuse the volume of a broker removed by a scale down.
+func ExceededQuotas(
+	quotas []corev1.ResourceQuota,
+	brokerResources corev1.ResourceRequirements,
+	newPods int32,
+) []string {
+	if newPods <= 0 {
+		return nil
+	}
+	demand := quotaDemand(brokerResources, int64(newPods))
+
+	var exceeded []string
+	for i := range quotas {
+		quota := &quotas[i]
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		names := make([]string, 0, len(quota.Status.Hard))
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			requested, ok := demand[corev1.ResourceName(name)]
+			if !ok {
+				continue
+			}
+			available := quota.Status.Hard[corev1.ResourceName(name)].DeepCopy()
+			available.Sub(quota.Status.Used[corev1.ResourceName(name)])
+			if requested.Cmp(available) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s: %s %s requested, %s available",
+					quota.Name, name, requested.String(), available.String()))
+			}
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded
+}
+
+// quotaDemand returns the quota usage of the Pods by the names of the quota
+// resources
+func quotaDemand(
+	brokerResources corev1.ResourceRequirements, pods int64,
+) corev1.ResourceList {
+	demand := corev1.ResourceList{
+		corev1.ResourcePods: *resource.NewQuantity(pods, resource.DecimalSI),
+	}
+	quotaNames := []struct {
+		name, requests, limits corev1.ResourceName
+	}{
+		{corev1.ResourceCPU, corev1.ResourceRequestsCPU, corev1.ResourceLimitsCPU},
+		{corev1.ResourceMemory, corev1.ResourceRequestsMemory, corev1.ResourceLimitsMemory},
+	}
+	for _, q := range quotaNames {
+		request, requested := brokerResources.Requests[q.name]
+		limit, limited := brokerResources.Limits[q.name]
+		if !requested && limited {
+			request, requested = limit, true
+		}
+		if requested {
+			// the plain resource name stands for the requests
+			demand[q.name] = multiplyQuantity(request, pods)
+			demand[q.requests] = multiplyQuantity(request, pods)
+		}
+		if limited {
+			demand[q.limits] = multiplyQuantity(limit, pods)
+		}
+	}
+	return demand
+}
+
+func multiplyQuantity(q resource.Quantity, n int64) resource.Quantity {
+	return *resource.NewMilliQuantity(q.MilliValue()*n, q.Format)
+}
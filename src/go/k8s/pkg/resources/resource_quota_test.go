@@ -0,0 +1,115 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExceededQuotas(t *testing.T) {
+	quota := func(name string, hard, used corev1.ResourceList) corev1.ResourceQuota {
+		return corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+			Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+		}
+	}
+	brokerResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	compute := quota("compute",
+		corev1.ResourceList{
+			corev1.ResourceRequestsCPU:    resource.MustParse("4"),
+			corev1.ResourceLimitsMemory:   resource.MustParse("8Gi"),
+			corev1.ResourcePods:           resource.MustParse("10"),
+			corev1.ResourceServices:       resource.MustParse("1"),
+			corev1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+		},
+		corev1.ResourceList{
+			corev1.ResourceRequestsCPU:    resource.MustParse("1500m"),
+			corev1.ResourceLimitsMemory:   resource.MustParse("2Gi"),
+			corev1.ResourcePods:           resource.MustParse("1"),
+			corev1.ResourceServices:       resource.MustParse("1"),
+			corev1.ResourceRequestsMemory: resource.MustParse("2Gi"),
+		})
+
+	tests := []struct {
+		name            string
+		quotas          []corev1.ResourceQuota
+		brokerResources corev1.ResourceRequirements
+		newPods         int32
+		expected        []string
+	}{
+		{
+			name:            "scale up fits",
+			quotas:          []corev1.ResourceQuota{compute},
+			brokerResources: brokerResources,
+			newPods:         2,
+		},
+		{
+			name:            "scale up exceeds",
+			quotas:          []corev1.ResourceQuota{compute},
+			brokerResources: brokerResources,
+			newPods:         4,
+			expected: []string{
+				"compute: limits.memory 8Gi requested, 6Gi available",
+				"compute: requests.cpu 4 requested, 2500m available",
+				"compute: requests.memory 8Gi requested, 6Gi available",
+			},
+		},
+		{
+			name: "requests default to limits",
+			quotas: []corev1.ResourceQuota{quota("cpu",
+				corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+				corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")})},
+			brokerResources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			},
+			newPods:  2,
+			expected: []string{"cpu: cpu 4 requested, 3 available"},
+		},
+		{
+			name: "scoped quota is skipped",
+			quotas: []corev1.ResourceQuota{func() corev1.ResourceQuota {
+				q := quota("best-effort",
+					corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")},
+					corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")})
+				q.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+				return q
+			}()},
+			brokerResources: brokerResources,
+			newPods:         1,
+		},
+		{
+			name:            "scale down",
+			quotas:          []corev1.ResourceQuota{compute},
+			brokerResources: brokerResources,
+			newPods:         -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, res.ExceededQuotas(tt.quotas, tt.brokerResources, tt.newPods))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RestartLimiter limits the number of clusters going through a rolling
+// restart at once, so upgrading a fleet of clusters doesn't overwhelm the
+// shared infrastructure. A single instance is shared by all clusters
+// reconciled by the operator. Nil RestartLimiter doesn't limit restarts.
+type RestartLimiter struct {
+	mu  sync.Mutex
+	max int
+	// active holds the clusters that are restarting
+	active map[types.NamespacedName]struct{}
+}
+
+// NewRestartLimiter creates RestartLimiter that allows at most max clusters
+// to restart at once
+func NewRestartLimiter(max int) *RestartLimiter {
+	return &RestartLimiter{
+		max:    max,
+		active: make(map[types.NamespacedName]struct{}),
+	}
+}
+
+// Acquire returns true if the cluster can start its rolling restart. The
+// cluster keeps the slot until it's released.
+func (l *RestartLimiter) Acquire(cluster types.NamespacedName) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.active[cluster]; ok {
+		return true
+	}
+	if len(l.active) >= l.max {
+		return false
+	}
+	l.active[cluster] = struct{}{}
+	return true
+}
+
+// Resume records the rolling restart of the cluster regardless of the
+// limit. It's used for restarts already in progress, e.g. started before
+// the operator was restarted, as interrupting them is worse than exceeding
+// the limit.
+func (l *RestartLimiter) Resume(cluster types.NamespacedName) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active[cluster] = struct{}{}
+}
+
+// Release frees the slot of the cluster once its rolling restart is
+// complete or the cluster is deleted
+func (l *RestartLimiter) Release(cluster types.NamespacedName) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.active, cluster)
+}
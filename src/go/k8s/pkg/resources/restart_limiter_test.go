@@ -0,0 +1,112 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRestartLimiter(t *testing.T) {
+	limiter := res.NewRestartLimiter(1)
+	first := types.NamespacedName{Name: "first", Namespace: "default"}
+	second := types.NamespacedName{Name: "second", Namespace: "default"}
+
+	assert.True(t, limiter.Acquire(first))
+	assert.True(t, limiter.Acquire(first), "cluster keeps its slot")
+	assert.False(t, limiter.Acquire(second))
+
+	limiter.Release(first)
+	assert.True(t, limiter.Acquire(second))
+
+	// restart in progress is not interrupted by the limit
+	limiter.Resume(first)
+	assert.False(t, limiter.Acquire(types.NamespacedName{Name: "third", Namespace: "default"}))
+
+	var unlimited *res.RestartLimiter
+	assert.True(t, unlimited.Acquire(first))
+}
+
+func TestRollingUpdateWaitsForRestartLimit(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	limiter := res.NewRestartLimiter(1)
+
+	newCluster := func(name string) (*redpandav1alpha1.Cluster, *appsv1.StatefulSet) {
+		cluster := pandaCluster()
+		cluster.Name = name
+		// without replicas the rolling update completes right after the pre-pull
+		cluster.Spec.Replicas = pointer.Int32Ptr(0)
+		existingSts := stsFromCluster(cluster)
+		cluster.Spec.Version = "new"
+		cluster.Spec.PrePullOnUpgrade = true
+		return cluster, existingSts
+	}
+	first, firstSts := newCluster("first")
+	second, secondSts := newCluster("second")
+
+	c := fake.NewClientBuilder().WithObjects(first, firstSts, second, secondSts).Build()
+	newSts := func(cluster *redpandav1alpha1.Cluster) *res.StatefulSetResource {
+		return res.NewStatefulSet(
+			c,
+			cluster,
+			scheme.Scheme,
+			"cluster.local",
+			"servicename",
+			types.NamespacedName{Name: "test", Namespace: "test"},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			"",
+			"latest",
+			ctrl.Log.WithName("test")).WithRestartLimiter(limiter)
+	}
+	firstRes := newSts(first)
+	secondRes := newSts(second)
+
+	// the first cluster starts the rolling update and waits for the image
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(firstRes.Ensure(ctx), &requeue))
+	assert.True(t, first.Status.Upgrading)
+
+	// the second cluster waits while the limit is reached
+	require.True(t, errors.As(secondRes.Ensure(ctx), &requeue))
+	assert.False(t, second.Status.Upgrading)
+	var ds appsv1.DaemonSet
+	assert.Error(t, c.Get(ctx, secondRes.ImagePrePullKey(), &ds),
+		"expecting the second cluster not to start the pre-pull")
+
+	// the first cluster completes the rolling update
+	require.NoError(t, c.Get(ctx, firstRes.ImagePrePullKey(), &ds))
+	ds.Status.DesiredNumberScheduled = 3
+	ds.Status.UpdatedNumberScheduled = 3
+	ds.Status.NumberReady = 3
+	require.NoError(t, c.Status().Update(ctx, &ds))
+	require.NoError(t, firstRes.Ensure(ctx))
+	assert.False(t, first.Status.Upgrading)
+
+	// and the second cluster takes its turn
+	require.True(t, errors.As(secondRes.Ensure(ctx), &requeue))
+	assert.True(t, second.Status.Upgrading)
+	require.NoError(t, c.Get(ctx, secondRes.ImagePrePullKey(), &ds))
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"fmt"
+
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	rpkTLSDir            = "/etc/tls/certs"
+	rpkTLSCAVolumeName   = "tlsca"
+	rpkTLSCertVolumeName = "tlscert"
+
+	// rpkClientFlags sets the positional parameters to the TLS and SASL
+	// flags of rpk, see rpkClientTLS and rpkClientSASL. The flags are passed
+	// only when the corresponding variables are set.
+	rpkClientFlags = `set --
+if [ -n "$TLS_TRUSTSTORE" ]; then set -- "$@" --tls-truststore "$TLS_TRUSTSTORE"; fi
+if [ -n "$TLS_CERT" ]; then set -- "$@" --tls-cert "$TLS_CERT" --tls-key "$TLS_KEY"; fi
+if [ -n "$SASL_USER" ]; then set -- "$@" --user "$SASL_USER" --password "$SASL_PASSWORD" --sasl-mechanism "$SASL_MECHANISM"; fi`
+)
+
+// rpkBrokers returns the variable with the address of the internal Kafka
+// API listener rpk connects to
+func rpkBrokers(
+	pandaCluster *redpandav1alpha1.Cluster, serviceFQDN string,
+) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name:  "BROKERS",
+		Value: fmt.Sprintf("%s:%d", serviceFQDN, pandaCluster.InternalKafkaAPIListener().Port),
+	}
+}
+
+// rpkClientTLS returns the mounts, volumes and variables of the certificates
+// rpk connects to the internal Kafka API listener with
+func rpkClientTLS(
+	pandaCluster *redpandav1alpha1.Cluster,
+	nodeCertSecretKey types.NamespacedName,
+	clientCertSecretKey types.NamespacedName,
+) ([]corev1.VolumeMount, []corev1.Volume, []corev1.EnvVar) {
+	listener := pandaCluster.InternalKafkaAPIListener()
+	if !listener.TLS {
+		return nil, nil, nil
+	}
+
+	caDir := rpkTLSDir + "/ca"
+	mounts := []corev1.VolumeMount{{Name: rpkTLSCAVolumeName, MountPath: caDir}}
+	volumes := []corev1.Volume{{
+		Name: rpkTLSCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: nodeCertSecretKey.Name,
+				Items:      []corev1.KeyToPath{{Key: cmetav1.TLSCAKey, Path: cmetav1.TLSCAKey}},
+			},
+		},
+	}}
+	env := []corev1.EnvVar{{Name: "TLS_TRUSTSTORE", Value: caDir + "/" + cmetav1.TLSCAKey}}
+	if !listener.RequireClientAuth {
+		return mounts, volumes, env
+	}
+
+	certDir := rpkTLSDir + "/client"
+	mounts = append(mounts, corev1.VolumeMount{Name: rpkTLSCertVolumeName, MountPath: certDir})
+	volumes = append(volumes, corev1.Volume{
+		Name: rpkTLSCertVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: clientCertSecretKey.Name,
+				Items: []corev1.KeyToPath{
+					{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+					{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+				},
+			},
+		},
+	})
+	env = append(env,
+		corev1.EnvVar{Name: "TLS_CERT", Value: certDir + "/" + corev1.TLSCertKey},
+		corev1.EnvVar{Name: "TLS_KEY", Value: certDir + "/" + corev1.TLSPrivateKeyKey})
+	return mounts, volumes, env
+}
+
+// rpkClientSASL returns the variables with the credentials of the first
+// superuser with password, which rpk authenticates as when SASL is enabled
+func rpkClientSASL(pandaCluster *redpandav1alpha1.Cluster) []corev1.EnvVar {
+	if !pandaCluster.Spec.EnableSASL {
+		return nil
+	}
+	for _, superuser := range pandaCluster.Spec.Superusers {
+		if superuser.PasswordSecretKeyRef == nil {
+			continue
+		}
+		return []corev1.EnvVar{
+			{
+				Name:  "SASL_USER",
+				Value: superuser.Username,
+			},
+			{
+				Name:      "SASL_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: superuser.PasswordSecretKeyRef},
+			},
+			{
+				Name:  "SASL_MECHANISM",
+				Value: string(pandaCluster.SuperuserMechanism(superuser.Username)),
+			},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BrokerPlacement returns the tolerations and the node selector of the
+// brokers, extended with the dedicated nodes taint and label if
+// DedicatedNodes is set
+func BrokerPlacement(
+	pandaCluster *redpandav1alpha1.Cluster,
+) ([]corev1.Toleration, map[string]string) {
+	tolerations := pandaCluster.Spec.Tolerations
+	nodeSelector := pandaCluster.Spec.NodeSelector
+	if !pandaCluster.Spec.DedicatedNodes {
+		return tolerations, nodeSelector
+	}
+
+	dedicated := corev1.Toleration{
+		Key:      redpandav1alpha1.DedicatedNodesKey,
+		Operator: corev1.TolerationOpEqual,
+		Value:    redpandav1alpha1.DedicatedNodesValue,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	tolerations = append([]corev1.Toleration{}, tolerations...)
+	found := false
+	for i := range tolerations {
+		found = found || tolerations[i].MatchToleration(&dedicated)
+	}
+	if !found {
+		tolerations = append(tolerations, dedicated)
+	}
+
+	selector := make(map[string]string, len(nodeSelector)+1)
+	for k, v := range nodeSelector {
+		selector[k] = v
+	}
+	selector[redpandav1alpha1.DedicatedNodesKey] = redpandav1alpha1.DedicatedNodesValue
+	return tolerations, selector
+}
+
+// SchedulableNodes returns the names of the nodes the brokers can be
+// scheduled on: nodes that are not cordoned, match the node selector and
+// have all NoSchedule and NoExecute taints tolerated
+func SchedulableNodes(
+	nodes []corev1.Node,
+	tolerations []corev1.Toleration,
+	nodeSelector map[string]string,
+) []string {
+	var schedulable []string
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Spec.Unschedulable || !matchesNodeSelector(node, nodeSelector) {
+			continue
+		}
+		if !toleratesTaints(node, tolerations) {
+			continue
+		}
+		schedulable = append(schedulable, node.Name)
+	}
+	return schedulable
+}
+
+// ArchitectureMismatch returns the reason the brokers can land on nodes the
+// image is not built for, or an empty string. The architectures are compared
+// only among the nodes SchedulableNodes would return. An empty imageArch
+// stands for a multi-arch image, which is expected to be pinned to one
+// architecture when the nodes are mixed.
+func ArchitectureMismatch(
+	nodes []corev1.Node,
+	tolerations []corev1.Toleration,
+	nodeSelector map[string]string,
+	imageArch string,
+) string {
+	if arch, ok := nodeSelector[corev1.LabelArchStable]; ok {
+		if imageArch != "" && arch != imageArch {
+			return fmt.Sprintf("Node selector targets %s nodes, the image is built for %s", arch, imageArch)
+		}
+		return ""
+	}
+
+	byName := make(map[string]*corev1.Node, len(nodes))
+	for i := range nodes {
+		byName[nodes[i].Name] = &nodes[i]
+	}
+	archSet := map[string]bool{}
+	for _, name := range SchedulableNodes(nodes, tolerations, nodeSelector) {
+		if arch := byName[name].Labels[corev1.LabelArchStable]; arch != "" {
+			archSet[arch] = true
+		}
+	}
+	archs := make([]string, 0, len(archSet))
+	for arch := range archSet {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+
+	switch {
+	case imageArch != "" && len(archs) > 0 && !archSet[imageArch]:
+		return fmt.Sprintf("The image is built for %s, the brokers can be scheduled only on %s nodes", imageArch, strings.Join(archs, ", "))
+	case imageArch != "" && len(archs) > 1:
+		return fmt.Sprintf("The image is built for %s, the brokers can be scheduled on %s nodes without %s node selector", imageArch, strings.Join(archs, ", "), corev1.LabelArchStable)
+	case len(archs) > 1:
+		return fmt.Sprintf("The brokers can be scheduled on %s nodes without %s node selector", strings.Join(archs, ", "), corev1.LabelArchStable)
+	}
+	return ""
+}
+
+func matchesNodeSelector(node *corev1.Node, nodeSelector map[string]string) bool {
+	for k, v := range nodeSelector {
+		if node.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// toleratesTaints returns true if the tolerations cover all taints of the
+// node that keep pods off it, PreferNoSchedule taints are ignored
+func toleratesTaints(node *corev1.Node, tolerations []corev1.Toleration) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			tolerated = tolerated || tolerations[j].ToleratesTaint(taint)
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSchedulableNodes(t *testing.T) {
+	dedicatedTaint := corev1.Taint{
+		Key:    redpandav1alpha1.DedicatedNodesKey,
+		Value:  redpandav1alpha1.DedicatedNodesValue,
+		Effect: corev1.TaintEffectNoSchedule,
+	}
+	gpuTaint := corev1.Taint{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoExecute}
+	preferTaint := corev1.Taint{Key: "spot", Value: "true", Effect: corev1.TaintEffectPreferNoSchedule}
+
+	node := func(name string, nodeLabels map[string]string, unschedulable bool, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable, Taints: taints},
+		}
+	}
+	dedicatedLabels := map[string]string{redpandav1alpha1.DedicatedNodesKey: redpandav1alpha1.DedicatedNodesValue}
+
+	tests := []struct {
+		name     string
+		nodes    []corev1.Node
+		spec     redpandav1alpha1.ClusterSpec
+		expected []string
+	}{
+		{
+			name:     "untainted nodes",
+			nodes:    []corev1.Node{node("a", nil, false), node("b", nil, false, preferTaint)},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:  "all nodes tainted",
+			nodes: []corev1.Node{node("a", nil, false, gpuTaint), node("b", dedicatedLabels, false, dedicatedTaint)},
+		},
+		{
+			name:  "toleration of another taint",
+			nodes: []corev1.Node{node("a", nil, false, gpuTaint)},
+			spec: redpandav1alpha1.ClusterSpec{
+				Tolerations: []corev1.Toleration{
+					{Key: "gpu", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		},
+		{
+			name:  "matching toleration",
+			nodes: []corev1.Node{node("a", nil, false, gpuTaint), node("b", nil, false, dedicatedTaint)},
+			spec: redpandav1alpha1.ClusterSpec{
+				Tolerations: []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+			},
+			expected: []string{"a"},
+		},
+		{
+			name: "dedicated nodes",
+			nodes: []corev1.Node{
+				node("a", nil, false),
+				node("b", dedicatedLabels, false, dedicatedTaint),
+				node("c", dedicatedLabels, true, dedicatedTaint),
+			},
+			spec:     redpandav1alpha1.ClusterSpec{DedicatedNodes: true},
+			expected: []string{"b"},
+		},
+		{
+			name:  "tolerated taint outside of node selector",
+			nodes: []corev1.Node{node("a", map[string]string{"zone": "a"}, false, gpuTaint)},
+			spec: redpandav1alpha1.ClusterSpec{
+				NodeSelector: map[string]string{"zone": "b"},
+				Tolerations:  []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &redpandav1alpha1.Cluster{Spec: tt.spec}
+			tolerations, nodeSelector := res.BrokerPlacement(cluster)
+			assert.Equal(t, tt.expected, res.SchedulableNodes(tt.nodes, tolerations, nodeSelector))
+		})
+	}
+}
+
+func TestArchitectureMismatch(t *testing.T) {
+	node := func(name, arch string, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelArchStable: arch}},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+	armTaint := corev1.Taint{Key: "arm", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	amd64 := []corev1.Node{node("a", "amd64"), node("b", "amd64")}
+	mixed := []corev1.Node{node("a", "amd64"), node("b", "arm64")}
+
+	tests := []struct {
+		name          string
+		nodes         []corev1.Node
+		version       string
+		nodeSelector  map[string]string
+		expectedMatch bool
+	}{
+		{"multi-arch image on single arch nodes", amd64, "v21.7.4", nil, true},
+		{"matching image", amd64, "v21.7.4-amd64", nil, true},
+		{"image of other architecture", amd64, "v21.7.4-arm64", nil, false},
+		{"multi-arch image on mixed nodes", mixed, "v21.7.4", nil, false},
+		{"single arch image on mixed nodes", mixed, "v21.7.4-amd64", nil, false},
+		{"mixed nodes with arch selector", mixed, "v21.7.4-arm64", map[string]string{corev1.LabelArchStable: "arm64"}, true},
+		{"arch selector of other architecture", mixed, "v21.7.4-arm64", map[string]string{corev1.LabelArchStable: "amd64"}, false},
+		{"nodes of other architecture are not schedulable", []corev1.Node{node("a", "amd64"), node("b", "arm64", armTaint)}, "v21.7.4-amd64", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &redpandav1alpha1.Cluster{Spec: redpandav1alpha1.ClusterSpec{
+				Version:      tt.version,
+				NodeSelector: tt.nodeSelector,
+			}}
+			tolerations, nodeSelector := res.BrokerPlacement(cluster)
+			mismatch := res.ArchitectureMismatch(tt.nodes, tolerations, nodeSelector, cluster.ImageArchitecture())
+			if tt.expectedMatch {
+				assert.Empty(t, mismatch)
+			} else {
+				assert.NotEmpty(t, mismatch)
+			}
+		})
+	}
+}
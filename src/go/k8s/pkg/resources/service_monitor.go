@@ -0,0 +1,246 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const metricsPath = "/metrics"
+
+// ServiceMonitorGVK is the kind of prometheus-operator ServiceMonitor. The
+// operator doesn't depend on prometheus-operator types, as the CRD is
+// optional.
+var ServiceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+var _ Resource = &ServiceMonitorResource{}
+
+// ServiceMonitorResource is part of the reconciliation of redpanda.vectorized.io CRD
+// creating the prometheus-operator ServiceMonitor scraping the brokers
+type ServiceMonitorResource struct {
+	k8sclient.Client
+	scheme       *runtime.Scheme
+	pandaCluster *redpandav1alpha1.Cluster
+	restMapper   meta.RESTMapper
+	serviceFQDN  string
+	// nodeCertSecretKey is the Admin API node certificate verified by the
+	// scraper
+	nodeCertSecretKey types.NamespacedName
+	// clientCertSecretKey is the certificate presented by the scraper, empty
+	// if the Admin API doesn't require client authentication
+	clientCertSecretKey types.NamespacedName
+	logger              logr.Logger
+}
+
+// NewServiceMonitor creates ServiceMonitorResource. The ServiceMonitor is
+// skipped if the restMapper doesn't know the kind.
+func NewServiceMonitor(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	scheme *runtime.Scheme,
+	restMapper meta.RESTMapper,
+	serviceFQDN string,
+	nodeCertSecretKey types.NamespacedName,
+	clientCertSecretKey types.NamespacedName,
+	logger logr.Logger,
+) *ServiceMonitorResource {
+	return &ServiceMonitorResource{
+		client,
+		scheme,
+		pandaCluster,
+		restMapper,
+		serviceFQDN,
+		nodeCertSecretKey,
+		clientCertSecretKey,
+		logger.WithValues("Kind", ServiceMonitorGVK.Kind),
+	}
+}
+
+// Ensure will manage the ServiceMonitor of the brokers. The ServiceMonitor is
+// removed when it's disabled.
+func (r *ServiceMonitorResource) Ensure(ctx context.Context) error {
+	installed, err := r.installed()
+	if err != nil || !installed {
+		return err
+	}
+	config := r.pandaCluster.Spec.ServiceMonitor
+	if config == nil || !config.Enabled {
+		return r.cleanup(ctx)
+	}
+
+	obj, err := r.obj()
+	if err != nil {
+		return fmt.Errorf("unable to construct object: %w", err)
+	}
+	created, err := CreateIfNotExists(ctx, r, obj, r.logger)
+	if err != nil || created {
+		return err
+	}
+	current := r.empty()
+	err = r.Get(ctx, r.Key(), current)
+	if err != nil {
+		return fmt.Errorf("error while fetching ServiceMonitor resource: %w", err)
+	}
+	return Update(ctx, current, obj, r.Client, r.logger)
+}
+
+// installed returns true if the ServiceMonitor CRD is installed
+func (r *ServiceMonitorResource) installed() (bool, error) {
+	if r.restMapper == nil {
+		return false, nil
+	}
+	_, err := r.restMapper.RESTMapping(ServiceMonitorGVK.GroupKind(), ServiceMonitorGVK.Version)
+	if meta.IsNoMatchError(err) {
+		if config := r.pandaCluster.Spec.ServiceMonitor; config != nil && config.Enabled {
+			r.logger.Info("ServiceMonitor CRD is not installed, skipping")
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to find ServiceMonitor kind: %w", err)
+	}
+	return true, nil
+}
+
+func (r *ServiceMonitorResource) cleanup(ctx context.Context) error {
+	current := r.empty()
+	err := r.Get(ctx, r.Key(), current)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while fetching ServiceMonitor resource: %w", err)
+	}
+	r.logger.Info("Removing ServiceMonitor", "name", current.GetName())
+	if err := r.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete ServiceMonitor: %w", err)
+	}
+	return nil
+}
+
+func (r *ServiceMonitorResource) empty() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(ServiceMonitorGVK)
+	return u
+}
+
+// obj returns resource managed client.Object
+func (r *ServiceMonitorResource) obj() (k8sclient.Object, error) {
+	objLabels := labels.ForCluster(r.pandaCluster).AsSet()
+	for k, v := range r.pandaCluster.Spec.ServiceMonitor.Labels {
+		objLabels[k] = v
+	}
+
+	selector := map[string]interface{}{}
+	for k, v := range labels.ForCluster(r.pandaCluster).AsAPISelector().MatchLabels {
+		selector[k] = v
+	}
+	port := AdminPortName
+	if r.pandaCluster.Spec.SeparateMetricsService {
+		selector[metricsServiceLabel] = "true"
+		port = MetricsPortName
+	} else {
+		selector[headlessServiceLabel] = "true"
+	}
+
+	endpoint := map[string]interface{}{
+		"port":   port,
+		"path":   metricsPath,
+		"scheme": "http",
+		"relabelings": []interface{}{
+			map[string]interface{}{
+				"sourceLabels": []interface{}{"__meta_kubernetes_pod_name"},
+				"targetLabel":  "pod",
+			},
+			map[string]interface{}{
+				"targetLabel": "redpanda_cluster",
+				"replacement": r.pandaCluster.Name,
+			},
+		},
+	}
+	if r.pandaCluster.Spec.Configuration.TLS.Metrics.Enabled {
+		endpoint["scheme"] = "https"
+		endpoint["tlsConfig"] = r.tlsConfig()
+	}
+
+	u := r.empty()
+	u.SetNamespace(r.Key().Namespace)
+	u.SetName(r.Key().Name)
+	u.SetLabels(objLabels)
+	u.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": selector,
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{r.pandaCluster.Namespace},
+		},
+		"endpoints": []interface{}{endpoint},
+	}
+
+	err := controllerutil.SetControllerReference(r.pandaCluster, u, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// tlsConfig verifies the Admin API node certificate and presents the client
+// certificate if there is one. The wildcard node certificate covers the DNS
+// names of all brokers, so the name of the first one is verified.
+func (r *ServiceMonitorResource) tlsConfig() map[string]interface{} {
+	secretKeySelector := func(name, key string) map[string]interface{} {
+		return map[string]interface{}{
+			"secret": map[string]interface{}{
+				"name": name,
+				"key":  key,
+			},
+		}
+	}
+
+	tlsConfig := map[string]interface{}{
+		"ca":         secretKeySelector(r.nodeCertSecretKey.Name, cmetav1.TLSCAKey),
+		"serverName": fmt.Sprintf("%s-0.%s", r.pandaCluster.Name, strings.TrimSuffix(r.serviceFQDN, ".")),
+	}
+	if r.clientCertSecretKey.Name != "" {
+		tlsConfig["cert"] = secretKeySelector(r.clientCertSecretKey.Name, corev1.TLSCertKey)
+		tlsConfig["keySecret"] = map[string]interface{}{
+			"name": r.clientCertSecretKey.Name,
+			"key":  corev1.TLSPrivateKeyKey,
+		}
+	}
+	return tlsConfig
+}
+
+// Key returns namespace/name object that is used to identify object.
+// For reference please visit types.NamespacedName docs in k8s.io/apimachinery
+func (r *ServiceMonitorResource) Key() types.NamespacedName {
+	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+}
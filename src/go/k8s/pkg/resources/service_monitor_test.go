@@ -0,0 +1,179 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func serviceMonitorScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(res.ServiceMonitorGVK, &unstructured.Unstructured{})
+	listGVK := res.ServiceMonitorGVK
+	listGVK.Kind += "List"
+	scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func serviceMonitorMapper(installed bool) meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	if installed {
+		mapper.Add(res.ServiceMonitorGVK, meta.RESTScopeNamespace)
+	}
+	return mapper
+}
+
+func TestServiceMonitor(t *testing.T) {
+	scheme := serviceMonitorScheme(t)
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.ServiceMonitor = &redpandav1alpha1.ServiceMonitorConfig{
+		Enabled: true,
+		Labels:  map[string]string{"release": "prometheus"},
+	}
+	cluster.Spec.Configuration.TLS.AdminAPI = redpandav1alpha1.AdminAPITLS{Enabled: true, RequireClientAuth: true}
+	cluster.Spec.Configuration.TLS.Metrics.Enabled = true
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	sm := res.NewServiceMonitor(c, cluster, scheme, serviceMonitorMapper(true),
+		"cluster.default.svc.cluster.local.",
+		types.NamespacedName{Name: "cluster-admin-api-node", Namespace: cluster.Namespace},
+		types.NamespacedName{Name: "cluster-metrics-client", Namespace: cluster.Namespace},
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sm.Ensure(ctx))
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(res.ServiceMonitorGVK)
+	require.NoError(t, c.Get(ctx, sm.Key(), actual))
+	assert.Equal(t, "prometheus", actual.GetLabels()["release"])
+	owner := metav1.GetControllerOf(actual)
+	require.NotNil(t, owner)
+	assert.Equal(t, cluster.UID, owner.UID)
+
+	matchLabels, _, err := unstructured.NestedStringMap(actual.Object, "spec", "selector", "matchLabels")
+	require.NoError(t, err)
+	assert.Equal(t, "true", matchLabels["redpanda.vectorized.io/headless"])
+
+	endpoints, _, err := unstructured.NestedSlice(actual.Object, "spec", "endpoints")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	endpoint := endpoints[0].(map[string]interface{})
+	assert.Equal(t, res.AdminPortName, endpoint["port"])
+	assert.Equal(t, "/metrics", endpoint["path"])
+	assert.Equal(t, "https", endpoint["scheme"])
+	relabelings, _, err := unstructured.NestedSlice(endpoint, "relabelings")
+	require.NoError(t, err)
+	assert.NotEmpty(t, relabelings)
+
+	tlsConfig, _, err := unstructured.NestedMap(endpoint, "tlsConfig")
+	require.NoError(t, err)
+	caName, _, _ := unstructured.NestedString(tlsConfig, "ca", "secret", "name")
+	assert.Equal(t, "cluster-admin-api-node", caName)
+	assert.Equal(t, "cluster-0.cluster.default.svc.cluster.local", tlsConfig["serverName"])
+	certName, _, _ := unstructured.NestedString(tlsConfig, "cert", "secret", "name")
+	assert.Equal(t, "cluster-metrics-client", certName)
+	keyName, _, _ := unstructured.NestedString(tlsConfig, "keySecret", "name")
+	assert.Equal(t, "cluster-metrics-client", keyName)
+
+	// disabled ServiceMonitor is removed
+	cluster.Spec.ServiceMonitor.Enabled = false
+	require.NoError(t, sm.Ensure(ctx))
+	err = c.Get(ctx, sm.Key(), actual)
+	assert.True(t, apierrors.IsNotFound(err), "expecting ServiceMonitor to be removed, got %v", err)
+}
+
+func TestServiceMonitorWithoutTLS(t *testing.T) {
+	scheme := serviceMonitorScheme(t)
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.ServiceMonitor = &redpandav1alpha1.ServiceMonitorConfig{Enabled: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	sm := res.NewServiceMonitor(c, cluster, scheme, serviceMonitorMapper(true),
+		"cluster.default.svc.cluster.local.", types.NamespacedName{}, types.NamespacedName{},
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sm.Ensure(ctx))
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(res.ServiceMonitorGVK)
+	require.NoError(t, c.Get(ctx, sm.Key(), actual))
+	endpoints, _, err := unstructured.NestedSlice(actual.Object, "spec", "endpoints")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	endpoint := endpoints[0].(map[string]interface{})
+	assert.Equal(t, "http", endpoint["scheme"])
+	assert.NotContains(t, endpoint, "tlsConfig")
+}
+
+func TestServiceMonitorSeparateMetricsService(t *testing.T) {
+	scheme := serviceMonitorScheme(t)
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.ServiceMonitor = &redpandav1alpha1.ServiceMonitorConfig{Enabled: true}
+	cluster.Spec.SeparateMetricsService = true
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	sm := res.NewServiceMonitor(c, cluster, scheme, serviceMonitorMapper(true),
+		"cluster.default.svc.cluster.local.", types.NamespacedName{}, types.NamespacedName{},
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sm.Ensure(ctx))
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(res.ServiceMonitorGVK)
+	require.NoError(t, c.Get(ctx, sm.Key(), actual))
+	matchLabels, _, err := unstructured.NestedStringMap(actual.Object, "spec", "selector", "matchLabels")
+	require.NoError(t, err)
+	assert.Equal(t, "true", matchLabels["redpanda.vectorized.io/metrics"])
+	assert.NotContains(t, matchLabels, "redpanda.vectorized.io/headless")
+	endpoints, _, err := unstructured.NestedSlice(actual.Object, "spec", "endpoints")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, res.MetricsPortName, endpoints[0].(map[string]interface{})["port"])
+}
+
+func TestServiceMonitorCRDNotInstalled(t *testing.T) {
+	scheme := serviceMonitorScheme(t)
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.ServiceMonitor = &redpandav1alpha1.ServiceMonitorConfig{Enabled: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	sm := res.NewServiceMonitor(c, cluster, scheme, serviceMonitorMapper(false),
+		"cluster.default.svc.cluster.local.", types.NamespacedName{}, types.NamespacedName{},
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sm.Ensure(ctx))
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(res.ServiceMonitorGVK)
+	err := c.Get(ctx, sm.Key(), actual)
+	assert.True(t, apierrors.IsNotFound(err), "expecting no ServiceMonitor, got %v", err)
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	cmetav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
@@ -36,13 +37,21 @@ var _ Resource = &StatefulSetResource{}
 var errNodePortMissing = errors.New("the node port is missing from the service")
 
 const (
-	redpandaContainerName      = "redpanda"
 	configuratorContainerName  = "redpanda-configurator"
 	configuratorContainerImage = "vectorized/configurator"
+	datadirOwnerContainerName  = "redpanda-datadir-owner"
+	dnsWaitContainerName       = "redpanda-dns-wait"
+	startupDelayContainerName  = "redpanda-startup-delay"
 
+	// RedpandaContainerName is the name of the container running the broker
+	RedpandaContainerName = "redpanda"
+	// ClusterDomainCheckContainerName is the name of the init container
+	// verifying that the cluster domain resolves
+	ClusterDomainCheckContainerName = "redpanda-cluster-domain-check"
+
+	// default IDs of the redpanda user in the Redpanda image
 	userID  = 101
 	groupID = 101
-	fsGroup = 101
 
 	configDestinationDir = "/etc/redpanda"
 	configSourceDir      = "/mnt/operator"
@@ -50,6 +59,17 @@ const (
 
 	datadirName            = "datadir"
 	defaultDatadirCapacity = "100Gi"
+
+	tmpDirName = "tmp-dir"
+	tmpDir     = "/tmp"
+
+	// redpandaEntrypoint is the entrypoint of the Redpanda image that is
+	// started explicitly when the container command is overridden
+	redpandaEntrypoint = "/entrypoint.sh"
+
+	// dnsWaitIntervalSeconds is the pause between the lookups of the
+	// external name of the broker
+	dnsWaitIntervalSeconds = 5
 )
 
 // StatefulSetResource is part of the reconciliation of redpanda.vectorized.io CRD
@@ -62,12 +82,21 @@ type StatefulSetResource struct {
 	serviceName                 string
 	nodePortName                types.NamespacedName
 	nodePortSvc                 corev1.Service
+	adminNodePortName           types.NamespacedName
+	adminNodePortSvc            corev1.Service
 	redpandaCertSecretKey       types.NamespacedName
 	internalClientCertSecretKey types.NamespacedName
 	adminCertSecretKey          types.NamespacedName
 	adminAPINodeCertSecretKey   types.NamespacedName
+	externalCertSecretKey       types.NamespacedName
+	brokerSet                   *BrokerStatefulSet
 	serviceAccountName          string
 	configuratorTag             string
+	pauseImage                  string
+	decommissionerFactory       BrokerDecommissionerFactory
+	restartLimiter              *RestartLimiter
+	certificateHash             string
+	configHash                  string
 	logger                      logr.Logger
 
 	LastObservedState *appsv1.StatefulSet
@@ -90,29 +119,110 @@ func NewStatefulSet(
 	logger logr.Logger,
 ) *StatefulSetResource {
 	return &StatefulSetResource{
-		client,
-		scheme,
-		pandaCluster,
-		serviceFQDN,
-		serviceName,
-		nodePortName,
-		corev1.Service{},
-		redpandaCertSecretKey,
-		internalClientCertSecretKey,
-		adminCertSecretKey,
-		adminAPINodeCertSecretKey,
-		serviceAccountName,
-		configuratorTag,
-		logger.WithValues("Kind", statefulSetKind()),
-		nil,
+		Client:                      client,
+		scheme:                      scheme,
+		pandaCluster:                pandaCluster,
+		serviceFQDN:                 serviceFQDN,
+		serviceName:                 serviceName,
+		nodePortName:                nodePortName,
+		redpandaCertSecretKey:       redpandaCertSecretKey,
+		internalClientCertSecretKey: internalClientCertSecretKey,
+		adminCertSecretKey:          adminCertSecretKey,
+		adminAPINodeCertSecretKey:   adminAPINodeCertSecretKey,
+		serviceAccountName:          serviceAccountName,
+		configuratorTag:             configuratorTag,
+		pauseImage:                  DefaultPauseImage,
+		logger:                      logger.WithValues("Kind", statefulSetKind()),
+	}
+}
+
+// WithExternalCert sets the Secret with the certificate of the external
+// Kafka API listener. It's mounted only if the cluster uses separate
+// external certificate.
+func (r *StatefulSetResource) WithExternalCert(
+	externalCertSecretKey types.NamespacedName,
+) *StatefulSetResource {
+	r.externalCertSecretKey = externalCertSecretKey
+	return r
+}
+
+// WithAdminNodePort sets the Service exposing the Admin API without the
+// Kafka API. Its node port is published only if the cluster uses separate
+// external Admin API.
+func (r *StatefulSetResource) WithAdminNodePort(
+	adminNodePortName types.NamespacedName,
+) *StatefulSetResource {
+	r.adminNodePortName = adminNodePortName
+	return r
+}
+
+// WithBrokerStatefulSet sets the zone StatefulSet managed by the resource,
+// the only StatefulSet of the cluster is managed otherwise
+func (r *StatefulSetResource) WithBrokerStatefulSet(
+	set BrokerStatefulSet,
+) *StatefulSetResource {
+	r.brokerSet = &set
+	return r
+}
+
+// brokerStatefulSet returns the StatefulSet managed by the resource
+func (r *StatefulSetResource) brokerStatefulSet() BrokerStatefulSet {
+	if r.brokerSet != nil {
+		return *r.brokerSet
+	}
+	return BrokerStatefulSets(r.pandaCluster)[0]
+}
+
+// lastBrokerStatefulSet returns true if the resource manages the last of
+// the StatefulSets of the cluster
+func (r *StatefulSetResource) lastBrokerStatefulSet() bool {
+	sets := BrokerStatefulSets(r.pandaCluster)
+	return r.brokerStatefulSet().Name == sets[len(sets)-1].Name
+}
+
+// WithPauseImage sets the image keeping the Pods of the image pre-pull
+// DaemonSet running, e.g. to pull it from a private registry
+func (r *StatefulSetResource) WithPauseImage(
+	pauseImage string,
+) *StatefulSetResource {
+	if pauseImage != "" {
+		r.pauseImage = pauseImage
+	}
+	return r
+}
+
+// WithRestartLimiter sets the limiter shared by all clusters that allows only
+// a limited number of clusters to run the rolling update at once
+func (r *StatefulSetResource) WithRestartLimiter(
+	limiter *RestartLimiter,
+) *StatefulSetResource {
+	r.restartLimiter = limiter
+	return r
+}
+
+// podAnnotations returns annotations of the Pod template, nil if there are
+// neither certificates nor configuration to track
+func (r *StatefulSetResource) podAnnotations() map[string]string {
+	annotations := make(map[string]string)
+	if r.certificateHash != "" {
+		annotations[CertificateHashAnnotation] = r.certificateHash
 	}
+	if r.configHash != "" {
+		annotations[ConfigHashAnnotation] = r.configHash
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
 }
 
 // Ensure will manage kubernetes v1.StatefulSet for redpanda.vectorized.io custom resource
 func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 	var sts appsv1.StatefulSet
 
-	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
+	// brokers exposed by their own Services don't use the shared node port
+	if r.pandaCluster.Spec.ExternalConnectivity.Enabled &&
+		!r.pandaCluster.Spec.ExternalConnectivity.PerBrokerServices() {
 		err := r.Get(ctx, r.nodePortName, &r.nodePortSvc)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve node port service %s: %w", r.nodePortName, err)
@@ -122,6 +232,30 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 			return fmt.Errorf("node port service %s: %w", r.nodePortName, errNodePortMissing)
 		}
 	}
+	if r.pandaCluster.SeparateExternalAdmin() {
+		err := r.Get(ctx, r.adminNodePortName, &r.adminNodePortSvc)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve admin node port service %s: %w", r.adminNodePortName, err)
+		}
+
+		if len(r.adminNodePortSvc.Spec.Ports) != 1 || r.adminNodePortSvc.Spec.Ports[0].NodePort == 0 {
+			return fmt.Errorf("admin node port service %s: %w", r.adminNodePortName, errNodePortMissing)
+		}
+	}
+
+	// renewed node certificates are loaded by restarting the brokers
+	certificateHash, err := CertificateHash(ctx, r,
+		r.redpandaCertSecretKey, r.adminAPINodeCertSecretKey, r.externalCertSecretKey)
+	if err != nil {
+		return err
+	}
+	r.certificateHash = certificateHash
+
+	configHash, err := ConfigHash(ctx, r, ConfigMapKey(r.pandaCluster))
+	if err != nil {
+		return err
+	}
+	r.configHash = configHash
 
 	obj, err := r.obj()
 	if err != nil {
@@ -142,7 +276,7 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 	}
 	r.LastObservedState = &sts
 
-	partitioned, err := r.shouldUsePartitionedUpdate(&sts)
+	partitioned, err := r.shouldUsePartitionedUpdate(ctx, &sts)
 	if err != nil {
 		return err
 	}
@@ -152,19 +286,57 @@ func (r *StatefulSetResource) Ensure(ctx context.Context) error {
 			return fmt.Errorf("failed to run partitioned update: %w", err)
 		}
 	} else {
+		replicas, err := r.drainedReplicas(ctx, &sts)
+		if err != nil {
+			return err
+		}
 		modified, err := r.obj()
 		if err != nil {
 			return err
 		}
+		if replicas != nil {
+			modified.(*appsv1.StatefulSet).Spec.Replicas = replicas
+		}
+		keepVolumeClaimTemplates(modified.(*appsv1.StatefulSet), &sts)
+		r.keepVPAResources(modified.(*appsv1.StatefulSet), &sts)
 		err = Update(ctx, &sts, modified, r.Client, r.logger)
 		if err != nil {
 			return err
 		}
+		if replicas != nil {
+			return r.updateDecommissioningStatus(ctx, nil)
+		}
 	}
 
 	return nil
 }
 
+// keepVolumeClaimTemplates copies the volume claim templates of the current
+// StatefulSet, as they are immutable. The claims are expanded by
+// VolumeExpander instead.
+func keepVolumeClaimTemplates(modified, current *appsv1.StatefulSet) {
+	modified.Spec.VolumeClaimTemplates = current.Spec.VolumeClaimTemplates
+}
+
+// keepVPAResources copies the resources of the redpanda container of the
+// current StatefulSet when they are managed by the VerticalPodAutoscaler,
+// so the operator doesn't revert the applied recommendations
+func (r *StatefulSetResource) keepVPAResources(modified, current *appsv1.StatefulSet) {
+	if !r.pandaCluster.ResourcesManagedByVPA() {
+		return
+	}
+	currentContainer, err := findContainer(current.Spec.Template.Spec.Containers, RedpandaContainerName)
+	if err != nil {
+		return
+	}
+	containers := modified.Spec.Template.Spec.Containers
+	for i := range containers {
+		if containers[i].Name == RedpandaContainerName {
+			containers[i].Resources = *currentContainer.Resources.DeepCopy()
+		}
+	}
+}
+
 func preparePVCResource(
 	name, namespace string,
 	storage redpandav1alpha1.StorageSpec,
@@ -193,6 +365,10 @@ func preparePVCResource(
 		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = storage.Capacity
 	}
 
+	if len(storage.AccessModes) > 0 {
+		pvc.Spec.AccessModes = storage.AccessModes
+	}
+
 	if len(storage.StorageClassName) > 0 {
 		pvc.Spec.StorageClassName = &storage.StorageClassName
 	}
@@ -207,8 +383,21 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 	var clusterLabels = labels.ForCluster(r.pandaCluster)
 
 	pvc := preparePVCResource(datadirName, r.pandaCluster.Namespace, r.pandaCluster.Spec.Storage, clusterLabels)
-	tolerations := r.pandaCluster.Spec.Tolerations
-	nodeSelector := r.pandaCluster.Spec.NodeSelector
+	tolerations, nodeSelector := BrokerPlacement(r.pandaCluster)
+
+	set := r.brokerStatefulSet()
+	replicas := r.pandaCluster.Spec.Replicas
+	selector := clusterLabels.AsAPISelector()
+	if set.Zone != "" {
+		replicas = &set.Replicas
+		selector.MatchLabels[ZoneLabel] = set.Zone
+		zoneSelector := make(map[string]string, len(nodeSelector)+1)
+		for k, v := range nodeSelector {
+			zoneSelector[k] = v
+		}
+		zoneSelector[corev1.LabelZoneFailureDomainStable] = set.Zone
+		nodeSelector = zoneSelector
+	}
 
 	ss := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -221,23 +410,25 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 			APIVersion: "apps/v1",
 		},
 		Spec: appsv1.StatefulSetSpec{
-			Replicas:            r.pandaCluster.Spec.Replicas,
+			Replicas:            replicas,
 			PodManagementPolicy: appsv1.ParallelPodManagement,
-			Selector:            clusterLabels.AsAPISelector(),
+			Selector:            selector,
 			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
 				Type: appsv1.RollingUpdateStatefulSetStrategyType,
 			},
 			ServiceName: r.pandaCluster.Name,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      r.pandaCluster.Name,
-					Namespace: r.pandaCluster.Namespace,
-					Labels:    clusterLabels.AsAPISelector().MatchLabels,
+					Name:        r.pandaCluster.Name,
+					Namespace:   r.pandaCluster.Namespace,
+					Labels:      selector.MatchLabels,
+					Annotations: r.podAnnotations(),
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: r.getServiceAccountName(),
 					SecurityContext: &corev1.PodSecurityContext{
-						FSGroup: pointer.Int64Ptr(fsGroup),
+						FSGroup:        pointer.Int64Ptr(r.runAsGroup()),
+						SeccompProfile: r.pandaCluster.Spec.SeccompProfile,
 					},
 					Volumes: append([]corev1.Volume{
 						{
@@ -265,13 +456,13 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 								EmptyDir: &corev1.EmptyDirVolumeSource{},
 							},
 						},
-					}, r.secretVolumes()...),
-					InitContainers: []corev1.Container{
+					}, append(append(r.secretVolumes(), r.readOnlyRootVolumes()...), cloudStorageTokenVolumes(r.pandaCluster)...)...),
+					InitContainers: append(append(r.datadirOwnerInitContainers(), []corev1.Container{
 						{
 							Name:            configuratorContainerName,
 							Image:           configuratorContainerImage + ":" + r.configuratorTag,
 							ImagePullPolicy: corev1.PullIfNotPresent,
-							Env: []corev1.EnvVar{
+							Env: append([]corev1.EnvVar{
 								{
 									Name:  "SERVICE_FQDN",
 									Value: r.serviceFQDN,
@@ -305,14 +496,27 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 									Name:  "EXTERNAL_CONNECTIVITY_SUBDOMAIN",
 									Value: r.pandaCluster.Spec.ExternalConnectivity.Subdomain,
 								},
+								{
+									Name:  "EXTERNAL_CONNECTIVITY_TYPE",
+									Value: string(r.pandaCluster.Spec.ExternalConnectivity.Type),
+								},
 								{
 									Name:  "HOST_PORT",
 									Value: r.getNodePort("kafka"),
 								},
-							},
+								{
+									Name: "POD_NAMESPACE",
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											APIVersion: "v1",
+											FieldPath:  "metadata.namespace",
+										},
+									},
+								},
+							}, r.nodeIDOffsetEnv()...),
 							SecurityContext: &corev1.SecurityContext{
-								RunAsUser:  pointer.Int64Ptr(userID),
-								RunAsGroup: pointer.Int64Ptr(groupID),
+								RunAsUser:  pointer.Int64Ptr(r.runAsUser()),
+								RunAsGroup: pointer.Int64Ptr(r.runAsGroup()),
 							},
 							VolumeMounts: []corev1.VolumeMount{
 								{
@@ -325,11 +529,12 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 								},
 							},
 						},
-					},
+					}...), append(append(r.clusterDomainCheckInitContainers(), r.dnsWaitInitContainers()...), r.startupDelayInitContainers()...)...),
 					Containers: []corev1.Container{
 						{
-							Name:  redpandaContainerName,
-							Image: r.pandaCluster.FullImageName(),
+							Name:    RedpandaContainerName,
+							Image:   r.pandaCluster.FullImageName(),
+							Command: r.redpandaCommand(),
 							Args: []string{
 								"redpanda",
 								"start",
@@ -338,9 +543,9 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 								// sometimes a little bit of memory is consumed by other processes than seastar
 								"--reserve-memory " + redpandav1alpha1.ReserveMemoryString,
 								r.portsConfiguration(),
-								"--default-log-level=debug",
+								"--default-log-level=" + r.logLevel(),
 							},
-							Env: []corev1.EnvVar{
+							Env: append([]corev1.EnvVar{
 								{
 									Name:  "REDPANDA_ENVIRONMENT",
 									Value: "kubernetes",
@@ -372,17 +577,15 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 										},
 									},
 								},
-							},
+							}, cloudStorageTokenEnv(r.pandaCluster)...),
 							Ports: append([]corev1.ContainerPort{
 								{
 									Name:          "rpc",
 									ContainerPort: int32(r.pandaCluster.Spec.Configuration.RPCServer.Port),
 								},
 							}, r.getPorts()...),
-							Resources: corev1.ResourceRequirements{
-								Limits:   r.pandaCluster.Spec.Resources.Limits,
-								Requests: r.pandaCluster.Spec.Resources.Requests,
-							},
+							Resources:     r.pandaCluster.ContainerResources(),
+							LivenessProbe: r.livenessProbe(),
 							VolumeMounts: append([]corev1.VolumeMount{
 								{
 									Name:      datadirName,
@@ -392,11 +595,14 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 									Name:      "config-dir",
 									MountPath: configDestinationDir,
 								},
-							}, r.secretVolumeMounts()...),
+							}, append(append(r.secretVolumeMounts(), r.readOnlyRootVolumeMounts()...), cloudStorageTokenVolumeMounts(r.pandaCluster)...)...),
+							SecurityContext: r.redpandaSecurityContext(),
 						},
 					},
 					Tolerations:  tolerations,
 					NodeSelector: nodeSelector,
+					HostNetwork:  r.pandaCluster.Spec.HostNetwork,
+					DNSPolicy:    r.dnsPolicy(),
 					Affinity: &corev1.Affinity{
 						PodAntiAffinity: &corev1.PodAntiAffinity{
 							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
@@ -432,6 +638,7 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 			},
 		},
 	}
+	r.applyExtras(&ss.Spec.Template.Spec)
 
 	err := controllerutil.SetControllerReference(r.pandaCluster, ss, r.scheme)
 	if err != nil {
@@ -441,6 +648,296 @@ func (r *StatefulSetResource) obj() (k8sclient.Object, error) {
 	return ss, nil
 }
 
+// nodeIDOffsetEnv returns the node ID of the first broker of the zone
+// StatefulSet for the configurator, which adds the ordinal of the broker
+func (r *StatefulSetResource) nodeIDOffsetEnv() []corev1.EnvVar {
+	set := r.brokerStatefulSet()
+	if set.Zone == "" {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  "NODE_ID_OFFSET",
+			Value: strconv.Itoa(int(set.NodeIDOffset)),
+		},
+	}
+}
+
+// logLevel returns the log level the brokers start with
+func (r *StatefulSetResource) logLevel() string {
+	if r.pandaCluster.Spec.LogLevel == "" {
+		return redpandav1alpha1.DefaultLogLevel
+	}
+	return r.pandaCluster.Spec.LogLevel
+}
+
+// datadirOwnerInitContainers returns the init container that changes the
+// owner of the data directory to the Redpanda user when it's requested
+func (r *StatefulSetResource) datadirOwnerInitContainers() []corev1.Container {
+	if !r.pandaCluster.Spec.Storage.FixPermissions {
+		return nil
+	}
+	return []corev1.Container{
+		{
+			Name:            datadirOwnerContainerName,
+			Image:           r.pandaCluster.FullImageName(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"chown", "-R", fmt.Sprintf("%d:%d", r.runAsUser(), r.runAsGroup()), dataDirectory},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  pointer.Int64Ptr(0),
+				RunAsGroup: pointer.Int64Ptr(0),
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      datadirName,
+					MountPath: dataDirectory,
+				},
+			},
+		},
+	}
+}
+
+// clusterDomainCheckInitContainers returns the init container that fails
+// when the Kubernetes API Service doesn't resolve under the cluster domain.
+// The headless Service of the brokers has no records until a broker is
+// ready, so it can't be used for the check.
+func (r *StatefulSetResource) clusterDomainCheckInitContainers() []corev1.Container {
+	if !r.pandaCluster.Spec.VerifyClusterDomain {
+		return nil
+	}
+	domain := r.pandaCluster.ClusterDomain()
+	name := "kubernetes.default.svc." + domain
+	script := fmt.Sprintf(`if ! getent hosts "%s" > /dev/null; then
+  echo "%s does not resolve, cluster domain %s doesn't match the DNS resolver of the node"
+  exit 1
+fi`, name, name, domain)
+	return []corev1.Container{
+		{
+			Name:            ClusterDomainCheckContainerName,
+			Image:           r.pandaCluster.FullImageName(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", script},
+			// the output of the failed check is reported in the status
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  pointer.Int64Ptr(r.runAsUser()),
+				RunAsGroup: pointer.Int64Ptr(r.runAsGroup()),
+			},
+		},
+	}
+}
+
+// FailedClusterDomainCheck returns the message of the first failed cluster
+// domain check of the pods, or empty string if no check failed
+func FailedClusterDomainCheck(pods []corev1.Pod) string {
+	for i := range pods {
+		for _, status := range pods[i].Status.InitContainerStatuses {
+			if status.Name != ClusterDomainCheckContainerName {
+				continue
+			}
+			// the last failure is kept while the check is restarted
+			terminated := status.State.Terminated
+			if terminated == nil && status.State.Waiting != nil {
+				terminated = status.LastTerminationState.Terminated
+			}
+			if terminated != nil && terminated.ExitCode != 0 {
+				return fmt.Sprintf("%s: %s", pods[i].Name, strings.TrimSpace(terminated.Message))
+			}
+		}
+	}
+	return ""
+}
+
+// dnsWaitInitContainers returns the init container that waits until the
+// name of the broker under the external Subdomain resolves. The broker is
+// started anyway when the name doesn't resolve before the timeout.
+func (r *StatefulSetResource) dnsWaitInitContainers() []corev1.Container {
+	externConn := r.pandaCluster.Spec.ExternalConnectivity
+	if !externConn.Enabled || externConn.Subdomain == "" || externConn.DNSPropagationTimeoutSeconds == nil {
+		return nil
+	}
+	name := "${POD_NAME}." + externConn.Subdomain
+	script := fmt.Sprintf(`deadline=$(($(date +%%s) + %d))
+until getent hosts "%s" > /dev/null; do
+  if [ "$(date +%%s)" -ge "$deadline" ]; then
+    echo "%s does not resolve, starting the broker anyway"
+    exit 0
+  fi
+  sleep %d
+done`, *externConn.DNSPropagationTimeoutSeconds, name, name, dnsWaitIntervalSeconds)
+	return []corev1.Container{
+		{
+			Name:            dnsWaitContainerName,
+			Image:           r.pandaCluster.FullImageName(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", script},
+			Env: []corev1.EnvVar{
+				{
+					Name: "POD_NAME",
+					ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{
+							APIVersion: "v1",
+							FieldPath:  "metadata.name",
+						},
+					},
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  pointer.Int64Ptr(r.runAsUser()),
+				RunAsGroup: pointer.Int64Ptr(r.runAsGroup()),
+			},
+		},
+	}
+}
+
+// startupDelayInitContainers returns the init container that delays the
+// start of the broker by StartupDelaySeconds for every broker before it.
+// The ordinal is the suffix of the pod name.
+func (r *StatefulSetResource) startupDelayInitContainers() []corev1.Container {
+	delay := r.pandaCluster.Spec.StartupDelaySeconds
+	if delay == nil {
+		return nil
+	}
+	script := fmt.Sprintf(`delay=$((${POD_NAME##*-} * %d))
+echo "delaying the start of ${POD_NAME} by ${delay}s"
+sleep "$delay"`, *delay)
+	return []corev1.Container{
+		{
+			Name:            startupDelayContainerName,
+			Image:           r.pandaCluster.FullImageName(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", script},
+			Env: []corev1.EnvVar{
+				{
+					Name: "POD_NAME",
+					ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{
+							APIVersion: "v1",
+							FieldPath:  "metadata.name",
+						},
+					},
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  pointer.Int64Ptr(r.runAsUser()),
+				RunAsGroup: pointer.Int64Ptr(r.runAsGroup()),
+			},
+		},
+	}
+}
+
+// runAsUser returns the UID of Redpanda processes
+func (r *StatefulSetResource) runAsUser() int64 {
+	if r.pandaCluster.Spec.RunAsUser != nil {
+		return *r.pandaCluster.Spec.RunAsUser
+	}
+	return userID
+}
+
+// runAsGroup returns the GID of Redpanda processes
+func (r *StatefulSetResource) runAsGroup() int64 {
+	if r.pandaCluster.Spec.RunAsGroup != nil {
+		return *r.pandaCluster.Spec.RunAsGroup
+	}
+	return groupID
+}
+
+// livenessProbe returns the probe restarting the broker when the ports
+// selected by the liveness probe mode don't accept connections
+func (r *StatefulSetResource) livenessProbe() *corev1.Probe {
+	config := r.pandaCluster.Spec.LivenessProbe
+	if config == nil {
+		return nil
+	}
+	// bash opens the connection when redirecting from /dev/tcp
+	portOpen := func(port int) string {
+		return fmt.Sprintf("(: < /dev/tcp/127.0.0.1/%d) 2>/dev/null", port)
+	}
+	admin := portOpen(r.pandaCluster.Spec.Configuration.AdminAPI.Port)
+	rpc := portOpen(r.pandaCluster.Spec.Configuration.RPCServer.Port)
+
+	var script string
+	switch config.Mode {
+	case redpandav1alpha1.LivenessProbeAdmin:
+		script = admin
+	case redpandav1alpha1.LivenessProbeRPC:
+		script = rpc
+	case redpandav1alpha1.LivenessProbeAll:
+		script = admin + " && " + rpc
+	default:
+		script = admin + " || " + rpc
+	}
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/bash", "-c", script}},
+		},
+		InitialDelaySeconds: config.InitialDelaySeconds,
+		PeriodSeconds:       config.PeriodSeconds,
+		FailureThreshold:    config.FailureThreshold,
+	}
+}
+
+// redpandaCommand returns the command of the broker container that raises
+// the open files limit before the image entrypoint is started with the
+// container arguments. The entrypoint of the image is kept when no limit is
+// configured.
+func (r *StatefulSetResource) redpandaCommand() []string {
+	limit := r.pandaCluster.Spec.OpenFilesLimit
+	if limit == nil {
+		return nil
+	}
+	script := fmt.Sprintf(`ulimit -n %d && exec %s "$@"`, *limit, redpandaEntrypoint)
+	// the argument following the script is $0 of the shell
+	return []string{"/bin/sh", "-c", script, redpandaEntrypoint}
+}
+
+// redpandaSecurityContext returns the security context of the Redpanda container.
+// With read-only root filesystem only the mounted volumes are writable.
+// The image user is used unless the IDs are overridden.
+func (r *StatefulSetResource) redpandaSecurityContext() *corev1.SecurityContext {
+	spec := r.pandaCluster.Spec
+	if !spec.ReadOnlyRootFilesystem && spec.RunAsUser == nil && spec.RunAsGroup == nil {
+		return nil
+	}
+	sc := &corev1.SecurityContext{
+		RunAsUser:  spec.RunAsUser,
+		RunAsGroup: spec.RunAsGroup,
+	}
+	if spec.ReadOnlyRootFilesystem {
+		sc.ReadOnlyRootFilesystem = pointer.BoolPtr(true)
+	}
+	return sc
+}
+
+// readOnlyRootVolumeMounts returns the writable mounts Redpanda needs when
+// the root filesystem is read-only. The configuration directory is already
+// backed by an emptyDir volume.
+func (r *StatefulSetResource) readOnlyRootVolumeMounts() []corev1.VolumeMount {
+	if !r.pandaCluster.Spec.ReadOnlyRootFilesystem {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      tmpDirName,
+			MountPath: tmpDir,
+		},
+	}
+}
+
+func (r *StatefulSetResource) readOnlyRootVolumes() []corev1.Volume {
+	if !r.pandaCluster.Spec.ReadOnlyRootFilesystem {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: tmpDirName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
 func (r *StatefulSetResource) secretVolumeMounts() []corev1.VolumeMount {
 	var mounts []corev1.VolumeMount
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
@@ -449,6 +946,12 @@ func (r *StatefulSetResource) secretVolumeMounts() []corev1.VolumeMount {
 			MountPath: tlsDir,
 		})
 	}
+	if r.pandaCluster.SeparateExternalCert() {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "tlsexternalcert",
+			MountPath: tlsExternalDir,
+		})
+	}
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
 		mounts = append(mounts, corev1.VolumeMount{
 			Name:      "tlsca",
@@ -489,6 +992,28 @@ func (r *StatefulSetResource) secretVolumes() []corev1.Volume {
 		})
 	}
 
+	// The external listener is served with its own keypair certificate.
+	if r.pandaCluster.SeparateExternalCert() {
+		vols = append(vols, corev1.Volume{
+			Name: "tlsexternalcert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.externalCertSecretKey.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  corev1.TLSPrivateKeyKey,
+							Path: corev1.TLSPrivateKeyKey,
+						},
+						{
+							Key:  corev1.TLSCertKey,
+							Path: corev1.TLSCertKey,
+						},
+					},
+				},
+			},
+		})
+	}
+
 	// When TLS client authentication is enabled, Redpanda needs the client's CA certificate.
 	if r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
 		vols = append(vols, corev1.Volume{
@@ -547,6 +1072,15 @@ func (r *StatefulSetResource) getNodePort(name string) string {
 	return ""
 }
 
+// dnsPolicy keeps the cluster DNS resolution of the brokers running in the
+// host network, the default policy would use the DNS servers of the node
+func (r *StatefulSetResource) dnsPolicy() corev1.DNSPolicy {
+	if r.pandaCluster.Spec.HostNetwork {
+		return corev1.DNSClusterFirstWithHostNet
+	}
+	return corev1.DNSClusterFirst
+}
+
 func (r *StatefulSetResource) getServiceAccountName() string {
 	if r.pandaCluster.Spec.ExternalConnectivity.Enabled {
 		return r.serviceAccountName
@@ -557,7 +1091,7 @@ func (r *StatefulSetResource) getServiceAccountName() string {
 // Key returns namespace/name object that is used to identify object.
 // For reference please visit types.NamespacedName docs in k8s.io/apimachinery
 func (r *StatefulSetResource) Key() types.NamespacedName {
-	return types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+	return types.NamespacedName{Name: r.brokerStatefulSet().Name, Namespace: r.pandaCluster.Namespace}
 }
 
 func (r *StatefulSetResource) portsConfiguration() string {
@@ -566,10 +1100,41 @@ func (r *StatefulSetResource) portsConfiguration() string {
 
 	// In every dns name there is trailing dot to query absolute path
 	// For trailing dot explanation please visit http://www.dns-sd.org/trailingdotsindomainnames.html
-	return fmt.Sprintf("--advertise-rpc-addr=$(POD_NAME).%s.$(POD_NAMESPACE).svc.cluster.local.:%d", svcName, rpcAPIPort)
+	return fmt.Sprintf("--advertise-rpc-addr=$(POD_NAME).%s.$(POD_NAMESPACE).svc.%s.:%d", svcName, r.pandaCluster.ClusterDomain(), rpcAPIPort)
 }
 
 func (r *StatefulSetResource) getPorts() []corev1.ContainerPort {
+	ports := r.defaultListenerPorts()
+	for _, port := range AdditionalListenerPorts(r.pandaCluster) {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: int32(port.Port),
+		})
+	}
+	return ports
+}
+
+// defaultListenerPorts returns the ports of the Internal and the External
+// Kafka API listeners and of the Admin API
+func (r *StatefulSetResource) defaultListenerPorts() []corev1.ContainerPort {
+	if r.pandaCluster.Spec.ExternalConnectivity.PerBrokerServices() {
+		// the broker Service forwards the traffic to the external listener,
+		// the Admin API is shared by internal and external clients
+		return []corev1.ContainerPort{
+			{
+				Name:          "kafka-internal",
+				ContainerPort: int32(r.pandaCluster.Spec.Configuration.KafkaAPI.Port),
+			},
+			{
+				Name:          "admin",
+				ContainerPort: int32(r.pandaCluster.Spec.Configuration.AdminAPI.Port),
+			},
+			{
+				Name:          "kafka-external",
+				ContainerPort: int32(calculateExternalPort(r.pandaCluster.Spec.Configuration.KafkaAPI.Port)),
+			},
+		}
+	}
 	if r.pandaCluster.Spec.ExternalConnectivity.Enabled &&
 		len(r.nodePortSvc.Spec.Ports) > 0 {
 		ports := []corev1.ContainerPort{
@@ -599,7 +1164,7 @@ func (r *StatefulSetResource) getPorts() []corev1.ContainerPort {
 		return ports
 	}
 
-	return []corev1.ContainerPort{
+	ports := []corev1.ContainerPort{
 		{
 			Name:          "kafka",
 			ContainerPort: int32(r.pandaCluster.Spec.Configuration.KafkaAPI.Port),
@@ -609,6 +1174,16 @@ func (r *StatefulSetResource) getPorts() []corev1.ContainerPort {
 			ContainerPort: int32(r.pandaCluster.Spec.Configuration.AdminAPI.Port),
 		},
 	}
+	if r.pandaCluster.SeparateExternalAdmin() && len(r.adminNodePortSvc.Spec.Ports) > 0 {
+		// the Admin API has no advertised address, so the external clients
+		// use the same listener as the internal ones
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "admin-external",
+			ContainerPort: int32(r.pandaCluster.Spec.Configuration.AdminAPI.Port),
+			HostPort:      r.adminNodePortSvc.Spec.Ports[0].NodePort,
+		})
+	}
+	return ports
 }
 
 func statefulSetKind() string {
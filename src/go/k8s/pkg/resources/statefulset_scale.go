@@ -0,0 +1,168 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// BrokerDecommissioner is the part of the Admin API used to drain brokers
+// before the cluster is scaled down
+type BrokerDecommissioner interface {
+	// DecommissionBroker starts moving all partitions away from the broker
+	DecommissionBroker(ctx context.Context, nodeID int) error
+	// IsBrokerDecommissioned returns true once the broker left the cluster
+	IsBrokerDecommissioned(ctx context.Context, nodeID int) (bool, error)
+	// DecommissionReplicasLeft returns the number of partition replicas
+	// still to be moved away from the broker
+	DecommissionReplicasLeft(ctx context.Context, nodeID int) (int, error)
+}
+
+// BrokerDecommissionerFactory creates BrokerDecommissioner. It's invoked
+// only while scaling down, so the Admin API is not queried otherwise.
+type BrokerDecommissionerFactory func(ctx context.Context) (BrokerDecommissioner, error)
+
+// WithBrokerDecommissioner sets the factory of the Admin API client used to
+// drain brokers when the cluster is scaled down with DrainOnScaleDown
+func (r *StatefulSetResource) WithBrokerDecommissioner(
+	factory BrokerDecommissionerFactory,
+) *StatefulSetResource {
+	r.decommissionerFactory = factory
+	return r
+}
+
+// drainedReplicas returns the number of replicas the StatefulSet can be
+// scaled to. When the cluster is scaled down with DrainOnScaleDown, the
+// broker with the highest ordinal is decommissioned first and the replicas
+// are decreased by one only after the broker left the cluster. Nil is
+// returned when the replicas from the spec can be applied right away. With
+// zone StatefulSets only the StatefulSet that loses a broker drains it.
+// The decommissioning status is cleared by the caller once the StatefulSet
+// is updated.
+func (r *StatefulSetResource) drainedReplicas(
+	ctx context.Context, sts *appsv1.StatefulSet,
+) (*int32, error) {
+	if !r.pandaCluster.Spec.DrainOnScaleDown || r.decommissionerFactory == nil ||
+		sts.Spec.Replicas == nil || r.pandaCluster.Spec.Replicas == nil {
+		return nil, nil
+	}
+	set := r.brokerStatefulSet()
+	current := *sts.Spec.Replicas
+	if set.Replicas >= current {
+		// the broker was removed, but the status update didn't go through
+		if decommissioning := r.pandaCluster.Status.DecommissioningNode; decommissioning != nil &&
+			*decommissioning >= set.NodeIDOffset && *decommissioning < set.NodeIDOffset+ZoneNodeIDStride {
+			return nil, r.updateDecommissioningStatus(ctx, nil)
+		}
+		return nil, nil
+	}
+
+	// only one broker is removed at a time
+	nodeID := set.NodeIDOffset + current - 1
+	decommissioner, err := r.decommissionerFactory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Admin API client: %w", err)
+	}
+
+	decommissioning := r.pandaCluster.Status.DecommissioningNode
+	if decommissioning == nil || *decommissioning != nodeID {
+		r.logger.Info("Decommissioning broker", "node-id", nodeID)
+		if err = decommissioner.DecommissionBroker(ctx, int(nodeID)); err != nil {
+			return nil, fmt.Errorf("unable to decommission broker %d: %w", nodeID, err)
+		}
+		if err = r.updateDecommissioningStatus(ctx, &nodeID); err != nil {
+			return nil, err
+		}
+		return nil, &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("broker %d is being decommissioned", nodeID)}
+	}
+
+	done, err := decommissioner.IsBrokerDecommissioned(ctx, int(nodeID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to check decommission of broker %d: %w", nodeID, err)
+	}
+	if !done {
+		if err = r.reportDecommissionProgress(ctx, decommissioner, nodeID); err != nil {
+			return nil, err
+		}
+		return nil, &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: fmt.Sprintf("waiting for partitions to move away from broker %d", nodeID)}
+	}
+
+	r.logger.Info("Broker decommissioned, removing its Pod", "node-id", nodeID)
+	// ordinals start at zero, so the removed ordinal is the number of the
+	// remaining brokers
+	replicas := current - 1
+	return &replicas, nil
+}
+
+// reportDecommissionProgress reports the partition replicas left on the
+// broker in the status. Brokers that don't report the progress only have
+// the decommissioning node in the status.
+func (r *StatefulSetResource) reportDecommissionProgress(
+	ctx context.Context, decommissioner BrokerDecommissioner, nodeID int32,
+) error {
+	left, err := decommissioner.DecommissionReplicasLeft(ctx, int(nodeID))
+	if err != nil {
+		r.logger.Info("Unable to fetch decommission progress", "node-id", nodeID, "error", err.Error())
+		return nil
+	}
+	progress := decommissionProgress(r.pandaCluster.Status.DecommissionProgress, int32(left))
+	if reflect.DeepEqual(progress, r.pandaCluster.Status.DecommissionProgress) {
+		return nil
+	}
+	r.logger.Info("Decommission in progress", "node-id", nodeID,
+		"remaining-partitions", progress.RemainingPartitions, "percent-complete", progress.PercentComplete)
+	r.pandaCluster.Status.DecommissionProgress = progress
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update decommission progress: %w", err)
+	}
+	return nil
+}
+
+// decommissionProgress computes the progress from the replicas left on the
+// broker. The first observation is taken as the initial number of replicas,
+// which is raised if more replicas are reported later, e.g. if partitions
+// were created in the meantime.
+func decommissionProgress(
+	previous *redpandav1alpha1.DecommissionProgress, left int32,
+) *redpandav1alpha1.DecommissionProgress {
+	initial := left
+	if previous != nil && previous.InitialPartitions > left {
+		initial = previous.InitialPartitions
+	}
+	percent := int32(100)
+	if initial > 0 {
+		percent = (initial - left) * 100 / initial
+	}
+	return &redpandav1alpha1.DecommissionProgress{
+		InitialPartitions:   initial,
+		RemainingPartitions: left,
+		PercentComplete:     percent,
+	}
+}
+
+// updateDecommissioningStatus sets the decommissioning node, the progress
+// of the previous decommission is cleared
+func (r *StatefulSetResource) updateDecommissioningStatus(
+	ctx context.Context, nodeID *int32,
+) error {
+	r.pandaCluster.Status.DecommissioningNode = nodeID
+	r.pandaCluster.Status.DecommissionProgress = nil
+	if err := r.Status().Update(ctx, r.pandaCluster); err != nil {
+		return fmt.Errorf("unable to update decommissioning status: %w", err)
+	}
+	return nil
+}
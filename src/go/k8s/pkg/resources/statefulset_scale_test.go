@@ -0,0 +1,178 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeDecommissioner simulates the Admin API of a cluster moving partitions
+// away from the decommissioned broker
+type fakeDecommissioner struct {
+	decommissioned []int
+	moved          bool
+	replicasLeft   int
+	progressErr    error
+}
+
+func (f *fakeDecommissioner) DecommissionBroker(_ context.Context, nodeID int) error {
+	f.decommissioned = append(f.decommissioned, nodeID)
+	return nil
+}
+
+func (f *fakeDecommissioner) IsBrokerDecommissioned(context.Context, int) (bool, error) {
+	return f.moved, nil
+}
+
+func (f *fakeDecommissioner) DecommissionReplicasLeft(context.Context, int) (int, error) {
+	return f.replicasLeft, f.progressErr
+}
+
+func TestEnsure_DrainOnScaleDown(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	existing := stsFromCluster(cluster)
+	cluster.Spec.Replicas = pointer.Int32Ptr(2)
+	cluster.Spec.DrainOnScaleDown = true
+
+	c := fake.NewClientBuilder().WithObjects(cluster, existing).Build()
+	decommissioner := &fakeDecommissioner{}
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test")).
+		WithBrokerDecommissioner(func(context.Context) (res.BrokerDecommissioner, error) {
+			return decommissioner, nil
+		})
+
+	replicas := func() int32 {
+		var actual v1.StatefulSet
+		require.NoError(t, c.Get(context.Background(), sts.Key(), &actual))
+		return *actual.Spec.Replicas
+	}
+
+	// the broker with the highest ordinal is decommissioned first
+	err := sts.Ensure(context.Background())
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(err, &requeue), err)
+	assert.Equal(t, []int{2}, decommissioner.decommissioned)
+	assert.Equal(t, pointer.Int32Ptr(2), cluster.Status.DecommissioningNode)
+	assert.Equal(t, int32(3), replicas())
+
+	// the Pod is kept until partitions move away
+	err = sts.Ensure(context.Background())
+	require.True(t, errors.As(err, &requeue), err)
+	assert.Equal(t, []int{2}, decommissioner.decommissioned, "decommission is not repeated")
+	assert.Equal(t, int32(3), replicas())
+
+	// and removed once the broker left the cluster
+	decommissioner.moved = true
+	require.NoError(t, sts.Ensure(context.Background()))
+	assert.Nil(t, cluster.Status.DecommissioningNode)
+	assert.Equal(t, int32(2), replicas())
+
+	// nothing happens once the cluster is scaled down
+	require.NoError(t, sts.Ensure(context.Background()))
+	assert.Equal(t, []int{2}, decommissioner.decommissioned)
+	assert.Equal(t, int32(2), replicas())
+}
+
+func TestEnsure_DecommissionProgress(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	existing := stsFromCluster(cluster)
+	cluster.Spec.Replicas = pointer.Int32Ptr(2)
+	cluster.Spec.DrainOnScaleDown = true
+
+	c := fake.NewClientBuilder().WithObjects(cluster, existing).Build()
+	decommissioner := &fakeDecommissioner{}
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test")).
+		WithBrokerDecommissioner(func(context.Context) (res.BrokerDecommissioner, error) {
+			return decommissioner, nil
+		})
+
+	var requeue *res.RequeueAfterError
+	err := sts.Ensure(context.Background())
+	require.True(t, errors.As(err, &requeue), err)
+	assert.Nil(t, cluster.Status.DecommissionProgress)
+
+	steps := []struct {
+		replicasLeft int
+		expected     redpandav1alpha1.DecommissionProgress
+	}{
+		{10, redpandav1alpha1.DecommissionProgress{InitialPartitions: 10, RemainingPartitions: 10, PercentComplete: 0}},
+		{4, redpandav1alpha1.DecommissionProgress{InitialPartitions: 10, RemainingPartitions: 4, PercentComplete: 60}},
+		{1, redpandav1alpha1.DecommissionProgress{InitialPartitions: 10, RemainingPartitions: 1, PercentComplete: 90}},
+	}
+	for _, step := range steps {
+		decommissioner.replicasLeft = step.replicasLeft
+		err = sts.Ensure(context.Background())
+		require.True(t, errors.As(err, &requeue), err)
+
+		var actual redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, &actual))
+		require.NotNil(t, actual.Status.DecommissionProgress)
+		assert.Equal(t, step.expected, *actual.Status.DecommissionProgress)
+	}
+
+	// brokers that don't report the progress keep the last one
+	decommissioner.progressErr = errors.New("not supported")
+	err = sts.Ensure(context.Background())
+	require.True(t, errors.As(err, &requeue), err)
+	assert.Equal(t, int32(90), cluster.Status.DecommissionProgress.PercentComplete)
+
+	// the progress is cleared with the decommissioning node
+	decommissioner.moved = true
+	require.NoError(t, sts.Ensure(context.Background()))
+	assert.Nil(t, cluster.Status.DecommissioningNode)
+	assert.Nil(t, cluster.Status.DecommissionProgress)
+}
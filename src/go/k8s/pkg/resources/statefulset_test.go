@@ -11,10 +11,17 @@ package resources_test
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
 	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
 	v1 "k8s.io/api/apps/v1"
@@ -49,6 +56,14 @@ func TestEnsure(t *testing.T) {
 	resourcesUpdatedSts := stsFromCluster(cluster).DeepCopy()
 	resourcesUpdatedSts.Spec.Template.Spec.Containers[0].Resources.Requests = newResources
 
+	vpaManaged := map[string]string{redpandav1alpha1.VPAManagedAnnotation: "true"}
+	vpaResourcesCluster := resourcesUpdatedCluster.DeepCopy()
+	vpaResourcesCluster.Annotations = vpaManaged
+	vpaReplicasCluster := replicasUpdatedCluster.DeepCopy()
+	vpaReplicasCluster.Annotations = vpaManaged
+	vpaNewCluster := resourcesUpdatedCluster.DeepCopy()
+	vpaNewCluster.Annotations = vpaManaged
+
 	var tests = []struct {
 		name           string
 		existingObject client.Object
@@ -58,6 +73,9 @@ func TestEnsure(t *testing.T) {
 		{"none existing", nil, cluster, stsResource},
 		{"update replicas", stsResource, replicasUpdatedCluster, replicasUpdatedSts},
 		{"update resources", stsResource, resourcesUpdatedCluster, resourcesUpdatedSts},
+		{"resources managed by VPA are kept", stsResource, vpaResourcesCluster, stsResource},
+		{"replicas are updated with resources managed by VPA", stsResource, vpaReplicasCluster, replicasUpdatedSts},
+		{"none existing with resources managed by VPA", nil, vpaNewCluster, resourcesUpdatedSts},
 	}
 
 	for _, tt := range tests {
@@ -105,6 +123,754 @@ func TestEnsure(t *testing.T) {
 	}
 }
 
+func TestEnsure_ReadOnlyRootFilesystem(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.ReadOnlyRootFilesystem = true
+
+	c := fake.NewClientBuilder().Build()
+	err := redpandav1alpha1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	err = sts.Ensure(context.Background())
+	assert.NoError(t, err)
+
+	actual := &v1.StatefulSet{}
+	err = c.Get(context.Background(), sts.Key(), actual)
+	assert.NoError(t, err)
+
+	container := actual.Spec.Template.Spec.Containers[0]
+	if assert.NotNil(t, container.SecurityContext) {
+		assert.Equal(t, pointer.BoolPtr(true), container.SecurityContext.ReadOnlyRootFilesystem)
+	}
+
+	mounts := map[string]string{}
+	for _, m := range container.VolumeMounts {
+		mounts[m.MountPath] = m.Name
+	}
+	volumes := map[string]corev1.Volume{}
+	for _, v := range actual.Spec.Template.Spec.Volumes {
+		volumes[v.Name] = v
+	}
+	for _, path := range []string{"/tmp", "/etc/redpanda"} {
+		name, ok := mounts[path]
+		if !assert.True(t, ok, "expecting writable mount at %s", path) {
+			continue
+		}
+		assert.NotNil(t, volumes[name].EmptyDir, "expecting %s to be backed by emptyDir", path)
+	}
+}
+
+func TestEnsure_FixDataDirPermissions(t *testing.T) {
+	tests := []struct {
+		name           string
+		fixPermissions bool
+	}{
+		{"disabled", false},
+		{"enabled", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.Storage.FixPermissions = tt.fixPermissions
+
+			c := fake.NewClientBuilder().Build()
+			err := redpandav1alpha1.AddToScheme(scheme.Scheme)
+			assert.NoError(t, err)
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+
+			err = sts.Ensure(context.Background())
+			assert.NoError(t, err)
+
+			actual := &v1.StatefulSet{}
+			err = c.Get(context.Background(), sts.Key(), actual)
+			assert.NoError(t, err)
+
+			var chown *corev1.Container
+			for i := range actual.Spec.Template.Spec.InitContainers {
+				if actual.Spec.Template.Spec.InitContainers[i].Name == "redpanda-datadir-owner" {
+					chown = &actual.Spec.Template.Spec.InitContainers[i]
+				}
+			}
+			if !tt.fixPermissions {
+				assert.Nil(t, chown)
+				return
+			}
+			if assert.NotNil(t, chown) {
+				assert.Equal(t, []string{"chown", "-R", "101:101", "/var/lib/redpanda/data"}, chown.Command)
+				assert.Equal(t, pointer.Int64Ptr(0), chown.SecurityContext.RunAsUser)
+			}
+		})
+	}
+}
+
+func TestEnsure_DNSWait(t *testing.T) {
+	tests := []struct {
+		name      string
+		subdomain string
+		timeout   *int32
+	}{
+		{"disabled", "redpanda.example.com", nil},
+		{"enabled", "redpanda.example.com", pointer.Int32Ptr(300)},
+		{"without subdomain", "", pointer.Int32Ptr(300)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.ExternalConnectivity = redpandav1alpha1.ExternalConnectivityConfig{
+				Enabled:                      true,
+				Subdomain:                    tt.subdomain,
+				DNSPropagationTimeoutSeconds: tt.timeout,
+			}
+
+			// the external listener is published on the node port
+			nodePort := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Name: "kafka", NodePort: 30001},
+						{Name: "admin", NodePort: 30002},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithObjects(nodePort).Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+			initContainers := actual.Spec.Template.Spec.InitContainers
+			var wait *corev1.Container
+			for i := range initContainers {
+				if initContainers[i].Name == "redpanda-dns-wait" {
+					wait = &initContainers[i]
+				}
+			}
+			if tt.timeout == nil || tt.subdomain == "" {
+				assert.Nil(t, wait)
+				return
+			}
+			require.NotNil(t, wait)
+			assert.Equal(t, "redpanda-dns-wait", initContainers[len(initContainers)-1].Name,
+				"the broker name is resolved after the configuration is rendered")
+			require.Len(t, wait.Command, 3)
+			assert.Equal(t, []string{"/bin/sh", "-c"}, wait.Command[:2])
+			assert.Contains(t, wait.Command[2], `getent hosts "${POD_NAME}.redpanda.example.com"`)
+			assert.Contains(t, wait.Command[2], "+ 300))")
+			assert.Equal(t, "POD_NAME", wait.Env[0].Name)
+			assert.Equal(t, "metadata.name", wait.Env[0].ValueFrom.FieldRef.FieldPath)
+		})
+	}
+}
+
+func TestEnsure_StartupDelay(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.StartupDelaySeconds = pointer.Int32Ptr(30)
+
+	c := fake.NewClientBuilder().Build()
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	var delay *corev1.Container
+	for i := range actual.Spec.Template.Spec.InitContainers {
+		if actual.Spec.Template.Spec.InitContainers[i].Name == "redpanda-startup-delay" {
+			delay = &actual.Spec.Template.Spec.InitContainers[i]
+		}
+	}
+	require.NotNil(t, delay)
+	require.Len(t, delay.Command, 3)
+	assert.Equal(t, []string{"/bin/sh", "-c"}, delay.Command[:2])
+	assert.Equal(t, "metadata.name", delay.Env[0].ValueFrom.FieldRef.FieldPath)
+
+	// the script is run with sleep that only reports the delay
+	bin := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bin, "sleep"), []byte("#!/bin/sh\necho \"slept $1\"\n"), 0700)) // nolint:gosec // test executable
+	for ordinal, expected := range []string{"slept 0", "slept 30", "slept 60"} {
+		cmd := exec.Command(delay.Command[0], delay.Command[1:]...) // nolint:gosec // command of the rendered init container
+		cmd.Env = []string{"PATH=" + bin, fmt.Sprintf("%s=%s-%d", delay.Env[0].Name, cluster.Name, ordinal)}
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(out), expected)
+	}
+
+}
+
+func TestEnsure_OpenFilesLimit(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.OpenFilesLimit = pointer.Int64Ptr(1024)
+
+	c := fake.NewClientBuilder().Build()
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	container := actual.Spec.Template.Spec.Containers[0]
+	require.Len(t, container.Command, 4)
+	assert.Equal(t, []string{"/bin/sh", "-c"}, container.Command[:2])
+	assert.Contains(t, container.Command[2], "ulimit -n 1024")
+	assert.Equal(t, []string{"redpanda", "start"}, container.Args[:2])
+
+	// the script is run with an entrypoint that reports the limit and the
+	// arguments it is started with
+	entrypoint := filepath.Join(t.TempDir(), "entrypoint.sh")
+	require.NoError(t, ioutil.WriteFile(entrypoint, []byte("#!/bin/sh\necho \"$(ulimit -n) $*\"\n"), 0700)) // nolint:gosec // test executable
+	script := strings.Replace(container.Command[2], "/entrypoint.sh", entrypoint, 1)
+	args := append([]string{"-c", script, container.Command[3]}, container.Args...)
+	out, err := exec.Command(container.Command[0], args...).Output() // nolint:gosec // command of the rendered container
+	require.NoError(t, err)
+	assert.Equal(t, "1024 "+strings.Join(container.Args, " ")+"\n", string(out))
+
+	// the image entrypoint is used without the limit
+	cluster.Spec.OpenFilesLimit = nil
+	require.NoError(t, sts.Ensure(context.Background()))
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+	assert.Empty(t, actual.Spec.Template.Spec.Containers[0].Command)
+}
+
+func TestEnsure_LivenessProbe(t *testing.T) {
+	// the probed ports are either accepting connections or closed
+	listen := func(t *testing.T, open bool) int {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := l.Addr().(*net.TCPAddr).Port
+		if open {
+			t.Cleanup(func() { l.Close() })
+		} else {
+			require.NoError(t, l.Close())
+		}
+		return port
+	}
+
+	tests := []struct {
+		name      string
+		mode      redpandav1alpha1.LivenessProbeMode
+		adminOpen bool
+		rpcOpen   bool
+		expected  bool
+	}{
+		{"admin up", redpandav1alpha1.LivenessProbeAdmin, true, false, true},
+		{"admin down", redpandav1alpha1.LivenessProbeAdmin, false, true, false},
+		{"rpc up", redpandav1alpha1.LivenessProbeRPC, false, true, true},
+		{"rpc down", redpandav1alpha1.LivenessProbeRPC, true, false, false},
+		{"any with admin down", "", false, true, true},
+		{"any with rpc down", redpandav1alpha1.LivenessProbeAny, true, false, true},
+		{"any with both down", redpandav1alpha1.LivenessProbeAny, false, false, false},
+		{"all up", redpandav1alpha1.LivenessProbeAll, true, true, true},
+		{"all with rpc down", redpandav1alpha1.LivenessProbeAll, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.LivenessProbe = &redpandav1alpha1.LivenessProbeConfig{Mode: tt.mode, FailureThreshold: 5}
+			cluster.Spec.Configuration.AdminAPI.Port = listen(t, tt.adminOpen)
+			cluster.Spec.Configuration.RPCServer.Port = listen(t, tt.rpcOpen)
+
+			c := fake.NewClientBuilder().Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+			probe := actual.Spec.Template.Spec.Containers[0].LivenessProbe
+			require.NotNil(t, probe)
+			require.NotNil(t, probe.Exec)
+			assert.Equal(t, int32(5), probe.FailureThreshold)
+
+			command := probe.Exec.Command
+			err := exec.Command(command[0], command[1:]...).Run() // nolint:gosec // command of the rendered probe
+			assert.Equal(t, tt.expected, err == nil)
+		})
+	}
+}
+
+func TestEnsure_ClusterDomainCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		clusterDomain string
+		expectFailure bool
+	}{
+		{"default domain", "", false},
+		{"matching domain", "cluster.local.", false},
+		{"mismatched domain", "corp.example", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.ClusterDomain = tt.clusterDomain
+			cluster.Spec.VerifyClusterDomain = true
+
+			c := fake.NewClientBuilder().Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+			var check *corev1.Container
+			for i := range actual.Spec.Template.Spec.InitContainers {
+				if actual.Spec.Template.Spec.InitContainers[i].Name == res.ClusterDomainCheckContainerName {
+					check = &actual.Spec.Template.Spec.InitContainers[i]
+				}
+			}
+			require.NotNil(t, check)
+			require.Len(t, check.Command, 3)
+			assert.Equal(t, corev1.TerminationMessageFallbackToLogsOnError, check.TerminationMessagePolicy)
+
+			// the script is run with getent resolving the names of the
+			// cluster.local domain only
+			bin := t.TempDir()
+			getent := "#!/bin/sh\n[ \"$2\" = \"kubernetes.default.svc.cluster.local\" ]\n"
+			require.NoError(t, ioutil.WriteFile(filepath.Join(bin, "getent"), []byte(getent), 0700)) // nolint:gosec // test executable
+			cmd := exec.Command(check.Command[0], check.Command[1:]...)                              // nolint:gosec // command of the rendered init container
+			cmd.Env = []string{"PATH=" + bin}
+			out, err := cmd.Output()
+			if !tt.expectFailure {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, string(out), "cluster domain corp.example doesn't match")
+		})
+	}
+}
+
+func TestFailedClusterDomainCheck(t *testing.T) {
+	failed := corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "does not resolve\n"},
+	}
+	succeeded := corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{ExitCode: 0},
+	}
+	backOff := corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+	}
+	pod := func(state, last corev1.ContainerState) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-0"},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{Name: res.ClusterDomainCheckContainerName, State: state, LastTerminationState: last},
+				},
+			},
+		}
+	}
+
+	assert.Empty(t, res.FailedClusterDomainCheck(nil))
+	assert.Empty(t, res.FailedClusterDomainCheck([]corev1.Pod{pod(succeeded, corev1.ContainerState{})}))
+	assert.Empty(t, res.FailedClusterDomainCheck([]corev1.Pod{pod(succeeded, failed)}))
+	assert.Equal(t, "cluster-0: does not resolve", res.FailedClusterDomainCheck([]corev1.Pod{pod(failed, corev1.ContainerState{})}))
+	assert.Equal(t, "cluster-0: does not resolve", res.FailedClusterDomainCheck([]corev1.Pod{pod(backOff, failed)}))
+}
+
+func TestEnsure_DedicatedNodes(t *testing.T) {
+	dedicated := corev1.Toleration{
+		Key:      "redpanda.vectorized.io/dedicated",
+		Operator: corev1.TolerationOpEqual,
+		Value:    "true",
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	spot := corev1.Toleration{Key: "spot", Operator: corev1.TolerationOpExists}
+	tests := []struct {
+		name                 string
+		dedicatedNodes       bool
+		tolerations          []corev1.Toleration
+		expectedTolerations  []corev1.Toleration
+		expectedNodeSelector map[string]string
+	}{
+		{
+			name:                 "disabled",
+			tolerations:          []corev1.Toleration{spot},
+			expectedTolerations:  []corev1.Toleration{spot},
+			expectedNodeSelector: map[string]string{"disk": "ssd"},
+		},
+		{
+			name:                 "enabled",
+			dedicatedNodes:       true,
+			tolerations:          []corev1.Toleration{spot},
+			expectedTolerations:  []corev1.Toleration{spot, dedicated},
+			expectedNodeSelector: map[string]string{"disk": "ssd", "redpanda.vectorized.io/dedicated": "true"},
+		},
+		{
+			name:                 "toleration already provided",
+			dedicatedNodes:       true,
+			tolerations:          []corev1.Toleration{dedicated},
+			expectedTolerations:  []corev1.Toleration{dedicated},
+			expectedNodeSelector: map[string]string{"disk": "ssd", "redpanda.vectorized.io/dedicated": "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.DedicatedNodes = tt.dedicatedNodes
+			cluster.Spec.Tolerations = tt.tolerations
+			cluster.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+
+			c := fake.NewClientBuilder().Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+			assert.Equal(t, tt.expectedTolerations, actual.Spec.Template.Spec.Tolerations)
+			assert.Equal(t, tt.expectedNodeSelector, actual.Spec.Template.Spec.NodeSelector)
+			// the spec of the cluster is not modified
+			assert.Equal(t, map[string]string{"disk": "ssd"}, cluster.Spec.NodeSelector)
+			assert.Equal(t, tt.tolerations, cluster.Spec.Tolerations)
+		})
+	}
+}
+
+func TestEnsure_ZoneStatefulSets(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+	cluster.Spec.ZoneStatefulSets = &redpandav1alpha1.ZoneStatefulSetsConfig{Zones: []string{"us-a", "us-b"}}
+
+	c := fake.NewClientBuilder().Build()
+	expected := []struct {
+		name     string
+		zone     string
+		replicas int32
+		offset   string
+	}{
+		{"cluster-us-a", "us-a", 2, "0"},
+		{"cluster-us-b", "us-b", 1, "1000"},
+	}
+	sets := res.BrokerStatefulSets(cluster)
+	require.Len(t, sets, len(expected))
+	for i, set := range sets {
+		sts := res.NewStatefulSet(
+			c,
+			cluster,
+			scheme.Scheme,
+			"cluster.local",
+			"servicename",
+			types.NamespacedName{Name: "test", Namespace: "test"},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			"",
+			"latest",
+			ctrl.Log.WithName("test")).
+			WithBrokerStatefulSet(set)
+		require.NoError(t, sts.Ensure(context.Background()))
+
+		actual := &v1.StatefulSet{}
+		require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+		assert.Equal(t, expected[i].name, actual.Name)
+		assert.Equal(t, expected[i].replicas, *actual.Spec.Replicas)
+		assert.Equal(t, expected[i].zone, actual.Spec.Selector.MatchLabels[res.ZoneLabel])
+		assert.Equal(t, expected[i].zone, actual.Spec.Template.Labels[res.ZoneLabel])
+		assert.Equal(t, map[string]string{
+			"disk":                        "ssd",
+			"topology.kubernetes.io/zone": expected[i].zone,
+		}, actual.Spec.Template.Spec.NodeSelector)
+
+		offset := ""
+		for _, container := range actual.Spec.Template.Spec.InitContainers {
+			if container.Name != "redpanda-configurator" {
+				continue
+			}
+			for _, e := range container.Env {
+				if e.Name == "NODE_ID_OFFSET" {
+					offset = e.Value
+				}
+			}
+		}
+		assert.Equal(t, expected[i].offset, offset)
+	}
+	// the spec of the cluster is not modified
+	assert.Equal(t, map[string]string{"disk": "ssd"}, cluster.Spec.NodeSelector)
+}
+
+func TestEnsure_RunAsIDs(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.RunAsUser = pointer.Int64Ptr(1001)
+	cluster.Spec.RunAsGroup = pointer.Int64Ptr(2002)
+	cluster.Spec.Storage.FixPermissions = true
+
+	c := fake.NewClientBuilder().Build()
+	err := redpandav1alpha1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	err = sts.Ensure(context.Background())
+	assert.NoError(t, err)
+
+	actual := &v1.StatefulSet{}
+	err = c.Get(context.Background(), sts.Key(), actual)
+	assert.NoError(t, err)
+
+	podSpec := actual.Spec.Template.Spec
+	assert.Equal(t, pointer.Int64Ptr(2002), podSpec.SecurityContext.FSGroup)
+
+	container := podSpec.Containers[0]
+	if assert.NotNil(t, container.SecurityContext) {
+		assert.Equal(t, pointer.Int64Ptr(1001), container.SecurityContext.RunAsUser)
+		assert.Equal(t, pointer.Int64Ptr(2002), container.SecurityContext.RunAsGroup)
+	}
+
+	for _, init := range podSpec.InitContainers {
+		switch init.Name {
+		case "redpanda-datadir-owner":
+			assert.Equal(t, []string{"chown", "-R", "1001:2002", "/var/lib/redpanda/data"}, init.Command)
+		case "redpanda-configurator":
+			assert.Equal(t, pointer.Int64Ptr(1001), init.SecurityContext.RunAsUser)
+			assert.Equal(t, pointer.Int64Ptr(2002), init.SecurityContext.RunAsGroup)
+		}
+	}
+}
+
+func TestEnsure_SeccompProfile(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.SeccompProfile = &corev1.SeccompProfile{
+		Type:             corev1.SeccompProfileTypeLocalhost,
+		LocalhostProfile: pointer.StringPtr("profiles/redpanda.json"),
+	}
+
+	c := fake.NewClientBuilder().Build()
+	err := redpandav1alpha1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	err = sts.Ensure(context.Background())
+	assert.NoError(t, err)
+
+	actual := &v1.StatefulSet{}
+	err = c.Get(context.Background(), sts.Key(), actual)
+	assert.NoError(t, err)
+
+	assert.Equal(t, cluster.Spec.SeccompProfile, actual.Spec.Template.Spec.SecurityContext.SeccompProfile)
+}
+
+func TestEnsure_CloudStorageProjectedToken(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.CloudStorage = redpandav1alpha1.CloudStorageConfig{
+		Enabled: true,
+		Bucket:  "archive",
+		Region:  "us-west-1",
+		ProjectedToken: &redpandav1alpha1.CloudStorageProjectedToken{
+			Audience:          "sts.amazonaws.com",
+			ExpirationSeconds: pointer.Int64Ptr(3600),
+			RoleARN:           "arn:aws:iam::123456789012:role/redpanda",
+		},
+	}
+
+	c := fake.NewClientBuilder().Build()
+	err := redpandav1alpha1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+	require.NoError(t, sts.Ensure(context.Background()))
+
+	actual := &v1.StatefulSet{}
+	require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+
+	var projected *corev1.ProjectedVolumeSource
+	var volumeName string
+	for _, v := range actual.Spec.Template.Spec.Volumes {
+		if v.Projected != nil {
+			projected, volumeName = v.Projected, v.Name
+		}
+	}
+	require.NotNil(t, projected, "expecting projected token volume")
+	require.Len(t, projected.Sources, 1)
+	token := projected.Sources[0].ServiceAccountToken
+	require.NotNil(t, token)
+	assert.Equal(t, "sts.amazonaws.com", token.Audience)
+	assert.Equal(t, pointer.Int64Ptr(3600), token.ExpirationSeconds)
+
+	var container *corev1.Container
+	for i := range actual.Spec.Template.Spec.Containers {
+		if actual.Spec.Template.Spec.Containers[i].Name == "redpanda" {
+			container = &actual.Spec.Template.Spec.Containers[i]
+		}
+	}
+	require.NotNil(t, container)
+	var mountPath string
+	for _, m := range container.VolumeMounts {
+		if m.Name == volumeName {
+			mountPath = m.MountPath
+			assert.True(t, m.ReadOnly)
+		}
+	}
+	require.NotEmpty(t, mountPath, "expecting projected token to be mounted")
+
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, mountPath+"/"+token.Path, env[res.WebIdentityTokenFileEnv])
+	assert.Equal(t, "arn:aws:iam::123456789012:role/redpanda", env[res.RoleARNEnv])
+}
+
 func stsFromCluster(pandaCluster *redpandav1alpha1.Cluster) *v1.StatefulSet {
 	fileSystemMode := corev1.PersistentVolumeFilesystem
 
@@ -194,3 +960,187 @@ func pandaCluster() *redpandav1alpha1.Cluster {
 		},
 	}
 }
+
+func TestEnsure_ExtraVolumesAndEnv(t *testing.T) {
+	tests := []struct {
+		name            string
+		inInitContainer bool
+	}{
+		{"redpanda container only", false},
+		{"init containers", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.ExtraVolumes = []corev1.Volume{
+				{
+					Name: "ca-bundle",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"},
+						},
+					},
+				},
+				// operator managed volumes take precedence
+				{Name: "config-dir"},
+			}
+			cluster.Spec.ExtraVolumeMounts = []corev1.VolumeMount{
+				{Name: "ca-bundle", MountPath: "/etc/ca", ReadOnly: true},
+				{Name: "ca-bundle", MountPath: "/etc/redpanda"},
+			}
+			cluster.Spec.ExtraEnv = []corev1.EnvVar{
+				{Name: "SSL_CERT_DIR", Value: "/etc/ca"},
+				{Name: "REDPANDA_ENVIRONMENT", Value: "custom"},
+			}
+			cluster.Spec.ExtraInInitContainers = tt.inInitContainer
+
+			c := fake.NewClientBuilder().Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+			podSpec := actual.Spec.Template.Spec
+
+			volumes := map[string]corev1.Volume{}
+			for _, v := range podSpec.Volumes {
+				_, duplicated := volumes[v.Name]
+				assert.False(t, duplicated, "volume %s is duplicated", v.Name)
+				volumes[v.Name] = v
+			}
+			require.Contains(t, volumes, "ca-bundle")
+			assert.NotNil(t, volumes["ca-bundle"].ConfigMap)
+			assert.NotNil(t, volumes["config-dir"].EmptyDir)
+
+			hasExtras := func(container *corev1.Container) bool {
+				mounts := map[string]string{}
+				for _, m := range container.VolumeMounts {
+					mounts[m.MountPath] = m.Name
+				}
+				env := map[string]string{}
+				for _, e := range container.Env {
+					env[e.Name] = e.Value
+				}
+				if container.Name == "redpanda" {
+					assert.Equal(t, "config-dir", mounts["/etc/redpanda"])
+					assert.Equal(t, "kubernetes", env["REDPANDA_ENVIRONMENT"])
+				}
+				return mounts["/etc/ca"] == "ca-bundle" && env["SSL_CERT_DIR"] == "/etc/ca"
+			}
+			require.Len(t, podSpec.Containers, 1)
+			assert.True(t, hasExtras(&podSpec.Containers[0]))
+			require.NotEmpty(t, podSpec.InitContainers)
+			for i := range podSpec.InitContainers {
+				assert.Equal(t, tt.inInitContainer, hasExtras(&podSpec.InitContainers[i]))
+			}
+		})
+	}
+}
+
+func TestEnsure_LogLevel(t *testing.T) {
+	tests := []struct {
+		logLevel string
+		expected string
+	}{
+		{"", "--default-log-level=debug"},
+		{"warn", "--default-log-level=warn"},
+		{"trace", "--default-log-level=trace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.LogLevel = tt.logLevel
+
+			c := fake.NewClientBuilder().Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+			require.Len(t, actual.Spec.Template.Spec.Containers, 1)
+			args := actual.Spec.Template.Spec.Containers[0].Args
+			assert.Contains(t, args, tt.expected)
+			assert.Len(t, filterPrefix(args, "--default-log-level"), 1)
+		})
+	}
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var filtered []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func TestEnsure_HostNetwork(t *testing.T) {
+	tests := []struct {
+		hostNetwork bool
+		dnsPolicy   corev1.DNSPolicy
+	}{
+		{false, corev1.DNSClusterFirst},
+		{true, corev1.DNSClusterFirstWithHostNet},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dnsPolicy), func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.HostNetwork = tt.hostNetwork
+
+			c := fake.NewClientBuilder().Build()
+			require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+			sts := res.NewStatefulSet(
+				c,
+				cluster,
+				scheme.Scheme,
+				"cluster.local",
+				"servicename",
+				types.NamespacedName{Name: "test", Namespace: "test"},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				types.NamespacedName{},
+				"",
+				"latest",
+				ctrl.Log.WithName("test"))
+			require.NoError(t, sts.Ensure(context.Background()))
+
+			actual := &v1.StatefulSet{}
+			require.NoError(t, c.Get(context.Background(), sts.Key(), actual))
+			assert.Equal(t, tt.hostNetwork, actual.Spec.Template.Spec.HostNetwork)
+			assert.Equal(t, tt.dnsPolicy, actual.Spec.Template.Spec.DNSPolicy)
+		})
+	}
+}
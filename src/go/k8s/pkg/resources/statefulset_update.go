@@ -19,9 +19,15 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const requeueDuration = time.Second * 10
@@ -32,8 +38,9 @@ const requeueDuration = time.Second * 10
 // before proceeding to the next pod.
 //
 // The process maintains an Upgrading bool status that is set to true once the
-// CR and statefulset images differ. It is set back to false when all pods are
-// verified to be updated.
+// CR and statefulset images differ. It is set back to false when all pods of
+// all zone StatefulSets are verified to be updated, so the cluster holds the
+// restart limiter for the whole upgrade.
 //
 // The steps are as follows: 1) check the Upgrading status or if the statefulset image
 // version differs from that of the cluster CR; 2) if true, set the Upgrading status
@@ -48,6 +55,14 @@ func (r *StatefulSetResource) runPartitionedUpdate(
 ) error {
 	newImage := r.pandaCluster.FullImageName()
 
+	clusterKey := types.NamespacedName{Name: r.pandaCluster.Name, Namespace: r.pandaCluster.Namespace}
+	if r.pandaCluster.Status.Upgrading {
+		r.restartLimiter.Resume(clusterKey)
+	} else if !r.restartLimiter.Acquire(clusterKey) {
+		return &RequeueAfterError{RequeueAfter: requeueDuration,
+			Msg: "waiting for rolling updates of other clusters to complete"}
+	}
+
 	if err := r.updateUpgradingStatus(ctx, true); err != nil {
 		return err
 	}
@@ -56,6 +71,10 @@ func (r *StatefulSetResource) runPartitionedUpdate(
 		r.logger.Info("Continuing cluster partitioned update", "cluster image", newImage)
 	}
 
+	if err := r.ensureImagePrePulled(ctx, newImage); err != nil {
+		return err
+	}
+
 	podSpec := &sts.Spec.Template.Spec
 	if err := r.modifyPodImage(podSpec, newImage); err != nil {
 		return err
@@ -65,27 +84,90 @@ func (r *StatefulSetResource) runPartitionedUpdate(
 		return err
 	}
 
+	// the zone StatefulSets are updated in order, the upgrade of the cluster
+	// is complete once the last one is updated
+	if !r.lastBrokerStatefulSet() {
+		return nil
+	}
+
+	if err := r.cleanupImagePrePull(ctx); err != nil {
+		return err
+	}
+
 	// Update is complete for all pods (and all are ready). Set upgrading status to false.
 	if err := r.updateUpgradingStatus(ctx, false); err != nil {
 		return err
 	}
+	r.restartLimiter.Release(clusterKey)
 
 	return nil
 }
 
-// shouldUsePartitionedUpdate returns true if changes on the CR require partitioned update
+// shouldUsePartitionedUpdate returns true if changes on the CR require
+// partitioned update, or if a previous update was interrupted, e.g. by the
+// operator restart, and left some Pods with outdated image or configuration
 func (r *StatefulSetResource) shouldUsePartitionedUpdate(
-	sts *appsv1.StatefulSet,
+	ctx context.Context, sts *appsv1.StatefulSet,
 ) (bool, error) {
 	upgrading := r.pandaCluster.Status.Upgrading
 
-	rpContainer, err := findContainer(sts.Spec.Template.Spec.Containers, redpandaContainerName)
+	rpContainer, err := findContainer(sts.Spec.Template.Spec.Containers, RedpandaContainerName)
 	if err != nil {
 		return false, err
 	}
 
 	newImage := r.pandaCluster.FullImageName()
-	return rpContainer.Image != newImage || upgrading, nil
+	if rpContainer.Image != newImage || upgrading {
+		return true, nil
+	}
+	return r.hasStalePods(ctx, newImage)
+}
+
+// hasStalePods returns true if any Pod of the StatefulSet runs image or
+// configuration other than the desired one
+func (r *StatefulSetResource) hasStalePods(
+	ctx context.Context, newImage string,
+) (bool, error) {
+	selector := labels.ForCluster(r.pandaCluster).AsAPISelector().MatchLabels
+	if zone := r.brokerStatefulSet().Zone; zone != "" {
+		// the zone StatefulSets are updated one after another
+		selector[ZoneLabel] = zone
+	}
+	var pods corev1.PodList
+	err := r.List(ctx, &pods, &k8sclient.ListOptions{
+		LabelSelector: k8slabels.SelectorFromSet(selector),
+		Namespace:     r.pandaCluster.Namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch PodList resource: %w", err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		container, err := findContainer(pod.Spec.Containers, RedpandaContainerName)
+		if err != nil {
+			return false, err
+		}
+		if container.Image != newImage || r.podOutdated(pod) != "" {
+			r.logger.Info("Found stale pod", "pod", pod.Name)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// podOutdated returns the first annotation of the Pod template the Pod
+// doesn't match, empty string if the Pod is up to date
+func (r *StatefulSetResource) podOutdated(pod *corev1.Pod) string {
+	for key, value := range r.podAnnotations() {
+		if pod.Annotations[key] != value {
+			return key
+		}
+	}
+	return ""
 }
 
 func (r *StatefulSetResource) updateUpgradingStatus(
@@ -119,8 +201,9 @@ func (r *StatefulSetResource) partitionUpdateImage(
 		}
 
 		// Continue only if error is due to Pod not ready, or unchanged image
-		// as an attempt to fix the Pod.
-		if !errors.Is(poderr, errContainerHasWrongImage) && !errors.Is(poderr, errPodNotReady) {
+		// or configuration as an attempt to fix the Pod.
+		if !errors.Is(poderr, errContainerHasWrongImage) && !errors.Is(poderr, errPodOutdated) &&
+			!errors.Is(poderr, errPodNotReady) {
 			return poderr
 		}
 
@@ -131,7 +214,16 @@ func (r *StatefulSetResource) partitionUpdateImage(
 				Msg: fmt.Sprintf("redpanda on pod (ordinal: %d) not ready", ordinal)}
 		}
 
-		if err := r.rollingUpdatePartition(ctx, ordinal, sts); err != nil {
+		// Up to maxUnavailable Pods with ordinal lower or equal to the ith
+		// one are updated at once.
+		partition := ordinal - r.maxUnavailable() + 1
+		if partition < 0 {
+			partition = 0
+		}
+		if err := r.rollingUpdatePartition(ctx, partition, sts); err != nil {
+			return err
+		}
+		if err := r.restartPartition(ctx, sts, newImage, partition, ordinal); err != nil {
 			return err
 		}
 
@@ -149,6 +241,41 @@ func (r *StatefulSetResource) partitionUpdateImage(
 	return nil
 }
 
+// maxUnavailable returns the number of brokers restarted at once, the
+// webhook keeps it within the majority of the replicas
+func (r *StatefulSetResource) maxUnavailable() int32 {
+	rollingUpdate := r.pandaCluster.Spec.RollingUpdate
+	if rollingUpdate == nil || rollingUpdate.MaxUnavailable < 1 {
+		return 1
+	}
+	return rollingUpdate.MaxUnavailable
+}
+
+// restartPartition deletes the Pods with ordinal in [from, to) still running
+// the previous image or configuration. The StatefulSet controller updates the Pods one at a
+// time, so the Pods are recreated with the new image concurrently only when
+// they are deleted. The Pod with ordinal to is left to the StatefulSet
+// controller.
+func (r *StatefulSetResource) restartPartition(
+	ctx context.Context, sts *appsv1.StatefulSet, newImage string, from, to int32,
+) error {
+	for ordinal := from; ordinal < to; ordinal++ {
+		err := r.podImageIdenticalToClusterImage(ctx, sts, newImage, ordinal)
+		if !errors.Is(err, errContainerHasWrongImage) && !errors.Is(err, errPodOutdated) {
+			continue
+		}
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", sts.Name, ordinal),
+			Namespace: sts.Namespace,
+		}}
+		r.logger.Info("Restarting pod", "pod", pod.Name)
+		if err := r.Delete(ctx, &pod); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to restart pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
 // Ensures the Redpanda pod has rejoined its groups after restarting,
 // i.e., is ready for I/O.
 func (r *StatefulSetResource) ensureRedpandaGroupsReady(
@@ -159,7 +286,7 @@ func (r *StatefulSetResource) ensureRedpandaGroupsReady(
 	}
 
 	headlessServiceWithPort := fmt.Sprintf("%s:%d", r.serviceFQDN,
-		r.pandaCluster.Spec.Configuration.KafkaAPI.Port)
+		r.pandaCluster.InternalKafkaAPIListener().Port)
 
 	addresses := []string{fmt.Sprintf("%s-%d.%s", sts.Name, ordinal, headlessServiceWithPort)}
 
@@ -179,16 +306,14 @@ func (r *StatefulSetResource) queryRedpandaForTopicMembers(
 	conf.ClientID = "operator"
 	conf.Admin.Timeout = time.Second
 
-	// TODO right now we support TLS only on one listener so if external
-	// connectivity is enabled, TLS is enabled only on external listener. This
-	// will be fixed by https://github.com/vectorizedio/redpanda/issues/1084
-	if !r.pandaCluster.Spec.ExternalConnectivity.Enabled && r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.Enabled {
+	listener := r.pandaCluster.InternalKafkaAPIListener()
+	if listener.TLS {
 		tlsConfig := tls.Config{MinVersion: tls.VersionTLS12} // TLS12 is min version allowed by gosec.
-		// For simplicity, we skip broker verification until per-listener
-		// TLS is available in Redpanda. This client calls the internal listener.
+		// For simplicity, we skip broker verification. This client calls
+		// the internal listener.
 		tlsConfig.InsecureSkipVerify = true
 
-		if err := r.populateTLSConfigCert(ctx, &tlsConfig); err != nil {
+		if err := r.populateTLSConfigCert(ctx, listener, &tlsConfig); err != nil {
 			return err
 		}
 
@@ -208,9 +333,11 @@ func (r *StatefulSetResource) queryRedpandaForTopicMembers(
 // Populates crypto/TLS configuration for certificate used by the operator
 // during its client authentication.
 func (r *StatefulSetResource) populateTLSConfigCert(
-	ctx context.Context, tlsConfig *tls.Config,
+	ctx context.Context,
+	listener redpandav1alpha1.ListenerSpec,
+	tlsConfig *tls.Config,
 ) error {
-	if !r.pandaCluster.Spec.Configuration.TLS.KafkaAPI.RequireClientAuth {
+	if !listener.RequireClientAuth {
 		return nil
 	}
 
@@ -240,7 +367,7 @@ func (r *StatefulSetResource) podImageIdenticalToClusterImage(
 		return err
 	}
 
-	container, err := findContainer(pod.Spec.Containers, redpandaContainerName)
+	container, err := findContainer(pod.Spec.Containers, RedpandaContainerName)
 	if err != nil {
 		return err
 	}
@@ -251,6 +378,12 @@ func (r *StatefulSetResource) podImageIdenticalToClusterImage(
 		return containerHasWrongImageError(podName, container.Name, container.Image, newImage)
 	}
 
+	if key := r.podOutdated(&pod); key != "" {
+		r.logger.Info("Pod not updated to current configuration", "pod", pod.Name,
+			"annotation", key)
+		return podOutdatedError(pod.Name, key)
+	}
+
 	if !podIsReady(&pod) {
 		r.logger.Info("Pod not ready yet", "pod", pod.Name)
 		return podNotReadyError(pod.Name)
@@ -275,6 +408,8 @@ func (r *StatefulSetResource) rollingUpdatePartition(
 	modifiedSts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{
 		Partition: &ordinal,
 	}
+	keepVolumeClaimTemplates(modifiedSts, sts)
+	r.keepVPAResources(modifiedSts, sts)
 	if err := Update(ctx, sts, modifiedSts, r.Client, r.logger); err != nil {
 		return fmt.Errorf("failed to update StatefulSet (ordinal %d): %w", ordinal, err)
 	}
@@ -285,7 +420,7 @@ func (r *StatefulSetResource) rollingUpdatePartition(
 func (r *StatefulSetResource) modifyPodImage(
 	stsSpec *corev1.PodSpec, newImage string,
 ) error {
-	return modifyContainerImage(stsSpec.Containers, redpandaContainerName, newImage)
+	return modifyContainerImage(stsSpec.Containers, RedpandaContainerName, newImage)
 }
 
 func modifyContainerImage(
@@ -350,6 +485,13 @@ func containerNotFoundError(container string) error {
 		errContainerNotFound, container)
 }
 
+var errPodOutdated = errors.New("pod has outdated configuration")
+
+func podOutdatedError(pod, annotation string) error {
+	return fmt.Errorf("podOutdated %w : pod: %s; annotation: %s",
+		errPodOutdated, pod, annotation)
+}
+
 var errPodNotReady = errors.New("pod not in ready state")
 
 func podNotReadyError(pod string) error {
@@ -0,0 +1,242 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRollingUpdateMaxUnavailable(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(5)
+	existingSts := stsFromCluster(cluster)
+	cluster.Spec.Version = "new"
+	cluster.Spec.RollingUpdate = &redpandav1alpha1.RollingUpdateConfig{MaxUnavailable: 2}
+
+	objs := []client.Object{cluster, existingSts}
+	for ordinal := 0; ordinal < 5; ordinal++ {
+		objs = append(objs, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", cluster.Name, ordinal),
+				Namespace: cluster.Namespace,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "redpanda", Image: "image:latest"}},
+			},
+		})
+	}
+
+	c := fake.NewClientBuilder().WithObjects(objs...).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	err := sts.Ensure(ctx)
+	var requeue *res.RequeueAfterError
+	require.True(t, errors.As(err, &requeue), "expecting requeue, got %v", err)
+
+	// two brokers with the highest ordinals are updated at once
+	var actualSts appsv1.StatefulSet
+	require.NoError(t, c.Get(ctx, sts.Key(), &actualSts))
+	require.NotNil(t, actualSts.Spec.UpdateStrategy.RollingUpdate)
+	assert.Equal(t, int32(3), *actualSts.Spec.UpdateStrategy.RollingUpdate.Partition)
+
+	// the lower one is restarted by the operator, the highest one is left
+	// to the StatefulSet controller
+	var pod corev1.Pod
+	err = c.Get(ctx, types.NamespacedName{Name: cluster.Name + "-3", Namespace: cluster.Namespace}, &pod)
+	assert.True(t, apierrors.IsNotFound(err), "expecting pod to be restarted, got %v", err)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: cluster.Name + "-4", Namespace: cluster.Namespace}, &pod))
+	for ordinal := 0; ordinal < 3; ordinal++ {
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%d", cluster.Name, ordinal), Namespace: cluster.Namespace}, &pod))
+	}
+}
+
+func TestRollingUpdateResumesWithStalePods(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	cluster.Spec.RollingUpdate = &redpandav1alpha1.RollingUpdateConfig{MaxUnavailable: 3}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      res.ConfigMapKey(cluster).Name,
+			Namespace: res.ConfigMapKey(cluster).Namespace,
+		},
+		Data: map[string]string{"redpanda.yaml": "redpanda:\n  developer_mode: true\n"},
+	}
+	c := fake.NewClientBuilder().WithObjects(cluster, stsFromCluster(cluster), configMap).Build()
+	configHash, err := res.ConfigHash(ctx, c, res.ConfigMapKey(cluster))
+	require.NoError(t, err)
+
+	// the rolling update was interrupted with only the 0th broker running
+	// the current configuration
+	pod := func(ordinal int, hash string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("%s-%d", cluster.Name, ordinal),
+				Namespace:   cluster.Namespace,
+				Labels:      labels.ForCluster(cluster).AsAPISelector().MatchLabels,
+				Annotations: map[string]string{res.ConfigHashAnnotation: hash},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "redpanda", Image: "image:latest"}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	require.NoError(t, c.Create(ctx, pod(0, configHash)))
+	require.NoError(t, c.Create(ctx, pod(1, "stale")))
+	require.NoError(t, c.Create(ctx, pod(2, "stale")))
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	// recreates the missing or stale Pods above the partition, like the
+	// StatefulSet controller does
+	rollOut := func() {
+		var actualSts appsv1.StatefulSet
+		require.NoError(t, c.Get(ctx, sts.Key(), &actualSts))
+		require.NotNil(t, actualSts.Spec.UpdateStrategy.RollingUpdate)
+		partition := int(*actualSts.Spec.UpdateStrategy.RollingUpdate.Partition)
+		for ordinal := partition; ordinal < 3; ordinal++ {
+			var existing corev1.Pod
+			key := types.NamespacedName{Name: fmt.Sprintf("%s-%d", cluster.Name, ordinal), Namespace: cluster.Namespace}
+			err := c.Get(ctx, key, &existing)
+			if err == nil && existing.Annotations[res.ConfigHashAnnotation] == configHash {
+				continue
+			}
+			if err == nil {
+				require.NoError(t, c.Delete(ctx, &existing))
+			}
+			require.NoError(t, c.Create(ctx, pod(ordinal, actualSts.Spec.Template.Annotations[res.ConfigHashAnnotation])))
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		err = sts.Ensure(ctx)
+		var requeue *res.RequeueAfterError
+		require.True(t, errors.As(err, &requeue), "expecting requeue, got %v", err)
+		if !strings.Contains(requeue.Msg, "restart") {
+			break
+		}
+		rollOut()
+	}
+
+	var pods corev1.PodList
+	require.NoError(t, c.List(ctx, &pods))
+	require.Len(t, pods.Items, 3)
+	for i := range pods.Items {
+		assert.Equal(t, configHash, pods.Items[i].Annotations[res.ConfigHashAnnotation], pods.Items[i].Name)
+	}
+
+	var actualCluster redpandav1alpha1.Cluster
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, &actualCluster))
+	assert.True(t, actualCluster.Status.Upgrading, "update completes once the brokers are verified")
+}
+
+func TestRollingUpdateZoneStatefulSets(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+
+	// the StatefulSets without brokers complete the update at once
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(0)
+	cluster.Spec.ZoneStatefulSets = &redpandav1alpha1.ZoneStatefulSetsConfig{Zones: []string{"us-a", "us-b"}}
+	objs := []client.Object{cluster}
+	for _, set := range res.BrokerStatefulSets(cluster) {
+		existingSts := stsFromCluster(cluster)
+		existingSts.Name = set.Name
+		objs = append(objs, existingSts)
+	}
+	cluster.Spec.Version = "new"
+
+	c := fake.NewClientBuilder().WithObjects(objs...).Build()
+	limiter := res.NewRestartLimiter(1)
+	other := types.NamespacedName{Name: "other", Namespace: cluster.Namespace}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	for i, set := range res.BrokerStatefulSets(cluster) {
+		sts := res.NewStatefulSet(
+			c,
+			cluster,
+			scheme.Scheme,
+			"cluster.local",
+			"servicename",
+			types.NamespacedName{Name: "test", Namespace: "test"},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			types.NamespacedName{},
+			"",
+			"latest",
+			ctrl.Log.WithName("test")).
+			WithRestartLimiter(limiter).
+			WithBrokerStatefulSet(set)
+		require.NoError(t, sts.Ensure(ctx))
+
+		// the upgrade of the cluster is complete with the last StatefulSet
+		last := i == len(res.BrokerStatefulSets(cluster))-1
+		var actualCluster redpandav1alpha1.Cluster
+		require.NoError(t, c.Get(ctx, key, &actualCluster))
+		assert.Equal(t, !last, actualCluster.Status.Upgrading, set.Name)
+		assert.Equal(t, last, limiter.Acquire(other), set.Name)
+		limiter.Release(other)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// UpgradePlan lists brokers that are going to be restarted by the
+// partitioned rolling update, in the order of restarts. Only one broker
+// is unavailable at a time.
+type UpgradePlan struct {
+	CurrentImage string
+	TargetImage  string
+	Steps        []UpgradeStep
+}
+
+// UpgradeStep is a restart of a single broker
+type UpgradeStep struct {
+	Pod     string
+	Ordinal int32
+}
+
+// PlanUpgrade computes the rolling update for the pending image change
+// without executing it. Nil is returned when the StatefulSet already runs
+// the image of the Cluster.
+func (r *StatefulSetResource) PlanUpgrade(
+	ctx context.Context,
+) (*UpgradePlan, error) {
+	var sts appsv1.StatefulSet
+	if err := r.Get(ctx, r.Key(), &sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			// brokers are started with the target image
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error while fetching StatefulSet resource: %w", err)
+	}
+
+	container, err := findContainer(sts.Spec.Template.Spec.Containers, RedpandaContainerName)
+	if err != nil {
+		return nil, err
+	}
+	partitioned, err := r.shouldUsePartitionedUpdate(ctx, &sts)
+	if err != nil || !partitioned {
+		return nil, err
+	}
+
+	targetImage := r.pandaCluster.FullImageName()
+	plan := &UpgradePlan{
+		CurrentImage: container.Image,
+		TargetImage:  targetImage,
+	}
+
+	// follows partitionUpdateImage, the brokers are restarted from
+	// the highest ordinal and the updated ones are skipped
+	for ordinal := *sts.Spec.Replicas - 1; ordinal >= 0; ordinal-- {
+		poderr := r.podImageIdenticalToClusterImage(ctx, &sts, targetImage, ordinal)
+		if poderr == nil {
+			continue
+		}
+		if !errors.Is(poderr, errContainerHasWrongImage) && !errors.Is(poderr, errPodOutdated) &&
+			!errors.Is(poderr, errPodNotReady) && !apierrors.IsNotFound(poderr) {
+			return nil, poderr
+		}
+		plan.Steps = append(plan.Steps, UpgradeStep{
+			Pod:     fmt.Sprintf("%s-%d", sts.Name, ordinal),
+			Ordinal: ordinal,
+		})
+	}
+	return plan, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	existingSts := stsFromCluster(cluster)
+	cluster.Spec.Version = "new"
+
+	objects := []client.Object{existingSts}
+	for ordinal, image := range []string{"image:latest", "image:latest", "image:new"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", cluster.Name, ordinal),
+				Namespace: cluster.Namespace,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "redpanda", Image: image}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		})
+	}
+	c := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	plan, err := sts.PlanUpgrade(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, plan)
+	assert.Equal(t, "image:latest", plan.CurrentImage)
+	assert.Equal(t, "image:new", plan.TargetImage)
+	// the last broker already runs the new image
+	assert.Equal(t, []res.UpgradeStep{
+		{Pod: "cluster-1", Ordinal: 1},
+		{Pod: "cluster-0", Ordinal: 0},
+	}, plan.Steps)
+
+	// the plan has no side effects
+	var pod corev1.Pod
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "cluster-0", Namespace: cluster.Namespace}, &pod))
+	assert.Equal(t, "image:latest", pod.Spec.Containers[0].Image)
+	assert.False(t, cluster.Status.Upgrading)
+}
+
+func TestPlanUpgradeWithoutImageChange(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	cluster := pandaCluster()
+	c := fake.NewClientBuilder().WithObjects(stsFromCluster(cluster)).Build()
+	sts := res.NewStatefulSet(
+		c,
+		cluster,
+		scheme.Scheme,
+		"cluster.local",
+		"servicename",
+		types.NamespacedName{Name: "test", Namespace: "test"},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		types.NamespacedName{},
+		"",
+		"latest",
+		ctrl.Log.WithName("test"))
+
+	plan, err := sts.PlanUpgrade(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, plan)
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ Reconciler = &VolumeExpander{}
+
+// VolumeExpander is part of the reconciliation of redpanda.vectorized.io CRD.
+// It grows the data directory volumes of the brokers when the requested
+// capacity increases, as volume claim templates of the StatefulSet can't be
+// changed. Volumes of storage classes that don't allow volume expansion are
+// reported by the VolumeExpansionBlocked condition.
+type VolumeExpander struct {
+	k8sclient.Client
+	pandaCluster *redpandav1alpha1.Cluster
+	recorder     record.EventRecorder
+	logger       logr.Logger
+}
+
+// NewVolumeExpander creates VolumeExpander
+func NewVolumeExpander(
+	client k8sclient.Client,
+	pandaCluster *redpandav1alpha1.Cluster,
+	recorder record.EventRecorder,
+	logger logr.Logger,
+) *VolumeExpander {
+	return &VolumeExpander{
+		client,
+		pandaCluster,
+		recorder,
+		logger.WithValues("Reconciler", "volume-expander"),
+	}
+}
+
+// Ensure patches the data directory claims smaller than the requested
+// capacity. Claims are never shrunk.
+func (r *VolumeExpander) Ensure(ctx context.Context) error {
+	capacity := r.pandaCluster.Spec.Storage.Capacity
+	if capacity.IsZero() {
+		return nil
+	}
+
+	var pvcs corev1.PersistentVolumeClaimList
+	err := r.List(ctx, &pvcs, &k8sclient.ListOptions{
+		LabelSelector: labels.ForCluster(r.pandaCluster).AsClientSelector(),
+		Namespace:     r.pandaCluster.Namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to fetch PersistentVolumeClaimList resource: %w", err)
+	}
+
+	claimPrefix := datadirName + "-" + r.pandaCluster.Name + "-"
+	expandable := make(map[string]bool)
+	var blocked []string
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !strings.HasPrefix(pvc.Name, claimPrefix) {
+			continue
+		}
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if capacity.Cmp(current) <= 0 {
+			continue
+		}
+
+		className := ""
+		if pvc.Spec.StorageClassName != nil {
+			className = *pvc.Spec.StorageClassName
+		}
+		allowed, ok := expandable[className]
+		if !ok {
+			allowed, err = r.allowsExpansion(ctx, className)
+			if err != nil {
+				return err
+			}
+			expandable[className] = allowed
+		}
+		if !allowed {
+			blocked = append(blocked, pvc.Name)
+			continue
+		}
+
+		r.logger.Info("Expanding volume", "claim", pvc.Name, "from", current.String(), "to", capacity.String())
+		base := pvc.DeepCopy()
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = capacity
+		if err := r.Patch(ctx, pvc, k8sclient.MergeFrom(base)); err != nil {
+			return fmt.Errorf("unable to expand PersistentVolumeClaim %s: %w", pvc.Name, err)
+		}
+	}
+
+	return r.reportBlocked(ctx, blocked)
+}
+
+// allowsExpansion returns true if the storage class allows volume expansion
+func (r *VolumeExpander) allowsExpansion(
+	ctx context.Context, className string,
+) (bool, error) {
+	if className == "" {
+		return false, nil
+	}
+	var sc storagev1.StorageClass
+	err := r.Get(ctx, types.NamespacedName{Name: className}, &sc)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch StorageClass %s: %w", className, err)
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// reportBlocked sets the VolumeExpansionBlocked condition. The reconciliation
+// isn't held, as the capacity can't be decreased back.
+func (r *VolumeExpander) reportBlocked(
+	ctx context.Context, claims []string,
+) error {
+	condition := metav1.Condition{
+		Type:               redpandav1alpha1.VolumeExpansionBlockedConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             "VolumesExpanded",
+		Message:            "Data directory volumes have the requested capacity",
+		ObservedGeneration: r.pandaCluster.Generation,
+	}
+	if len(claims) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ExpansionNotAllowed"
+		condition.Message = fmt.Sprintf("storage class doesn't allow volume expansion of %s to %s",
+			strings.Join(claims, ", "), r.pandaCluster.Spec.Storage.Capacity.String())
+	}
+
+	existing := meta.FindStatusCondition(r.pandaCluster.Status.Conditions, condition.Type)
+	if existing == nil && len(claims) == 0 {
+		return nil
+	}
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return nil
+	}
+	if len(claims) > 0 {
+		r.recorder.Event(r.pandaCluster, corev1.EventTypeWarning, "VolumeExpansionNotAllowed", condition.Message)
+	}
+	meta.SetStatusCondition(&r.pandaCluster.Status.Conditions, condition)
+	return r.Status().Update(ctx, r.pandaCluster)
+}
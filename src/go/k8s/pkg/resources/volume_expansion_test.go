@@ -0,0 +1,111 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/labels"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVolumeExpander(t *testing.T) {
+	require.NoError(t, redpandav1alpha1.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name             string
+		capacity         string
+		allowExpansion   bool
+		expectedCapacity string
+		expectBlocked    bool
+	}{
+		{"growth is patched", "20Gi", true, "20Gi", false},
+		{"unchanged capacity is not patched", "10Gi", true, "10Gi", false},
+		{"claims are never shrunk", "5Gi", true, "10Gi", false},
+		{"growth without volume expansion is reported", "20Gi", false, "10Gi", true},
+		{"unchanged capacity without volume expansion", "10Gi", false, "10Gi", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := pandaCluster()
+			cluster.Spec.Replicas = pointer.Int32Ptr(2)
+			cluster.Spec.Storage.StorageClassName = "standard"
+			cluster.Spec.Storage.Capacity = resource.MustParse(tt.capacity)
+
+			objs := []client.Object{
+				cluster,
+				&storagev1.StorageClass{
+					ObjectMeta:           metav1.ObjectMeta{Name: "standard"},
+					Provisioner:          "ebs.csi.aws.com",
+					AllowVolumeExpansion: pointer.BoolPtr(tt.allowExpansion),
+				},
+			}
+			for ordinal := 0; ordinal < 2; ordinal++ {
+				objs = append(objs, &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("datadir-%s-%d", cluster.Name, ordinal),
+						Namespace: cluster.Namespace,
+						Labels:    labels.ForCluster(cluster),
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: pointer.StringPtr("standard"),
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse("10Gi"),
+							},
+						},
+					},
+				})
+			}
+			c := fake.NewClientBuilder().WithObjects(objs...).Build()
+			recorder := record.NewFakeRecorder(10)
+
+			err := res.NewVolumeExpander(c, cluster, recorder, ctrl.Log.WithName("test")).Ensure(context.Background())
+			require.NoError(t, err)
+
+			for ordinal := 0; ordinal < 2; ordinal++ {
+				var pvc corev1.PersistentVolumeClaim
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{
+					Name:      fmt.Sprintf("datadir-%s-%d", cluster.Name, ordinal),
+					Namespace: cluster.Namespace,
+				}, &pvc))
+				actual := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				assert.Equal(t, tt.expectedCapacity, actual.String())
+			}
+
+			var actualCluster redpandav1alpha1.Cluster
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, &actualCluster))
+			blocked := meta.IsStatusConditionTrue(actualCluster.Status.Conditions, redpandav1alpha1.VolumeExpansionBlockedConditionType)
+			assert.Equal(t, tt.expectBlocked, blocked)
+			if tt.expectBlocked {
+				assert.Len(t, recorder.Events, 1)
+			} else {
+				assert.Len(t, recorder.Events, 0)
+			}
+		})
+	}
+}
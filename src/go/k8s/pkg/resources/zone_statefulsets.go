@@ -0,0 +1,82 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources
+
+import (
+	"fmt"
+
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+)
+
+const (
+	// ZoneLabel tells apart the Pods of the zone StatefulSets, the value is
+	// the zone
+	ZoneLabel = "redpanda.vectorized.io/zone"
+	// ZoneNodeIDStride separates the node IDs of the zone StatefulSets. The
+	// broker with ordinal k in the zone listed at index i gets node ID
+	// i*ZoneNodeIDStride+k, so the broker with node ID 0, which forms the
+	// cluster, is the first broker of the first zone.
+	ZoneNodeIDStride = 1000
+)
+
+// BrokerStatefulSet is one of the StatefulSets running the brokers of the
+// cluster
+type BrokerStatefulSet struct {
+	Name string
+	// Zone is empty unless the cluster runs a StatefulSet per zone
+	Zone         string
+	Replicas     int32
+	NodeIDOffset int32
+}
+
+// BrokerStatefulSets returns the StatefulSets of the brokers. With zone
+// StatefulSets the replicas of the cluster are split evenly over the zones
+// and the zones listed first get the remainder, so scaling the cluster by
+// one broker changes the replicas of a single StatefulSet.
+func BrokerStatefulSets(
+	pandaCluster *redpandav1alpha1.Cluster,
+) []BrokerStatefulSet {
+	var replicas int32
+	if pandaCluster.Spec.Replicas != nil {
+		replicas = *pandaCluster.Spec.Replicas
+	}
+	zones := pandaCluster.StatefulSetZones()
+	if len(zones) == 0 {
+		return []BrokerStatefulSet{{Name: pandaCluster.Name, Replicas: replicas}}
+	}
+
+	sets := make([]BrokerStatefulSet, 0, len(zones))
+	count := int32(len(zones))
+	for i, zone := range zones {
+		zoneReplicas := replicas / count
+		if int32(i) < replicas%count {
+			zoneReplicas++
+		}
+		sets = append(sets, BrokerStatefulSet{
+			Name:         pandaCluster.Name + "-" + zone,
+			Zone:         zone,
+			Replicas:     zoneReplicas,
+			NodeIDOffset: int32(i) * ZoneNodeIDStride,
+		})
+	}
+	return sets
+}
+
+// BrokerPodNames returns the names of the Pods of all brokers, the broker
+// with node ID 0 comes first
+func BrokerPodNames(pandaCluster *redpandav1alpha1.Cluster) []string {
+	var names []string
+	for _, set := range BrokerStatefulSets(pandaCluster) {
+		for ordinal := int32(0); ordinal < set.Replicas; ordinal++ {
+			names = append(names, fmt.Sprintf("%s-%d", set.Name, ordinal))
+		}
+	}
+	return names
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redpandav1alpha1 "github.com/vectorizedio/redpanda/src/go/k8s/apis/redpanda/v1alpha1"
+	res "github.com/vectorizedio/redpanda/src/go/k8s/pkg/resources"
+	"k8s.io/utils/pointer"
+)
+
+func TestBrokerStatefulSets(t *testing.T) {
+	cluster := pandaCluster()
+	cluster.Spec.Replicas = pointer.Int32Ptr(3)
+	assert.Equal(t, []res.BrokerStatefulSet{{Name: "cluster", Replicas: 3}}, res.BrokerStatefulSets(cluster))
+	assert.Equal(t, []string{"cluster-0", "cluster-1", "cluster-2"}, res.BrokerPodNames(cluster))
+
+	cluster.Spec.Replicas = pointer.Int32Ptr(5)
+	cluster.Spec.ZoneStatefulSets = &redpandav1alpha1.ZoneStatefulSetsConfig{Zones: []string{"a", "b", "c"}}
+	assert.Equal(t, []res.BrokerStatefulSet{
+		{Name: "cluster-a", Zone: "a", Replicas: 2, NodeIDOffset: 0},
+		{Name: "cluster-b", Zone: "b", Replicas: 2, NodeIDOffset: 1000},
+		{Name: "cluster-c", Zone: "c", Replicas: 1, NodeIDOffset: 2000},
+	}, res.BrokerStatefulSets(cluster))
+	assert.Equal(t, []string{
+		"cluster-a-0", "cluster-a-1",
+		"cluster-b-0", "cluster-b-1",
+		"cluster-c-0",
+	}, res.BrokerPodNames(cluster))
+
+	// scaling by one broker changes a single StatefulSet
+	before := res.BrokerStatefulSets(cluster)
+	cluster.Spec.Replicas = pointer.Int32Ptr(6)
+	after := res.BrokerStatefulSets(cluster)
+	changed := 0
+	for i := range before {
+		if before[i].Replicas != after[i].Replicas {
+			changed++
+		}
+	}
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, int32(2), after[2].Replicas)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// Package version parses Redpanda versions and decides which version
+// transitions are safe to roll out
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownVersion is returned for tags that don't name a release,
+	// e.g. latest or dev
+	ErrUnknownVersion = errors.New("not a release version")
+	// ErrDowngrade is returned by CheckTransition if the desired version is
+	// older than the current one. The on-disk format is not guaranteed to
+	// be readable by older versions.
+	ErrDowngrade = errors.New("downgrade is not allowed")
+	// ErrMajorUpgrade is returned by CheckTransition if the desired version
+	// skips a major version
+	ErrMajorUpgrade = errors.New("upgrade across more than one major version is not allowed")
+)
+
+// Version is a Redpanda release, e.g. v21.4.12. The major version is the
+// year of the release.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// Parse parses the version reported by the Admin API, e.g.
+// "v21.4.12 (rev 6c1b5f6)", or the image tag, e.g. "v21.4.12-beta1". The
+// pre-release and build suffixes are ignored, the patch defaults to 0.
+func Parse(s string) (Version, error) {
+	v := strings.TrimSpace(s)
+	if i := strings.IndexByte(v, ' '); i >= 0 {
+		v = v[:i]
+	}
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("%q: %w", s, ErrUnknownVersion)
+	}
+	numbers := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("%q: %w", s, ErrUnknownVersion)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Compare returns -1, 0 or 1 if the version is older, the same or newer
+// than the other one
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// CheckTransition returns an error if the brokers running the current
+// version can't be safely rolled to the desired version
+func CheckTransition(current, desired Version) error {
+	if desired.Compare(current) < 0 {
+		return fmt.Errorf("%s to %s: %w", current, desired, ErrDowngrade)
+	}
+	if desired.Major-current.Major > 1 {
+		return fmt.Errorf("%s to %s: %w", current, desired, ErrMajorUpgrade)
+	}
+	return nil
+}
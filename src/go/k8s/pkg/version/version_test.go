@@ -0,0 +1,85 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/k8s/pkg/version"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected version.Version
+		err      error
+	}{
+		{"v21.4.12", version.Version{Major: 21, Minor: 4, Patch: 12}, nil},
+		{"21.4.12", version.Version{Major: 21, Minor: 4, Patch: 12}, nil},
+		{"v21.4.12 (rev 6c1b5f6)", version.Version{Major: 21, Minor: 4, Patch: 12}, nil},
+		{"v21.5.1-beta2", version.Version{Major: 21, Minor: 5, Patch: 1}, nil},
+		{"v21.5.1-dev-a1b2c3d", version.Version{Major: 21, Minor: 5, Patch: 1}, nil},
+		{"v21.5.1+build.7", version.Version{Major: 21, Minor: 5, Patch: 1}, nil},
+		{"v21.6", version.Version{Major: 21, Minor: 6}, nil},
+		{"latest", version.Version{}, version.ErrUnknownVersion},
+		{"dev", version.Version{}, version.ErrUnknownVersion},
+		{"", version.Version{}, version.ErrUnknownVersion},
+		{"v21", version.Version{}, version.ErrUnknownVersion},
+		{"v21.4.x", version.Version{}, version.ErrUnknownVersion},
+		{"v21.4.12.1", version.Version{}, version.ErrUnknownVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := version.Parse(tt.input)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "expecting %v, got %v", tt.err, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		err     error
+	}{
+		{"same version", "v21.4.12", "v21.4.12", nil},
+		{"patch upgrade", "v21.4.12", "v21.4.13", nil},
+		{"minor upgrade", "v21.4.12", "v21.5.1", nil},
+		{"major upgrade", "v21.11.3", "v22.1.1", nil},
+		{"pre-release of running version", "v21.5.1", "v21.5.1-rc1", nil},
+		{"patch downgrade", "v21.4.12", "v21.4.11", version.ErrDowngrade},
+		{"minor downgrade", "v21.5.1", "v21.4.12", version.ErrDowngrade},
+		{"major downgrade", "v22.1.1", "v21.11.3", version.ErrDowngrade},
+		{"multi-major upgrade", "v21.11.3", "v23.1.1", version.ErrMajorUpgrade},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := version.Parse(tt.current)
+			require.NoError(t, err)
+			desired, err := version.Parse(tt.desired)
+			require.NoError(t, err)
+			err = version.CheckTransition(current, desired)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.err), "expecting %v, got %v", tt.err, err)
+		})
+	}
+}